@@ -0,0 +1 @@
+ALTER TABLE jobs ADD COLUMN partial boolean NOT NULL DEFAULT false;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS "job_progress";
\ No newline at end of file
@@ -0,0 +1,15 @@
+CREATE TABLE
+    job_progress (
+        job_id bigint PRIMARY KEY,
+        total integer NOT NULL DEFAULT 0,
+        parsed integer NOT NULL DEFAULT 0,
+        lang_detected integer NOT NULL DEFAULT 0,
+        embedded integer NOT NULL DEFAULT 0,
+        sentiment_scored integer NOT NULL DEFAULT 0,
+        failed integer NOT NULL DEFAULT 0,
+        updated_at timestamptz NOT NULL DEFAULT (now())
+    );
+
+ALTER TABLE job_progress
+ADD
+    FOREIGN KEY (job_id) REFERENCES jobs (id) ON DELETE CASCADE ON UPDATE CASCADE;
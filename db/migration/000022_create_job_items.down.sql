@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "job_items";
+
+DROP TYPE IF EXISTS "job_item_stage";
\ No newline at end of file
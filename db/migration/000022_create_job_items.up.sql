@@ -0,0 +1,31 @@
+CREATE TYPE "job_item_stage" AS ENUM (
+    'pending',
+    'retrying',
+    'done',
+    'dead'
+);
+
+CREATE TABLE
+    job_items (
+        id bigserial PRIMARY KEY,
+        job_id bigint NOT NULL,
+        news_id bigint NOT NULL,
+        stage job_item_stage NOT NULL DEFAULT 'pending',
+        attempts integer NOT NULL DEFAULT 0,
+        last_error text DEFAULT null,
+        next_attempt_at timestamptz NOT NULL DEFAULT (now()),
+        created_at timestamptz NOT NULL DEFAULT (now()),
+        updated_at timestamptz NOT NULL DEFAULT (now())
+    );
+
+CREATE UNIQUE INDEX ON job_items (job_id, news_id);
+
+CREATE INDEX ON job_items (job_id, stage, next_attempt_at);
+
+ALTER TABLE job_items
+ADD
+    FOREIGN KEY (job_id) REFERENCES jobs (id) ON DELETE CASCADE ON UPDATE CASCADE;
+
+ALTER TABLE job_items
+ADD
+    FOREIGN KEY (news_id) REFERENCES news (id) ON DELETE CASCADE ON UPDATE CASCADE;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "job_schedules";
+
+ALTER TABLE jobs DROP COLUMN IF EXISTS parent_id;
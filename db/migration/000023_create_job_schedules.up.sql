@@ -0,0 +1,24 @@
+ALTER TABLE jobs ADD COLUMN parent_id bigint DEFAULT null;
+
+ALTER TABLE jobs
+ADD
+    FOREIGN KEY (parent_id) REFERENCES jobs (id) ON DELETE SET NULL ON UPDATE CASCADE;
+
+CREATE INDEX ON jobs (parent_id);
+
+CREATE TABLE
+    job_schedules (
+        job_id bigint PRIMARY KEY,
+        cron_expr varchar(64) NOT NULL,
+        src_cache_query json NOT NULL,
+        next_run_at timestamptz NOT NULL,
+        last_run_at timestamptz DEFAULT null,
+        created_at timestamptz NOT NULL DEFAULT (now()),
+        updated_at timestamptz NOT NULL DEFAULT (now())
+    );
+
+CREATE INDEX ON job_schedules (next_run_at);
+
+ALTER TABLE job_schedules
+ADD
+    FOREIGN KEY (job_id) REFERENCES jobs (id) ON DELETE CASCADE ON UPDATE CASCADE;
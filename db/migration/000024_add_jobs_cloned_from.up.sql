@@ -0,0 +1,7 @@
+ALTER TABLE jobs ADD COLUMN cloned_from bigint DEFAULT null;
+
+ALTER TABLE jobs
+ADD
+    FOREIGN KEY (cloned_from) REFERENCES jobs (id) ON DELETE SET NULL ON UPDATE CASCADE;
+
+CREATE INDEX ON jobs (cloned_from);
@@ -0,0 +1,11 @@
+DROP TRIGGER IF EXISTS jobs_status_webhook ON jobs;
+
+DROP FUNCTION IF EXISTS enqueue_webhook_deliveries;
+
+DROP TABLE IF EXISTS "webhook_deliveries";
+
+DROP TABLE IF EXISTS "webhooks";
+
+DROP TABLE IF EXISTS "webhook_secrets";
+
+DROP TYPE IF EXISTS "webhook_delivery_stage";
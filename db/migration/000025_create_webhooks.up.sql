@@ -0,0 +1,100 @@
+CREATE TYPE "webhook_delivery_stage" AS ENUM (
+    'pending',
+    'retrying',
+    'delivered',
+    'failed'
+);
+
+CREATE TABLE
+    webhook_secrets (
+        owner uuid PRIMARY KEY,
+        secret varchar(64) NOT NULL,
+        created_at timestamptz NOT NULL DEFAULT (now()),
+        updated_at timestamptz NOT NULL DEFAULT (now())
+    );
+
+ALTER TABLE webhook_secrets
+ADD
+    FOREIGN KEY (owner) REFERENCES users (id) ON DELETE CASCADE ON UPDATE CASCADE;
+
+CREATE TABLE
+    webhooks (
+        id bigserial PRIMARY KEY,
+        owner uuid NOT NULL,
+        url varchar(2048) NOT NULL,
+        created_at timestamptz NOT NULL DEFAULT (now())
+    );
+
+CREATE UNIQUE INDEX ON webhooks (owner, url);
+
+ALTER TABLE webhooks
+ADD
+    FOREIGN KEY (owner) REFERENCES users (id) ON DELETE CASCADE ON UPDATE CASCADE;
+
+CREATE TABLE
+    webhook_deliveries (
+        id bigserial PRIMARY KEY,
+        webhook_id bigint NOT NULL,
+        job_id bigint NOT NULL,
+        status job_status NOT NULL,
+        payload json NOT NULL,
+        stage webhook_delivery_stage NOT NULL DEFAULT 'pending',
+        attempts integer NOT NULL DEFAULT 0,
+        response_code integer DEFAULT null,
+        last_error text DEFAULT null,
+        next_attempt_at timestamptz NOT NULL DEFAULT (now()),
+        created_at timestamptz NOT NULL DEFAULT (now()),
+        updated_at timestamptz NOT NULL DEFAULT (now())
+    );
+
+CREATE INDEX ON webhook_deliveries (stage, next_attempt_at);
+
+CREATE INDEX ON webhook_deliveries (webhook_id);
+
+ALTER TABLE webhook_deliveries
+ADD
+    FOREIGN KEY (webhook_id) REFERENCES webhooks (id) ON DELETE CASCADE ON UPDATE CASCADE;
+
+ALTER TABLE webhook_deliveries
+ADD
+    FOREIGN KEY (job_id) REFERENCES jobs (id) ON DELETE CASCADE ON UPDATE CASCADE;
+
+-- queue a delivery to each webhook of the owner whenever the status of a job
+-- changes, the payload is built here so that it reflects the job at the time
+-- of the change.
+CREATE FUNCTION enqueue_webhook_deliveries() RETURNS trigger AS $$
+BEGIN
+    INSERT INTO
+        webhook_deliveries (webhook_id, job_id, status, payload)
+    SELECT
+        w.id,
+        NEW.id,
+        NEW.status,
+        json_build_object(
+            'event', 'job.status_changed',
+            'job_id', NEW.id,
+            'ulid', NEW.ulid,
+            'status', NEW.status,
+            'previous_status', OLD.status,
+            'summary', json_build_object(
+                'total', COALESCE(p.total, 0),
+                'parsed', COALESCE(p.parsed, 0),
+                'lang_detected', COALESCE(p.lang_detected, 0),
+                'embedded', COALESCE(p.embedded, 0),
+                'sentiment_scored', COALESCE(p.sentiment_scored, 0),
+                'failed', COALESCE(p.failed, 0)
+            ),
+            'changed_at', CURRENT_TIMESTAMP
+        )
+    FROM webhooks AS w
+        LEFT JOIN job_progress AS p ON p.job_id = NEW.id
+    WHERE w.owner = NEW.owner;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER jobs_status_webhook
+AFTER UPDATE OF status ON jobs
+FOR EACH ROW
+WHEN (OLD.status IS DISTINCT FROM NEW.status)
+EXECUTE FUNCTION enqueue_webhook_deliveries();
@@ -0,0 +1,20 @@
+DROP FUNCTION IF EXISTS sentiment_score(sentiment);
+
+ALTER TABLE embeddings DROP COLUMN IF EXISTS confidence;
+
+ALTER TABLE embeddings DROP COLUMN IF EXISTS score;
+
+UPDATE embeddings SET sentiment = 'positive' WHERE sentiment = 'very_positive';
+
+UPDATE embeddings SET sentiment = 'negative' WHERE sentiment = 'very_negative';
+
+-- enum values can not be dropped, recreate the type instead
+ALTER TYPE sentiment RENAME TO sentiment_old;
+
+CREATE TYPE sentiment AS ENUM ('positive', 'neutral', 'negative');
+
+ALTER TABLE embeddings
+ALTER COLUMN
+    sentiment TYPE sentiment USING sentiment:: text:: sentiment;
+
+DROP TYPE sentiment_old;
@@ -0,0 +1,26 @@
+ALTER TYPE sentiment ADD VALUE IF NOT EXISTS 'very_positive' BEFORE 'positive';
+
+ALTER TYPE sentiment ADD VALUE IF NOT EXISTS 'very_negative' AFTER 'negative';
+
+ALTER TABLE embeddings
+ADD
+    COLUMN score smallint DEFAULT null CHECK (score BETWEEN 1 AND 5),
+ADD
+    COLUMN confidence real DEFAULT null CHECK (
+        confidence BETWEEN 0 AND 1
+    );
+
+-- the score of a sentiment on the 1-5 scale, rows analyzed before the scale
+-- was introduced only have positive, neutral or negative.
+CREATE OR REPLACE FUNCTION sentiment_score(s sentiment) RETURNS smallint
+LANGUAGE sql IMMUTABLE AS $$
+    SELECT (
+        CASE s::text
+            WHEN 'very_negative' THEN 1
+            WHEN 'negative' THEN 2
+            WHEN 'neutral' THEN 3
+            WHEN 'positive' THEN 4
+            WHEN 'very_positive' THEN 5
+        END
+    )::smallint;
+$$;
@@ -0,0 +1,2 @@
+DELETE FROM apis WHERE id = 7;
+ALTER SEQUENCE apis_id_seq RESTART WITH 7;
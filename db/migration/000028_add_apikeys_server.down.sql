@@ -0,0 +1,5 @@
+ALTER TABLE apikeys DROP COLUMN IF EXISTS auth_header;
+
+ALTER TABLE apikeys DROP COLUMN IF EXISTS models;
+
+ALTER TABLE apikeys DROP COLUMN IF EXISTS base_url;
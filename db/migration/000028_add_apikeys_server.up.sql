@@ -0,0 +1,9 @@
+-- an OpenAI compatible server, e.g. a self-hosted llama.cpp server, vLLM or
+-- LM Studio, used instead of the default server of the API
+ALTER TABLE apikeys
+ADD
+    COLUMN base_url varchar(256) DEFAULT null,
+ADD
+    COLUMN models varchar(64) [] DEFAULT '{}' NOT NULL,
+ADD
+    COLUMN auth_header varchar(64) DEFAULT null;
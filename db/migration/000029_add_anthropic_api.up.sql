@@ -0,0 +1,6 @@
+INSERT INTO apis (
+    id, name, type, image, icon, document_url, created_at, updated_at
+) VALUES 
+    (8, 'Anthropic', 'language_model', 'logo_Default.svg', 'favicon_Default.svg', 'https://docs.anthropic.com/claude/reference/messages_post', '2020-01-01 00:00:00', '2020-01-01 00:00:00');
+
+ALTER SEQUENCE apis_id_seq RESTART WITH 9;
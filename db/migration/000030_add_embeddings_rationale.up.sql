@@ -0,0 +1,2 @@
+-- why the model gives the sentiment, for the analysts to audit
+ALTER TABLE embeddings ADD COLUMN rationale text DEFAULT null;
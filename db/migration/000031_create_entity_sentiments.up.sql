@@ -0,0 +1,23 @@
+CREATE TABLE
+    entity_sentiments (
+        id bigserial PRIMARY KEY,
+        news_id bigint NOT NULL,
+        entity varchar(64) NOT NULL,
+        model varchar(32) NOT NULL,
+        sentiment sentiment NOT NULL,
+        score smallint NOT NULL CHECK (score BETWEEN 1 AND 5),
+        confidence real DEFAULT null CHECK (
+            confidence BETWEEN 0 AND 1
+        ),
+        rationale text DEFAULT null,
+        created_at timestamptz NOT NULL DEFAULT (now()),
+        updated_at timestamptz NOT NULL DEFAULT (now())
+    );
+
+CREATE UNIQUE INDEX ON entity_sentiments (news_id, entity, model);
+
+CREATE INDEX ON entity_sentiments (entity, model);
+
+ALTER TABLE entity_sentiments
+ADD
+    FOREIGN KEY (news_id) REFERENCES news (id) ON DELETE CASCADE ON UPDATE CASCADE;
@@ -0,0 +1,22 @@
+CREATE TABLE
+    annotations (
+        id bigserial PRIMARY KEY,
+        news_id bigint NOT NULL,
+        task varchar(64) NOT NULL,
+        label varchar(64) NOT NULL,
+        model varchar(32) NOT NULL,
+        confidence real DEFAULT null CHECK (
+            confidence BETWEEN 0 AND 1
+        ),
+        rationale text DEFAULT null,
+        created_at timestamptz NOT NULL DEFAULT (now()),
+        updated_at timestamptz NOT NULL DEFAULT (now())
+    );
+
+CREATE UNIQUE INDEX ON annotations (news_id, task, model);
+
+CREATE INDEX ON annotations (task, model);
+
+ALTER TABLE annotations
+ADD
+    FOREIGN KEY (news_id) REFERENCES news (id) ON DELETE CASCADE ON UPDATE CASCADE;
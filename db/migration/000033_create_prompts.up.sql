@@ -0,0 +1,16 @@
+CREATE TABLE
+    prompts (
+        id bigserial PRIMARY KEY,
+        owner uuid NOT NULL,
+        provider varchar(32) NOT NULL,
+        task varchar(32) NOT NULL,
+        version integer NOT NULL CHECK (version > 0),
+        body text NOT NULL,
+        created_at timestamptz NOT NULL DEFAULT (now())
+    );
+
+CREATE UNIQUE INDEX ON prompts (owner, provider, task, version);
+
+ALTER TABLE prompts
+ADD
+    FOREIGN KEY (owner) REFERENCES users (id) ON DELETE CASCADE ON UPDATE CASCADE;
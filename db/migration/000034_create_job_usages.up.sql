@@ -0,0 +1,18 @@
+CREATE TABLE
+    job_usages (
+        id bigserial PRIMARY KEY,
+        job_id bigint NOT NULL,
+        provider varchar(32) NOT NULL,
+        model varchar(64) NOT NULL,
+        operation varchar(32) NOT NULL,
+        input_tokens integer NOT NULL DEFAULT 0 CHECK (input_tokens >= 0),
+        output_tokens integer NOT NULL DEFAULT 0 CHECK (output_tokens >= 0),
+        cost double precision DEFAULT null,
+        created_at timestamptz NOT NULL DEFAULT (now())
+    );
+
+CREATE INDEX ON job_usages (job_id);
+
+ALTER TABLE job_usages
+ADD
+    FOREIGN KEY (job_id) REFERENCES jobs (id) ON DELETE CASCADE ON UPDATE CASCADE;
@@ -0,0 +1,20 @@
+CREATE TABLE
+    llm_responses (
+        provider varchar(32) NOT NULL,
+        model varchar(64) NOT NULL,
+        operation varchar(32) NOT NULL,
+        prompt_id bigint NOT NULL DEFAULT 0,
+        input_hash char(64) NOT NULL,
+        response jsonb NOT NULL,
+        expires_at timestamptz NOT NULL,
+        created_at timestamptz NOT NULL DEFAULT (now()),
+        PRIMARY KEY (
+            provider,
+            model,
+            operation,
+            prompt_id,
+            input_hash
+        )
+    );
+
+CREATE INDEX ON llm_responses (expires_at);
@@ -0,0 +1,35 @@
+DROP INDEX IF EXISTS embeddings_char_ngram_hash_256_idx;
+
+DROP INDEX IF EXISTS embeddings_multilingual_light_v3_idx;
+
+DROP INDEX IF EXISTS embeddings_english_light_v3_idx;
+
+DROP INDEX IF EXISTS embeddings_multilingual_v3_idx;
+
+DROP INDEX IF EXISTS embeddings_english_v3_idx;
+
+DROP INDEX IF EXISTS embeddings_3_small_idx;
+
+DROP INDEX IF EXISTS embeddings_ada002_idx;
+
+ALTER TABLE embeddings DROP CONSTRAINT IF EXISTS embeddings_model_fkey;
+
+-- vectors larger than the old column can not be kept
+DELETE FROM embeddings WHERE vector_dims(embedding) > 1536;
+
+UPDATE embeddings
+SET
+    embedding = (
+        embedding:: real [] || array_fill(
+            0:: real,
+            ARRAY [1536 - vector_dims(embedding)]
+        )
+    ):: vector
+WHERE
+    vector_dims(embedding) < 1536;
+
+ALTER TABLE embeddings ALTER COLUMN embedding TYPE vector(1536);
+
+CREATE INDEX embeddings_embedding_idx ON embeddings USING hnsw (embedding vector_ip_ops);
+
+DROP TABLE IF EXISTS "embedding_models";
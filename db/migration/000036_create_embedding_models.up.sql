@@ -0,0 +1,134 @@
+-- the embedding models and the size and the metric of their vectors, an
+-- embedding is stored at the native size of its model instead of being padded
+CREATE TABLE
+    embedding_models (
+        name varchar(32) PRIMARY KEY,
+        provider varchar(32) NOT NULL,
+        dim integer NOT NULL CHECK (dim > 0),
+        metric varchar(16) NOT NULL CHECK (
+            metric IN ('cosine', 'inner_product', 'l2')
+        ),
+        created_at timestamptz NOT NULL DEFAULT (now())
+    );
+
+INSERT INTO
+    embedding_models (name, provider, dim, metric)
+VALUES (
+        'text-embedding-ada-002',
+        'openai',
+        1536,
+        'cosine'
+    ), (
+        'text-embedding-3-small',
+        'openai',
+        1536,
+        'cosine'
+    ), (
+        'text-embedding-3-large',
+        'openai',
+        3072,
+        'cosine'
+    ), (
+        'embed-english-v3.0',
+        'cohere',
+        1024,
+        'cosine'
+    ), (
+        'embed-multilingual-v3.0',
+        'cohere',
+        1024,
+        'cosine'
+    ), (
+        'embed-english-light-v3.0',
+        'cohere',
+        384,
+        'cosine'
+    ), (
+        'embed-multilingual-light-v3.0',
+        'cohere',
+        384,
+        'cosine'
+    ), (
+        'char-ngram-hash-256',
+        'lexicon',
+        256,
+        'cosine'
+    );
+
+-- the models of the existing rows that are not known keep the old size
+INSERT INTO
+    embedding_models (name, provider, dim, metric)
+SELECT DISTINCT
+    model,
+    'unknown',
+    1536,
+    'inner_product'
+FROM embeddings
+WHERE
+    model NOT IN (
+        SELECT name
+        FROM embedding_models
+    );
+
+DROP INDEX IF EXISTS embeddings_embedding_idx;
+
+ALTER TABLE embeddings ALTER COLUMN embedding TYPE vector;
+
+-- the existing rows are padded with zeros to 1536, cut them back to the size
+-- of their model
+UPDATE embeddings AS e
+SET
+    embedding = (e.embedding:: real []) [1:m.dim]:: vector
+FROM embedding_models AS m
+WHERE
+    e.model = m.name
+    AND vector_dims(e.embedding) > m.dim;
+
+ALTER TABLE embeddings
+ADD
+    FOREIGN KEY (model) REFERENCES embedding_models (name) ON UPDATE CASCADE;
+
+-- an index is built on vectors of a fixed size, so there is one per model.
+-- A search must use the same cast and filter on the model to use it. hnsw
+-- indexes up to 2000 dimensions, text-embedding-3-large is not indexed.
+CREATE INDEX embeddings_ada002_idx ON embeddings USING hnsw (
+    (embedding:: vector(1536)) vector_cosine_ops
+)
+WHERE
+    model = 'text-embedding-ada-002';
+
+CREATE INDEX embeddings_3_small_idx ON embeddings USING hnsw (
+    (embedding:: vector(1536)) vector_cosine_ops
+)
+WHERE
+    model = 'text-embedding-3-small';
+
+CREATE INDEX embeddings_english_v3_idx ON embeddings USING hnsw (
+    (embedding:: vector(1024)) vector_cosine_ops
+)
+WHERE
+    model = 'embed-english-v3.0';
+
+CREATE INDEX embeddings_multilingual_v3_idx ON embeddings USING hnsw (
+    (embedding:: vector(1024)) vector_cosine_ops
+)
+WHERE
+    model = 'embed-multilingual-v3.0';
+
+CREATE INDEX embeddings_english_light_v3_idx ON embeddings USING hnsw (
+    (embedding:: vector(384)) vector_cosine_ops
+)
+WHERE
+    model = 'embed-english-light-v3.0';
+
+CREATE INDEX embeddings_multilingual_light_v3_idx ON embeddings USING hnsw (
+    (embedding:: vector(384)) vector_cosine_ops
+)
+WHERE
+    model = 'embed-multilingual-light-v3.0';
+
+CREATE INDEX embeddings_char_ngram_hash_256_idx ON embeddings USING hnsw (
+    (embedding:: vector(256)) vector_cosine_ops
+)
+WHERE
+    model = 'char-ngram-hash-256';
@@ -0,0 +1,21 @@
+-- the vectors of the chunks of the news, which are split along the paragraphs
+-- under the input limit of the model. The vector in embeddings is pooled from
+-- them.
+CREATE TABLE
+    embedding_chunks (
+        news_id bigint NOT NULL,
+        model varchar(32) NOT NULL,
+        chunk_index smallint NOT NULL CHECK (chunk_index >= 0),
+        n_tokens integer NOT NULL CHECK (n_tokens >= 0),
+        embedding vector NOT NULL,
+        created_at timestamptz NOT NULL DEFAULT (now()),
+        PRIMARY KEY (news_id, model, chunk_index)
+    );
+
+ALTER TABLE embedding_chunks
+ADD
+    FOREIGN KEY (news_id) REFERENCES news (id) ON DELETE CASCADE ON UPDATE CASCADE;
+
+ALTER TABLE embedding_chunks
+ADD
+    FOREIGN KEY (model) REFERENCES embedding_models (name) ON UPDATE CASCADE;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "story_news";
+
+DROP TABLE IF EXISTS "stories";
@@ -0,0 +1,41 @@
+-- the news of different sources covering the same event, grouped by the
+-- similarity of their embeddings of a model and their publish time
+CREATE TABLE
+    stories (
+        id bigserial PRIMARY KEY,
+        model varchar(32) NOT NULL,
+        -- the headline of the first news of the story
+        title text NOT NULL,
+        first_publish_at timestamptz NOT NULL,
+        last_publish_at timestamptz NOT NULL,
+        created_at timestamptz NOT NULL DEFAULT (now()),
+        updated_at timestamptz NOT NULL DEFAULT (now())
+    );
+
+CREATE INDEX ON stories (last_publish_at);
+
+ALTER TABLE stories
+ADD
+    FOREIGN KEY (model) REFERENCES embedding_models (name) ON UPDATE CASCADE;
+
+-- a news is in at most one story of a model, similarity is the mean cosine
+-- similarity to the news of the story when it joined
+CREATE TABLE
+    story_news (
+        story_id bigint NOT NULL,
+        news_id bigint NOT NULL,
+        model varchar(32) NOT NULL,
+        similarity real NOT NULL DEFAULT 1,
+        created_at timestamptz NOT NULL DEFAULT (now()),
+        PRIMARY KEY (story_id, news_id)
+    );
+
+CREATE UNIQUE INDEX ON story_news (model, news_id);
+
+ALTER TABLE story_news
+ADD
+    FOREIGN KEY (story_id) REFERENCES stories (id) ON DELETE CASCADE ON UPDATE CASCADE;
+
+ALTER TABLE story_news
+ADD
+    FOREIGN KEY (news_id) REFERENCES news (id) ON DELETE CASCADE ON UPDATE CASCADE;
@@ -0,0 +1 @@
+ALTER TABLE job_items DROP COLUMN IF EXISTS embedding_id;
@@ -0,0 +1,7 @@
+-- the embedding, and so the sentiment, the job got for the news, which may
+-- have been made by another job. The items done before are left null.
+ALTER TABLE job_items ADD COLUMN embedding_id bigint DEFAULT null;
+
+ALTER TABLE job_items
+ADD
+    FOREIGN KEY (embedding_id) REFERENCES embeddings (id) ON DELETE SET NULL ON UPDATE CASCADE;
@@ -0,0 +1,35 @@
+UPDATE jobs
+SET
+    llm_query = jsonb_set(
+        llm_query:: jsonb,
+        '{embedding-options,embedding_model}',
+        '"char-ngram-hash-256"'
+    ):: json
+WHERE
+    llm_query:: jsonb #>> '{embedding-options,embedding_model}' = 'anthropic-char-ngram-hash-256';
+
+-- the chunks of the news embedded by both analyzers are kept once
+DELETE FROM embedding_chunks AS a USING embedding_chunks AS l
+WHERE
+    a.model = 'anthropic-char-ngram-hash-256'
+    AND l.model = 'char-ngram-hash-256'
+    AND l.news_id = a.news_id
+    AND l.chunk_index = a.chunk_index;
+
+UPDATE embedding_chunks
+SET
+    model = 'char-ngram-hash-256'
+WHERE
+    model = 'anthropic-char-ngram-hash-256';
+
+UPDATE embeddings
+SET
+    model = 'char-ngram-hash-256'
+WHERE
+    model = 'anthropic-char-ngram-hash-256';
+
+DROP INDEX IF EXISTS embeddings_anthropic_char_ngram_hash_256_idx;
+
+DELETE FROM embedding_models
+WHERE
+    name = 'anthropic-char-ngram-hash-256';
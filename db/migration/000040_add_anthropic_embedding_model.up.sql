@@ -0,0 +1,39 @@
+-- the articles analyzed by Anthropic are embedded locally as the lexicon
+-- analyzer does, but under a model of their own, so that the sentiments of
+-- the two analyzers are not reused for each other
+INSERT INTO
+    embedding_models (name, provider, dim, metric)
+VALUES (
+        'anthropic-char-ngram-hash-256',
+        'anthropic',
+        256,
+        'cosine'
+    );
+
+CREATE INDEX embeddings_anthropic_char_ngram_hash_256_idx ON embeddings USING hnsw (
+    (embedding:: vector(256)) vector_cosine_ops
+)
+WHERE
+    model = 'anthropic-char-ngram-hash-256';
+
+-- the existing Anthropic jobs keep the model their results are stored under,
+-- which the analyzer lists as a legacy model
+UPDATE jobs AS j
+SET
+    llm_query = (
+        j.llm_query:: jsonb || jsonb_build_object(
+            'embedding-options',
+            COALESCE(
+                j.llm_query:: jsonb -> 'embedding-options',
+                '{}':: jsonb
+            ) || '{"embedding_model": "char-ngram-hash-256"}':: jsonb
+        )
+    ):: json
+FROM apis AS a
+WHERE
+    j.llm_api_id = a.id
+    AND a.name = 'Anthropic'
+    AND COALESCE(
+        j.llm_query:: jsonb #>> '{embedding-options,embedding_model}',
+        ''
+    ) = '';
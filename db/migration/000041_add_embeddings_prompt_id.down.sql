@@ -0,0 +1 @@
+ALTER TABLE embeddings DROP COLUMN IF EXISTS prompt_id;
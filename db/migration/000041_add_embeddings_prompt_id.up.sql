@@ -0,0 +1,9 @@
+-- the prompt the sentiment of the embedding was classified with, null for the
+-- default prompt of the analyzer, so that the sentiments of one prompt are not
+-- reused for another; the embeddings, which the jobs and stories refer to,
+-- outlive the prompt
+ALTER TABLE embeddings ADD COLUMN prompt_id bigint DEFAULT null;
+
+ALTER TABLE embeddings
+ADD
+    FOREIGN KEY (prompt_id) REFERENCES prompts (id) ON DELETE SET NULL ON UPDATE CASCADE;
@@ -0,0 +1,15 @@
+DROP INDEX IF EXISTS entity_sentiments_news_id_entity_model_owner_prompt_id_idx;
+
+-- keep the latest sentiment of a news toward an entity given by a model
+DELETE FROM entity_sentiments AS a USING entity_sentiments AS b
+WHERE
+    a.news_id = b.news_id
+    AND a.entity = b.entity
+    AND a.model = b.model
+    AND a.id < b.id;
+
+CREATE UNIQUE INDEX ON entity_sentiments (news_id, entity, model);
+
+ALTER TABLE entity_sentiments DROP COLUMN IF EXISTS prompt_id;
+
+ALTER TABLE entity_sentiments DROP COLUMN IF EXISTS owner;
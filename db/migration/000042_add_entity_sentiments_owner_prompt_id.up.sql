@@ -0,0 +1,24 @@
+-- the owner and the prompt of the job the sentiment was classified for, so
+-- that the jobs of one owner or prompt do not overwrite the sentiments of
+-- another; the sentiments classified before are not attributed to any owner
+ALTER TABLE entity_sentiments ADD COLUMN owner uuid DEFAULT null;
+
+ALTER TABLE entity_sentiments ADD COLUMN prompt_id bigint DEFAULT null;
+
+ALTER TABLE entity_sentiments
+ADD
+    FOREIGN KEY (owner) REFERENCES users (id) ON DELETE CASCADE ON UPDATE CASCADE;
+
+ALTER TABLE entity_sentiments
+ADD
+    FOREIGN KEY (prompt_id) REFERENCES prompts (id) ON DELETE CASCADE ON UPDATE CASCADE;
+
+DROP INDEX IF EXISTS entity_sentiments_news_id_entity_model_idx;
+
+CREATE UNIQUE INDEX ON entity_sentiments (
+    news_id,
+    entity,
+    model,
+    owner,
+    prompt_id
+) NULLS NOT DISTINCT;
@@ -0,0 +1,15 @@
+DROP INDEX IF EXISTS annotations_news_id_model_owner_task_hash_idx;
+
+-- keep the latest label of a news in a task given by a model
+DELETE FROM annotations AS a USING annotations AS b
+WHERE
+    a.news_id = b.news_id
+    AND a.task = b.task
+    AND a.model = b.model
+    AND a.id < b.id;
+
+CREATE UNIQUE INDEX ON annotations (news_id, task, model);
+
+ALTER TABLE annotations DROP COLUMN IF EXISTS task_hash;
+
+ALTER TABLE annotations DROP COLUMN IF EXISTS owner;
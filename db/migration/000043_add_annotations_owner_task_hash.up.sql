@@ -0,0 +1,20 @@
+-- the owner of the job and the hash of the definition of its task, so that
+-- the tasks of the same name of different owners or definitions do not
+-- overwrite the labels of each other; the labels given before are not
+-- attributed to any owner
+ALTER TABLE annotations ADD COLUMN owner uuid DEFAULT null;
+
+ALTER TABLE annotations ADD COLUMN task_hash character(64) DEFAULT null;
+
+ALTER TABLE annotations
+ADD
+    FOREIGN KEY (owner) REFERENCES users (id) ON DELETE CASCADE ON UPDATE CASCADE;
+
+DROP INDEX IF EXISTS annotations_news_id_task_model_idx;
+
+CREATE UNIQUE INDEX ON annotations (
+    news_id,
+    model,
+    owner,
+    task_hash
+) NULLS NOT DISTINCT;
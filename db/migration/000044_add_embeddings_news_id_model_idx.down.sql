@@ -0,0 +1 @@
+DROP INDEX IF EXISTS embeddings_news_id_model_idx;
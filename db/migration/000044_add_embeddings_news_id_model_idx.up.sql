@@ -0,0 +1,2 @@
+-- the latest embedding of a news of a model is looked up by the stories
+CREATE INDEX ON embeddings (news_id, model);
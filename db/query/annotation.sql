@@ -0,0 +1,75 @@
+-- name: UpsertAnnotation :one
+INSERT INTO
+    annotations (
+        news_id,
+        task,
+        label,
+        model,
+        confidence,
+        rationale,
+        owner,
+        task_hash,
+        created_at,
+        updated_at
+    )
+VALUES (
+        $1,
+        $2,
+        $3,
+        $4,
+        $5,
+        $6,
+        $7,
+        $8,
+        CURRENT_TIMESTAMP,
+        CURRENT_TIMESTAMP
+    ) ON CONFLICT (news_id, model, owner, task_hash) DO
+UPDATE
+SET
+    label = EXCLUDED.label,
+    confidence = EXCLUDED.confidence,
+    rationale = EXCLUDED.rationale,
+    updated_at = CURRENT_TIMESTAMP RETURNING id;
+
+-- name: GetJobAnnotationStats :many
+WITH annotated AS (
+        SELECT n.source, a.label
+        FROM newsjobs AS nj
+            INNER JOIN news AS n ON nj.news_id = n.id
+            INNER JOIN annotations AS a ON a.news_id = n.id
+        WHERE
+            nj.job_id = @job_id:: bigint
+            AND a.model = @model:: text
+            AND a.owner = @owner:: uuid
+            AND a.task_hash = @task_hash:: text
+    ),
+    counted AS (
+        SELECT (
+                CASE
+                    WHEN GROUPING(a.source) = 0 THEN a.source
+                    ELSE ''
+                END
+            ):: text AS source,
+            a.label:: text AS label,
+            COUNT(*) AS n_news
+        FROM annotated AS a
+        GROUP BY
+            GROUPING SETS ( (a.label), (a.source, a.label))
+    )
+SELECT
+    c.source,
+    c.label,
+    c.n_news, (
+        c.n_news:: float8 / SUM(c.n_news) OVER (PARTITION BY c.source)
+    ):: float8 AS ratio
+FROM counted AS c
+ORDER BY c.source, c.label;
+
+-- name: GetAnnotatedNewsIds :many
+SELECT news_id
+FROM annotations
+WHERE
+    news_id = ANY(@news_ids:: int [])
+    AND model = $1
+    AND owner = @owner:: uuid
+    AND task_hash = @task_hash:: text;
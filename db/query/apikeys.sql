@@ -1,19 +1,20 @@
 -- name: ListAPIKey :many
 WITH k AS (
-  SELECT id, owner, api_id, key
+  SELECT id, owner, api_id, key, base_url, models, auth_header
     FROM apikeys
    WHERE owner = $1
      AND deleted_at IS NULL
 )
 SELECT k.id AS api_key_id, k.owner, k.key, 
-       a.id AS api_id, a.type, a.name, a.image, a.icon
+       a.id AS api_id, a.type, a.name, a.image, a.icon,
+       k.base_url, k.models, k.auth_header
   FROM apis AS a
  INNER JOIN k
     ON a.id = k.api_id
  WHERE a.deleted_at IS NULL;
 
 -- name: GetAPIKey :one
-SELECT id, owner, api_id, key 
+SELECT id, owner, api_id, key, base_url, models, auth_header
   FROM apikeys
  WHERE owner = $1 
    AND api_id = $2
@@ -21,9 +22,9 @@ SELECT id, owner, api_id, key
 
 -- name: CreateAPIKey :one
 INSERT INTO apikeys (
-    owner, api_id, key
+    owner, api_id, key, base_url, models, auth_header
 ) VALUES (
-    $1, $2, $3
+    $1, $2, $3, $4, $5, $6
 )
 RETURNING id;
 
@@ -31,6 +32,9 @@ RETURNING id;
 UPDATE apikeys
    SET key = $1,
        api_id = @old_api_id,
+       base_url = @base_url,
+       models = @models,
+       auth_header = @auth_header,
        updated_at = CURRENT_TIMESTAMP
  WHERE owner = $2
    AND api_id = @new_api_id
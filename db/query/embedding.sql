@@ -5,6 +5,10 @@ INSERT INTO
         model,
         embedding,
         sentiment,
+        score,
+        confidence,
+        rationale,
+        prompt_id,
         created_at,
         updated_at
     )
@@ -13,6 +17,10 @@ VALUES (
         $2,
         $3,
         $4,
+        $5,
+        $6,
+        $7,
+        $8,
         CURRENT_TIMESTAMP,
         CURRENT_TIMESTAMP
     ) RETURNING id;
@@ -28,6 +36,7 @@ FROM embeddings
 WHERE
     news_id = ANY(@news_ids:: int [])
     AND model = $1
+    AND prompt_id IS NOT DISTINCT FROM sqlc.narg(prompt_id)
     AND deleted_at IS NULL;
 
 -- name: GetEmbeddingByJobId :many
@@ -37,9 +46,216 @@ SELECT
     e.news_id,
     e.model,
     e.embedding,
-    e.sentiment
-FROM embeddings AS e
-    INNER JOIN newsjobs AS nj ON e.news_id = nj.news_id
+    e.sentiment,
+    COALESCE(e.score, sentiment_score(e.sentiment)):: smallint AS score,
+    e.confidence,
+    e.rationale,
+    n.title,
+    n.link,
+    n.source,
+    n.publish_at,
+    n.language
+FROM newsjobs AS nj
+    INNER JOIN news AS n ON nj.news_id = n.id
+    LEFT JOIN job_items AS ji ON ji.job_id = nj.job_id AND ji.news_id = n.id
+    INNER JOIN LATERAL (
+        SELECT
+            id,
+            news_id,
+            model,
+            embedding,
+            sentiment,
+            score,
+            confidence,
+            rationale
+        FROM embeddings
+        WHERE
+            news_id = n.id
+            AND model = @model
+            AND deleted_at IS NULL
+            -- the embedding the job got, or the latest one for the news done
+            -- before the job items recorded it
+            AND (
+                id = ji.embedding_id
+                OR (
+                    ji.embedding_id IS NULL
+                    AND (ji.id IS NULL OR ji.stage = 'done')
+                )
+            )
+        ORDER BY id DESC
+        LIMIT 1
+    ) AS e ON true
 WHERE
     nj.job_id = $1
-    AND e.deleted_at IS NULL;
\ No newline at end of file
+    AND e.id > @next:: bigint
+ORDER BY e.id
+LIMIT @n:: int;
+
+-- name: CompareJobSentiments :many
+SELECT
+    n.id AS news_id,
+    n.title,
+    n.link,
+    ea.sentiment AS sentiment_a,
+    eb.sentiment AS sentiment_b
+FROM newsjobs AS nja
+    INNER JOIN newsjobs AS njb ON nja.news_id = njb.news_id
+    INNER JOIN news AS n ON nja.news_id = n.id
+    LEFT JOIN job_items AS jia ON jia.job_id = nja.job_id AND jia.news_id = n.id
+    LEFT JOIN LATERAL (
+        SELECT sentiment
+        FROM embeddings AS e
+        WHERE
+            e.news_id = n.id
+            AND e.model = @model_a
+            AND e.deleted_at IS NULL
+            -- the embedding the job got, or the latest one for the news done
+            -- before the job items recorded it
+            AND (
+                e.id = jia.embedding_id
+                OR (
+                    jia.embedding_id IS NULL
+                    AND (jia.id IS NULL OR jia.stage = 'done')
+                )
+            )
+        ORDER BY e.id DESC
+        LIMIT 1
+    ) AS ea ON true
+    LEFT JOIN job_items AS jib ON jib.job_id = njb.job_id AND jib.news_id = n.id
+    LEFT JOIN LATERAL (
+        SELECT sentiment
+        FROM embeddings AS e
+        WHERE
+            e.news_id = n.id
+            AND e.model = @model_b
+            AND e.deleted_at IS NULL
+            -- the embedding the job got, or the latest one for the news done
+            -- before the job items recorded it
+            AND (
+                e.id = jib.embedding_id
+                OR (
+                    jib.embedding_id IS NULL
+                    AND (jib.id IS NULL OR jib.stage = 'done')
+                )
+            )
+        ORDER BY e.id DESC
+        LIMIT 1
+    ) AS eb ON true
+WHERE
+    nja.job_id = @job_a
+    AND njb.job_id = @job_b
+ORDER BY n.id;
+
+-- name: GetJobSentimentStats :many
+WITH analyzed AS (
+        SELECT
+            DISTINCT ON (n.id) n.id,
+            n.source,
+            n.category,
+            (n.publish_at AT TIME ZONE 'UTC'):: date AS publish_day,
+            COALESCE(n.language, '') AS language,
+            e.sentiment,
+            COALESCE(e.score, sentiment_score(e.sentiment)) AS score
+        FROM newsjobs AS nj
+            INNER JOIN news AS n ON nj.news_id = n.id
+            LEFT JOIN job_items AS ji ON ji.job_id = nj.job_id AND ji.news_id = n.id
+            INNER JOIN embeddings AS e ON e.news_id = n.id
+        WHERE
+            nj.job_id = @job_id:: bigint
+            AND e.model = @model:: text
+            AND e.deleted_at IS NULL
+            -- the embedding the job got, or the latest one for the news done
+            -- before the job items recorded it
+            AND (
+                e.id = ji.embedding_id
+                OR (
+                    ji.embedding_id IS NULL
+                    AND (ji.id IS NULL OR ji.stage = 'done')
+                )
+            )
+        ORDER BY n.id, e.id DESC
+    )
+SELECT (
+        CASE
+            WHEN GROUPING(a.source) = 0 THEN 'source'
+            WHEN GROUPING(a.category) = 0 THEN 'category'
+            WHEN GROUPING(a.publish_day) = 0 THEN 'publish_day'
+            WHEN GROUPING(a.language) = 0 THEN 'language'
+            ELSE 'overall'
+        END
+    ):: text AS dimension, (
+        CASE
+            WHEN GROUPING(a.source) = 0 THEN a.source
+            WHEN GROUPING(a.category) = 0 THEN a.category
+            WHEN GROUPING(a.publish_day) = 0 THEN a.publish_day:: text
+            WHEN GROUPING(a.language) = 0 THEN a.language
+            ELSE ''
+        END
+    ):: text AS bucket,
+    COUNT(*) AS n_news,
+    COUNT(*) FILTER (
+        WHERE
+            a.sentiment = 'very_positive'
+    ) AS n_very_positive,
+    COUNT(*) FILTER (
+        WHERE
+            a.sentiment = 'positive'
+    ) AS n_positive,
+    COUNT(*) FILTER (
+        WHERE
+            a.sentiment = 'neutral'
+    ) AS n_neutral,
+    COUNT(*) FILTER (
+        WHERE
+            a.sentiment = 'negative'
+    ) AS n_negative,
+    COUNT(*) FILTER (
+        WHERE
+            a.sentiment = 'very_negative'
+    ) AS n_very_negative,
+    COALESCE(
+        COUNT(*) FILTER (
+            WHERE
+                a.sentiment = 'very_positive'
+        ):: float8 / NULLIF(COUNT(*), 0),
+        0
+    ):: float8 AS very_positive_ratio,
+    COALESCE(
+        COUNT(*) FILTER (
+            WHERE
+                a.sentiment = 'positive'
+        ):: float8 / NULLIF(COUNT(*), 0),
+        0
+    ):: float8 AS positive_ratio,
+    COALESCE(
+        COUNT(*) FILTER (
+            WHERE
+                a.sentiment = 'neutral'
+        ):: float8 / NULLIF(COUNT(*), 0),
+        0
+    ):: float8 AS neutral_ratio,
+    COALESCE(
+        COUNT(*) FILTER (
+            WHERE
+                a.sentiment = 'negative'
+        ):: float8 / NULLIF(COUNT(*), 0),
+        0
+    ):: float8 AS negative_ratio,
+    COALESCE(
+        COUNT(*) FILTER (
+            WHERE
+                a.sentiment = 'very_negative'
+        ):: float8 / NULLIF(COUNT(*), 0),
+        0
+    ):: float8 AS very_negative_ratio,
+    COALESCE(AVG(a.score), 0):: float8 AS mean_score
+FROM analyzed AS a
+GROUP BY
+    GROUPING SETS (
+        (),
+        (a.source),
+        (a.category),
+        (a.publish_day),
+        (a.language)
+    )
+ORDER BY dimension, bucket;
\ No newline at end of file
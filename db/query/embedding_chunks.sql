@@ -0,0 +1,22 @@
+-- name: UpsertEmbeddingChunk :execrows
+INSERT INTO
+    embedding_chunks (
+        news_id,
+        model,
+        chunk_index,
+        n_tokens,
+        embedding
+    )
+VALUES ($1, $2, $3, $4, $5) ON CONFLICT (news_id, model, chunk_index) DO
+UPDATE
+SET
+    n_tokens = EXCLUDED.n_tokens,
+    embedding = EXCLUDED.embedding,
+    created_at = CURRENT_TIMESTAMP;
+
+-- name: DeleteEmbeddingChunksFrom :execrows
+DELETE FROM embedding_chunks
+WHERE
+    news_id = @news_id
+    AND model = @model
+    AND chunk_index >= @chunk_index;
@@ -0,0 +1,4 @@
+-- name: GetEmbeddingModel :one
+SELECT name, provider, dim, metric
+FROM embedding_models
+WHERE name = @name;
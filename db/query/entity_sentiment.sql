@@ -0,0 +1,121 @@
+-- name: UpsertEntitySentiment :one
+INSERT INTO
+    entity_sentiments (
+        news_id,
+        entity,
+        model,
+        sentiment,
+        score,
+        confidence,
+        rationale,
+        owner,
+        prompt_id,
+        created_at,
+        updated_at
+    )
+VALUES (
+        $1,
+        $2,
+        $3,
+        $4,
+        $5,
+        $6,
+        $7,
+        $8,
+        $9,
+        CURRENT_TIMESTAMP,
+        CURRENT_TIMESTAMP
+    ) ON CONFLICT (news_id, entity, model, owner, prompt_id) DO
+UPDATE
+SET
+    sentiment = EXCLUDED.sentiment,
+    score = EXCLUDED.score,
+    confidence = EXCLUDED.confidence,
+    rationale = EXCLUDED.rationale,
+    updated_at = CURRENT_TIMESTAMP RETURNING id;
+
+-- name: GetJobEntitySentimentStats :many
+WITH analyzed AS (
+        SELECT
+            es.entity,
+            n.source,
+            es.sentiment,
+            es.score
+        FROM newsjobs AS nj
+            INNER JOIN news AS n ON nj.news_id = n.id
+            INNER JOIN entity_sentiments AS es ON es.news_id = n.id
+        WHERE
+            nj.job_id = @job_id:: bigint
+            AND es.model = @model:: text
+            AND es.entity = ANY(@entities:: text [])
+            AND es.owner = @owner:: uuid
+            AND es.prompt_id IS NOT DISTINCT FROM sqlc.narg(prompt_id)
+    )
+SELECT
+    a.entity:: text AS entity, (
+        CASE
+            WHEN GROUPING(a.source) = 0 THEN a.source
+            ELSE ''
+        END
+    ):: text AS source,
+    COUNT(*) AS n_news,
+    COUNT(*) FILTER (
+        WHERE
+            a.sentiment = 'very_positive'
+    ) AS n_very_positive,
+    COUNT(*) FILTER (
+        WHERE
+            a.sentiment = 'positive'
+    ) AS n_positive,
+    COUNT(*) FILTER (
+        WHERE
+            a.sentiment = 'neutral'
+    ) AS n_neutral,
+    COUNT(*) FILTER (
+        WHERE
+            a.sentiment = 'negative'
+    ) AS n_negative,
+    COUNT(*) FILTER (
+        WHERE
+            a.sentiment = 'very_negative'
+    ) AS n_very_negative,
+    COALESCE(
+        COUNT(*) FILTER (
+            WHERE
+                a.sentiment = 'very_positive'
+        ):: float8 / NULLIF(COUNT(*), 0),
+        0
+    ):: float8 AS very_positive_ratio,
+    COALESCE(
+        COUNT(*) FILTER (
+            WHERE
+                a.sentiment = 'positive'
+        ):: float8 / NULLIF(COUNT(*), 0),
+        0
+    ):: float8 AS positive_ratio,
+    COALESCE(
+        COUNT(*) FILTER (
+            WHERE
+                a.sentiment = 'neutral'
+        ):: float8 / NULLIF(COUNT(*), 0),
+        0
+    ):: float8 AS neutral_ratio,
+    COALESCE(
+        COUNT(*) FILTER (
+            WHERE
+                a.sentiment = 'negative'
+        ):: float8 / NULLIF(COUNT(*), 0),
+        0
+    ):: float8 AS negative_ratio,
+    COALESCE(
+        COUNT(*) FILTER (
+            WHERE
+                a.sentiment = 'very_negative'
+        ):: float8 / NULLIF(COUNT(*), 0),
+        0
+    ):: float8 AS very_negative_ratio,
+    COALESCE(AVG(a.score), 0):: float8 AS mean_score
+FROM analyzed AS a
+GROUP BY
+    GROUPING SETS ( (a.entity), (a.entity, a.source))
+ORDER BY entity, source;
@@ -0,0 +1,95 @@
+-- name: CreateJobItems :execrows
+
+INSERT INTO
+    job_items (job_id, news_id)
+SELECT job_id, news_id
+FROM newsjobs
+WHERE
+    job_id = $1 ON CONFLICT (job_id, news_id) DO NOTHING;
+
+-- name: GetDueJobItems :many
+
+SELECT
+    ji.id,
+    ji.news_id,
+    ji.attempts,
+    n.title,
+    n.description,
+    n.content,
+    n.language
+FROM job_items AS ji
+    INNER JOIN news AS n ON ji.news_id = n.id
+WHERE
+    ji.job_id = $1
+    AND ji.stage IN ('pending', 'retrying')
+    AND ji.next_attempt_at <= CURRENT_TIMESTAMP
+ORDER BY ji.id;
+
+-- name: GetNextJobItemAttempt :one
+
+SELECT next_attempt_at
+FROM job_items
+WHERE
+    job_id = $1
+    AND stage IN ('pending', 'retrying')
+ORDER BY next_attempt_at
+LIMIT 1;
+
+-- name: GetJobItemsByStage :many
+
+SELECT
+    ji.id,
+    ji.news_id,
+    n.title,
+    n.link,
+    ji.stage,
+    ji.attempts,
+    ji.last_error,
+    ji.next_attempt_at,
+    ji.updated_at
+FROM job_items AS ji
+    INNER JOIN jobs AS j ON ji.job_id = j.id
+    INNER JOIN news AS n ON ji.news_id = n.id
+WHERE
+    j.owner = $1
+    AND j.id = $2
+    AND ji.stage = $3
+    AND j.deleted_at IS NULL
+ORDER BY ji.id;
+
+-- name: MarkJobItemDone :execrows
+
+UPDATE job_items
+SET
+    stage = 'done',
+    embedding_id = @embedding_id,
+    last_error = NULL,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = @id;
+
+-- name: MarkJobItemFailed :execrows
+
+UPDATE job_items
+SET
+    stage = @stage,
+    attempts = attempts + 1,
+    last_error = @last_error,
+    next_attempt_at = @next_attempt_at,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = @id;
+
+-- name: RequeueJobItems :execrows
+
+UPDATE job_items
+SET
+    stage = 'pending',
+    attempts = 0,
+    next_attempt_at = CURRENT_TIMESTAMP,
+    updated_at = CURRENT_TIMESTAMP
+WHERE
+    job_id = @job_id
+    AND stage = 'dead'
+    AND (
+        @item_id:: bigint = 0
+        OR id = @item_id:: bigint
+    );
@@ -0,0 +1,69 @@
+-- name: CreateJobProgress :exec
+
+INSERT INTO
+    job_progress (
+        job_id,
+        total,
+        parsed,
+        lang_detected,
+        failed,
+        updated_at
+    )
+VALUES (
+        $1,
+        $2,
+        $3,
+        $4,
+        $5,
+        CURRENT_TIMESTAMP
+    ) ON CONFLICT (job_id) DO
+UPDATE
+SET
+    total = EXCLUDED.total,
+    parsed = EXCLUDED.parsed,
+    lang_detected = EXCLUDED.lang_detected,
+    failed = job_progress.failed + EXCLUDED.failed,
+    updated_at = CURRENT_TIMESTAMP;
+
+-- name: IncrJobProgress :exec
+
+INSERT INTO
+    job_progress (
+        job_id,
+        embedded,
+        sentiment_scored,
+        failed,
+        updated_at
+    )
+VALUES (
+        $1,
+        $2,
+        $3,
+        $4,
+        CURRENT_TIMESTAMP
+    ) ON CONFLICT (job_id) DO
+UPDATE
+SET
+    embedded = job_progress.embedded + EXCLUDED.embedded,
+    sentiment_scored = job_progress.sentiment_scored + EXCLUDED.sentiment_scored,
+    failed = job_progress.failed + EXCLUDED.failed,
+    updated_at = CURRENT_TIMESTAMP;
+
+-- name: GetJobProgress :one
+
+SELECT
+    j.id AS job_id,
+    j.status,
+    COALESCE(jp.total, 0):: int AS total,
+    COALESCE(jp.parsed, 0):: int AS parsed,
+    COALESCE(jp.lang_detected, 0):: int AS lang_detected,
+    COALESCE(jp.embedded, 0):: int AS embedded,
+    COALESCE(jp.sentiment_scored, 0):: int AS sentiment_scored,
+    COALESCE(jp.failed, 0):: int AS failed,
+    COALESCE(jp.updated_at, j.updated_at):: timestamptz AS updated_at
+FROM jobs AS j
+    LEFT JOIN job_progress AS jp ON j.id = jp.job_id
+WHERE
+    j.owner = $1
+    AND j.id = $2
+    AND j.deleted_at IS NULL;
@@ -0,0 +1,83 @@
+-- name: CreateJobSchedule :exec
+
+INSERT INTO
+    job_schedules (
+        job_id,
+        cron_expr,
+        src_cache_query,
+        next_run_at
+    )
+VALUES ($1, $2, $3, $4);
+
+-- name: GetJobSchedule :one
+
+SELECT
+    s.job_id,
+    s.cron_expr,
+    s.next_run_at,
+    s.last_run_at, (
+        SELECT COUNT(*)
+        FROM jobs AS c
+        WHERE
+            c.parent_id = s.job_id
+            AND c.deleted_at IS NULL
+    ) AS n_runs
+FROM job_schedules AS s
+    INNER JOIN jobs AS j ON s.job_id = j.id
+WHERE
+    j.owner = $1
+    AND j.id = $2
+    AND j.deleted_at IS NULL;
+
+-- name: UpdateJobSchedule :execrows
+
+UPDATE job_schedules AS s
+SET
+    cron_expr = @cron_expr,
+    next_run_at = @next_run_at,
+    updated_at = CURRENT_TIMESTAMP
+FROM jobs AS j
+WHERE
+    s.job_id = j.id
+    AND j.owner = @owner
+    AND j.id = @job_id
+    AND j.deleted_at IS NULL;
+
+-- name: DeleteJobSchedule :execrows
+
+DELETE FROM job_schedules AS s USING jobs AS j
+WHERE
+    s.job_id = j.id
+    AND j.owner = $1
+    AND j.id = $2;
+
+-- name: GetDueJobSchedules :many
+
+SELECT
+    s.job_id,
+    j.owner,
+    s.cron_expr,
+    s.src_cache_query,
+    s.next_run_at,
+    j.src_api_id,
+    j.src_query,
+    j.llm_api_id,
+    j.llm_query
+FROM job_schedules AS s
+    INNER JOIN jobs AS j ON s.job_id = j.id
+WHERE
+    s.next_run_at <= CURRENT_TIMESTAMP
+    AND j.deleted_at IS NULL
+ORDER BY s.next_run_at ASC
+LIMIT $1;
+
+-- name: ClaimJobSchedule :execrows
+
+UPDATE job_schedules
+SET
+    next_run_at = @next_run_at,
+    last_run_at = CURRENT_TIMESTAMP,
+    updated_at = CURRENT_TIMESTAMP
+WHERE
+    job_id = @job_id
+    AND next_run_at = @prev_run_at;
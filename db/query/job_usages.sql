@@ -0,0 +1,36 @@
+-- name: CreateJobUsage :one
+INSERT INTO
+    job_usages (
+        job_id,
+        provider,
+        model,
+        operation,
+        input_tokens,
+        output_tokens,
+        cost
+    )
+VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id;
+
+-- name: GetJobUsage :many
+SELECT
+    u.provider,
+    u.model,
+    u.operation,
+    COUNT(*) AS n_calls,
+    SUM(u.input_tokens):: bigint AS input_tokens,
+    SUM(u.output_tokens):: bigint AS output_tokens,
+    COALESCE(SUM(u.cost), 0):: float8 AS cost,
+    (COUNT(*) - COUNT(u.cost)):: bigint AS n_unpriced
+FROM job_usages AS u
+    INNER JOIN jobs AS j ON u.job_id = j.id
+WHERE
+    u.job_id = @job_id:: bigint
+    AND j.owner = @owner:: uuid
+GROUP BY
+    u.provider,
+    u.model,
+    u.operation
+ORDER BY
+    u.provider,
+    u.model,
+    u.operation;
@@ -112,6 +112,9 @@ SELECT
     j.src_query,
     allm.name AS analyzer,
     j.llm_query,
+    j.partial,
+    j.parent_id,
+    j.cloned_from,
     j.created_at,
     j.updated_at
 FROM jobs AS j
@@ -152,6 +155,22 @@ WHERE owner = $1
 GROUP BY status
 ORDER BY status ASC;
 
+-- name: GetJobQuotaUsage :one
+
+SELECT
+    COUNT(*) FILTER (
+        WHERE
+            status IN ('created', 'running')
+            AND deleted_at IS NULL
+    ) AS n_running,
+    COUNT(*) FILTER (
+        WHERE
+            created_at >= @since
+            AND parent_id IS NULL
+    ) AS n_today
+FROM jobs
+WHERE owner = @owner;
+
 -- name: CreateJob :one
 
 INSERT INTO
@@ -162,9 +181,38 @@ INSERT INTO
         src_api_id,
         src_query,
         llm_api_id,
-        llm_query
+        llm_query,
+        parent_id
+    )
+VALUES ($1, $2, 'created', $3, $4, $5, $6, $7) RETURNING id;
+
+-- name: CloneJob :one
+
+INSERT INTO
+    jobs (
+        ulid,
+        owner,
+        status,
+        src_api_id,
+        src_query,
+        llm_api_id,
+        llm_query,
+        cloned_from
     )
-VALUES ($1, $2, 'created', $3, $4, $5, $6) RETURNING id;
+SELECT
+    @ulid,
+    j.owner,
+    'created',
+    j.src_api_id,
+    j.src_query,
+    @llm_api_id,
+    @llm_query,
+    j.id
+FROM jobs AS j
+WHERE
+    j.owner = @owner
+    AND j.id = @id
+    AND j.deleted_at IS NULL RETURNING id;
 
 -- name: UpdateJobStatus :execrows
 
@@ -201,10 +249,29 @@ WITH ranked_jobs AS (
                 ORDER BY
                     id ASC
             ) as rn
-        FROM jobs
+        FROM jobs AS j
         WHERE
             deleted_at IS NULL
             AND status = 'created'
+            -- a job put back while its items wait for their next attempt is
+            -- skipped until one of them is due
+            AND (
+                NOT EXISTS (
+                    SELECT 1
+                    FROM job_items AS ji
+                    WHERE
+                        ji.job_id = j.id
+                        AND ji.stage IN ('pending', 'retrying')
+                )
+                OR EXISTS (
+                    SELECT 1
+                    FROM job_items AS ji
+                    WHERE
+                        ji.job_id = j.id
+                        AND ji.stage IN ('pending', 'retrying')
+                        AND ji.next_attempt_at <= CURRENT_TIMESTAMP
+                )
+            )
     )
 SELECT
     id,
@@ -213,4 +280,27 @@ SELECT
     llm_query
 FROM ranked_jobs
 WHERE rn <= @n:: int
-ORDER BY id DESC;
\ No newline at end of file
+ORDER BY id DESC;
+
+-- name: UpdateJobStatusFrom :execrows
+
+UPDATE jobs
+SET
+    status = @to_status,
+    updated_at = CURRENT_TIMESTAMP
+WHERE
+    id = @id
+    AND owner = @owner
+    AND status:: text = ANY(@from_status:: text [])
+    AND deleted_at IS NULL;
+
+-- name: MarkJobPartial :execrows
+
+UPDATE jobs
+SET
+    partial = true,
+    updated_at = CURRENT_TIMESTAMP
+WHERE
+    id = $1
+    AND owner = $2
+    AND deleted_at IS NULL;
@@ -0,0 +1,37 @@
+-- name: GetLLMResponse :one
+SELECT response
+FROM llm_responses
+WHERE
+    provider = @provider
+    AND model = @model
+    AND operation = @operation
+    AND prompt_id = @prompt_id
+    AND input_hash = @input_hash
+    AND expires_at > NOW();
+
+-- name: UpsertLLMResponse :execrows
+INSERT INTO
+    llm_responses (
+        provider,
+        model,
+        operation,
+        prompt_id,
+        input_hash,
+        response,
+        expires_at
+    )
+VALUES ($1, $2, $3, $4, $5, $6, $7) ON CONFLICT (
+        provider,
+        model,
+        operation,
+        prompt_id,
+        input_hash
+    ) DO
+UPDATE
+SET
+    response = EXCLUDED.response,
+    expires_at = EXCLUDED.expires_at,
+    created_at = CURRENT_TIMESTAMP;
+
+-- name: CleanUpLLMResponses :execrows
+DELETE FROM llm_responses WHERE expires_at <= NOW();
@@ -27,7 +27,7 @@ WHERE md5_hash = $1;
 
 -- name: GetNewsByMD5Hashs :many
 
-SELECT id FROM news WHERE md5_hash = ANY(@md5_hash:: string []);
+SELECT id, md5_hash FROM news WHERE md5_hash = ANY(@md5_hash:: text []);
 
 -- name: GetNewsByKeywords :many
 
@@ -5,3 +5,25 @@ INSERT INTO newsjobs (
     $1, $2
 )
 RETURNING id;
+
+
+-- name: GetNewsByJobId :many
+SELECT
+    n.id,
+    n.title,
+    n.description,
+    n.content,
+    n.language
+FROM news AS n
+    INNER JOIN newsjobs AS nj ON n.id = nj.news_id
+WHERE nj.job_id = $1
+ORDER BY n.id;
+
+
+-- name: CloneNewsJobs :execrows
+INSERT INTO newsjobs (
+    job_id, news_id
+)
+SELECT @to_job_id:: bigint, news_id
+FROM newsjobs
+WHERE job_id = @from_job_id:: bigint;
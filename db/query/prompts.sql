@@ -0,0 +1,41 @@
+-- name: CreatePrompt :one
+
+INSERT INTO
+    prompts (
+        owner,
+        provider,
+        task,
+        version,
+        body
+    )
+SELECT
+    @owner:: uuid,
+    @provider:: varchar,
+    @task:: varchar,
+    COALESCE(MAX(p.version), 0) + 1,
+    @body:: text
+FROM prompts AS p
+WHERE
+    p.owner = @owner
+    AND p.provider = @provider
+    AND p.task = @task
+RETURNING id, version;
+
+-- name: GetPrompt :one
+
+SELECT *
+FROM prompts
+WHERE owner = $1 AND id = $2;
+
+-- name: ListPrompts :many
+
+SELECT
+    id,
+    provider,
+    task,
+    version,
+    body,
+    created_at
+FROM prompts
+WHERE owner = $1
+ORDER BY provider, task, version DESC;
@@ -0,0 +1,219 @@
+-- name: GetUnclusteredNews :many
+SELECT
+    e.news_id,
+    e.model,
+    e.embedding,
+    n.source,
+    n.title,
+    n.publish_at
+FROM embeddings AS e
+    INNER JOIN news AS n ON e.news_id = n.id
+WHERE
+    e.deleted_at IS NULL
+    -- the latest embedding of the news of the model only
+    AND NOT EXISTS (
+        SELECT 1
+        FROM embeddings AS l
+        WHERE
+            l.news_id = e.news_id
+            AND l.model = e.model
+            AND l.deleted_at IS NULL
+            AND l.id > e.id
+    )
+    AND NOT EXISTS (
+        SELECT 1
+        FROM story_news AS sn
+        WHERE
+            sn.model = e.model
+            AND sn.news_id = e.news_id
+    )
+ORDER BY n.publish_at, e.id
+LIMIT @n:: int;
+
+-- name: GetNearestNewsIds :many
+SELECT news_id
+FROM embeddings
+WHERE
+    model = @model
+    AND deleted_at IS NULL
+-- searched exactly, for the models that are not indexed
+ORDER BY embedding <=> @embedding
+LIMIT @n:: int;
+
+-- name: GetNearestNewsIds256 :many
+SELECT news_id
+FROM embeddings
+WHERE
+    model = @model
+    AND deleted_at IS NULL
+-- the same cast as the hnsw indexes of the models of 256 dimensions
+ORDER BY embedding:: vector(256) <=> @embedding
+LIMIT @n:: int;
+
+-- name: GetNearestNewsIds384 :many
+SELECT news_id
+FROM embeddings
+WHERE
+    model = @model
+    AND deleted_at IS NULL
+-- the same cast as the hnsw indexes of the models of 384 dimensions
+ORDER BY embedding:: vector(384) <=> @embedding
+LIMIT @n:: int;
+
+-- name: GetNearestNewsIds1024 :many
+SELECT news_id
+FROM embeddings
+WHERE
+    model = @model
+    AND deleted_at IS NULL
+-- the same cast as the hnsw indexes of the models of 1024 dimensions
+ORDER BY embedding:: vector(1024) <=> @embedding
+LIMIT @n:: int;
+
+-- name: GetNearestNewsIds1536 :many
+SELECT news_id
+FROM embeddings
+WHERE
+    model = @model
+    AND deleted_at IS NULL
+-- the same cast as the hnsw indexes of the models of 1536 dimensions
+ORDER BY embedding:: vector(1536) <=> @embedding
+LIMIT @n:: int;
+
+-- name: GetNearestStory :one
+SELECT
+    sn.story_id,
+    (1 - AVG(e.embedding <=> @embedding)):: float8 AS similarity
+FROM story_news AS sn
+    INNER JOIN news AS n ON sn.news_id = n.id
+    INNER JOIN LATERAL (
+        SELECT embedding
+        FROM embeddings
+        WHERE
+            news_id = sn.news_id
+            AND model = sn.model
+            AND deleted_at IS NULL
+        ORDER BY id DESC
+        LIMIT 1
+    ) AS e ON true
+WHERE
+    sn.model = @model
+    -- the stories of the nearest news found by GetNearestNewsIds
+    AND sn.story_id IN (
+        SELECT story_id
+        FROM story_news
+        WHERE
+            model = @model
+            AND news_id = ANY(@news_ids:: bigint [])
+    )
+    AND n.publish_at BETWEEN @publish_from AND @publish_to
+    AND NOT EXISTS (
+        SELECT 1
+        FROM story_news AS o
+            INNER JOIN news AS m ON o.news_id = m.id
+        WHERE
+            o.story_id = sn.story_id
+            AND m.source = @source
+    )
+GROUP BY sn.story_id
+ORDER BY similarity DESC, sn.story_id
+LIMIT 1;
+
+-- name: CreateStory :one
+WITH story AS (
+        INSERT INTO
+            stories (
+                model,
+                title,
+                first_publish_at,
+                last_publish_at
+            )
+        VALUES (
+                @model,
+                @title,
+                @publish_at,
+                @publish_at
+            ) RETURNING id
+    )
+INSERT INTO
+    story_news (story_id, news_id, model)
+SELECT id, @news_id, @model
+FROM story RETURNING story_id;
+
+-- name: AddStoryNews :execrows
+WITH added AS (
+        INSERT INTO
+            story_news (
+                story_id,
+                news_id,
+                model,
+                similarity
+            )
+        VALUES (
+                @story_id,
+                @news_id,
+                @model,
+                @similarity
+            ) ON CONFLICT DO NOTHING RETURNING story_id
+    )
+UPDATE stories
+SET
+    first_publish_at = LEAST(first_publish_at, @publish_at),
+    last_publish_at = GREATEST(last_publish_at, @publish_at),
+    updated_at = CURRENT_TIMESTAMP
+WHERE id IN (
+        SELECT story_id
+        FROM added
+    );
+
+-- name: GetStory :one
+SELECT
+    id,
+    model,
+    title,
+    first_publish_at,
+    last_publish_at,
+    created_at,
+    updated_at
+FROM stories
+WHERE id = $1;
+
+-- name: GetStoryNews :many
+SELECT
+    n.id AS news_id,
+    n.source,
+    n.title,
+    n.link,
+    n.publish_at,
+    sn.similarity,
+    e.sentiment,
+    COALESCE(e.score, sentiment_score(e.sentiment)):: smallint AS score
+FROM story_news AS sn
+    INNER JOIN news AS n ON sn.news_id = n.id
+    INNER JOIN LATERAL (
+        SELECT sentiment, score
+        FROM embeddings
+        WHERE
+            news_id = sn.news_id
+            AND model = sn.model
+            AND deleted_at IS NULL
+        ORDER BY id DESC
+        LIMIT 1
+    ) AS e ON true
+WHERE sn.story_id = $1
+ORDER BY n.publish_at, n.id;
+
+-- name: ListStories :many
+SELECT
+    s.id,
+    s.model,
+    s.title,
+    s.first_publish_at,
+    s.last_publish_at,
+    COUNT(*):: int AS n_sources
+FROM stories AS s
+    INNER JOIN story_news AS sn ON sn.story_id = s.id
+GROUP BY s.id
+HAVING COUNT(*) >= @min_sources:: int
+ORDER BY s.last_publish_at DESC, s.id DESC
+LIMIT @n:: int;
@@ -11,6 +11,12 @@ INSERT INTO users (
 )
 RETURNING id;
 
+-- name: LockUser :one
+SELECT id FROM users
+ WHERE id = $1
+   AND deleted_at IS NULL
+   FOR UPDATE;
+
 -- name: UpdatePassword :execrows
 UPDATE users
    SET password = $1,
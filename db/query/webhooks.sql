@@ -0,0 +1,105 @@
+-- name: CreateWebhook :one
+
+INSERT INTO webhooks (owner, url) VALUES ($1, $2) RETURNING id;
+
+-- name: ListWebhooks :many
+
+SELECT id, url, created_at FROM webhooks WHERE owner = $1 ORDER BY id;
+
+-- name: DeleteWebhook :execrows
+
+DELETE FROM webhooks WHERE owner = $1 AND id = $2;
+
+-- name: CreateWebhookSecret :execrows
+
+INSERT INTO
+    webhook_secrets (owner, secret)
+VALUES ($1, $2) ON CONFLICT (owner) DO NOTHING;
+
+-- name: RotateWebhookSecret :execrows
+
+INSERT INTO
+    webhook_secrets (owner, secret)
+VALUES ($1, $2) ON CONFLICT (owner) DO
+UPDATE
+SET
+    secret = EXCLUDED.secret,
+    updated_at = CURRENT_TIMESTAMP;
+
+-- name: GetWebhookSecret :one
+
+SELECT secret, updated_at FROM webhook_secrets WHERE owner = $1;
+
+-- name: GetDueWebhookDeliveries :many
+
+SELECT
+    d.id,
+    d.job_id,
+    d.payload,
+    d.attempts,
+    d.next_attempt_at,
+    w.url,
+    s.secret
+FROM webhook_deliveries AS d
+    INNER JOIN webhooks AS w ON d.webhook_id = w.id
+    INNER JOIN webhook_secrets AS s ON w.owner = s.owner
+WHERE
+    d.stage IN ('pending', 'retrying')
+    AND d.next_attempt_at <= CURRENT_TIMESTAMP
+ORDER BY d.next_attempt_at ASC
+LIMIT $1;
+
+-- name: ClaimWebhookDelivery :execrows
+
+UPDATE webhook_deliveries
+SET
+    next_attempt_at = @next_attempt_at,
+    updated_at = CURRENT_TIMESTAMP
+WHERE
+    id = @id
+    AND stage IN ('pending', 'retrying')
+    AND next_attempt_at = @prev_attempt_at;
+
+-- name: MarkWebhookDeliveryDelivered :execrows
+
+UPDATE webhook_deliveries
+SET
+    stage = 'delivered',
+    attempts = attempts + 1,
+    response_code = @response_code,
+    last_error = NULL,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = @id;
+
+-- name: MarkWebhookDeliveryFailed :execrows
+
+UPDATE webhook_deliveries
+SET
+    stage = @stage,
+    attempts = attempts + 1,
+    response_code = @response_code,
+    last_error = @last_error,
+    next_attempt_at = @next_attempt_at,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = @id;
+
+-- name: ListWebhookDeliveries :many
+
+SELECT
+    d.id,
+    d.webhook_id,
+    w.url,
+    d.job_id,
+    d.status,
+    d.stage,
+    d.attempts,
+    d.response_code,
+    d.last_error,
+    d.next_attempt_at,
+    d.created_at,
+    d.updated_at
+FROM webhook_deliveries AS d
+    INNER JOIN webhooks AS w ON d.webhook_id = w.id
+WHERE w.owner = $1
+ORDER BY d.id DESC
+LIMIT $2;
@@ -57,6 +57,20 @@ CREATE TYPE public.event_type AS ENUM (
 
 ALTER TYPE public.event_type OWNER TO admin;
 
+--
+-- Name: job_item_stage; Type: TYPE; Schema: public; Owner: admin
+--
+
+CREATE TYPE public.job_item_stage AS ENUM (
+    'pending',
+    'retrying',
+    'done',
+    'dead'
+);
+
+
+ALTER TYPE public.job_item_stage OWNER TO admin;
+
 --
 -- Name: job_status; Type: TYPE; Schema: public; Owner: admin
 --
@@ -89,18 +103,138 @@ ALTER TYPE public.role OWNER TO admin;
 --
 
 CREATE TYPE public.sentiment AS ENUM (
+    'very_positive',
     'positive',
     'neutral',
-    'negative'
+    'negative',
+    'very_negative'
 );
 
 
 ALTER TYPE public.sentiment OWNER TO admin;
 
+--
+-- Name: webhook_delivery_stage; Type: TYPE; Schema: public; Owner: admin
+--
+
+CREATE TYPE public.webhook_delivery_stage AS ENUM (
+    'pending',
+    'retrying',
+    'delivered',
+    'failed'
+);
+
+
+ALTER TYPE public.webhook_delivery_stage OWNER TO admin;
+
+--
+-- Name: enqueue_webhook_deliveries(); Type: FUNCTION; Schema: public; Owner: admin
+--
+
+CREATE FUNCTION public.enqueue_webhook_deliveries() RETURNS trigger
+    LANGUAGE plpgsql
+    AS $$
+BEGIN
+    INSERT INTO
+        webhook_deliveries (webhook_id, job_id, status, payload)
+    SELECT
+        w.id,
+        NEW.id,
+        NEW.status,
+        json_build_object(
+            'event', 'job.status_changed',
+            'job_id', NEW.id,
+            'ulid', NEW.ulid,
+            'status', NEW.status,
+            'previous_status', OLD.status,
+            'summary', json_build_object(
+                'total', COALESCE(p.total, 0),
+                'parsed', COALESCE(p.parsed, 0),
+                'lang_detected', COALESCE(p.lang_detected, 0),
+                'embedded', COALESCE(p.embedded, 0),
+                'sentiment_scored', COALESCE(p.sentiment_scored, 0),
+                'failed', COALESCE(p.failed, 0)
+            ),
+            'changed_at', CURRENT_TIMESTAMP
+        )
+    FROM webhooks AS w
+        LEFT JOIN job_progress AS p ON p.job_id = NEW.id
+    WHERE w.owner = NEW.owner;
+    RETURN NULL;
+END;
+$$;
+
+
+ALTER FUNCTION public.enqueue_webhook_deliveries() OWNER TO admin;
+
+--
+-- Name: sentiment_score(public.sentiment); Type: FUNCTION; Schema: public; Owner: admin
+--
+
+CREATE FUNCTION public.sentiment_score(s public.sentiment) RETURNS smallint
+    LANGUAGE sql IMMUTABLE
+    AS $$
+    SELECT (
+        CASE s::text
+            WHEN 'very_negative' THEN 1
+            WHEN 'negative' THEN 2
+            WHEN 'neutral' THEN 3
+            WHEN 'positive' THEN 4
+            WHEN 'very_positive' THEN 5
+        END
+    )::smallint;
+$$;
+
+
+ALTER FUNCTION public.sentiment_score(s public.sentiment) OWNER TO admin;
+
 SET default_tablespace = '';
 
 SET default_table_access_method = heap;
 
+--
+-- Name: annotations; Type: TABLE; Schema: public; Owner: admin
+--
+
+CREATE TABLE public.annotations (
+    id bigint NOT NULL,
+    news_id bigint NOT NULL,
+    task character varying(64) NOT NULL,
+    label character varying(64) NOT NULL,
+    model character varying(32) NOT NULL,
+    confidence real,
+    rationale text,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL,
+    owner uuid,
+    task_hash character(64),
+    CONSTRAINT annotations_confidence_check CHECK (((confidence >= (0)::double precision) AND (confidence <= (1)::double precision)))
+);
+
+
+ALTER TABLE public.annotations OWNER TO admin;
+
+--
+-- Name: annotations_id_seq; Type: SEQUENCE; Schema: public; Owner: admin
+--
+
+CREATE SEQUENCE public.annotations_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+
+ALTER SEQUENCE public.annotations_id_seq OWNER TO admin;
+
+--
+-- Name: annotations_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: admin
+--
+
+ALTER SEQUENCE public.annotations_id_seq OWNED BY public.annotations.id;
+
+
 --
 -- Name: apikeys; Type: TABLE; Schema: public; Owner: admin
 --
@@ -112,7 +246,10 @@ CREATE TABLE public.apikeys (
     key text NOT NULL,
     created_at timestamp with time zone DEFAULT now() NOT NULL,
     updated_at timestamp with time zone DEFAULT now() NOT NULL,
-    deleted_at timestamp with time zone
+    deleted_at timestamp with time zone,
+    base_url character varying(256) DEFAULT NULL::character varying,
+    models character varying(64)[] DEFAULT '{}'::character varying[] NOT NULL,
+    auth_header character varying(64) DEFAULT NULL::character varying
 );
 
 
@@ -181,6 +318,41 @@ ALTER SEQUENCE public.apis_id_seq OWNER TO admin;
 ALTER SEQUENCE public.apis_id_seq OWNED BY public.apis.id;
 
 
+--
+-- Name: embedding_chunks; Type: TABLE; Schema: public; Owner: admin
+--
+
+CREATE TABLE public.embedding_chunks (
+    news_id bigint NOT NULL,
+    model character varying(32) NOT NULL,
+    chunk_index smallint NOT NULL,
+    n_tokens integer NOT NULL,
+    embedding public.vector NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    CONSTRAINT embedding_chunks_chunk_index_check CHECK ((chunk_index >= 0)),
+    CONSTRAINT embedding_chunks_n_tokens_check CHECK ((n_tokens >= 0))
+);
+
+
+ALTER TABLE public.embedding_chunks OWNER TO admin;
+
+--
+-- Name: embedding_models; Type: TABLE; Schema: public; Owner: admin
+--
+
+CREATE TABLE public.embedding_models (
+    name character varying(32) NOT NULL,
+    provider character varying(32) NOT NULL,
+    dim integer NOT NULL,
+    metric character varying(16) NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    CONSTRAINT embedding_models_dim_check CHECK ((dim > 0)),
+    CONSTRAINT embedding_models_metric_check CHECK (((metric)::text = ANY ((ARRAY['cosine'::character varying, 'inner_product'::character varying, 'l2'::character varying])::text[])))
+);
+
+
+ALTER TABLE public.embedding_models OWNER TO admin;
+
 --
 -- Name: embeddings; Type: TABLE; Schema: public; Owner: admin
 --
@@ -189,11 +361,17 @@ CREATE TABLE public.embeddings (
     id bigint NOT NULL,
     model character varying(32) NOT NULL,
     news_id bigint NOT NULL,
-    embedding public.vector(1536),
+    embedding public.vector,
     sentiment public.sentiment NOT NULL,
     created_at timestamp with time zone DEFAULT now() NOT NULL,
     updated_at timestamp with time zone DEFAULT now() NOT NULL,
-    deleted_at timestamp with time zone
+    deleted_at timestamp with time zone,
+    score smallint,
+    confidence real,
+    rationale text,
+    prompt_id bigint,
+    CONSTRAINT embeddings_confidence_check CHECK (((confidence >= (0)::double precision) AND (confidence <= (1)::double precision))),
+    CONSTRAINT embeddings_score_check CHECK (((score >= 1) AND (score <= 5)))
 );
 
 
@@ -259,6 +437,169 @@ ALTER SEQUENCE public.endpoints_id_seq OWNER TO admin;
 ALTER SEQUENCE public.endpoints_id_seq OWNED BY public.endpoints.id;
 
 
+--
+-- Name: entity_sentiments; Type: TABLE; Schema: public; Owner: admin
+--
+
+CREATE TABLE public.entity_sentiments (
+    id bigint NOT NULL,
+    news_id bigint NOT NULL,
+    entity character varying(64) NOT NULL,
+    model character varying(32) NOT NULL,
+    sentiment public.sentiment NOT NULL,
+    score smallint NOT NULL,
+    confidence real,
+    rationale text,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL,
+    owner uuid,
+    prompt_id bigint,
+    CONSTRAINT entity_sentiments_confidence_check CHECK (((confidence >= (0)::double precision) AND (confidence <= (1)::double precision))),
+    CONSTRAINT entity_sentiments_score_check CHECK (((score >= 1) AND (score <= 5)))
+);
+
+
+ALTER TABLE public.entity_sentiments OWNER TO admin;
+
+--
+-- Name: entity_sentiments_id_seq; Type: SEQUENCE; Schema: public; Owner: admin
+--
+
+CREATE SEQUENCE public.entity_sentiments_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+
+ALTER SEQUENCE public.entity_sentiments_id_seq OWNER TO admin;
+
+--
+-- Name: entity_sentiments_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: admin
+--
+
+ALTER SEQUENCE public.entity_sentiments_id_seq OWNED BY public.entity_sentiments.id;
+
+
+--
+-- Name: job_items; Type: TABLE; Schema: public; Owner: admin
+--
+
+CREATE TABLE public.job_items (
+    id bigint NOT NULL,
+    job_id bigint NOT NULL,
+    news_id bigint NOT NULL,
+    stage public.job_item_stage DEFAULT 'pending'::public.job_item_stage NOT NULL,
+    attempts integer DEFAULT 0 NOT NULL,
+    last_error text,
+    next_attempt_at timestamp with time zone DEFAULT now() NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL,
+    embedding_id bigint
+);
+
+
+ALTER TABLE public.job_items OWNER TO admin;
+
+--
+-- Name: job_items_id_seq; Type: SEQUENCE; Schema: public; Owner: admin
+--
+
+CREATE SEQUENCE public.job_items_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+
+ALTER SEQUENCE public.job_items_id_seq OWNER TO admin;
+
+--
+-- Name: job_items_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: admin
+--
+
+ALTER SEQUENCE public.job_items_id_seq OWNED BY public.job_items.id;
+
+
+--
+-- Name: job_progress; Type: TABLE; Schema: public; Owner: admin
+--
+
+CREATE TABLE public.job_progress (
+    job_id bigint NOT NULL,
+    total integer DEFAULT 0 NOT NULL,
+    parsed integer DEFAULT 0 NOT NULL,
+    lang_detected integer DEFAULT 0 NOT NULL,
+    embedded integer DEFAULT 0 NOT NULL,
+    sentiment_scored integer DEFAULT 0 NOT NULL,
+    failed integer DEFAULT 0 NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
+ALTER TABLE public.job_progress OWNER TO admin;
+
+--
+-- Name: job_schedules; Type: TABLE; Schema: public; Owner: admin
+--
+
+CREATE TABLE public.job_schedules (
+    job_id bigint NOT NULL,
+    cron_expr character varying(64) NOT NULL,
+    src_cache_query json NOT NULL,
+    next_run_at timestamp with time zone NOT NULL,
+    last_run_at timestamp with time zone,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
+ALTER TABLE public.job_schedules OWNER TO admin;
+
+--
+-- Name: job_usages; Type: TABLE; Schema: public; Owner: admin
+--
+
+CREATE TABLE public.job_usages (
+    id bigint NOT NULL,
+    job_id bigint NOT NULL,
+    provider character varying(32) NOT NULL,
+    model character varying(64) NOT NULL,
+    operation character varying(32) NOT NULL,
+    input_tokens integer DEFAULT 0 NOT NULL,
+    output_tokens integer DEFAULT 0 NOT NULL,
+    cost double precision,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    CONSTRAINT job_usages_input_tokens_check CHECK ((input_tokens >= 0)),
+    CONSTRAINT job_usages_output_tokens_check CHECK ((output_tokens >= 0))
+);
+
+
+ALTER TABLE public.job_usages OWNER TO admin;
+
+--
+-- Name: job_usages_id_seq; Type: SEQUENCE; Schema: public; Owner: admin
+--
+
+CREATE SEQUENCE public.job_usages_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+
+ALTER SEQUENCE public.job_usages_id_seq OWNER TO admin;
+
+--
+-- Name: job_usages_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: admin
+--
+
+ALTER SEQUENCE public.job_usages_id_seq OWNED BY public.job_usages.id;
+
+
 --
 -- Name: jobs; Type: TABLE; Schema: public; Owner: admin
 --
@@ -274,7 +615,10 @@ CREATE TABLE public.jobs (
     llm_query json NOT NULL,
     created_at timestamp with time zone DEFAULT now() NOT NULL,
     updated_at timestamp with time zone DEFAULT now() NOT NULL,
-    deleted_at timestamp with time zone
+    deleted_at timestamp with time zone,
+    partial boolean DEFAULT false NOT NULL,
+    parent_id bigint,
+    cloned_from bigint
 );
 
 
@@ -335,6 +679,24 @@ ALTER SEQUENCE public.keywords_id_seq OWNER TO admin;
 ALTER SEQUENCE public.keywords_id_seq OWNED BY public.keywords.id;
 
 
+--
+-- Name: llm_responses; Type: TABLE; Schema: public; Owner: admin
+--
+
+CREATE TABLE public.llm_responses (
+    provider character varying(32) NOT NULL,
+    model character varying(64) NOT NULL,
+    operation character varying(32) NOT NULL,
+    prompt_id bigint DEFAULT 0 NOT NULL,
+    input_hash character(64) NOT NULL,
+    response jsonb NOT NULL,
+    expires_at timestamp with time zone NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
+ALTER TABLE public.llm_responses OWNER TO admin;
+
 --
 -- Name: logs; Type: TABLE; Schema: public; Owner: admin
 --
@@ -451,232 +813,711 @@ ALTER SEQUENCE public.newsjobs_id_seq OWNED BY public.newsjobs.id;
 
 
 --
--- Name: schema_migrations; Type: TABLE; Schema: public; Owner: admin
+-- Name: prompts; Type: TABLE; Schema: public; Owner: admin
 --
 
-CREATE TABLE public.schema_migrations (
-    version bigint NOT NULL,
-    dirty boolean NOT NULL
+CREATE TABLE public.prompts (
+    id bigint NOT NULL,
+    owner uuid NOT NULL,
+    provider character varying(32) NOT NULL,
+    task character varying(32) NOT NULL,
+    version integer NOT NULL,
+    body text NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    CONSTRAINT prompts_version_check CHECK ((version > 0))
 );
 
 
-ALTER TABLE public.schema_migrations OWNER TO admin;
+ALTER TABLE public.prompts OWNER TO admin;
 
 --
--- Name: users; Type: TABLE; Schema: public; Owner: admin
+-- Name: prompts_id_seq; Type: SEQUENCE; Schema: public; Owner: admin
 --
 
-CREATE TABLE public.users (
-    id uuid DEFAULT gen_random_uuid() NOT NULL,
-    password bytea NOT NULL,
-    first_name character varying(30) NOT NULL,
-    last_name character varying(30) NOT NULL,
-    role public.role NOT NULL,
-    email character varying(320) NOT NULL,
-    opt character varying(128) DEFAULT NULL::character varying,
-    created_at timestamp with time zone DEFAULT now() NOT NULL,
-    updated_at timestamp with time zone DEFAULT now() NOT NULL,
-    deleted_at timestamp with time zone,
-    password_updated_at timestamp with time zone DEFAULT now() NOT NULL
-);
+CREATE SEQUENCE public.prompts_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
 
 
-ALTER TABLE public.users OWNER TO admin;
+ALTER SEQUENCE public.prompts_id_seq OWNER TO admin;
 
 --
--- Name: apikeys id; Type: DEFAULT; Schema: public; Owner: admin
+-- Name: prompts_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: admin
 --
 
-ALTER TABLE ONLY public.apikeys ALTER COLUMN id SET DEFAULT nextval('public.apikeys_id_seq'::regclass);
+ALTER SEQUENCE public.prompts_id_seq OWNED BY public.prompts.id;
 
 
 --
--- Name: apis id; Type: DEFAULT; Schema: public; Owner: admin
+-- Name: schema_migrations; Type: TABLE; Schema: public; Owner: admin
 --
 
-ALTER TABLE ONLY public.apis ALTER COLUMN id SET DEFAULT nextval('public.apis_id_seq'::regclass);
-
-
---
--- Name: embeddings id; Type: DEFAULT; Schema: public; Owner: admin
---
+CREATE TABLE public.schema_migrations (
+    version bigint NOT NULL,
+    dirty boolean NOT NULL
+);
 
-ALTER TABLE ONLY public.embeddings ALTER COLUMN id SET DEFAULT nextval('public.embeddings_id_seq'::regclass);
 
+ALTER TABLE public.schema_migrations OWNER TO admin;
 
 --
--- Name: endpoints id; Type: DEFAULT; Schema: public; Owner: admin
+-- Name: stories; Type: TABLE; Schema: public; Owner: admin
 --
 
-ALTER TABLE ONLY public.endpoints ALTER COLUMN id SET DEFAULT nextval('public.endpoints_id_seq'::regclass);
-
-
---
--- Name: jobs id; Type: DEFAULT; Schema: public; Owner: admin
---
+CREATE TABLE public.stories (
+    id bigint NOT NULL,
+    model character varying(32) NOT NULL,
+    title text NOT NULL,
+    first_publish_at timestamp with time zone NOT NULL,
+    last_publish_at timestamp with time zone NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL
+);
 
-ALTER TABLE ONLY public.jobs ALTER COLUMN id SET DEFAULT nextval('public.jobs_id_seq'::regclass);
 
+ALTER TABLE public.stories OWNER TO admin;
 
 --
--- Name: keywords id; Type: DEFAULT; Schema: public; Owner: admin
+-- Name: stories_id_seq; Type: SEQUENCE; Schema: public; Owner: admin
 --
 
-ALTER TABLE ONLY public.keywords ALTER COLUMN id SET DEFAULT nextval('public.keywords_id_seq'::regclass);
+CREATE SEQUENCE public.stories_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
 
 
+ALTER SEQUENCE public.stories_id_seq OWNER TO admin;
+
 --
--- Name: logs id; Type: DEFAULT; Schema: public; Owner: admin
+-- Name: stories_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: admin
 --
 
-ALTER TABLE ONLY public.logs ALTER COLUMN id SET DEFAULT nextval('public.logs_id_seq'::regclass);
+ALTER SEQUENCE public.stories_id_seq OWNED BY public.stories.id;
 
 
 --
--- Name: news id; Type: DEFAULT; Schema: public; Owner: admin
+-- Name: story_news; Type: TABLE; Schema: public; Owner: admin
 --
 
-ALTER TABLE ONLY public.news ALTER COLUMN id SET DEFAULT nextval('public.news_id_seq'::regclass);
+CREATE TABLE public.story_news (
+    story_id bigint NOT NULL,
+    news_id bigint NOT NULL,
+    model character varying(32) NOT NULL,
+    similarity real DEFAULT 1 NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
 
+ALTER TABLE public.story_news OWNER TO admin;
 
 --
--- Name: newsjobs id; Type: DEFAULT; Schema: public; Owner: admin
+-- Name: users; Type: TABLE; Schema: public; Owner: admin
 --
 
-ALTER TABLE ONLY public.newsjobs ALTER COLUMN id SET DEFAULT nextval('public.newsjobs_id_seq'::regclass);
-
-
---
+CREATE TABLE public.users (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    password bytea NOT NULL,
+    first_name character varying(30) NOT NULL,
+    last_name character varying(30) NOT NULL,
+    role public.role NOT NULL,
+    email character varying(320) NOT NULL,
+    opt character varying(128) DEFAULT NULL::character varying,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL,
+    deleted_at timestamp with time zone,
+    password_updated_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
+ALTER TABLE public.users OWNER TO admin;
+
+--
+-- Name: webhook_deliveries; Type: TABLE; Schema: public; Owner: admin
+--
+
+CREATE TABLE public.webhook_deliveries (
+    id bigint NOT NULL,
+    webhook_id bigint NOT NULL,
+    job_id bigint NOT NULL,
+    status public.job_status NOT NULL,
+    payload json NOT NULL,
+    stage public.webhook_delivery_stage DEFAULT 'pending'::public.webhook_delivery_stage NOT NULL,
+    attempts integer DEFAULT 0 NOT NULL,
+    response_code integer,
+    last_error text,
+    next_attempt_at timestamp with time zone DEFAULT now() NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
+ALTER TABLE public.webhook_deliveries OWNER TO admin;
+
+--
+-- Name: webhook_deliveries_id_seq; Type: SEQUENCE; Schema: public; Owner: admin
+--
+
+CREATE SEQUENCE public.webhook_deliveries_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+
+ALTER SEQUENCE public.webhook_deliveries_id_seq OWNER TO admin;
+
+--
+-- Name: webhook_deliveries_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: admin
+--
+
+ALTER SEQUENCE public.webhook_deliveries_id_seq OWNED BY public.webhook_deliveries.id;
+
+
+--
+-- Name: webhook_secrets; Type: TABLE; Schema: public; Owner: admin
+--
+
+CREATE TABLE public.webhook_secrets (
+    owner uuid NOT NULL,
+    secret character varying(64) NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
+ALTER TABLE public.webhook_secrets OWNER TO admin;
+
+--
+-- Name: webhooks; Type: TABLE; Schema: public; Owner: admin
+--
+
+CREATE TABLE public.webhooks (
+    id bigint NOT NULL,
+    owner uuid NOT NULL,
+    url character varying(2048) NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
+ALTER TABLE public.webhooks OWNER TO admin;
+
+--
+-- Name: webhooks_id_seq; Type: SEQUENCE; Schema: public; Owner: admin
+--
+
+CREATE SEQUENCE public.webhooks_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+
+ALTER SEQUENCE public.webhooks_id_seq OWNER TO admin;
+
+--
+-- Name: webhooks_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: admin
+--
+
+ALTER SEQUENCE public.webhooks_id_seq OWNED BY public.webhooks.id;
+
+
+--
+-- Name: annotations id; Type: DEFAULT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.annotations ALTER COLUMN id SET DEFAULT nextval('public.annotations_id_seq'::regclass);
+
+
+--
+-- Name: apikeys id; Type: DEFAULT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.apikeys ALTER COLUMN id SET DEFAULT nextval('public.apikeys_id_seq'::regclass);
+
+
+--
+-- Name: apis id; Type: DEFAULT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.apis ALTER COLUMN id SET DEFAULT nextval('public.apis_id_seq'::regclass);
+
+
+--
+-- Name: embeddings id; Type: DEFAULT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.embeddings ALTER COLUMN id SET DEFAULT nextval('public.embeddings_id_seq'::regclass);
+
+
+--
+-- Name: endpoints id; Type: DEFAULT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.endpoints ALTER COLUMN id SET DEFAULT nextval('public.endpoints_id_seq'::regclass);
+
+
+--
+-- Name: entity_sentiments id; Type: DEFAULT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.entity_sentiments ALTER COLUMN id SET DEFAULT nextval('public.entity_sentiments_id_seq'::regclass);
+
+
+--
+-- Name: job_items id; Type: DEFAULT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.job_items ALTER COLUMN id SET DEFAULT nextval('public.job_items_id_seq'::regclass);
+
+
+--
+-- Name: job_usages id; Type: DEFAULT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.job_usages ALTER COLUMN id SET DEFAULT nextval('public.job_usages_id_seq'::regclass);
+
+
+--
+-- Name: jobs id; Type: DEFAULT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.jobs ALTER COLUMN id SET DEFAULT nextval('public.jobs_id_seq'::regclass);
+
+
+--
+-- Name: keywords id; Type: DEFAULT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.keywords ALTER COLUMN id SET DEFAULT nextval('public.keywords_id_seq'::regclass);
+
+
+--
+-- Name: logs id; Type: DEFAULT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.logs ALTER COLUMN id SET DEFAULT nextval('public.logs_id_seq'::regclass);
+
+
+--
+-- Name: news id; Type: DEFAULT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.news ALTER COLUMN id SET DEFAULT nextval('public.news_id_seq'::regclass);
+
+
+--
+-- Name: newsjobs id; Type: DEFAULT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.newsjobs ALTER COLUMN id SET DEFAULT nextval('public.newsjobs_id_seq'::regclass);
+
+
+--
+-- Name: prompts id; Type: DEFAULT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.prompts ALTER COLUMN id SET DEFAULT nextval('public.prompts_id_seq'::regclass);
+
+
+--
+-- Name: stories id; Type: DEFAULT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.stories ALTER COLUMN id SET DEFAULT nextval('public.stories_id_seq'::regclass);
+
+
+--
+-- Name: webhook_deliveries id; Type: DEFAULT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.webhook_deliveries ALTER COLUMN id SET DEFAULT nextval('public.webhook_deliveries_id_seq'::regclass);
+
+
+--
+-- Name: webhooks id; Type: DEFAULT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.webhooks ALTER COLUMN id SET DEFAULT nextval('public.webhooks_id_seq'::regclass);
+
+
+--
+-- Name: annotations annotations_pkey; Type: CONSTRAINT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.annotations
+    ADD CONSTRAINT annotations_pkey PRIMARY KEY (id);
+
+
+--
 -- Name: apikeys apikeys_pkey; Type: CONSTRAINT; Schema: public; Owner: admin
 --
 
-ALTER TABLE ONLY public.apikeys
-    ADD CONSTRAINT apikeys_pkey PRIMARY KEY (id);
+ALTER TABLE ONLY public.apikeys
+    ADD CONSTRAINT apikeys_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: apis apis_pkey; Type: CONSTRAINT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.apis
+    ADD CONSTRAINT apis_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: embedding_chunks embedding_chunks_pkey; Type: CONSTRAINT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.embedding_chunks
+    ADD CONSTRAINT embedding_chunks_pkey PRIMARY KEY (news_id, model, chunk_index);
+
+
+--
+-- Name: embedding_models embedding_models_pkey; Type: CONSTRAINT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.embedding_models
+    ADD CONSTRAINT embedding_models_pkey PRIMARY KEY (name);
+
+
+--
+-- Name: embeddings embeddings_pkey; Type: CONSTRAINT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.embeddings
+    ADD CONSTRAINT embeddings_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: endpoints endpoints_pkey; Type: CONSTRAINT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.endpoints
+    ADD CONSTRAINT endpoints_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: entity_sentiments entity_sentiments_pkey; Type: CONSTRAINT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.entity_sentiments
+    ADD CONSTRAINT entity_sentiments_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: job_items job_items_pkey; Type: CONSTRAINT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.job_items
+    ADD CONSTRAINT job_items_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: job_progress job_progress_pkey; Type: CONSTRAINT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.job_progress
+    ADD CONSTRAINT job_progress_pkey PRIMARY KEY (job_id);
+
+
+--
+-- Name: job_schedules job_schedules_pkey; Type: CONSTRAINT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.job_schedules
+    ADD CONSTRAINT job_schedules_pkey PRIMARY KEY (job_id);
+
+
+--
+-- Name: job_usages job_usages_pkey; Type: CONSTRAINT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.job_usages
+    ADD CONSTRAINT job_usages_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: jobs jobs_pkey; Type: CONSTRAINT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.jobs
+    ADD CONSTRAINT jobs_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: jobs jobs_ulid_key; Type: CONSTRAINT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.jobs
+    ADD CONSTRAINT jobs_ulid_key UNIQUE (ulid);
+
+
+--
+-- Name: keywords keywords_pkey; Type: CONSTRAINT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.keywords
+    ADD CONSTRAINT keywords_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: llm_responses llm_responses_pkey; Type: CONSTRAINT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.llm_responses
+    ADD CONSTRAINT llm_responses_pkey PRIMARY KEY (provider, model, operation, prompt_id, input_hash);
+
+
+--
+-- Name: logs logs_pkey; Type: CONSTRAINT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.logs
+    ADD CONSTRAINT logs_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: news news_md5_hash_key; Type: CONSTRAINT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.news
+    ADD CONSTRAINT news_md5_hash_key UNIQUE (md5_hash);
+
+
+--
+-- Name: news news_pkey; Type: CONSTRAINT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.news
+    ADD CONSTRAINT news_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: newsjobs newsjobs_pkey; Type: CONSTRAINT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.newsjobs
+    ADD CONSTRAINT newsjobs_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: prompts prompts_pkey; Type: CONSTRAINT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.prompts
+    ADD CONSTRAINT prompts_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: schema_migrations schema_migrations_pkey; Type: CONSTRAINT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.schema_migrations
+    ADD CONSTRAINT schema_migrations_pkey PRIMARY KEY (version);
+
+
+--
+-- Name: stories stories_pkey; Type: CONSTRAINT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.stories
+    ADD CONSTRAINT stories_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: story_news story_news_pkey; Type: CONSTRAINT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.story_news
+    ADD CONSTRAINT story_news_pkey PRIMARY KEY (story_id, news_id);
+
+
+--
+-- Name: users users_email_key; Type: CONSTRAINT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.users
+    ADD CONSTRAINT users_email_key UNIQUE (email);
+
+
+--
+-- Name: users users_pkey; Type: CONSTRAINT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.users
+    ADD CONSTRAINT users_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: webhook_deliveries webhook_deliveries_pkey; Type: CONSTRAINT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.webhook_deliveries
+    ADD CONSTRAINT webhook_deliveries_pkey PRIMARY KEY (id);
 
 
 --
--- Name: apis apis_pkey; Type: CONSTRAINT; Schema: public; Owner: admin
+-- Name: webhook_secrets webhook_secrets_pkey; Type: CONSTRAINT; Schema: public; Owner: admin
 --
 
-ALTER TABLE ONLY public.apis
-    ADD CONSTRAINT apis_pkey PRIMARY KEY (id);
+ALTER TABLE ONLY public.webhook_secrets
+    ADD CONSTRAINT webhook_secrets_pkey PRIMARY KEY (owner);
 
 
 --
--- Name: embeddings embeddings_pkey; Type: CONSTRAINT; Schema: public; Owner: admin
+-- Name: webhooks webhooks_pkey; Type: CONSTRAINT; Schema: public; Owner: admin
 --
 
-ALTER TABLE ONLY public.embeddings
-    ADD CONSTRAINT embeddings_pkey PRIMARY KEY (id);
+ALTER TABLE ONLY public.webhooks
+    ADD CONSTRAINT webhooks_pkey PRIMARY KEY (id);
 
 
 --
--- Name: endpoints endpoints_pkey; Type: CONSTRAINT; Schema: public; Owner: admin
+-- Name: annotations_news_id_model_owner_task_hash_idx; Type: INDEX; Schema: public; Owner: admin
 --
 
-ALTER TABLE ONLY public.endpoints
-    ADD CONSTRAINT endpoints_pkey PRIMARY KEY (id);
+CREATE UNIQUE INDEX annotations_news_id_model_owner_task_hash_idx ON public.annotations USING btree (news_id, model, owner, task_hash) NULLS NOT DISTINCT;
 
 
 --
--- Name: jobs jobs_pkey; Type: CONSTRAINT; Schema: public; Owner: admin
+-- Name: annotations_task_model_idx; Type: INDEX; Schema: public; Owner: admin
 --
 
-ALTER TABLE ONLY public.jobs
-    ADD CONSTRAINT jobs_pkey PRIMARY KEY (id);
+CREATE INDEX annotations_task_model_idx ON public.annotations USING btree (task, model);
 
 
 --
--- Name: jobs jobs_ulid_key; Type: CONSTRAINT; Schema: public; Owner: admin
+-- Name: apikeys_owner_api_id_idx; Type: INDEX; Schema: public; Owner: admin
 --
 
-ALTER TABLE ONLY public.jobs
-    ADD CONSTRAINT jobs_ulid_key UNIQUE (ulid);
+CREATE INDEX apikeys_owner_api_id_idx ON public.apikeys USING btree (owner, api_id);
 
 
 --
--- Name: keywords keywords_pkey; Type: CONSTRAINT; Schema: public; Owner: admin
+-- Name: embeddings_3_small_idx; Type: INDEX; Schema: public; Owner: admin
 --
 
-ALTER TABLE ONLY public.keywords
-    ADD CONSTRAINT keywords_pkey PRIMARY KEY (id);
+CREATE INDEX embeddings_3_small_idx ON public.embeddings USING hnsw (((embedding)::public.vector(1536)) public.vector_cosine_ops) WHERE ((model)::text = 'text-embedding-3-small'::text);
 
 
 --
--- Name: logs logs_pkey; Type: CONSTRAINT; Schema: public; Owner: admin
+-- Name: embeddings_ada002_idx; Type: INDEX; Schema: public; Owner: admin
 --
 
-ALTER TABLE ONLY public.logs
-    ADD CONSTRAINT logs_pkey PRIMARY KEY (id);
+CREATE INDEX embeddings_ada002_idx ON public.embeddings USING hnsw (((embedding)::public.vector(1536)) public.vector_cosine_ops) WHERE ((model)::text = 'text-embedding-ada-002'::text);
 
 
 --
--- Name: news news_md5_hash_key; Type: CONSTRAINT; Schema: public; Owner: admin
+-- Name: embeddings_anthropic_char_ngram_hash_256_idx; Type: INDEX; Schema: public; Owner: admin
 --
 
-ALTER TABLE ONLY public.news
-    ADD CONSTRAINT news_md5_hash_key UNIQUE (md5_hash);
+CREATE INDEX embeddings_anthropic_char_ngram_hash_256_idx ON public.embeddings USING hnsw (((embedding)::public.vector(256)) public.vector_cosine_ops) WHERE ((model)::text = 'anthropic-char-ngram-hash-256'::text);
 
 
 --
--- Name: news news_pkey; Type: CONSTRAINT; Schema: public; Owner: admin
+-- Name: embeddings_char_ngram_hash_256_idx; Type: INDEX; Schema: public; Owner: admin
 --
 
-ALTER TABLE ONLY public.news
-    ADD CONSTRAINT news_pkey PRIMARY KEY (id);
+CREATE INDEX embeddings_char_ngram_hash_256_idx ON public.embeddings USING hnsw (((embedding)::public.vector(256)) public.vector_cosine_ops) WHERE ((model)::text = 'char-ngram-hash-256'::text);
 
 
 --
--- Name: newsjobs newsjobs_pkey; Type: CONSTRAINT; Schema: public; Owner: admin
+-- Name: embeddings_english_light_v3_idx; Type: INDEX; Schema: public; Owner: admin
 --
 
-ALTER TABLE ONLY public.newsjobs
-    ADD CONSTRAINT newsjobs_pkey PRIMARY KEY (id);
+CREATE INDEX embeddings_english_light_v3_idx ON public.embeddings USING hnsw (((embedding)::public.vector(384)) public.vector_cosine_ops) WHERE ((model)::text = 'embed-english-light-v3.0'::text);
 
 
 --
--- Name: schema_migrations schema_migrations_pkey; Type: CONSTRAINT; Schema: public; Owner: admin
+-- Name: embeddings_english_v3_idx; Type: INDEX; Schema: public; Owner: admin
 --
 
-ALTER TABLE ONLY public.schema_migrations
-    ADD CONSTRAINT schema_migrations_pkey PRIMARY KEY (version);
+CREATE INDEX embeddings_english_v3_idx ON public.embeddings USING hnsw (((embedding)::public.vector(1024)) public.vector_cosine_ops) WHERE ((model)::text = 'embed-english-v3.0'::text);
 
 
 --
--- Name: users users_email_key; Type: CONSTRAINT; Schema: public; Owner: admin
+-- Name: embeddings_model_sentiment_idx; Type: INDEX; Schema: public; Owner: admin
 --
 
-ALTER TABLE ONLY public.users
-    ADD CONSTRAINT users_email_key UNIQUE (email);
+CREATE INDEX embeddings_model_sentiment_idx ON public.embeddings USING btree (model, sentiment);
 
 
 --
--- Name: users users_pkey; Type: CONSTRAINT; Schema: public; Owner: admin
+-- Name: embeddings_multilingual_light_v3_idx; Type: INDEX; Schema: public; Owner: admin
 --
 
-ALTER TABLE ONLY public.users
-    ADD CONSTRAINT users_pkey PRIMARY KEY (id);
+CREATE INDEX embeddings_multilingual_light_v3_idx ON public.embeddings USING hnsw (((embedding)::public.vector(384)) public.vector_cosine_ops) WHERE ((model)::text = 'embed-multilingual-light-v3.0'::text);
 
 
 --
--- Name: apikeys_owner_api_id_idx; Type: INDEX; Schema: public; Owner: admin
+-- Name: embeddings_multilingual_v3_idx; Type: INDEX; Schema: public; Owner: admin
 --
 
-CREATE INDEX apikeys_owner_api_id_idx ON public.apikeys USING btree (owner, api_id);
+CREATE INDEX embeddings_multilingual_v3_idx ON public.embeddings USING hnsw (((embedding)::public.vector(1024)) public.vector_cosine_ops) WHERE ((model)::text = 'embed-multilingual-v3.0'::text);
 
 
 --
--- Name: embeddings_embedding_idx; Type: INDEX; Schema: public; Owner: admin
+-- Name: embeddings_news_id_model_idx; Type: INDEX; Schema: public; Owner: admin
 --
 
-CREATE INDEX embeddings_embedding_idx ON public.embeddings USING hnsw (embedding public.vector_ip_ops);
+CREATE INDEX embeddings_news_id_model_idx ON public.embeddings USING btree (news_id, model);
 
 
 --
--- Name: embeddings_model_sentiment_idx; Type: INDEX; Schema: public; Owner: admin
+-- Name: entity_sentiments_entity_model_idx; Type: INDEX; Schema: public; Owner: admin
 --
 
-CREATE INDEX embeddings_model_sentiment_idx ON public.embeddings USING btree (model, sentiment);
+CREATE INDEX entity_sentiments_entity_model_idx ON public.entity_sentiments USING btree (entity, model);
+
+
+--
+-- Name: entity_sentiments_news_id_entity_model_owner_prompt_id_idx; Type: INDEX; Schema: public; Owner: admin
+--
+
+CREATE UNIQUE INDEX entity_sentiments_news_id_entity_model_owner_prompt_id_idx ON public.entity_sentiments USING btree (news_id, entity, model, owner, prompt_id) NULLS NOT DISTINCT;
+
+
+--
+-- Name: job_items_job_id_news_id_idx; Type: INDEX; Schema: public; Owner: admin
+--
+
+CREATE UNIQUE INDEX job_items_job_id_news_id_idx ON public.job_items USING btree (job_id, news_id);
+
+
+--
+-- Name: job_items_job_id_stage_next_attempt_at_idx; Type: INDEX; Schema: public; Owner: admin
+--
+
+CREATE INDEX job_items_job_id_stage_next_attempt_at_idx ON public.job_items USING btree (job_id, stage, next_attempt_at);
+
+
+--
+-- Name: job_schedules_next_run_at_idx; Type: INDEX; Schema: public; Owner: admin
+--
+
+CREATE INDEX job_schedules_next_run_at_idx ON public.job_schedules USING btree (next_run_at);
+
+
+--
+-- Name: job_usages_job_id_idx; Type: INDEX; Schema: public; Owner: admin
+--
+
+CREATE INDEX job_usages_job_id_idx ON public.job_usages USING btree (job_id);
+
+
+--
+-- Name: jobs_cloned_from_idx; Type: INDEX; Schema: public; Owner: admin
+--
+
+CREATE INDEX jobs_cloned_from_idx ON public.jobs USING btree (cloned_from);
 
 
 --
@@ -686,6 +1527,13 @@ CREATE INDEX embeddings_model_sentiment_idx ON public.embeddings USING btree (mo
 CREATE INDEX jobs_owner_status_idx ON public.jobs USING btree (owner, status);
 
 
+--
+-- Name: jobs_parent_id_idx; Type: INDEX; Schema: public; Owner: admin
+--
+
+CREATE INDEX jobs_parent_id_idx ON public.jobs USING btree (parent_id);
+
+
 --
 -- Name: jobs_ulid_idx; Type: INDEX; Schema: public; Owner: admin
 --
@@ -700,6 +1548,13 @@ CREATE INDEX jobs_ulid_idx ON public.jobs USING btree (ulid);
 CREATE INDEX keywords_keyword_idx ON public.keywords USING btree (keyword);
 
 
+--
+-- Name: llm_responses_expires_at_idx; Type: INDEX; Schema: public; Owner: admin
+--
+
+CREATE INDEX llm_responses_expires_at_idx ON public.llm_responses USING btree (expires_at);
+
+
 --
 -- Name: logs_user_id_type_idx; Type: INDEX; Schema: public; Owner: admin
 --
@@ -735,6 +1590,27 @@ CREATE INDEX news_publish_at_idx ON public.news USING btree (publish_at);
 CREATE INDEX newsjobs_job_id_news_id_idx ON public.newsjobs USING btree (job_id, news_id);
 
 
+--
+-- Name: prompts_owner_provider_task_version_idx; Type: INDEX; Schema: public; Owner: admin
+--
+
+CREATE UNIQUE INDEX prompts_owner_provider_task_version_idx ON public.prompts USING btree (owner, provider, task, version);
+
+
+--
+-- Name: stories_last_publish_at_idx; Type: INDEX; Schema: public; Owner: admin
+--
+
+CREATE INDEX stories_last_publish_at_idx ON public.stories USING btree (last_publish_at);
+
+
+--
+-- Name: story_news_model_news_id_idx; Type: INDEX; Schema: public; Owner: admin
+--
+
+CREATE UNIQUE INDEX story_news_model_news_id_idx ON public.story_news USING btree (model, news_id);
+
+
 --
 -- Name: users_email_idx; Type: INDEX; Schema: public; Owner: admin
 --
@@ -742,6 +1618,50 @@ CREATE INDEX newsjobs_job_id_news_id_idx ON public.newsjobs USING btree (job_id,
 CREATE INDEX users_email_idx ON public.users USING btree (email);
 
 
+--
+-- Name: webhook_deliveries_stage_next_attempt_at_idx; Type: INDEX; Schema: public; Owner: admin
+--
+
+CREATE INDEX webhook_deliveries_stage_next_attempt_at_idx ON public.webhook_deliveries USING btree (stage, next_attempt_at);
+
+
+--
+-- Name: webhook_deliveries_webhook_id_idx; Type: INDEX; Schema: public; Owner: admin
+--
+
+CREATE INDEX webhook_deliveries_webhook_id_idx ON public.webhook_deliveries USING btree (webhook_id);
+
+
+--
+-- Name: webhooks_owner_url_idx; Type: INDEX; Schema: public; Owner: admin
+--
+
+CREATE UNIQUE INDEX webhooks_owner_url_idx ON public.webhooks USING btree (owner, url);
+
+
+--
+-- Name: jobs jobs_status_webhook; Type: TRIGGER; Schema: public; Owner: admin
+--
+
+CREATE TRIGGER jobs_status_webhook AFTER UPDATE OF status ON public.jobs FOR EACH ROW WHEN ((old.status IS DISTINCT FROM new.status)) EXECUTE FUNCTION public.enqueue_webhook_deliveries();
+
+
+--
+-- Name: annotations annotations_news_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.annotations
+    ADD CONSTRAINT annotations_news_id_fkey FOREIGN KEY (news_id) REFERENCES public.news(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
+--
+-- Name: annotations annotations_owner_fkey; Type: FK CONSTRAINT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.annotations
+    ADD CONSTRAINT annotations_owner_fkey FOREIGN KEY (owner) REFERENCES public.users(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
 --
 -- Name: apikeys apikeys_api_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: admin
 --
@@ -758,6 +1678,30 @@ ALTER TABLE ONLY public.apikeys
     ADD CONSTRAINT apikeys_owner_fkey FOREIGN KEY (owner) REFERENCES public.users(id) ON UPDATE CASCADE ON DELETE CASCADE;
 
 
+--
+-- Name: embedding_chunks embedding_chunks_model_fkey; Type: FK CONSTRAINT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.embedding_chunks
+    ADD CONSTRAINT embedding_chunks_model_fkey FOREIGN KEY (model) REFERENCES public.embedding_models(name) ON UPDATE CASCADE;
+
+
+--
+-- Name: embedding_chunks embedding_chunks_news_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.embedding_chunks
+    ADD CONSTRAINT embedding_chunks_news_id_fkey FOREIGN KEY (news_id) REFERENCES public.news(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
+--
+-- Name: embeddings embeddings_model_fkey; Type: FK CONSTRAINT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.embeddings
+    ADD CONSTRAINT embeddings_model_fkey FOREIGN KEY (model) REFERENCES public.embedding_models(name) ON UPDATE CASCADE;
+
+
 --
 -- Name: embeddings embeddings_news_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: admin
 --
@@ -766,6 +1710,14 @@ ALTER TABLE ONLY public.embeddings
     ADD CONSTRAINT embeddings_news_id_fkey FOREIGN KEY (news_id) REFERENCES public.news(id) ON UPDATE CASCADE ON DELETE CASCADE;
 
 
+--
+-- Name: embeddings embeddings_prompt_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.embeddings
+    ADD CONSTRAINT embeddings_prompt_id_fkey FOREIGN KEY (prompt_id) REFERENCES public.prompts(id) ON UPDATE CASCADE ON DELETE SET NULL;
+
+
 --
 -- Name: endpoints endpoints_api_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: admin
 --
@@ -774,6 +1726,86 @@ ALTER TABLE ONLY public.endpoints
     ADD CONSTRAINT endpoints_api_id_fkey FOREIGN KEY (api_id) REFERENCES public.apis(id) ON UPDATE CASCADE ON DELETE CASCADE;
 
 
+--
+-- Name: entity_sentiments entity_sentiments_news_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.entity_sentiments
+    ADD CONSTRAINT entity_sentiments_news_id_fkey FOREIGN KEY (news_id) REFERENCES public.news(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
+--
+-- Name: entity_sentiments entity_sentiments_owner_fkey; Type: FK CONSTRAINT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.entity_sentiments
+    ADD CONSTRAINT entity_sentiments_owner_fkey FOREIGN KEY (owner) REFERENCES public.users(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
+--
+-- Name: entity_sentiments entity_sentiments_prompt_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.entity_sentiments
+    ADD CONSTRAINT entity_sentiments_prompt_id_fkey FOREIGN KEY (prompt_id) REFERENCES public.prompts(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
+--
+-- Name: job_items job_items_embedding_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.job_items
+    ADD CONSTRAINT job_items_embedding_id_fkey FOREIGN KEY (embedding_id) REFERENCES public.embeddings(id) ON UPDATE CASCADE ON DELETE SET NULL;
+
+
+--
+-- Name: job_items job_items_job_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.job_items
+    ADD CONSTRAINT job_items_job_id_fkey FOREIGN KEY (job_id) REFERENCES public.jobs(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
+--
+-- Name: job_items job_items_news_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.job_items
+    ADD CONSTRAINT job_items_news_id_fkey FOREIGN KEY (news_id) REFERENCES public.news(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
+--
+-- Name: job_progress job_progress_job_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.job_progress
+    ADD CONSTRAINT job_progress_job_id_fkey FOREIGN KEY (job_id) REFERENCES public.jobs(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
+--
+-- Name: job_schedules job_schedules_job_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.job_schedules
+    ADD CONSTRAINT job_schedules_job_id_fkey FOREIGN KEY (job_id) REFERENCES public.jobs(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
+--
+-- Name: job_usages job_usages_job_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.job_usages
+    ADD CONSTRAINT job_usages_job_id_fkey FOREIGN KEY (job_id) REFERENCES public.jobs(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
+--
+-- Name: jobs jobs_cloned_from_fkey; Type: FK CONSTRAINT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.jobs
+    ADD CONSTRAINT jobs_cloned_from_fkey FOREIGN KEY (cloned_from) REFERENCES public.jobs(id) ON UPDATE CASCADE ON DELETE SET NULL;
+
+
 --
 -- Name: jobs jobs_llm_api_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: admin
 --
@@ -790,6 +1822,14 @@ ALTER TABLE ONLY public.jobs
     ADD CONSTRAINT jobs_owner_fkey FOREIGN KEY (owner) REFERENCES public.users(id) ON UPDATE CASCADE ON DELETE CASCADE;
 
 
+--
+-- Name: jobs jobs_parent_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.jobs
+    ADD CONSTRAINT jobs_parent_id_fkey FOREIGN KEY (parent_id) REFERENCES public.jobs(id) ON UPDATE CASCADE ON DELETE SET NULL;
+
+
 --
 -- Name: jobs jobs_src_api_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: admin
 --
@@ -830,6 +1870,70 @@ ALTER TABLE ONLY public.newsjobs
     ADD CONSTRAINT newsjobs_news_id_fkey FOREIGN KEY (news_id) REFERENCES public.news(id) ON UPDATE CASCADE ON DELETE CASCADE;
 
 
+--
+-- Name: prompts prompts_owner_fkey; Type: FK CONSTRAINT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.prompts
+    ADD CONSTRAINT prompts_owner_fkey FOREIGN KEY (owner) REFERENCES public.users(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
+--
+-- Name: stories stories_model_fkey; Type: FK CONSTRAINT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.stories
+    ADD CONSTRAINT stories_model_fkey FOREIGN KEY (model) REFERENCES public.embedding_models(name) ON UPDATE CASCADE;
+
+
+--
+-- Name: story_news story_news_news_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.story_news
+    ADD CONSTRAINT story_news_news_id_fkey FOREIGN KEY (news_id) REFERENCES public.news(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
+--
+-- Name: story_news story_news_story_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.story_news
+    ADD CONSTRAINT story_news_story_id_fkey FOREIGN KEY (story_id) REFERENCES public.stories(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
+--
+-- Name: webhook_deliveries webhook_deliveries_job_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.webhook_deliveries
+    ADD CONSTRAINT webhook_deliveries_job_id_fkey FOREIGN KEY (job_id) REFERENCES public.jobs(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
+--
+-- Name: webhook_deliveries webhook_deliveries_webhook_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.webhook_deliveries
+    ADD CONSTRAINT webhook_deliveries_webhook_id_fkey FOREIGN KEY (webhook_id) REFERENCES public.webhooks(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
+--
+-- Name: webhook_secrets webhook_secrets_owner_fkey; Type: FK CONSTRAINT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.webhook_secrets
+    ADD CONSTRAINT webhook_secrets_owner_fkey FOREIGN KEY (owner) REFERENCES public.users(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
+--
+-- Name: webhooks webhooks_owner_fkey; Type: FK CONSTRAINT; Schema: public; Owner: admin
+--
+
+ALTER TABLE ONLY public.webhooks
+    ADD CONSTRAINT webhooks_owner_fkey FOREIGN KEY (owner) REFERENCES public.users(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
 --
 -- PostgreSQL database dump complete
 --
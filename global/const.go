@@ -6,6 +6,8 @@ type CtxKey string
 
 const (
 	CtxUserInfo CtxKey = "UserInfo"
+	// a <-chan struct{} that is closed when the server is shutting down
+	CtxServerShutdown CtxKey = "ServerShutdown"
 )
 
 const (
@@ -27,6 +29,11 @@ const (
 	PREVIEW_CACHE_KEY_SALT_LEN = 32
 )
 
+const JOB_PROGRESS_STREAM_INTERVAL = 1 * time.Second
+
+// the number of rows queried at a time while exporting a job
+const JOB_EXPORT_PAGE_SIZE = 500
+
 const (
 	JWT_SECRET_CACHE_PREFIX = "JWT-SECRET:"
 	JWT_SECRET_CACHE_SUFFIX = ""
@@ -245,4 +245,33 @@ func setDefaultForOption() {
 	viper.SetDefault("App.SSL.Path", "./secrets")
 	viper.SetDefault("App.SSL.CertFile", "server.crt")
 	viper.SetDefault("App.SSL.KeyFile", ".server.key")
+
+	viper.SetDefault("JobRunner.Interval", 10*time.Second)
+	viper.SetDefault("JobRunner.NJobsPerUser", 1)
+	viper.SetDefault("JobRunner.Timeout", 30*time.Minute)
+	viper.SetDefault("JobRunner.MaxAttempts", 5)
+	viper.SetDefault("JobRunner.BackoffBase", 2*time.Second)
+	viper.SetDefault("JobRunner.BackoffMax", time.Minute)
+	viper.SetDefault("JobRunner.BatchBudget", 2000)
+	viper.SetDefault("JobRunner.BatchSize", 10)
+
+	viper.SetDefault("JobScheduler.Interval", time.Minute)
+	viper.SetDefault("JobScheduler.NSchedules", 10)
+	viper.SetDefault("JobScheduler.MaxPages", 5)
+	viper.SetDefault("JobScheduler.Timeout", 5*time.Minute)
+
+	viper.SetDefault("Webhook.Interval", 10*time.Second)
+	viper.SetDefault("Webhook.NDeliveries", 50)
+	viper.SetDefault("Webhook.Timeout", 10*time.Second)
+	viper.SetDefault("Webhook.MaxAttempts", 8)
+	viper.SetDefault("Webhook.BackoffBase", 10*time.Second)
+	viper.SetDefault("Webhook.BackoffMax", time.Hour)
+	viper.SetDefault("Webhook.AllowPrivate", false)
+
+	viper.SetDefault("Quota.User.MaxRunningJobs", 2)
+	viper.SetDefault("Quota.User.MaxArticlesPerJob", 200)
+	viper.SetDefault("Quota.User.MaxJobsPerDay", 20)
+	viper.SetDefault("Quota.Admin.MaxRunningJobs", 0)
+	viper.SetDefault("Quota.Admin.MaxArticlesPerJob", 0)
+	viper.SetDefault("Quota.Admin.MaxJobsPerDay", 0)
 }
@@ -63,7 +63,11 @@ var jsonConfig = []byte(`{
         "forbidden": "/forbidden"
       }
     }
-  }
+  },
+  "pricing": [
+    {"model": "gpt-4o-mini", "input": 0.15, "output": 0.6},
+    {"model": "embed-multilingual-light-v3.0", "input": 0.1, "output": 0}
+  ]
 }`)
 
 func TestReadConfig(t *testing.T) {
@@ -83,6 +87,16 @@ func TestReadConfig(t *testing.T) {
 	require.Equal(t, "/js", opt.App.StaticFile.SubFolder["js"])
 	require.Equal(t, "/static/*", opt.App.RoutePattern.StaticPage)
 
+	// the dots in the model names are kept
+	cost, ok := opt.Pricing.Cost("embed-multilingual-light-v3.0", 1000, 0)
+	require.True(t, ok)
+	require.InDelta(t, 1e-4, cost, 1e-12)
+	cost, ok = opt.Pricing.Cost("gpt-4o-mini", 1000, 1000)
+	require.True(t, ok)
+	require.InDelta(t, 7.5e-4, cost, 1e-12)
+	_, ok = opt.Pricing.Cost("command", 1000, 1000)
+	require.False(t, ok)
+
 	t.Log(opt.App.Template)
 }
 
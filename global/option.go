@@ -9,11 +9,17 @@ import (
 )
 
 type Option struct {
-	Token        TokenMakerOption        `mapstructure:"token"`
-	RateLimiter  RateLimiter             `mapstructure:"ratelimiter"`
-	Password     PasswordOption          `mapstructure:"password"`
-	App          AppOption               `mapstructure:"app"`
-	Microservice map[string]Microservice `mapstructure:"microservice"`
+	Token          TokenMakerOption        `mapstructure:"token"`
+	RateLimiter    RateLimiter             `mapstructure:"ratelimiter"`
+	Password       PasswordOption          `mapstructure:"password"`
+	App            AppOption               `mapstructure:"app"`
+	Microservice   map[string]Microservice `mapstructure:"microservice"`
+	JobRunner      JobRunnerOption         `mapstructure:"jobRunner"`
+	JobScheduler   JobSchedulerOption      `mapstructure:"jobScheduler"`
+	StoryClusterer StoryClustererOption    `mapstructure:"storyClusterer"`
+	Webhook        WebhookOption           `mapstructure:"webhook"`
+	Quota          QuotaOptions            `mapstructure:"quota"`
+	Pricing        PriceOptions            `mapstructure:"pricing"`
 }
 
 func (opt Option) String() string {
@@ -71,6 +77,86 @@ func (rlOpt RateLimit) RateLimitOption() ratelimit.Option {
 	return ratelimit.Per(rlOpt.Per)
 }
 
+type JobRunnerOption struct {
+	Interval     time.Duration `mapstructure:"interval"`
+	NJobsPerUser int           `mapstructure:"nJobsPerUser"`
+	Timeout      time.Duration `mapstructure:"timeout"`
+	MaxAttempts  int           `mapstructure:"maxAttempts"`
+	BackoffBase  time.Duration `mapstructure:"backoffBase"`
+	BackoffMax   time.Duration `mapstructure:"backoffMax"`
+	BatchBudget  int           `mapstructure:"batchBudget"`
+	BatchSize    int           `mapstructure:"batchSize"`
+	CacheTTL     time.Duration `mapstructure:"cacheTTL"`
+}
+
+type JobSchedulerOption struct {
+	Interval   time.Duration `mapstructure:"interval"`
+	NSchedules int           `mapstructure:"nSchedules"`
+	MaxPages   int           `mapstructure:"maxPages"`
+	Timeout    time.Duration `mapstructure:"timeout"`
+}
+
+// StoryClustererOption groups the news of different sources into stories, a
+// news joins a story if its mean cosine similarity to the news of the story
+// is at least Threshold and one of them is published within Window of it.
+type StoryClustererOption struct {
+	Interval  time.Duration `mapstructure:"interval"`
+	BatchSize int           `mapstructure:"batchSize"`
+	Threshold float64       `mapstructure:"threshold"`
+	Window    time.Duration `mapstructure:"window"`
+}
+
+type WebhookOption struct {
+	Interval     time.Duration `mapstructure:"interval"`
+	NDeliveries  int           `mapstructure:"nDeliveries"`
+	Timeout      time.Duration `mapstructure:"timeout"`
+	MaxAttempts  int           `mapstructure:"maxAttempts"`
+	BackoffBase  time.Duration `mapstructure:"backoffBase"`
+	BackoffMax   time.Duration `mapstructure:"backoffMax"`
+	AllowPrivate bool          `mapstructure:"allowPrivate"`
+}
+
+// QuotaOption limits the jobs a user can submit, 0 means unlimited.
+type QuotaOption struct {
+	MaxRunningJobs    int `mapstructure:"maxRunningJobs"`
+	MaxArticlesPerJob int `mapstructure:"maxArticlesPerJob"`
+	MaxJobsPerDay     int `mapstructure:"maxJobsPerDay"`
+}
+
+// QuotaOptions are the quotas keyed by role.
+type QuotaOptions map[string]QuotaOption
+
+// Of returns the quota of the role, roles without a quota share the one of
+// "user".
+func (q QuotaOptions) Of(role string) QuotaOption {
+	if opt, ok := q[role]; ok {
+		return opt
+	}
+	return q["user"]
+}
+
+// ModelPrice is the price of a model in USD per million tokens.
+type ModelPrice struct {
+	Model  string  `mapstructure:"model"`
+	Input  float64 `mapstructure:"input"`
+	Output float64 `mapstructure:"output"`
+}
+
+// PriceOptions is the price table of the models. It is a list rather than a
+// map keyed by model, since viper splits keys at the dots in the names.
+type PriceOptions []ModelPrice
+
+// Cost returns the estimated cost in USD of the tokens of the model, and false
+// if the model is not in the table.
+func (p PriceOptions) Cost(model string, input, output int) (float64, bool) {
+	for _, price := range p {
+		if price.Model == model {
+			return (float64(input)*price.Input + float64(output)*price.Output) / 1e6, true
+		}
+	}
+	return 0, false
+}
+
 type PasswordOption struct {
 	ASCIIOnly     bool `mapstructure:"asciiOnly"`
 	MinLength     int  `mapstructure:"minLength"`
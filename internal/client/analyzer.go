@@ -0,0 +1,208 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client/option"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/model"
+)
+
+var ErrAnalyzerHasBeenRegistered = errors.New("the Analyzer has already been registered")
+var ErrAnalyzerNotFound = errors.New("unregistered analyzer")
+var ErrEmptyResponse = errors.New("empty response")
+var ErrResponseLengthMismatch = errors.New("the length of response does not match the input")
+
+var AnalyzerRepo = analyzerRepo{}
+
+func RegisterAnalyzer(anlz Analyzer) error {
+	return AnalyzerRepo.RegisterAnalyzer(anlz)
+}
+
+// EmbeddingModel is an embedding model supported by an Analyzer, Dim is the
+// size of its vectors and MaxTokens the limit of its input, 0 if there is
+// none. A legacy model is kept for the jobs created with it, but is not
+// offered for new ones.
+type EmbeddingModel struct {
+	Name      string
+	Dim       int
+	MaxTokens int
+	Legacy    bool
+}
+
+// ModelInfo describes an Analyzer and the options it accepts.
+type ModelInfo struct {
+	// the value of AnalyzerOption.APIName, the Analyzer is registered under
+	Name                  string
+	DefaultEmbeddingModel string
+	EmbeddingModels       []EmbeddingModel
+	SentimentPrompt       string
+	// the model the prompts are sent to, empty if the Analyzer sends none
+	ChatModel string
+	// the options of AnalyzerOption.InputType and .Truncate, empty if the
+	// Analyzer ignores them
+	InputTypes []string
+	Truncates  []string
+	// true if the Analyzer runs locally and needs no API key
+	Offline bool
+	// true if the Analyzer could be used on the compatible servers at
+	// Credential.BaseURL
+	CompatibleServers bool
+}
+
+// EmbeddingModelOf returns the embedding model specified by opt, or the default
+// one if there is none.
+func (info ModelInfo) EmbeddingModelOf(opt *option.AnalyzerOption) string {
+	if opt == nil || opt.EmbeddingModel == "" {
+		return info.DefaultEmbeddingModel
+	}
+	return opt.EmbeddingModel
+}
+
+// EmbeddingModelNamed returns the embedding model of the name and whether the
+// Analyzer supports it.
+func (info ModelInfo) EmbeddingModelNamed(name string) (EmbeddingModel, bool) {
+	for _, m := range info.EmbeddingModels {
+		if m.Name == name {
+			return m, true
+		}
+	}
+	return EmbeddingModel{}, false
+}
+
+// Credential is an API key and the server it is used on. Only analyzers of
+// which ModelInfo.CompatibleServers is set use the server fields.
+type Credential struct {
+	Key string
+	// the base url of the server, the official one if empty
+	BaseURL string
+	// the header the key is sent in, the provider's default if empty
+	AuthHeader string
+	// the chat models served, any model is allowed if empty
+	Models []string
+}
+
+// SentimentResult is the sentiment of a text. Confidence is nil and Rationale
+// is empty if the provider does not give them. Err is the error of the text
+// if the provider classifies the texts one by one and fails on it, the
+// results of the other texts are still valid then.
+type SentimentResult struct {
+	Sentiment  model.Sentiment
+	Confidence *float32
+	Rationale  string
+	Err        error `json:"-"`
+}
+
+// Analyzer is a language model provider. Both methods return a result for
+// each of the texts, in order, and ErrResponseLengthMismatch if the provider
+// returns fewer results than the texts. ClassifySentiment also returns it on
+// more results, since the scores can not be matched to the texts then.
+type Analyzer interface {
+	ModelInfo() ModelInfo
+	Embed(ctx context.Context, cli *http.Client, cred Credential,
+		opt *option.AnalyzerOption, texts ...string) ([][]float32, error)
+	ClassifySentiment(ctx context.Context, cli *http.Client, cred Credential,
+		opt *option.AnalyzerOption, texts ...string) ([]SentimentResult, error)
+}
+
+// EntitySentiment is the sentiment of a text toward an entity it mentions.
+type EntitySentiment struct {
+	Entity string
+	SentimentResult
+}
+
+// EntityAnalyzer is an Analyzer that also classifies the sentiment of texts
+// toward the entities in opt.Entities, such as people, parties and
+// organisations. ClassifyEntitySentiment returns the results of each of the
+// texts, in order, of which the entities the text does not mention are left
+// out.
+type EntityAnalyzer interface {
+	Analyzer
+	ClassifyEntitySentiment(ctx context.Context, cli *http.Client, cred Credential,
+		opt *option.AnalyzerOption, texts ...string) ([][]EntitySentiment, error)
+}
+
+// Annotation is the label of a text in a custom classification task. Label is
+// empty if the provider gives none of the labels of the task.
+type Annotation struct {
+	Label      string
+	Confidence *float32
+	Rationale  string
+}
+
+// Classifier is an Analyzer that also labels texts in the custom
+// classification task in opt.ClassificationOptions, such as the stance or the
+// frame of the news. Classify returns an annotation for each of the texts, in
+// order.
+type Classifier interface {
+	Analyzer
+	Classify(ctx context.Context, cli *http.Client, cred Credential,
+		opt *option.AnalyzerOption, texts ...string) ([]Annotation, error)
+}
+
+type analyzerRepo map[string]Analyzer
+
+func (repo analyzerRepo) RegisterAnalyzer(anlz Analyzer) error {
+	name := anlz.ModelInfo().Name
+	if _, ok := repo[name]; ok {
+		return ErrAnalyzerHasBeenRegistered
+	}
+	repo[name] = anlz
+	return nil
+}
+
+func (repo analyzerRepo) Get(apiname string) (Analyzer, error) {
+	anlz, ok := repo[apiname]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrAnalyzerNotFound, apiname)
+	}
+	return anlz, nil
+}
+
+// Names returns the names of the registered analyzers in alphabetical order.
+func (repo analyzerRepo) Names() []string {
+	names := make([]string, 0, len(repo))
+	for name := range repo {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// EmbeddingModel returns the embedding model used by the analyzer of opt,
+// which is the default model of the analyzer if opt does not specify one.
+func (repo analyzerRepo) EmbeddingModel(opt *option.AnalyzerOption) (string, error) {
+	if opt == nil {
+		return "", fmt.Errorf("%w: empty analyzer options", ErrAnalyzerNotFound)
+	}
+	anlz, err := repo.Get(opt.APIName)
+	if err != nil {
+		return "", err
+	}
+	return anlz.ModelInfo().EmbeddingModelOf(opt), nil
+}
+
+// ToSentiment converts the score in the SentimentAnalysisPrompt of the
+// providers, which is on the 1-5 scale, to a model.Sentiment.
+func ToSentiment(score int) (model.Sentiment, error) {
+	return model.SentimentOfScore(score)
+}
+
+// WrapText encloses each of the texts with the symbols used by the
+// SentimentAnalysisPrompt of the providers.
+func WrapText(texts ...string) string {
+	sb := strings.Builder{}
+	for i, text := range texts {
+		if i > 0 {
+			sb.WriteString(" ")
+		}
+		sb.WriteString("[^]")
+		sb.WriteString(text)
+		sb.WriteString("[$]")
+	}
+	return sb.String()
+}
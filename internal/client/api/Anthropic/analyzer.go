@@ -0,0 +1,150 @@
+package anthropic
+
+import (
+	"context"
+	"net/http"
+
+	cli "github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client"
+	lexicon "github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client/api/Lexicon"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client/option"
+)
+
+func init() {
+	cli.RegisterAnalyzer(Analyzer{})
+}
+
+// Analyzer analyzes news with the messages endpoint. Anthropic provides no
+// embeddings endpoint, so the articles are embedded locally as the lexicon
+// analyzer does.
+type Analyzer struct{}
+
+func (Analyzer) ModelInfo() cli.ModelInfo {
+	return cli.ModelInfo{
+		Name:                  "anthropic",
+		DefaultEmbeddingModel: EmbeddingModelCharNgram256,
+		EmbeddingModels: []cli.EmbeddingModel{
+			{Name: EmbeddingModelCharNgram256, Dim: lexicon.EmbeddingDim},
+			// the model of the jobs created before the one above was added
+			{Name: lexicon.EmbeddingModelCharNgram256, Dim: lexicon.EmbeddingDim, Legacy: true},
+		},
+		SentimentPrompt: SentimentAnalysisPrompt,
+		ChatModel:       ModelClaude3Haiku,
+	}
+}
+
+func (a Analyzer) Embed(ctx context.Context, client *http.Client, cred cli.Credential,
+	opt *option.AnalyzerOption, texts ...string) ([][]float32, error) {
+	embds := make([][]float32, len(texts))
+	for i, text := range texts {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		embds[i] = lexicon.Embed(text)
+	}
+	return embds, nil
+}
+
+func (a Analyzer) ClassifySentiment(ctx context.Context, client *http.Client, cred cli.Credential,
+	opt *option.AnalyzerOption, texts ...string) ([]cli.SentimentResult, error) {
+	req := NewSentimentAnalysisRequestWithPrompt(cred.Key,
+		opt.SentimentPromptOr(SentimentAnalysisPrompt), cli.WrapText(texts...))
+	req.Body.MaxTokens = opt.MaxTokens
+
+	obj, err := send(ctx, client, cli.OperationSentiment, req)
+	if err != nil {
+		return nil, err
+	}
+
+	scores, err := SentimentAnalysisObject{*obj}.Content()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(scores) == 0 {
+		return nil, cli.ErrEmptyResponse
+	}
+	if len(scores) != len(texts) {
+		return nil, cli.ErrResponseLengthMismatch
+	}
+
+	results := make([]cli.SentimentResult, len(texts))
+	for i := range results {
+		if results[i].Sentiment, err = cli.ToSentiment(scores[i]); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// the operation of which the usage of Summarize is recorded
+const OperationSummary = "summary"
+
+// Summarize returns a summary of the article of no more than three sentences.
+func (a Analyzer) Summarize(ctx context.Context, client *http.Client, cred cli.Credential,
+	text string) (string, error) {
+	obj, err := send(ctx, client, OperationSummary, NewSummaryRequest(cred.Key, cli.WrapText(text)))
+	if err != nil {
+		return "", err
+	}
+
+	summary := obj.Text()
+	if summary == "" {
+		return "", cli.ErrEmptyResponse
+	}
+	return summary, nil
+}
+
+func (a Analyzer) Classify(ctx context.Context, client *http.Client, cred cli.Credential,
+	opt *option.AnalyzerOption, texts ...string) ([]cli.Annotation, error) {
+	if opt.Task == "" || len(opt.Labels) == 0 {
+		return make([]cli.Annotation, len(texts)), nil
+	}
+
+	req := NewClassificationRequest(cred.Key, &opt.ClassificationOptions, cli.WrapText(texts...))
+	req.Body.MaxTokens = opt.MaxTokens
+
+	obj, err := send(ctx, client, cli.OperationClassification, req)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := ClassificationObject{*obj}.Content()
+	if err != nil {
+		return nil, err
+	}
+	return cli.ToAnnotations(&opt.ClassificationOptions, len(texts), results), nil
+}
+
+// send sends the request and records the usage of it as op.
+func send(ctx context.Context, client *http.Client, op string,
+	req Request[MessagesRequestBody]) (*MessagesObject, error) {
+	if err := req.Modify(ctx); err != nil {
+		return nil, err
+	}
+	if err := req.Validate(ctx); err != nil {
+		return nil, err
+	}
+
+	httpReq, err := req.ToHTTPRequest()
+	if err != nil {
+		return nil, err
+	}
+
+	httpResp, err := client.Do(httpReq.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := ParseHTTPResponse[MessagesObject](httpResp)
+	if err != nil {
+		return nil, err
+	}
+
+	cli.RecordUsage(ctx, cli.Usage{
+		Operation:    op,
+		Model:        req.Body.Model,
+		InputTokens:  resp.Body.Usage.InputTokens,
+		OutputTokens: resp.Body.Usage.OutputTokens,
+	})
+	return &resp.Body, nil
+}
@@ -0,0 +1,72 @@
+package anthropic
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/go-playground/mold/v4"
+	"github.com/go-playground/mold/v4/modifiers"
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	API_SCHEME  = "https"
+	API_HOST    = "api.anthropic.com"
+	API_VERSION = "v1"
+	// the value of the anthropic-version header
+	ANTHROPIC_VERSION = "2023-06-01"
+)
+
+var API_URL = fmt.Sprintf("%s://%s/%s", API_SCHEME, API_HOST, API_VERSION)
+
+// API Endpoints
+const (
+	EPMessages string = "messages"
+)
+
+const (
+	ModelClaude3Opus   = "claude-3-opus-20240229"
+	ModelClaude3Sonnet = "claude-3-sonnet-20240229"
+	ModelClaude3Haiku  = "claude-3-haiku-20240307"
+)
+
+// the articles are embedded locally as the lexicon analyzer does, but stored
+// under a model of their own, so that the sentiments stored with them are not
+// taken for the ones of the lexicon analyzer
+const EmbeddingModelCharNgram256 = "anthropic-char-ngram-hash-256"
+
+var Modifier = struct {
+	*mold.Transformer
+	sync.Once
+}{}
+
+func SetModifier(m *mold.Transformer) {
+	Modifier.Once.Do(func() {
+		Modifier.Transformer = m
+	})
+}
+
+func GetModifier() *mold.Transformer {
+	Modifier.Do(func() {
+		Modifier.Transformer = modifiers.New()
+	})
+	return Modifier.Transformer
+}
+
+var Validator = struct {
+	*validator.Validate
+	sync.Once
+}{}
+
+func SetValidator(v *validator.Validate) {
+	Validator.Once.Do(func() {
+		Validator.Validate = v
+	})
+}
+
+func GetValidator() *validator.Validate {
+	Validator.Do(func() {
+		Validator.Validate = validator.New()
+	})
+	return Validator.Validate
+}
@@ -0,0 +1,236 @@
+package anthropic_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	cli "github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client"
+	anthropic "github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client/api/Anthropic"
+	lexicon "github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client/api/Lexicon"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client/option"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/model"
+	ec "github.com/ChiaYuChang/NewsSentimentAnalyzer/pkgs/errorCode"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	TEST_API_KEY       = "[[::TEST_API_KEY::]]"
+	TEST_ERROR_API_KEY = "[[::TEST_ERROR_API_KEY::]]"
+)
+
+// redirect sends the requests to api.anthropic.com to the test server.
+type redirect struct {
+	url *url.URL
+}
+
+func (rd redirect) RoundTrip(req *http.Request) (*http.Response, error) {
+	req.URL.Scheme = rd.url.Scheme
+	req.URL.Host = rd.url.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func newTestServer(t *testing.T, fixture string, statusCode int) (*httptest.Server, *http.Client) {
+	f, err := os.ReadFile("./example_response/" + fixture)
+	require.NoError(t, err)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(
+		fmt.Sprintf("/%s/%s", anthropic.API_VERSION, anthropic.EPMessages),
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			if r.Header.Get("X-Api-Key") != TEST_API_KEY {
+				f, _ := os.ReadFile("./example_response/unauthorization.json")
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write(f)
+				return
+			}
+			if r.Header.Get("Anthropic-Version") != anthropic.ANTHROPIC_VERSION {
+				f, _ := os.ReadFile("./example_response/bad_request.json")
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write(f)
+				return
+			}
+
+			body := anthropic.MessagesRequestBody{}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.MaxTokens == 0 {
+				f, _ := os.ReadFile("./example_response/bad_request.json")
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write(f)
+				return
+			}
+			w.WriteHeader(statusCode)
+			w.Write(f)
+		},
+	)
+
+	srvr := httptest.NewServer(mux)
+	srvrUrl, err := url.Parse(srvr.URL)
+	require.NoError(t, err)
+	return srvr, &http.Client{Timeout: 3 * time.Second, Transport: redirect{url: srvrUrl}}
+}
+
+func TestMessagesRequest(t *testing.T) {
+	req := anthropic.NewSentimentAnalysisRequest(TEST_API_KEY, cli.WrapText("I love this movie"))
+	require.NoError(t, req.Modify(context.Background()))
+	require.NoError(t, req.Validate(context.Background()))
+	require.Equal(t, anthropic.ModelClaude3Haiku, req.Body.Model)
+	require.Equal(t, 1024, req.Body.MaxTokens)
+	require.Equal(t, anthropic.SentimentAnalysisPrompt, req.Body.System)
+	require.Len(t, req.Body.Messages, 1)
+	require.Equal(t, anthropic.RoleUser, req.Body.Messages[0].Role)
+
+	httpReq, err := req.ToHTTPRequest()
+	require.NoError(t, err)
+	require.Equal(t, fmt.Sprintf("%s/%s", anthropic.API_URL, anthropic.EPMessages), httpReq.URL.String())
+	require.Equal(t, TEST_API_KEY, httpReq.Header.Get("X-Api-Key"))
+	require.Equal(t, anthropic.ANTHROPIC_VERSION, httpReq.Header.Get("Anthropic-Version"))
+	require.Empty(t, httpReq.Header.Get("Authorization"))
+
+	req.Body.Messages = append(req.Body.Messages, anthropic.Message{Role: "system", Content: "hi"})
+	require.Error(t, req.Validate(context.Background()))
+
+	req = anthropic.NewMessagesRequest(TEST_API_KEY)
+	require.NoError(t, req.Modify(context.Background()))
+	require.Error(t, req.Validate(context.Background()))
+}
+
+func TestSentimentAnalysisObject(t *testing.T) {
+	type testCase struct {
+		Name   string
+		Text   string
+		Scores []int
+		Err    bool
+	}
+
+	tcs := []testCase{
+		{Name: "list", Text: "[5, 1]", Scores: []int{5, 1}},
+		{Name: "wrapped list", Text: "Here are the scores: [4, 2, 3]", Scores: []int{4, 2, 3}},
+		{Name: "empty", Text: "", Scores: nil},
+		{Name: "not a list", Text: "positive", Err: true},
+	}
+
+	for i := range tcs {
+		tc := tcs[i]
+		t.Run(tc.Name, func(t *testing.T) {
+			obj := anthropic.SentimentAnalysisObject{MessagesObject: anthropic.MessagesObject{
+				Content: []anthropic.ContentBlock{{Type: anthropic.ContentTypeText, Text: tc.Text}},
+			}}
+			scores, err := obj.Content()
+			if tc.Err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.Scores, scores)
+		})
+	}
+}
+
+func TestClassificationObject(t *testing.T) {
+	type testCase struct {
+		Name    string
+		Text    string
+		Results []cli.ClassificationResult
+		Err     bool
+	}
+
+	tcs := []testCase{
+		{
+			Name: "wrapped object",
+			Text: `Here you are: {"results": [{"id": 1, "label": "oppose", "rationale": "核廢料"}]}`,
+			Results: []cli.ClassificationResult{
+				{Id: 1, Label: "oppose", Rationale: "核廢料"},
+			},
+		},
+		{Name: "empty", Text: "", Results: []cli.ClassificationResult{}},
+		{Name: "not an object", Text: "[5, 1]", Err: true},
+	}
+
+	for i := range tcs {
+		tc := tcs[i]
+		t.Run(tc.Name, func(t *testing.T) {
+			obj := anthropic.ClassificationObject{MessagesObject: anthropic.MessagesObject{
+				Content: []anthropic.ContentBlock{{Type: anthropic.ContentTypeText, Text: tc.Text}},
+			}}
+			results, err := obj.Content()
+			if tc.Err {
+				require.ErrorIs(t, err, anthropic.ErrUnparsableClassification)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.Results, results)
+		})
+	}
+}
+
+func TestAnalyzer(t *testing.T) {
+	srvr, client := newTestServer(t, "messages.json", http.StatusOK)
+	defer srvr.Close()
+
+	anlz, err := cli.AnalyzerRepo.Get("anthropic")
+	require.NoError(t, err)
+
+	cred := cli.Credential{Key: TEST_API_KEY}
+	opt := &option.AnalyzerOption{APIName: "anthropic"}
+	texts := []string{"I love this movie", "I hate you", "It is Monday"}
+
+	sentiments, err := anlz.ClassifySentiment(context.Background(), client, cred, opt, texts...)
+	require.NoError(t, err)
+	require.Equal(t, []cli.SentimentResult{
+		{Sentiment: model.SentimentVeryPositive},
+		{Sentiment: model.SentimentVeryNegative},
+		{Sentiment: model.SentimentNeutral},
+	}, sentiments)
+
+	_, err = anlz.ClassifySentiment(context.Background(), client, cred, opt, append(texts, "one more")...)
+	require.ErrorIs(t, err, cli.ErrResponseLengthMismatch)
+
+	embds, err := anlz.Embed(context.Background(), nil, cred, opt, texts...)
+	require.NoError(t, err)
+	require.Len(t, embds, len(texts))
+	require.Len(t, embds[0], anlz.ModelInfo().EmbeddingModels[0].Dim)
+	// the sentiments are stored under the embedding model, which must not be
+	// shared with the lexicon analyzer
+	require.NotEqual(t, lexicon.EmbeddingModelCharNgram256, anlz.ModelInfo().DefaultEmbeddingModel)
+	// but the jobs created before keep it
+	legacy, ok := anlz.ModelInfo().EmbeddingModelNamed(lexicon.EmbeddingModelCharNgram256)
+	require.True(t, ok)
+	require.True(t, legacy.Legacy)
+
+	cred.Key = TEST_ERROR_API_KEY
+	_, err = anlz.ClassifySentiment(context.Background(), client, cred, opt, texts...)
+	require.Error(t, err)
+	require.True(t, ec.MustGetEcErr(ec.ECUnauthorized).IsEqual(err))
+}
+
+func TestSummarize(t *testing.T) {
+	srvr, client := newTestServer(t, "summary.json", http.StatusOK)
+	defer srvr.Close()
+
+	summary, err := anthropic.Analyzer{}.Summarize(
+		context.Background(), client, cli.Credential{Key: TEST_API_KEY}, "立法院今（1）日三讀通過《道路交通安全基本法》")
+	require.NoError(t, err)
+	require.Equal(t, "立法院於12月1日三讀通過《道路交通安全基本法》，以2050年道路交通事故零死亡為目標，未來由行政院統籌交通安全會報。", summary)
+}
+
+func TestErrorResponse(t *testing.T) {
+	srvr, client := newTestServer(t, "overloaded.json", anthropic.StatusOverloaded)
+	defer srvr.Close()
+
+	_, err := anthropic.Analyzer{}.Summarize(
+		context.Background(), client, cli.Credential{Key: TEST_API_KEY}, "text")
+	require.Error(t, err)
+
+	ecErr, ok := err.(*ec.Error)
+	require.True(t, ok)
+	require.Equal(t, ec.ECServiceUnavailable, ecErr.ErrorCode)
+	require.Equal(t, "overloaded_error", ecErr.Message)
+	require.Contains(t, ecErr.Details, "Overloaded")
+}
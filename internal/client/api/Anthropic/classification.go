@@ -0,0 +1,47 @@
+package anthropic
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	cli "github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client/option"
+)
+
+var ErrUnparsableClassification = errors.New("unparsable classification response")
+
+// NewClassificationRequest asks for the label of each of the statements of the
+// text, which are wrapped by WrapText, in the task.
+func NewClassificationRequest(apikey string, task *option.ClassificationOptions, text string) Request[MessagesRequestBody] {
+	req := NewMessagesRequest(apikey)
+	req.Body.
+		SetSystem(cli.ClassificationPrompt(task)).
+		SetTemperature(0).
+		AppendUserMessages(text)
+	return req
+}
+
+type ClassificationObject struct {
+	MessagesObject
+}
+
+// Content returns the labels in the JSON object of the response, the text
+// around which is ignored.
+func (obj ClassificationObject) Content() ([]cli.ClassificationResult, error) {
+	text := obj.Text()
+	if strings.TrimSpace(text) == "" {
+		return []cli.ClassificationResult{}, nil
+	}
+
+	if i, j := strings.Index(text, "{"), strings.LastIndex(text, "}"); i >= 0 && j > i {
+		text = text[i : j+1]
+	}
+	var results struct {
+		Results []cli.ClassificationResult `json:"results"`
+	}
+	if err := json.Unmarshal([]byte(text), &results); err != nil || results.Results == nil {
+		return nil, ErrUnparsableClassification
+	}
+	return results.Results, nil
+}
@@ -0,0 +1,42 @@
+package anthropic
+
+import (
+	"fmt"
+	"net/http"
+
+	ec "github.com/ChiaYuChang/NewsSentimentAnalyzer/pkgs/errorCode"
+)
+
+// StatusOverloaded is returned when the API is temporarily overloaded.
+const StatusOverloaded = 529
+
+type ErrorResponseBody struct {
+	Type  string `json:"type"`
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (erb ErrorResponseBody) ToEcError(statusCode int) *ec.Error {
+	if statusCode == StatusOverloaded {
+		// retry later, the same as the other unavailable services
+		statusCode = http.StatusServiceUnavailable
+	}
+
+	ecErr, ok := ec.GetEcErr(ec.ErrorCode(statusCode))
+	if !ok {
+		if statusCode >= http.StatusInternalServerError {
+			ecErr = ec.MustGetEcErr(ec.ECServerError)
+		} else {
+			ecErr = ec.MustGetEcErr(ec.ECBadRequest)
+		}
+		ecErr.WithDetails(fmt.Sprintf("original error code: %d", statusCode))
+	}
+
+	if erb.Error.Type != "" {
+		ecErr.Message = erb.Error.Type
+	}
+	ecErr.WithDetails(erb.Error.Message)
+	return ecErr
+}
@@ -0,0 +1,122 @@
+package anthropic
+
+import "strings"
+
+// See https://docs.anthropic.com/claude/reference/messages_post
+func NewMessagesRequest(apikey string, messages ...Message) Request[MessagesRequestBody] {
+	return Request[MessagesRequestBody]{
+		Body: MessagesRequestBody{
+			Messages: messages,
+		},
+		apikey: apikey,
+	}
+}
+
+// see https://docs.anthropic.com/claude/reference/messages_post
+type MessagesRequestBody struct {
+	Messages      []Message `json:"messages"                                                     validate:"required,dive"`
+	Model         string    `json:"model"                  mod:"default=claude-3-haiku-20240307" validate:"required"`
+	MaxTokens     int       `json:"max_tokens"             mod:"default=1024"                    validate:"gt=0,lte=4096"`
+	Metadata      *Metadata `json:"metadata,omitempty"`
+	StopSequences []string  `json:"stop_sequences,omitempty"`
+	Stream        bool      `json:"stream,omitempty"       mod:"default=false"`
+	System        string    `json:"system,omitempty"`
+	Temperature   *float32  `json:"temperature,omitempty"                                        validate:"omitempty,gte=0.0,lte=1.0"`
+	TopK          *int      `json:"top_k,omitempty"                                              validate:"omitempty,gt=0"`
+	TopP          *float32  `json:"top_p,omitempty"                                              validate:"omitempty,gt=0.0,lte=1.0"`
+}
+
+func (body MessagesRequestBody) Endpoint() string {
+	return EPMessages
+}
+
+func (body *MessagesRequestBody) SetSystem(system string) *MessagesRequestBody {
+	body.System = system
+	return body
+}
+
+func (body *MessagesRequestBody) AppendUserMessages(content string) *MessagesRequestBody {
+	body.Messages = append(body.Messages, Message{Role: RoleUser, Content: content})
+	return body
+}
+
+func (body *MessagesRequestBody) AppendAssistantMessages(content string) *MessagesRequestBody {
+	body.Messages = append(body.Messages, Message{Role: RoleAssistant, Content: content})
+	return body
+}
+
+func (body *MessagesRequestBody) SetModel(model string) *MessagesRequestBody {
+	body.Model = model
+	return body
+}
+
+func (body *MessagesRequestBody) SetMaxTokens(maxTokens int) *MessagesRequestBody {
+	body.MaxTokens = maxTokens
+	return body
+}
+
+func (body *MessagesRequestBody) SetTemperature(temperature float32) *MessagesRequestBody {
+	body.Temperature = &temperature
+	return body
+}
+
+const (
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// Message is a turn of the conversation, the messages must alternate between
+// the user and the assistant, starting with the user.
+type Message struct {
+	Role    string `json:"role"    validate:"required,oneof=user assistant"`
+	Content string `json:"content" validate:"required"`
+}
+
+type Metadata struct {
+	UserId string `json:"user_id,omitempty"`
+}
+
+type MessagesObject struct {
+	Id           string         `json:"id"`
+	Type         string         `json:"type"`
+	Role         string         `json:"role"`
+	Content      []ContentBlock `json:"content"`
+	Model        string         `json:"model"`
+	StopReason   string         `json:"stop_reason"`
+	StopSequence *string        `json:"stop_sequence"`
+	Usage        Usage          `json:"usage"`
+}
+
+func (obj MessagesObject) Endpoint() string {
+	return EPMessages
+}
+
+// Text returns the concatenated text of the text blocks of the content.
+func (obj MessagesObject) Text() string {
+	sb := strings.Builder{}
+	for _, block := range obj.Content {
+		if block.Type == ContentTypeText {
+			sb.WriteString(block.Text)
+		}
+	}
+	return sb.String()
+}
+
+const ContentTypeText = "text"
+
+type ContentBlock struct {
+	Type string `json:"type"`
+	Text string `json:"text,omitempty"`
+}
+
+type Usage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// stop reasons
+const (
+	StopReasonEndTurn      = "end_turn"
+	StopReasonMaxTokens    = "max_tokens"
+	StopReasonStopSequence = "stop_sequence"
+)
@@ -0,0 +1,46 @@
+package anthropic
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+const SentimentAnalysisPrompt = `Your task is to assess the sentiments conveyed in a set of statements. Each statement will be enclosed with the symbols [^] and [$]. Consider the overall tone, emotional nuances, and context within the statements. Score each statement on a scale from 1 to 5: 1 for very negative, 2 for negative, 3 for neutral, 4 for positive, and 5 for very positive. Please present your responses in a JSON list and nothing else. Respond sequentially without repeating the provided sentences. For instance, if the sentence is [^]I love this movie[$] [^]I hate you[$], your corresponding response should be [5, 1].`
+
+// See https://docs.anthropic.com/claude/reference/messages_post
+func NewSentimentAnalysisRequest(apikey string, text string) Request[MessagesRequestBody] {
+	return NewSentimentAnalysisRequestWithPrompt(apikey, SentimentAnalysisPrompt, text)
+}
+
+// NewSentimentAnalysisRequestWithPrompt is NewSentimentAnalysisRequest with a
+// prompt of the user, which should ask for the same JSON list.
+func NewSentimentAnalysisRequestWithPrompt(apikey, prompt, text string) Request[MessagesRequestBody] {
+	req := NewMessagesRequest(apikey)
+	req.Body.
+		SetSystem(prompt).
+		SetTemperature(0).
+		AppendUserMessages(text)
+	return req
+}
+
+type SentimentAnalysisObject struct {
+	MessagesObject
+}
+
+// Content returns the score of each statement, in order. The text around the
+// JSON list, which the model adds now and then, is ignored.
+func (obj SentimentAnalysisObject) Content() ([]int, error) {
+	var content []int
+	text := obj.Text()
+	if strings.TrimSpace(text) == "" {
+		return content, nil
+	}
+
+	if i, j := strings.Index(text, "["), strings.LastIndex(text, "]"); i >= 0 && j > i {
+		text = text[i : j+1]
+	}
+	if err := json.Unmarshal([]byte(text), &content); err != nil {
+		return nil, err
+	}
+	return content, nil
+}
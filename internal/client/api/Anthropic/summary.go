@@ -0,0 +1,13 @@
+package anthropic
+
+const SummaryPrompt = `Your task is to summarise a news article. The article will be enclosed with the symbols [^] and [$]. Write a neutral summary of no more than three sentences in the language of the article, covering who, what, when and where. Respond with the summary only.`
+
+// See https://docs.anthropic.com/claude/reference/messages_post
+func NewSummaryRequest(apikey string, text string) Request[MessagesRequestBody] {
+	req := NewMessagesRequest(apikey)
+	req.Body.
+		SetSystem(SummaryPrompt).
+		SetTemperature(0).
+		AppendUserMessages(text)
+	return req
+}
@@ -0,0 +1,137 @@
+package cohere
+
+import (
+	"context"
+	"net/http"
+
+	cli "github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client/option"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/model"
+)
+
+func init() {
+	cli.RegisterAnalyzer(Analyzer{})
+}
+
+// Analyzer analyzes news with the embed and chat endpoints.
+type Analyzer struct{}
+
+func (Analyzer) ModelInfo() cli.ModelInfo {
+	return cli.ModelInfo{
+		Name:                  "cohere",
+		DefaultEmbeddingModel: EmbedModelMultilingualLightv3,
+		EmbeddingModels: []cli.EmbeddingModel{
+			{Name: EmbedModelEnglishv3, Dim: 1024, MaxTokens: 512},
+			{Name: EmbedModelMultilingualv3, Dim: 1024, MaxTokens: 512},
+			{Name: EmbedModelEnglishLightv3, Dim: 384, MaxTokens: 512},
+			{Name: EmbedModelMultilingualLightv3, Dim: 384, MaxTokens: 512},
+		},
+		SentimentPrompt: SentimentAnalysisPrompt,
+		ChatModel:       GenerateModelCommand,
+		InputTypes: []string{
+			InputTypeSearchDocument,
+			InputTypeSearchQuery,
+			InputTypeClassification,
+			InputTypeClustering,
+		},
+		Truncates: []string{TruncateEnd, TruncateStart},
+	}
+}
+
+func (a Analyzer) Embed(ctx context.Context, client *http.Client, cred cli.Credential,
+	opt *option.AnalyzerOption, texts ...string) ([][]float32, error) {
+	req := NewEmbedRequest(cred.Key, texts...)
+	req.Body.WithModel(a.ModelInfo().EmbeddingModelOf(opt))
+	req.Body.WithInputType(opt.InputType)
+	req.Body.WithTruncate(opt.Truncate)
+	if err := req.Modify(ctx); err != nil {
+		return nil, err
+	}
+	if err := req.Validate(ctx); err != nil {
+		return nil, err
+	}
+
+	httpReq, err := req.ToHTTPRequest()
+	if err != nil {
+		return nil, err
+	}
+
+	httpResp, err := client.Do(httpReq.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := ParseHTTPResponse[EmbedResponseBody](httpResp)
+	if err != nil {
+		return nil, err
+	}
+
+	cli.RecordUsage(ctx, cli.Usage{
+		Operation:   cli.OperationEmbedding,
+		Model:       req.Body.Model,
+		InputTokens: resp.Body.Meta.BilledUnits.InputTokens,
+	})
+
+	if resp.Body.Len() == 0 {
+		return nil, cli.ErrEmptyResponse
+	}
+	if resp.Body.Len() < len(texts) {
+		return nil, cli.ErrResponseLengthMismatch
+	}
+	return resp.Body.Embeddings[:len(texts)], nil
+}
+
+// ClassifySentiment sends a request for each of the texts, since the prompt
+// scores one article at a time. The error of a text is left in its result.
+func (a Analyzer) ClassifySentiment(ctx context.Context, client *http.Client, cred cli.Credential,
+	opt *option.AnalyzerOption, texts ...string) ([]cli.SentimentResult, error) {
+	results := make([]cli.SentimentResult, len(texts))
+	for i, text := range texts {
+		sentiment, err := a.classify(ctx, client, cred.Key, opt.SentimentPromptOr(SentimentAnalysisPrompt), text)
+		if err != nil {
+			// the results of the other texts are kept, they have been paid for
+			results[i] = cli.SentimentResult{Err: err}
+			continue
+		}
+		results[i] = cli.SentimentResult{Sentiment: sentiment}
+	}
+	return results, nil
+}
+
+func (a Analyzer) classify(ctx context.Context, client *http.Client, apikey, prompt, text string) (model.Sentiment, error) {
+	req := NewSentimentAnalysisRequestWithPrompt(apikey, prompt, cli.WrapText(text))
+	if err := req.Modify(ctx); err != nil {
+		return "", err
+	}
+	if err := req.Validate(ctx); err != nil {
+		return "", err
+	}
+
+	httpReq, err := req.ToHTTPRequest()
+	if err != nil {
+		return "", err
+	}
+
+	httpResp, err := client.Do(httpReq.WithContext(ctx))
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := ParseHTTPResponse[ChatResponseBody](httpResp)
+	if err != nil {
+		return "", err
+	}
+
+	cli.RecordUsage(ctx, cli.Usage{
+		Operation:    cli.OperationSentiment,
+		Model:        req.Body.Model,
+		InputTokens:  resp.Body.Meta.BilledUnits.InputTokens,
+		OutputTokens: resp.Body.Meta.BilledUnits.OutputTokens,
+	})
+
+	scores, err := SentimentAnalysisObject(resp.Body).Content()
+	if err != nil {
+		return "", err
+	}
+	return cli.ToSentiment(scores[0])
+}
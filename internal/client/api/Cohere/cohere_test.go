@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -12,7 +13,10 @@ import (
 	"testing"
 	"time"
 
+	cli "github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client"
 	cohere "github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client/api/Cohere"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client/option"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/model"
 	"github.com/stretchr/testify/require"
 )
 
@@ -177,3 +181,49 @@ func TestCoChat(t *testing.T) {
 	require.Equal(t, "e4d0bfa2-e9e2-4080-a37e-1f62ab92f2e4", resp.Body.GenerationId.String())
 
 }
+
+// redirect sends the requests to the test server
+type redirect struct {
+	url *url.URL
+}
+
+func (rd redirect) RoundTrip(req *http.Request) (*http.Response, error) {
+	req.URL.Scheme = rd.url.Scheme
+	req.URL.Host = rd.url.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestClassifySentimentKeepsResults(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc(
+		fmt.Sprintf("/%s/%s", cohere.API_VERSION, cohere.EPChat),
+		func(w http.ResponseWriter, r *http.Request) {
+			b, _ := io.ReadAll(r.Body)
+			w.Header().Set("Content-Type", "application/json")
+			if strings.Contains(string(b), "[[::FAIL::]]") {
+				w.WriteHeader(http.StatusTooManyRequests)
+				w.Write([]byte(`{"message": "too many requests"}`))
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"text": "4", "meta": {"billed_units": {"input_tokens": 10, "output_tokens": 1}}}`))
+		},
+	)
+	srvr := httptest.NewServer(mux)
+	defer srvr.Close()
+
+	srvrUrl, err := url.Parse(srvr.URL)
+	require.NoError(t, err)
+	client := &http.Client{Timeout: 3 * time.Second, Transport: redirect{url: srvrUrl}}
+
+	// the failure of a text does not discard the results of the others
+	results, err := cohere.Analyzer{}.ClassifySentiment(context.Background(), client,
+		cli.Credential{Key: TEST_API_KEY}, &option.AnalyzerOption{}, "text 1", "[[::FAIL::]]", "text 3")
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	require.NoError(t, results[0].Err)
+	require.Equal(t, model.SentimentPositive, results[0].Sentiment)
+	require.Error(t, results[1].Err)
+	require.NoError(t, results[2].Err)
+	require.Equal(t, model.SentimentPositive, results[2].Sentiment)
+}
@@ -1,6 +1,11 @@
 package cohere
 
-import ec "github.com/ChiaYuChang/NewsSentimentAnalyzer/pkgs/errorCode"
+import (
+	"fmt"
+	"net/http"
+
+	ec "github.com/ChiaYuChang/NewsSentimentAnalyzer/pkgs/errorCode"
+)
 
 type ErrorResponseBody struct {
 	Code    int    `json:"-"`
@@ -8,7 +13,15 @@ type ErrorResponseBody struct {
 }
 
 func (erb ErrorResponseBody) ToEcError() *ec.Error {
-	ecErr := ec.MustGetEcErr(ec.ErrorCode(erb.Code))
+	ecErr, ok := ec.GetEcErr(ec.ErrorCode(erb.Code))
+	if !ok {
+		if erb.Code >= http.StatusInternalServerError {
+			ecErr = ec.MustGetEcErr(ec.ECServerError)
+		} else {
+			ecErr = ec.MustGetEcErr(ec.ECBadRequest)
+		}
+		ecErr.WithDetails(fmt.Sprintf("original error code: %d", erb.Code))
+	}
 	ecErr.Message = erb.Message
 	return ecErr
 }
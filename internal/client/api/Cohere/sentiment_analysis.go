@@ -1,12 +1,22 @@
 package cohere
 
-import "github.com/ChiaYuChang/NewsSentimentAnalyzer/pkgs/convert"
+import (
+	"strings"
 
-const SentimentAnalysisPrompt = `As an AI specializing in language and emotion analysis, your task is to assess the sentiments conveyed in an article. The given article will be enclosed with the symbols [^] and [$]. Consider the overall tone, emotional nuances, and context within the statements. Classify the article into one of three categories: -1 negative, 0 for neutral, and 1 for positive. Please present your responses with a single number. Respond sequentially without repeating the provided sentences. For example, if the article is [^]I love this movie[$], your corresponding response should be 1.`
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/pkgs/convert"
+)
+
+const SentimentAnalysisPrompt = `As an AI specializing in language and emotion analysis, your task is to assess the sentiments conveyed in an article. The given article will be enclosed with the symbols [^] and [$]. Consider the overall tone, emotional nuances, and context within the statements. Score the article on a scale from 1 to 5: 1 for very negative, 2 for negative, 3 for neutral, 4 for positive, and 5 for very positive. Please present your responses with a single number. Respond sequentially without repeating the provided sentences. For example, if the article is [^]I love this movie[$], your corresponding response should be 5.`
 
 func NewSentimentAnalysisRequest(apikey, text string) Request[ChatRequestBody] {
+	return NewSentimentAnalysisRequestWithPrompt(apikey, SentimentAnalysisPrompt, text)
+}
+
+// NewSentimentAnalysisRequestWithPrompt is NewSentimentAnalysisRequest with a
+// prompt of the user, which should ask for a single number as well.
+func NewSentimentAnalysisRequestWithPrompt(apikey, prompt, text string) Request[ChatRequestBody] {
 	req := NewChatRequest(apikey, text)
-	req.Body.AppendChatHistory(ChatRoleChatBot, SentimentAnalysisPrompt, "").
+	req.Body.AppendChatHistory(ChatRoleChatBot, prompt, "").
 		SetTemperature(0.001).
 		SetCitationQuality(CitationQualityAccurate)
 	return req
@@ -14,6 +24,12 @@ func NewSentimentAnalysisRequest(apikey, text string) Request[ChatRequestBody] {
 
 type SentimentAnalysisObject ChatResponseBody
 
-func (obj ChatResponseBody) Content() (int, error) {
-	return convert.StrTo(obj.Text).Int()
+// Content returns the score of the article, the prompt scores one article per
+// request.
+func (obj SentimentAnalysisObject) Content() ([]int, error) {
+	score, err := convert.StrTo(strings.TrimSpace(obj.Text)).Int()
+	if err != nil {
+		return nil, err
+	}
+	return []int{score}, nil
 }
@@ -0,0 +1,149 @@
+package lexicon
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"net/http"
+	"unicode"
+
+	cli "github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client/option"
+)
+
+func init() {
+	cli.RegisterAnalyzer(Analyzer{Lexicon: Default})
+}
+
+const (
+	EmbeddingModelCharNgram256 = "char-ngram-hash-256"
+	EmbeddingDim               = 256
+)
+
+const SentimentAnalysisPrompt = `The article is scored offline with a Traditional Chinese sentiment lexicon, no API key is needed. Each sentiment word is weighted by the negations (e.g. 不, 沒有) and degree adverbs (e.g. 很, 非常) in front of it within the same clause, and the mean weight of the words is mapped to the 1-5 scale, from very negative to very positive. Articles without any sentiment word are neutral. The embedding is a hashed bag of character unigrams and bigrams, which is only suitable for finding near-duplicate articles.`
+
+// Analyzer analyzes news locally with a Lexicon, it ignores the http client
+// and the api key.
+type Analyzer struct {
+	Lexicon *Lexicon
+}
+
+func (Analyzer) ModelInfo() cli.ModelInfo {
+	return cli.ModelInfo{
+		Name:                  "lexicon",
+		DefaultEmbeddingModel: EmbeddingModelCharNgram256,
+		EmbeddingModels: []cli.EmbeddingModel{
+			{Name: EmbeddingModelCharNgram256, Dim: EmbeddingDim},
+		},
+		SentimentPrompt: SentimentAnalysisPrompt,
+		Offline:         true,
+	}
+}
+
+func (a Analyzer) Embed(ctx context.Context, client *http.Client, cred cli.Credential,
+	opt *option.AnalyzerOption, texts ...string) ([][]float32, error) {
+	embds := make([][]float32, len(texts))
+	for i, text := range texts {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		embds[i] = Embed(text)
+	}
+	return embds, nil
+}
+
+func (a Analyzer) ClassifySentiment(ctx context.Context, client *http.Client, cred cli.Credential,
+	opt *option.AnalyzerOption, texts ...string) ([]cli.SentimentResult, error) {
+	results := make([]cli.SentimentResult, len(texts))
+	for i, text := range texts {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		score, n := a.Lexicon.Score(text)
+		results[i] = cli.SentimentResult{
+			Sentiment: ToSentiment(score),
+			Rationale: rationale(score, n),
+		}
+	}
+	return results, nil
+}
+
+// ClassifyEntitySentiment scores the sentences mentioning each of the entities
+// in opt.Entities.
+func (a Analyzer) ClassifyEntitySentiment(ctx context.Context, client *http.Client, cred cli.Credential,
+	opt *option.AnalyzerOption, texts ...string) ([][]cli.EntitySentiment, error) {
+	results := make([][]cli.EntitySentiment, len(texts))
+	for i, text := range texts {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		results[i] = []cli.EntitySentiment{}
+		for _, entity := range opt.Entities {
+			score, n, ok := a.Lexicon.ScoreToward(text, entity)
+			if !ok {
+				continue
+			}
+			results[i] = append(results[i], cli.EntitySentiment{
+				Entity: entity,
+				SentimentResult: cli.SentimentResult{
+					Sentiment: ToSentiment(score),
+					Rationale: rationale(score, n),
+				},
+			})
+		}
+	}
+	return results, nil
+}
+
+func rationale(score float64, n int) string {
+	if n == 0 {
+		return "no sentiment word"
+	}
+	return fmt.Sprintf("%d sentiment words, mean weight %.2f", n, score)
+}
+
+// Embed returns the L2 normalized hashed bag of the character unigrams and
+// bigrams of text, spaces and punctuation are skipped.
+func Embed(text string) []float32 {
+	embd := make([]float32, EmbeddingDim)
+	add := func(gram string) {
+		h := fnv.New32a()
+		h.Write([]byte(gram))
+		sum := h.Sum32()
+		// the highest bit decides the sign, which keeps the collisions from
+		// piling up on the same side
+		if sum&(1<<31) == 0 {
+			embd[sum%EmbeddingDim]++
+		} else {
+			embd[sum%EmbeddingDim]--
+		}
+	}
+
+	var prev rune
+	for _, r := range text {
+		if unicode.IsSpace(r) || unicode.IsPunct(r) {
+			prev = 0
+			continue
+		}
+		r = unicode.ToLower(r)
+		add(string(r))
+		if prev != 0 {
+			add(string([]rune{prev, r}))
+		}
+		prev = r
+	}
+
+	var norm float64
+	for _, v := range embd {
+		norm += float64(v * v)
+	}
+	if norm == 0 {
+		return embd
+	}
+	norm = math.Sqrt(norm)
+	for i := range embd {
+		embd[i] = float32(float64(embd[i]) / norm)
+	}
+	return embd
+}
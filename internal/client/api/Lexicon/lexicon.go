@@ -0,0 +1,208 @@
+package lexicon
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/model"
+)
+
+type kind uint8
+
+const (
+	kindWord kind = iota
+	kindNegation
+	kindDegree
+)
+
+type entry struct {
+	kind   kind
+	weight float64
+}
+
+// Lexicon scores text with a dictionary of sentiment words. A sentiment word
+// is modified by the negations and degree adverbs in front of it within the
+// same clause, e.g. 不好 is negative and 非常好 is more positive than 好.
+type Lexicon struct {
+	entries map[string]entry
+	maxLen  int
+}
+
+// NewLexicon returns a Lexicon of sentiment words and their weights, positive
+// for positive words and negative for negative ones, negations, and degree
+// adverbs and their multipliers. The longest match wins, so that 不錯 is a
+// word rather than a negation followed by 錯.
+func NewLexicon(words map[string]float64, negations []string, degrees map[string]float64) *Lexicon {
+	lex := &Lexicon{entries: map[string]entry{}}
+	for w, d := range degrees {
+		lex.add(w, entry{kind: kindDegree, weight: d})
+	}
+	for _, w := range negations {
+		lex.add(w, entry{kind: kindNegation})
+	}
+	for w, s := range words {
+		lex.add(w, entry{kind: kindWord, weight: s})
+	}
+	return lex
+}
+
+func (lex *Lexicon) add(w string, e entry) {
+	lex.entries[w] = e
+	if n := len([]rune(w)); n > lex.maxLen {
+		lex.maxLen = n
+	}
+}
+
+// Score returns the mean weight of the sentiment words in text after
+// modification and the number of them. Words are matched greedily from the
+// left, the longest first.
+func (lex *Lexicon) Score(text string) (float64, int) {
+	runes := []rune(text)
+	mods := []entry{}
+	sum, n := 0.0, 0
+	for i := 0; i < len(runes); {
+		e, l, ok := lex.match(runes[i:])
+		if !ok {
+			if isClauseBreak(runes[i]) {
+				mods = mods[:0]
+			}
+			i++
+			continue
+		}
+		i += l
+
+		if e.kind != kindWord {
+			mods = append(mods, e)
+			continue
+		}
+		sum += modify(e.weight, mods)
+		n++
+		mods = mods[:0]
+	}
+
+	if n == 0 {
+		return 0, 0
+	}
+	return sum / float64(n), n
+}
+
+// ScoreToward returns the mean weight and the number of the sentiment words in
+// the sentences of text that mention entity, and whether any sentence does.
+// The entity is matched case-insensitively.
+func (lex *Lexicon) ScoreToward(text, entity string) (float64, int, bool) {
+	entity = strings.ToLower(entity)
+	sum, n, mentioned := 0.0, 0, false
+	for _, sentence := range strings.FieldsFunc(text, isSentenceBreak) {
+		if !strings.Contains(strings.ToLower(sentence), entity) {
+			continue
+		}
+		mentioned = true
+		score, m := lex.Score(sentence)
+		sum += score * float64(m)
+		n += m
+	}
+
+	if n == 0 {
+		return 0, 0, mentioned
+	}
+	return sum / float64(n), n, mentioned
+}
+
+// Sentiment returns the sentiment of text, text without any sentiment word is
+// neutral.
+func (lex *Lexicon) Sentiment(text string) model.Sentiment {
+	score, _ := lex.Score(text)
+	return ToSentiment(score)
+}
+
+// ToSentiment maps the mean weight returned by Score to the 1-5 scale.
+func ToSentiment(score float64) model.Sentiment {
+	switch {
+	case score >= 1.5:
+		return model.SentimentVeryPositive
+	case score >= 0.5:
+		return model.SentimentPositive
+	case score > -0.5:
+		return model.SentimentNeutral
+	case score > -1.5:
+		return model.SentimentNegative
+	default:
+		return model.SentimentVeryNegative
+	}
+}
+
+func (lex *Lexicon) match(runes []rune) (entry, int, bool) {
+	l := lex.maxLen
+	if l > len(runes) {
+		l = len(runes)
+	}
+	for ; l > 0; l-- {
+		if e, ok := lex.entries[string(runes[:l])]; ok {
+			return e, l, true
+		}
+	}
+	return entry{}, 0, false
+}
+
+// modify applies the modifiers to weight from the nearest one. A negation in
+// front of a degree adverb weakens the word instead of reversing it, e.g.
+// 不很好 is less positive than 好 rather than as negative as 很不好.
+func modify(weight float64, mods []entry) float64 {
+	intensified := false
+	for i := len(mods) - 1; i >= 0; i-- {
+		switch mods[i].kind {
+		case kindDegree:
+			weight *= mods[i].weight
+			intensified = true
+		case kindNegation:
+			if intensified {
+				weight *= -0.5
+			} else {
+				weight *= -1
+			}
+		}
+	}
+	return weight
+}
+
+func isClauseBreak(r rune) bool {
+	return unicode.IsPunct(r) || r == '\n'
+}
+
+func isSentenceBreak(r rune) bool {
+	return strings.ContainsRune("。！？；!?;\n", r)
+}
+
+// Default is the built-in Traditional Chinese lexicon.
+var Default = NewLexicon(
+	map[string]float64{
+		// positive
+		"好": 1, "佳": 1, "讚": 1, "贏": 1, "美好": 1, "不錯": 1, "良好": 1,
+		"成功": 1, "成長": 1, "上漲": 1, "增加": 1, "穩定": 1, "改善": 1,
+		"進步": 1, "突破": 1, "創新": 1, "支持": 1, "滿意": 1, "喜歡": 1,
+		"開心": 1, "高興": 1, "快樂": 1, "幸福": 1, "樂觀": 1, "希望": 1,
+		"受益": 1, "獲利": 1, "利多": 1, "繁榮": 1, "安全": 1, "順利": 1,
+		"肯定": 1, "精彩": 1, "亮眼": 1, "強勁": 1, "復甦": 1, "歡迎": 1,
+		"感謝": 1, "勝利": 1, "有效": 1, "合作": 1, "和平": 1, "回升": 1,
+		"優秀": 1.5, "優異": 1.5, "創新高": 1.5, "大漲": 2, "暴漲": 2,
+		"完美": 2, "卓越": 2,
+		// negative
+		"壞": -1, "差": -1, "輸": -1, "失敗": -1, "下跌": -1, "減少": -1,
+		"衰退": -1, "危機": -1, "風險": -1, "擔憂": -1, "擔心": -1, "損失": -1,
+		"虧損": -1, "問題": -1, "困難": -1, "批評": -1, "反對": -1, "抗議": -1,
+		"衝突": -1, "攻擊": -1, "事故": -1, "犯罪": -1, "詐騙": -1, "不滿": -1,
+		"不安": -1, "悲觀": -1, "失望": -1, "難過": -1, "嚴重": -1, "惡化": -1,
+		"疲軟": -1, "低迷": -1, "利空": -1, "裁員": -1, "違法": -1, "爭議": -1,
+		"威脅": -1, "緊張": -1, "下滑": -1, "倒閉": -1.5, "恐慌": -1.5,
+		"憤怒": -1.5, "痛苦": -1.5, "糟糕": -1.5, "醜聞": -1.5, "貪污": -1.5,
+		"戰爭": -1.5, "死亡": -1.5, "傷亡": -1.5, "崩盤": -2, "暴跌": -2,
+		"災難": -2, "慘重": -2,
+	},
+	[]string{"不", "沒", "沒有", "無", "毫無", "未", "別", "非", "並非"},
+	map[string]float64{
+		"非常": 2, "極": 2, "極為": 2, "極度": 2, "最": 2,
+		"十分": 1.6, "相當": 1.6, "特別": 1.6, "超": 1.6, "太": 1.6,
+		"很": 1.3, "更": 1.2, "較": 1.2, "比較": 1.2,
+		"有點": 0.6, "有些": 0.6, "稍微": 0.6, "略": 0.6,
+	},
+)
@@ -0,0 +1,133 @@
+package lexicon_test
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	cli "github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client"
+	lexicon "github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client/api/Lexicon"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client/option"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSentiment(t *testing.T) {
+	type testCase struct {
+		Name      string
+		Text      string
+		Sentiment model.Sentiment
+	}
+
+	tcs := []testCase{
+		{Name: "no sentiment word", Text: "今天是星期一", Sentiment: model.SentimentNeutral},
+		{Name: "positive", Text: "這部電影好看又精彩", Sentiment: model.SentimentPositive},
+		{Name: "negative", Text: "股市下跌，投資人擔心", Sentiment: model.SentimentNegative},
+		{Name: "negation", Text: "服務不好", Sentiment: model.SentimentNegative},
+		{Name: "double negation", Text: "不是不好", Sentiment: model.SentimentPositive},
+		{Name: "negated negative", Text: "沒有問題", Sentiment: model.SentimentPositive},
+		{Name: "word over negation", Text: "表現不錯", Sentiment: model.SentimentPositive},
+		{Name: "degree adverb", Text: "非常好", Sentiment: model.SentimentVeryPositive},
+		{Name: "degree adverb on negation", Text: "非常不好", Sentiment: model.SentimentVeryNegative},
+		{Name: "negation on degree adverb", Text: "不很好", Sentiment: model.SentimentNegative},
+		{Name: "strong word", Text: "股市崩盤", Sentiment: model.SentimentVeryNegative},
+		{Name: "clause break", Text: "不，很好", Sentiment: model.SentimentPositive},
+		{Name: "mixed", Text: "營收成長，獲利創新高，但匯率風險升高", Sentiment: model.SentimentPositive},
+	}
+
+	for i := range tcs {
+		tc := tcs[i]
+		t.Run(tc.Name, func(t *testing.T) {
+			require.Equal(t, tc.Sentiment, lexicon.Default.Sentiment(tc.Text))
+		})
+	}
+}
+
+func TestScore(t *testing.T) {
+	score, n := lexicon.Default.Score("很好，不滿")
+	require.Equal(t, 2, n)
+	require.InDelta(t, (1.3-1)/2, score, 1e-9)
+
+	score, _ = lexicon.Default.Score("有點擔心")
+	require.InDelta(t, -0.6, score, 1e-9)
+
+	lex := lexicon.NewLexicon(map[string]float64{"棒": 1}, []string{"不"}, map[string]float64{"超": 3})
+	score, n = lex.Score("超棒")
+	require.Equal(t, 1, n)
+	require.Equal(t, 3.0, score)
+
+	score, n = lex.Score("好")
+	require.Equal(t, 0, n)
+	require.Equal(t, 0.0, score)
+}
+
+func TestEmbed(t *testing.T) {
+	embd := lexicon.Embed("台積電營收成長")
+	require.Len(t, embd, lexicon.EmbeddingDim)
+	require.Equal(t, embd, lexicon.Embed("台積電營收成長"))
+	require.NotEqual(t, embd, lexicon.Embed("台積電營收衰退"))
+
+	var norm float64
+	for _, v := range embd {
+		norm += float64(v * v)
+	}
+	require.InDelta(t, 1, math.Sqrt(norm), 1e-6)
+	require.Equal(t, make([]float32, lexicon.EmbeddingDim), lexicon.Embed("，。"))
+}
+
+func TestAnalyzer(t *testing.T) {
+	anlz, err := cli.AnalyzerRepo.Get("lexicon")
+	require.NoError(t, err)
+	require.True(t, anlz.ModelInfo().Offline)
+
+	// neither the http client nor the api key is used
+	texts := []string{"非常好", "今天是星期一", "股市崩盤"}
+	embds, err := anlz.Embed(context.Background(), nil, cli.Credential{}, nil, texts...)
+	require.NoError(t, err)
+	require.Len(t, embds, len(texts))
+
+	sentiments, err := anlz.ClassifySentiment(context.Background(), nil, cli.Credential{}, nil, texts...)
+	require.NoError(t, err)
+	require.Equal(t, []cli.SentimentResult{
+		{Sentiment: model.SentimentVeryPositive, Rationale: "1 sentiment words, mean weight 2.00"},
+		{Sentiment: model.SentimentNeutral, Rationale: "no sentiment word"},
+		{Sentiment: model.SentimentVeryNegative, Rationale: "1 sentiment words, mean weight -2.00"},
+	}, sentiments)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = anlz.ClassifySentiment(ctx, nil, cli.Credential{}, nil, texts...)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestClassifyEntitySentiment(t *testing.T) {
+	anlz, err := cli.AnalyzerRepo.Get("lexicon")
+	require.NoError(t, err)
+	entAnlz, ok := anlz.(cli.EntityAnalyzer)
+	require.True(t, ok)
+
+	opt := &option.AnalyzerOption{}
+	opt.Entities = []string{"台積電", "國民黨", "TSMC"}
+	texts := []string{
+		"台積電營收大漲。國民黨表現不好。",
+		"今天是星期一",
+		"tsmc 的股價回升",
+	}
+
+	results, err := entAnlz.ClassifyEntitySentiment(context.Background(), nil, cli.Credential{}, opt, texts...)
+	require.NoError(t, err)
+	require.Len(t, results, len(texts))
+
+	// only the sentence mentioning an entity counts toward it
+	require.Len(t, results[0], 2)
+	require.Equal(t, "台積電", results[0][0].Entity)
+	require.Equal(t, model.SentimentVeryPositive, results[0][0].Sentiment)
+	require.Equal(t, "國民黨", results[0][1].Entity)
+	require.Equal(t, model.SentimentNegative, results[0][1].Sentiment)
+
+	require.Empty(t, results[1])
+
+	require.Len(t, results[2], 1)
+	require.Equal(t, "TSMC", results[2][0].Entity)
+	require.Equal(t, model.SentimentPositive, results[2][0].Sentiment)
+}
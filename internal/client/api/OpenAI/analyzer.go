@@ -0,0 +1,263 @@
+package openai
+
+import (
+	"context"
+	"net/http"
+	"sort"
+
+	cli "github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client/option"
+)
+
+func init() {
+	cli.RegisterAnalyzer(Analyzer{})
+}
+
+const (
+	EmbeddingModelAda002 = "text-embedding-ada-002"
+	EmbeddingModel3Small = "text-embedding-3-small"
+	EmbeddingModel3Large = "text-embedding-3-large"
+	// the cheapest model with structured outputs
+	DefaultChatModel = "gpt-4o-mini"
+)
+
+// ServerOf returns the server cred is used on.
+func ServerOf(cred cli.Credential) Server {
+	return Server{
+		BaseURL:    cred.BaseURL,
+		AuthHeader: cred.AuthHeader,
+		Models:     cred.Models,
+	}
+}
+
+// Analyzer analyzes news with the embeddings and chat completions endpoints.
+type Analyzer struct{}
+
+func (Analyzer) ModelInfo() cli.ModelInfo {
+	return cli.ModelInfo{
+		Name:                  "openai",
+		DefaultEmbeddingModel: EmbeddingModelAda002,
+		EmbeddingModels: []cli.EmbeddingModel{
+			{Name: EmbeddingModelAda002, Dim: 1536, MaxTokens: 8191},
+			{Name: EmbeddingModel3Small, Dim: 1536, MaxTokens: 8191},
+			{Name: EmbeddingModel3Large, Dim: 3072, MaxTokens: 8191},
+		},
+		SentimentPrompt:   SentimentAnalysisPrompt,
+		ChatModel:         DefaultChatModel,
+		CompatibleServers: true,
+	}
+}
+
+// Embed embeds the texts with the embedding model of the job. The models of
+// cred name the chat models of the server, so they do not restrict it.
+func (a Analyzer) Embed(ctx context.Context, client *http.Client, cred cli.Credential,
+	opt *option.AnalyzerOption, texts ...string) ([][]float32, error) {
+	srv := ServerOf(cred)
+	srv.Models = nil
+	req := NewEmbeddingsRequest(cred.Key, texts...)
+	req.WithServer(srv)
+	req.Body.WithModel(a.ModelInfo().EmbeddingModelOf(opt))
+	if err := req.Modify(ctx); err != nil {
+		return nil, err
+	}
+	if err := req.Validate(ctx); err != nil {
+		return nil, err
+	}
+
+	httpReq, err := req.ToHTTPRequest()
+	if err != nil {
+		return nil, err
+	}
+
+	httpResp, err := client.Do(httpReq.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := ParseHTTPResponse[EmbeddingsResponseBody](httpResp)
+	if err != nil {
+		return nil, err
+	}
+
+	cli.RecordUsage(ctx, cli.Usage{
+		Operation:   cli.OperationEmbedding,
+		Model:       req.Body.Model,
+		InputTokens: resp.Body.Usage.PromptTokens,
+	})
+
+	if resp.Body.Len() == 0 {
+		return nil, cli.ErrEmptyResponse
+	}
+	if resp.Body.Len() < len(texts) {
+		return nil, cli.ErrResponseLengthMismatch
+	}
+
+	data := resp.Body.Data
+	sort.Slice(data, func(i, j int) bool { return data[i].Index < data[j].Index })
+	embds := make([][]float32, len(texts))
+	for i := range embds {
+		embds[i] = data[i].Embedding
+	}
+	return embds, nil
+}
+
+func (a Analyzer) ClassifySentiment(ctx context.Context, client *http.Client, cred cli.Credential,
+	opt *option.AnalyzerOption, texts ...string) ([]cli.SentimentResult, error) {
+	srv := ServerOf(cred)
+	req := NewSentimentAnalysisRequestWithPrompt(cred.Key,
+		opt.SentimentPromptOr(SentimentAnalysisPrompt), cli.WrapText(texts...))
+	req.WithServer(srv)
+	req.Body.SetModel(srv.Model(DefaultChatModel))
+	req.Body.MaxTokens = opt.MaxTokens
+
+	obj, err := send(ctx, client, cli.OperationSentiment, req)
+	if err != nil {
+		return nil, err
+	}
+
+	scores, err := SentimentAnalysisObject(*obj).Content()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(scores) == 0 {
+		return nil, cli.ErrEmptyResponse
+	}
+	if len(scores) != len(texts) {
+		return nil, cli.ErrResponseLengthMismatch
+	}
+
+	results := make([]cli.SentimentResult, len(texts))
+	for i, score := range scores {
+		// a missing or repeated id means the scores can not be matched
+		if score.Id != i+1 {
+			return nil, cli.ErrResponseLengthMismatch
+		}
+		if results[i].Sentiment, err = cli.ToSentiment(score.Score); err != nil {
+			return nil, err
+		}
+		if c := score.Confidence; c != nil && *c >= 0 && *c <= 1 {
+			results[i].Confidence = c
+		}
+		results[i].Rationale = score.Rationale
+	}
+	return results, nil
+}
+
+// ClassifyEntitySentiment classifies the sentiment toward the entities in
+// opt.Entities of all the texts in a request. The results of the texts or the
+// entities that are not asked for are dropped.
+func (a Analyzer) ClassifyEntitySentiment(ctx context.Context, client *http.Client, cred cli.Credential,
+	opt *option.AnalyzerOption, texts ...string) ([][]cli.EntitySentiment, error) {
+	results := make([][]cli.EntitySentiment, len(texts))
+	for i := range results {
+		results[i] = []cli.EntitySentiment{}
+	}
+	if len(opt.Entities) == 0 {
+		return results, nil
+	}
+
+	srv := ServerOf(cred)
+	req := NewEntitySentimentRequest(cred.Key, opt.Entities, cli.WrapText(texts...))
+	req.WithServer(srv)
+	req.Body.SetModel(srv.Model(DefaultChatModel))
+	req.Body.MaxTokens = opt.MaxTokens
+
+	obj, err := send(ctx, client, cli.OperationEntitySentiment, req)
+	if err != nil {
+		return nil, err
+	}
+
+	scores, err := EntitySentimentObject(*obj).Content()
+	if err != nil {
+		return nil, err
+	}
+
+	asked := map[string]bool{}
+	for _, e := range opt.Entities {
+		asked[e] = true
+	}
+	seen := map[EntitySentimentScore]bool{}
+	for _, score := range scores {
+		key := EntitySentimentScore{Entity: score.Entity, SentimentScore: SentimentScore{Id: score.Id}}
+		if score.Id < 1 || score.Id > len(texts) || !asked[score.Entity] || seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		sentiment, err := cli.ToSentiment(score.Score)
+		if err != nil {
+			return nil, err
+		}
+		result := cli.EntitySentiment{
+			Entity: score.Entity,
+			SentimentResult: cli.SentimentResult{
+				Sentiment: sentiment,
+				Rationale: score.Rationale,
+			},
+		}
+		if c := score.Confidence; c != nil && *c >= 0 && *c <= 1 {
+			result.Confidence = c
+		}
+		results[score.Id-1] = append(results[score.Id-1], result)
+	}
+	return results, nil
+}
+
+func (a Analyzer) Classify(ctx context.Context, client *http.Client, cred cli.Credential,
+	opt *option.AnalyzerOption, texts ...string) ([]cli.Annotation, error) {
+	if opt.Task == "" || len(opt.Labels) == 0 {
+		return make([]cli.Annotation, len(texts)), nil
+	}
+
+	srv := ServerOf(cred)
+	req := NewClassificationRequest(cred.Key, &opt.ClassificationOptions, cli.WrapText(texts...))
+	req.WithServer(srv)
+	req.Body.SetModel(srv.Model(DefaultChatModel))
+	req.Body.MaxTokens = opt.MaxTokens
+
+	obj, err := send(ctx, client, cli.OperationClassification, req)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := ClassificationObject(*obj).Content()
+	if err != nil {
+		return nil, err
+	}
+	return cli.ToAnnotations(&opt.ClassificationOptions, len(texts), results), nil
+}
+
+// send sends the request and records the usage of it as op.
+func send(ctx context.Context, client *http.Client, op string,
+	req Request[ChatCompletionsRequestBody]) (*ChatCompletionsObject, error) {
+	if err := req.Modify(ctx); err != nil {
+		return nil, err
+	}
+	if err := req.Validate(ctx); err != nil {
+		return nil, err
+	}
+
+	httpReq, err := req.ToHTTPRequest()
+	if err != nil {
+		return nil, err
+	}
+
+	httpResp, err := client.Do(httpReq.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := ParseHTTPResponse[ChatCompletionsObject](httpResp)
+	if err != nil {
+		return nil, err
+	}
+
+	cli.RecordUsage(ctx, cli.Usage{
+		Operation:    op,
+		Model:        req.Body.Model,
+		InputTokens:  resp.Body.Usage.PromptTokens,
+		OutputTokens: resp.Body.Usage.CompletionTokens,
+	})
+	return &resp.Body, nil
+}
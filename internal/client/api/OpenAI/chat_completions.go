@@ -47,6 +47,10 @@ func (body ChatCompletionsRequestBody) Endpoint() string {
 	return EPChatCompletions
 }
 
+func (body ChatCompletionsRequestBody) ModelName() string {
+	return body.Model
+}
+
 func (body *ChatCompletionsRequestBody) AppendSystemMessages(content, name string) *ChatCompletionsRequestBody {
 	body.Messages = append(body.Messages, systemMessage{
 		Content: content,
@@ -120,6 +124,30 @@ func (body *ChatCompletionsRequestBody) EnsureJsonResponse() *ChatCompletionsReq
 	return body
 }
 
+// SetJSONSchema makes the model respond with a JSON object that matches the
+// schema, see https://platform.openai.com/docs/guides/structured-outputs
+func (body *ChatCompletionsRequestBody) SetJSONSchema(name string, schema map[string]any) *ChatCompletionsRequestBody {
+	body.ResponseFormat = &ResponseFormat{
+		Type: "json_schema",
+		JSONSchema: &JSONSchema{
+			Name:   name,
+			Schema: schema,
+			Strict: true,
+		},
+	}
+	return body
+}
+
+func (body *ChatCompletionsRequestBody) AppendTools(tools ...Tool) *ChatCompletionsRequestBody {
+	body.Tools = append(body.Tools, tools...)
+	return body
+}
+
+func (body *ChatCompletionsRequestBody) SetToolChoice(choice ToolChoice) *ChatCompletionsRequestBody {
+	body.ToolChoice = choice
+	return body
+}
+
 // Messages
 type Message interface {
 	json.Marshaler
@@ -183,7 +211,16 @@ func (msg toolMessage) MarshalJSON() ([]byte, error) {
 }
 
 type ResponseFormat struct {
-	Type string `json:"type" validate:"oneof=text json_object"`
+	Type       string      `json:"type"                  validate:"oneof=text json_object json_schema"`
+	JSONSchema *JSONSchema `json:"json_schema,omitempty" validate:"required_if=Type json_schema"`
+}
+
+// see https://platform.openai.com/docs/guides/structured-outputs
+type JSONSchema struct {
+	Name        string         `json:"name"                  validate:"required,max=64"`
+	Description string         `json:"description,omitempty"`
+	Schema      map[string]any `json:"schema"                validate:"required"`
+	Strict      bool           `json:"strict,omitempty"`
 }
 
 type Tool struct {
@@ -200,6 +237,23 @@ type ToolFunction struct {
 type ToolFunctionParameters struct {
 	Type       string         `json:"type"`
 	Properties map[string]any `json:"properties"`
+	Required   []string       `json:"required,omitempty"`
+}
+
+// NewFunctionTool returns a function tool of which the parameters are the
+// properties of the object schema.
+func NewFunctionTool(name, description string, schema map[string]any) Tool {
+	tool := Tool{
+		Type: "function",
+		Function: ToolFunction{
+			Description: description,
+			Name:        name,
+			Parameters:  ToolFunctionParameters{Type: "object"},
+		},
+	}
+	tool.Function.Parameters.Properties, _ = schema["properties"].(map[string]any)
+	tool.Function.Parameters.Required, _ = schema["required"].([]string)
+	return tool
 }
 
 type ToolChoice interface {
@@ -222,7 +276,14 @@ func (tcObj ToolChoiceObject) MarshalJSON() ([]byte, error) {
 type ToolChoiceString string
 
 func (tcStr ToolChoiceString) MarshalJSON() ([]byte, error) {
-	return []byte(string(tcStr)), nil
+	return json.Marshal(string(tcStr))
+}
+
+// ToolChoiceFunction forces the model to call the function.
+func ToolChoiceFunction(name string) ToolChoiceObject {
+	tc := ToolChoiceObject{Type: "function"}
+	tc.Function.Name = name
+	return tc
 }
 
 const (
@@ -0,0 +1,75 @@
+package openai
+
+import (
+	"encoding/json"
+	"errors"
+
+	cli "github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client/option"
+)
+
+var ErrUnparsableClassification = errors.New("unparsable classification response")
+
+// the name of the response format of the custom classification
+const ClassificationName = "report_labels"
+
+// ClassificationSchema returns the JSON schema of the response of the custom
+// classification, of which the label is one of the given ones.
+func ClassificationSchema(labels []string) map[string]any {
+	return resultsSchema(map[string]any{
+		"id": map[string]any{
+			"type":        "integer",
+			"description": "the position of the statement, starting from 1",
+		},
+		"label": map[string]any{
+			"type":        "string",
+			"enum":        labels,
+			"description": "the label of the statement",
+		},
+		"confidence": map[string]any{
+			"type":        "number",
+			"description": "how confident the label is, from 0 to 1",
+		},
+		"rationale": map[string]any{
+			"type":        "string",
+			"description": "why the statement gets the label, in one short sentence",
+		},
+	})
+}
+
+// NewClassificationRequest asks for the label of each of the statements of the
+// text, which are wrapped by WrapText, in the task.
+func NewClassificationRequest(apikey string, task *option.ClassificationOptions, text string) Request[ChatCompletionsRequestBody] {
+	req := Request[ChatCompletionsRequestBody]{
+		Body:   ChatCompletionsRequestBody{},
+		apikey: apikey,
+	}
+	labels := make([]string, len(task.Labels))
+	for i, l := range task.Labels {
+		labels[i] = l.Name
+	}
+	req.Body.
+		AppendSystemMessages(cli.ClassificationPrompt(task), "").
+		AppendUserMessages(text, "").
+		SetJSONSchema(ClassificationName, ClassificationSchema(labels))
+	return req
+}
+
+type ClassificationObject ChatCompletionsObject
+
+// Content returns the labels of the first choice.
+func (obj ClassificationObject) Content() ([]cli.ClassificationResult, error) {
+	var content []cli.ClassificationResult
+	if len(obj.Choices) == 0 {
+		return content, nil
+	}
+
+	text := resultText(ChatCompletionsObject(obj), ClassificationName)
+	var results struct {
+		Results []cli.ClassificationResult `json:"results"`
+	}
+	if err := json.Unmarshal([]byte(enclosed(text, "{", "}")), &results); err != nil || results.Results == nil {
+		return nil, ErrUnparsableClassification
+	}
+	return results.Results, nil
+}
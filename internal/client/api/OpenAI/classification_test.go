@@ -0,0 +1,73 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	cli "github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client"
+	openai "github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client/api/OpenAI"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client/option"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassify(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/"+openai.EPChatCompletions, func(w http.ResponseWriter, r *http.Request) {
+		m := map[string]any{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&m))
+		format := m["response_format"].(map[string]any)["json_schema"].(map[string]any)
+		require.Equal(t, openai.ClassificationName, format["name"])
+
+		// the labels, descriptions and examples are in the prompt
+		schema, _ := json.Marshal(format["schema"])
+		require.Contains(t, string(schema), `"enum":["support","oppose"]`)
+		prompt := m["messages"].([]any)[0].(map[string]any)["content"].(string)
+		require.Contains(t, prompt, "Does the statement support nuclear power?")
+		require.Contains(t, prompt, "- support: 支持核電\n")
+		require.Contains(t, prompt, "[^]核廢料無處可去[$] is labeled oppose\n")
+
+		results := `[` +
+			`{"id": 2, "label": "oppose", "confidence": 0.7, "rationale": "擔憂核廢料"},` +
+			// neither a label nor in range, both are dropped
+			`{"id": 1, "label": "neutral", "confidence": 0.5, "rationale": "無"},` +
+			`{"id": 3, "label": "support", "confidence": 0.5, "rationale": "無"}]`
+		content, _ := json.Marshal(`{"results": ` + results + `}`)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"object": "chat.completion", "model": "gpt-4o-mini", "choices": [` +
+			`{"index": 0, "message": {"role": "assistant", "content": ` + string(content) + `}, "finish_reason": "stop"}]}`))
+	})
+	srvr := httptest.NewServer(mux)
+	defer srvr.Close()
+
+	anlz, err := cli.AnalyzerRepo.Get("openai")
+	require.NoError(t, err)
+	classifier, ok := anlz.(cli.Classifier)
+	require.True(t, ok)
+
+	client := &http.Client{Timeout: 3 * time.Second}
+	cred := cli.Credential{Key: TEST_API_KEY, BaseURL: srvr.URL + "/v1"}
+	opt := &option.AnalyzerOption{APIName: "openai"}
+	texts := []string{"核電廠將延役", "核廢料無處可去"}
+
+	// nothing is sent without a task
+	annotations, err := classifier.Classify(context.Background(), client, cred, opt, texts...)
+	require.NoError(t, err)
+	require.Equal(t, []cli.Annotation{{}, {}}, annotations)
+
+	require.NoError(t, opt.ParseClassification("stance", "Does the statement support nuclear power?",
+		"support: 支持核電\noppose: 反對核電", "oppose: 核廢料無處可去"))
+	annotations, err = classifier.Classify(context.Background(), client, cred, opt, texts...)
+	require.NoError(t, err)
+	require.Len(t, annotations, 2)
+	require.Empty(t, annotations[0].Label)
+	require.Equal(t, "oppose", annotations[1].Label)
+	require.Equal(t, "擔憂核廢料", annotations[1].Rationale)
+	require.InDelta(t, 0.7, *annotations[1].Confidence, 1e-6)
+	require.True(t, strings.HasPrefix(cli.ClassificationPrompt(&opt.ClassificationOptions), "Your task"))
+}
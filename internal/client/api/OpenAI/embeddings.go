@@ -26,6 +26,10 @@ func (body EmbeddingsRequestBody) Endpoint() string {
 	return EPEmbeddings
 }
 
+func (body EmbeddingsRequestBody) ModelName() string {
+	return body.Model
+}
+
 func (body *EmbeddingsRequestBody) WithModel(model string) {
 	body.Model = model
 }
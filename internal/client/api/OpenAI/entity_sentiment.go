@@ -0,0 +1,75 @@
+package openai
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+const EntitySentimentPrompt = `As an AI specializing in language and emotion analysis, your task is to assess the sentiments conveyed in a set of statements toward the target entities, such as people, parties and organisations. The target entities are listed as a JSON list in the first line, and each statement is enclosed with the symbols [^] and [$]. For each statement and each target entity the statement mentions, by name or by an unambiguous reference, score the sentiment toward the entity rather than the overall tone, on a scale from 1 to 5: 1 for very negative, 2 for negative, 3 for neutral, 4 for positive, and 5 for very positive. Skip the entities a statement does not mention. Please present your responses in a JSON object, of which results lists an object for each statement and mentioned entity: id is the position of the statement starting from 1, entity is the target entity exactly as listed, score is the score, confidence is how confident you are in the score from 0 to 1, and rationale explains the score in one short sentence in the language of the statement. For instance, if the entities are ["Alice", "Bob"] and the sentences are [^]Alice won the award[$] [^]Bob was fined[$], your corresponding response should be {"results": [{"id": 1, "entity": "Alice", "score": 5, "confidence": 0.9, "rationale": "Alice won an award."}, {"id": 2, "entity": "Bob", "score": 2, "confidence": 0.8, "rationale": "Bob was fined."}]}.`
+
+// the name of the response format and the tool of the entity sentiment analysis
+const EntitySentimentName = "report_entity_sentiments"
+
+// EntitySentimentSchema returns the JSON schema of the response of the entity
+// sentiment analysis, of which the entity is one of the given ones.
+func EntitySentimentSchema(entities []string) map[string]any {
+	properties := map[string]any{
+		"entity": map[string]any{
+			"type":        "string",
+			"enum":        entities,
+			"description": "the target entity the score is toward",
+		},
+	}
+	for k, v := range sentimentScoreProperties {
+		properties[k] = v
+	}
+	return resultsSchema(properties)
+}
+
+// NewEntitySentimentRequest asks for the sentiment of the text, of which the
+// statements are wrapped by WrapText, toward each of the entities.
+func NewEntitySentimentRequest(apikey string, entities []string, text string) Request[ChatCompletionsRequestBody] {
+	req := Request[ChatCompletionsRequestBody]{
+		Body:   ChatCompletionsRequestBody{},
+		apikey: apikey,
+	}
+	list, _ := json.Marshal(entities)
+	req.Body.
+		AppendSystemMessages(EntitySentimentPrompt, "").
+		AppendUserMessages(string(list)+"\n"+text, "").
+		SetJSONSchema(EntitySentimentName, EntitySentimentSchema(entities))
+	return req
+}
+
+// EntitySentimentScore is the score of a statement toward an entity.
+type EntitySentimentScore struct {
+	Entity string `json:"entity"`
+	SentimentScore
+}
+
+type EntitySentimentObject ChatCompletionsObject
+
+// Content returns the scores of the first choice, in the order of the ids of
+// the statements. Unlike SentimentAnalysisObject, a bare list of scores is
+// not accepted since it tells nothing about the entities.
+func (obj EntitySentimentObject) Content() ([]EntitySentimentScore, error) {
+	var content []EntitySentimentScore
+	if len(obj.Choices) == 0 {
+		return content, nil
+	}
+
+	text := resultText(ChatCompletionsObject(obj), EntitySentimentName)
+	var results struct {
+		Results []EntitySentimentScore `json:"results"`
+	}
+	if err := json.Unmarshal([]byte(enclosed(text, "{", "}")), &results); err != nil || results.Results == nil {
+		return nil, ErrUnparsableSentiment
+	}
+	content = results.Results
+	sort.SliceStable(content, func(i, j int) bool { return content[i].Id < content[j].Id })
+	for i := range content {
+		content[i].Entity = strings.TrimSpace(content[i].Entity)
+	}
+	return content, nil
+}
@@ -0,0 +1,76 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	cli "github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client"
+	openai "github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client/api/OpenAI"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client/option"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyEntitySentiment(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/"+openai.EPChatCompletions, func(w http.ResponseWriter, r *http.Request) {
+		m := map[string]any{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&m))
+		format := m["response_format"].(map[string]any)["json_schema"].(map[string]any)
+		require.Equal(t, openai.EntitySentimentName, format["name"])
+
+		// the entity is one of the asked ones
+		schema, _ := json.Marshal(format["schema"])
+		require.Contains(t, string(schema), `"enum":["賴清德","國民黨"]`)
+		require.True(t, strings.HasPrefix(m["messages"].([]any)[1].(map[string]any)["content"].(string), `["賴清德","國民黨"]`+"\n[^]"))
+
+		results := `[` +
+			`{"id": 2, "entity": "國民黨", "score": 2, "confidence": 0.7, "rationale": "批評國民黨"},` +
+			`{"id": 1, "entity": "賴清德", "score": 4, "confidence": 0.9, "rationale": "肯定賴清德"},` +
+			// neither asked for nor in range, both are dropped
+			`{"id": 1, "entity": "民進黨", "score": 3, "confidence": 0.5, "rationale": "無"},` +
+			`{"id": 3, "entity": "國民黨", "score": 3, "confidence": 0.5, "rationale": "無"},` +
+			// repeated, the first one is kept
+			`{"id": 1, "entity": "賴清德", "score": 1, "confidence": 2, "rationale": "重複"}]`
+		content, _ := json.Marshal(`{"results": ` + results + `}`)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"object": "chat.completion", "model": "gpt-4o-mini", "choices": [` +
+			`{"index": 0, "message": {"role": "assistant", "content": ` + string(content) + `}, "finish_reason": "stop"}]}`))
+	})
+	srvr := httptest.NewServer(mux)
+	defer srvr.Close()
+
+	anlz, err := cli.AnalyzerRepo.Get("openai")
+	require.NoError(t, err)
+	entAnlz, ok := anlz.(cli.EntityAnalyzer)
+	require.True(t, ok)
+
+	client := &http.Client{Timeout: 3 * time.Second}
+	cred := cli.Credential{Key: TEST_API_KEY, BaseURL: srvr.URL + "/v1"}
+	opt := &option.AnalyzerOption{APIName: "openai"}
+	texts := []string{"賴清德出席活動", "國民黨遭批評"}
+
+	// nothing is sent without entities
+	results, err := entAnlz.ClassifyEntitySentiment(context.Background(), client, cred, opt, texts...)
+	require.NoError(t, err)
+	require.Equal(t, [][]cli.EntitySentiment{{}, {}}, results)
+
+	opt.Entities = []string{"賴清德", "國民黨"}
+	results, err = entAnlz.ClassifyEntitySentiment(context.Background(), client, cred, opt, texts...)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	require.Len(t, results[0], 1)
+	require.Equal(t, "賴清德", results[0][0].Entity)
+	require.Equal(t, model.SentimentPositive, results[0][0].Sentiment)
+	require.Equal(t, "肯定賴清德", results[0][0].Rationale)
+	require.Len(t, results[1], 1)
+	require.Equal(t, "國民黨", results[1][0].Entity)
+	require.Equal(t, model.SentimentNegative, results[1][0].Sentiment)
+	require.InDelta(t, 0.7, *results[1][0].Confidence, 1e-6)
+}
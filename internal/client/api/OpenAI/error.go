@@ -2,6 +2,7 @@ package openai
 
 import (
 	"fmt"
+	"net/http"
 
 	ec "github.com/ChiaYuChang/NewsSentimentAnalyzer/pkgs/errorCode"
 )
@@ -18,9 +19,13 @@ type ErrorResponseBody struct {
 }
 
 func (erb ErrorResponseBody) ToEcError(statusCode int) *ec.Error {
-	ecErr, ok := ec.GetEcErr(ec.ErrorCode(erb.StatusCode))
+	ecErr, ok := ec.GetEcErr(ec.ErrorCode(statusCode))
 	if !ok {
-		ecErr = ec.MustGetEcErr(ec.ECBadRequest)
+		if statusCode >= http.StatusInternalServerError {
+			ecErr = ec.MustGetEcErr(ec.ECServerError)
+		} else {
+			ecErr = ec.MustGetEcErr(ec.ECBadRequest)
+		}
 		ecErr.WithDetails(fmt.Sprintf("original error code: %d", statusCode))
 	}
 
@@ -296,7 +296,7 @@ func TestChatCompletions(t *testing.T) {
 			content, err := saObj.Content()
 			require.NoError(t, err)
 			for i, c := range content {
-				t.Logf("content[%d]: %d", i, c)
+				t.Logf("content[%d]: %+v", i, c)
 			}
 		})
 	}
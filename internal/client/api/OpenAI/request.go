@@ -10,11 +10,19 @@ import (
 
 type RequestBody interface {
 	Endpoint() string
+	ModelName() string
 }
 
 type Request[T RequestBody] struct {
 	Body   T
 	apikey string
+	server Server
+}
+
+// WithServer sends the request to srv instead of api.openai.com.
+func (r *Request[T]) WithServer(srv Server) *Request[T] {
+	r.server = srv
+	return r
 }
 
 func (r Request[T]) String() string {
@@ -31,7 +39,13 @@ func (r *Request[T]) Modify(ctx context.Context) error {
 }
 
 func (r Request[T]) Validate(ctx context.Context) error {
-	return GetValidator().StructCtx(ctx, r.Body)
+	if err := GetValidator().StructCtx(ctx, r.Body); err != nil {
+		return err
+	}
+	if !r.server.Allow(r.Body.ModelName()) {
+		return fmt.Errorf("%w: %s", ErrModelNotAllowed, r.Body.ModelName())
+	}
+	return nil
 }
 
 func (r Request[T]) ToHTTPRequest() (*http.Request, error) {
@@ -41,13 +55,13 @@ func (r Request[T]) ToHTTPRequest() (*http.Request, error) {
 	}
 
 	req, err := http.NewRequest(http.MethodPost,
-		fmt.Sprintf("%s/%s", API_URL, r.EndPoint()),
+		r.server.URL(r.EndPoint()),
 		bytes.NewBuffer(body))
 	if err != nil {
 		return nil, err
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", r.apikey))
+	r.server.SetAuth(req, r.apikey)
 	return req, nil
 }
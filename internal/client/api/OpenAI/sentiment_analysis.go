@@ -1,30 +1,167 @@
 package openai
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+)
 
-const SentimentAnalysisPrompt = `As an AI specializing in language and emotion analysis, your task is to assess the sentiments conveyed in a set of statements. Each statement will be enclosed with the symbols [^] and [$]. Consider the overall tone, emotional nuances, and context within the statements. Classify each statement into one of three categories: -1 for negative, 0 for neutral, and 1 for positive. Please present your responses in a JSON list. Respond sequentially without repeating the provided sentences. For instance, if the sentence is [^]I love this movie[$] [^]I hate you[$], your corresponding response should be [1, -1].`
+var ErrUnparsableSentiment = errors.New("unparsable sentiment analysis response")
+
+const SentimentAnalysisPrompt = `As an AI specializing in language and emotion analysis, your task is to assess the sentiments conveyed in a set of statements. Each statement will be enclosed with the symbols [^] and [$]. Consider the overall tone, emotional nuances, and context within the statements. Score each statement on a scale from 1 to 5: 1 for very negative, 2 for negative, 3 for neutral, 4 for positive, and 5 for very positive. Please present your responses in a JSON object, of which results lists an object for each statement in order: id is the position of the statement starting from 1, score is the score, confidence is how confident you are in the score from 0 to 1, and rationale explains the score in one short sentence in the language of the statement. Respond without repeating the provided sentences. For instance, if the sentence is [^]I love this movie[$] [^]I hate you[$], your corresponding response should be {"results": [{"id": 1, "score": 5, "confidence": 0.9, "rationale": "The speaker loves the movie."}, {"id": 2, "score": 1, "confidence": 0.9, "rationale": "The speaker expresses hatred."}]}.`
+
+// the name of the response format and the tool of the sentiment analysis
+const SentimentAnalysisName = "report_sentiments"
+
+// the properties of the score of a statement
+var sentimentScoreProperties = map[string]any{
+	"id": map[string]any{
+		"type":        "integer",
+		"description": "the position of the statement, starting from 1",
+	},
+	"score": map[string]any{
+		"type":        "integer",
+		"enum":        []int{1, 2, 3, 4, 5},
+		"description": "1 for very negative, 2 for negative, 3 for neutral, 4 for positive, and 5 for very positive",
+	},
+	"confidence": map[string]any{
+		"type":        "number",
+		"description": "how confident the score is, from 0 to 1",
+	},
+	"rationale": map[string]any{
+		"type":        "string",
+		"description": "why the statement gets the score, in one short sentence",
+	},
+}
+
+// resultsSchema returns the strict JSON schema of an object of which results
+// is a list of objects of the properties, every property is required.
+func resultsSchema(properties map[string]any) map[string]any {
+	required := make([]string, 0, len(properties))
+	for k := range properties {
+		required = append(required, k)
+	}
+	sort.Strings(required)
+
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"results": map[string]any{
+				"type": "array",
+				"items": map[string]any{
+					"type":                 "object",
+					"properties":           properties,
+					"required":             required,
+					"additionalProperties": false,
+				},
+			},
+		},
+		"required":             []string{"results"},
+		"additionalProperties": false,
+	}
+}
+
+// SentimentAnalysisSchema is the JSON schema of the response of the sentiment
+// analysis, which is strict so every property is required.
+var SentimentAnalysisSchema = resultsSchema(sentimentScoreProperties)
 
 // See https://platform.openai.com/docs/api-reference/chat
 func NewSentimentAnalysisRequest(apikey string, text string) Request[ChatCompletionsRequestBody] {
+	return NewSentimentAnalysisRequestWithPrompt(apikey, SentimentAnalysisPrompt, text)
+}
+
+// NewSentimentAnalysisRequestWithPrompt is NewSentimentAnalysisRequest with a
+// prompt of the user, which should ask for the same JSON object.
+func NewSentimentAnalysisRequestWithPrompt(apikey, prompt, text string) Request[ChatCompletionsRequestBody] {
+	req := Request[ChatCompletionsRequestBody]{
+		Body:   ChatCompletionsRequestBody{},
+		apikey: apikey,
+	}
+	req.Body.
+		AppendSystemMessages(prompt, "").
+		AppendUserMessages(text, "").
+		SetJSONSchema(SentimentAnalysisName, SentimentAnalysisSchema)
+	return req
+}
+
+// NewSentimentAnalysisToolRequest asks for the same objects as
+// NewSentimentAnalysisRequest by forcing a function call, for the models and
+// compatible servers without structured outputs.
+func NewSentimentAnalysisToolRequest(apikey string, text string) Request[ChatCompletionsRequestBody] {
 	req := Request[ChatCompletionsRequestBody]{
 		Body:   ChatCompletionsRequestBody{},
 		apikey: apikey,
 	}
 	req.Body.
 		AppendSystemMessages(SentimentAnalysisPrompt, "").
-		AppendUserMessages(text, "")
+		AppendUserMessages(text, "").
+		AppendTools(NewFunctionTool(SentimentAnalysisName,
+			"Report the sentiment of each statement", SentimentAnalysisSchema)).
+		SetToolChoice(ToolChoiceFunction(SentimentAnalysisName))
 	return req
 }
 
+// SentimentScore is the score of a statement. Confidence is nil if the model
+// does not give one.
+type SentimentScore struct {
+	Id         int      `json:"id"`
+	Score      int      `json:"score"`
+	Confidence *float32 `json:"confidence"`
+	Rationale  string   `json:"rationale"`
+}
+
 type SentimentAnalysisObject ChatCompletionsObject
 
-func (obj SentimentAnalysisObject) Content() ([][]int, error) {
-	content := make([][]int, len(obj.Choices))
-	for i := range obj.Choices {
-		err := json.Unmarshal([]byte(obj.Choices[i].Message.Content), &content[i])
-		if err != nil {
-			return nil, err
-		}
+// Content returns the score of each statement, in the order of their ids, from
+// the first choice. The results are read from the call of the sentiment tool
+// if there is one, or from the message otherwise. The prose the model adds
+// around the JSON is ignored, and a bare JSON list of scores, which the models
+// without structured outputs may still respond with, is accepted.
+func (obj SentimentAnalysisObject) Content() ([]SentimentScore, error) {
+	var content []SentimentScore
+	if len(obj.Choices) == 0 {
+		return content, nil
+	}
+
+	text := resultText(ChatCompletionsObject(obj), SentimentAnalysisName)
+	var results struct {
+		Results []SentimentScore `json:"results"`
+	}
+	if err := json.Unmarshal([]byte(enclosed(text, "{", "}")), &results); err == nil && results.Results != nil {
+		content = results.Results
+		sort.SliceStable(content, func(i, j int) bool { return content[i].Id < content[j].Id })
+		return content, nil
+	}
+
+	var scores []int
+	if err := json.Unmarshal([]byte(enclosed(text, "[", "]")), &scores); err != nil {
+		return nil, ErrUnparsableSentiment
+	}
+	for i, score := range scores {
+		content = append(content, SentimentScore{Id: i + 1, Score: score})
 	}
 	return content, nil
 }
+
+// resultText returns the arguments of the call of the tool from the first
+// choice if there is one, or the content of the message otherwise.
+func resultText(obj ChatCompletionsObject, tool string) string {
+	msg := obj.Choices[0].Message
+	for _, tc := range msg.ToolCalls {
+		if tc.Function.Name == tool {
+			return tc.Function.Arguments
+		}
+	}
+	return msg.Content
+}
+
+// enclosed returns the text from the first open to the last close, or the
+// whole text if there is none.
+func enclosed(text, open, close string) string {
+	i, j := strings.Index(text, open), strings.LastIndex(text, close)
+	if i < 0 || j < i {
+		return text
+	}
+	return text[i : j+1]
+}
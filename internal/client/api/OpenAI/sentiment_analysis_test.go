@@ -0,0 +1,114 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	openai "github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client/api/OpenAI"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSentimentAnalysisObjectContent(t *testing.T) {
+	confidence := func(c float32) *float32 { return &c }
+
+	type testCase struct {
+		Name    string
+		Message string
+		Scores  []openai.SentimentScore
+		Err     error
+	}
+
+	tcs := []testCase{
+		{
+			Name: "json schema",
+			Message: `{"role": "assistant", "content": "{\"results\": [` +
+				`{\"id\": 2, \"score\": 1, \"confidence\": 0.6, \"rationale\": \"hatred\"}, ` +
+				`{\"id\": 1, \"score\": 5, \"confidence\": 0.9, \"rationale\": \"love\"}]}"}`,
+			Scores: []openai.SentimentScore{
+				{Id: 1, Score: 5, Confidence: confidence(0.9), Rationale: "love"},
+				{Id: 2, Score: 1, Confidence: confidence(0.6), Rationale: "hatred"},
+			},
+		},
+		{
+			Name: "json in prose",
+			Message: `{"role": "assistant", "content": "Sure! {\"results\": ` +
+				`[{\"id\": 1, \"score\": 3, \"rationale\": \"a fact\"}]} Hope it helps."}`,
+			Scores: []openai.SentimentScore{
+				{Id: 1, Score: 3, Rationale: "a fact"},
+			},
+		},
+		{
+			Name: "tool call",
+			Message: `{"role": "assistant", "content": "", "tool_calls": [{"id": "call_1", "type": "function", ` +
+				`"function": {"name": "report_sentiments", "arguments": "{\"results\": ` +
+				`[{\"id\": 1, \"score\": 4, \"confidence\": 0.7, \"rationale\": \"good news\"}]}"}}]}`,
+			Scores: []openai.SentimentScore{
+				{Id: 1, Score: 4, Confidence: confidence(0.7), Rationale: "good news"},
+			},
+		},
+		{
+			Name:    "list of scores",
+			Message: `{"role": "assistant", "content": "[4, 2]"}`,
+			Scores: []openai.SentimentScore{
+				{Id: 1, Score: 4},
+				{Id: 2, Score: 2},
+			},
+		},
+		{
+			Name:    "unparsable",
+			Message: `{"role": "assistant", "content": "I can not score these statements."}`,
+			Err:     openai.ErrUnparsableSentiment,
+		},
+	}
+
+	for i := range tcs {
+		tc := tcs[i]
+		t.Run(tc.Name, func(t *testing.T) {
+			var obj openai.ChatCompletionsObject
+			require.NoError(t, json.Unmarshal([]byte(`{"object": "chat.completion", "choices": [{"index": 0, "message": `+
+				tc.Message+`, "finish_reason": "stop"}]}`), &obj))
+
+			scores, err := openai.SentimentAnalysisObject(obj).Content()
+			if tc.Err != nil {
+				require.ErrorIs(t, err, tc.Err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.Scores, scores)
+		})
+	}
+}
+
+func TestSentimentAnalysisRequest(t *testing.T) {
+	body := func(req openai.Request[openai.ChatCompletionsRequestBody]) map[string]any {
+		require.NoError(t, req.Modify(context.Background()))
+		require.NoError(t, req.Validate(context.Background()))
+
+		b, err := json.Marshal(req.Body)
+		require.NoError(t, err)
+
+		m := map[string]any{}
+		require.NoError(t, json.Unmarshal(b, &m))
+		return m
+	}
+
+	m := body(openai.NewSentimentAnalysisRequest(TEST_API_KEY, "[^]I love this movie[$]"))
+	format := m["response_format"].(map[string]any)
+	require.Equal(t, "json_schema", format["type"])
+	schema := format["json_schema"].(map[string]any)
+	require.Equal(t, openai.SentimentAnalysisName, schema["name"])
+	require.Equal(t, true, schema["strict"])
+	require.NotContains(t, m, "tools")
+
+	m = body(openai.NewSentimentAnalysisToolRequest(TEST_API_KEY, "[^]I love this movie[$]"))
+	require.NotContains(t, m, "response_format")
+	tools := m["tools"].([]any)
+	require.Len(t, tools, 1)
+	require.Equal(t, openai.SentimentAnalysisName,
+		tools[0].(map[string]any)["function"].(map[string]any)["name"])
+	require.Equal(t, map[string]any{
+		"type":     "function",
+		"function": map[string]any{"name": openai.SentimentAnalysisName},
+	}, m["tool_choice"])
+}
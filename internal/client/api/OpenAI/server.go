@@ -0,0 +1,66 @@
+package openai
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var ErrModelNotAllowed = errors.New("the model is not allowed by the server")
+
+// Server is an OpenAI compatible server, e.g. a local inference server. The
+// zero value is api.openai.com.
+type Server struct {
+	// the url the endpoints are appended to, API_URL if empty
+	BaseURL string
+	// the header the api key is sent in, without the Bearer scheme, the
+	// Authorization header with the Bearer scheme if empty
+	AuthHeader string
+	// the models served, any model is allowed if empty. The embedding models
+	// are not restricted, since the one of a job is fixed by its vectors.
+	Models []string
+}
+
+// URL returns the url of the endpoint on the server.
+func (srv Server) URL(endpoint string) string {
+	baseURL := API_URL
+	if srv.BaseURL != "" {
+		baseURL = strings.TrimRight(srv.BaseURL, "/")
+	}
+	return fmt.Sprintf("%s/%s", baseURL, endpoint)
+}
+
+// Allow reports whether the model is served.
+func (srv Server) Allow(model string) bool {
+	if len(srv.Models) == 0 {
+		return true
+	}
+	for _, m := range srv.Models {
+		if m == model {
+			return true
+		}
+	}
+	return false
+}
+
+// Model returns model if it is served, or the first of the models otherwise.
+func (srv Server) Model(model string) string {
+	if srv.Allow(model) {
+		return model
+	}
+	return srv.Models[0]
+}
+
+// SetAuth sets the api key to the header of req, the header is omitted if the
+// api key is empty since local servers seldom require one.
+func (srv Server) SetAuth(req *http.Request, apikey string) {
+	if apikey == "" {
+		return
+	}
+	if srv.AuthHeader == "" || http.CanonicalHeaderKey(srv.AuthHeader) == "Authorization" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", apikey))
+		return
+	}
+	req.Header.Set(srv.AuthHeader, apikey)
+}
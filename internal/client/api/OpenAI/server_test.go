@@ -0,0 +1,143 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	cli "github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client"
+	openai "github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client/api/OpenAI"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client/option"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer(t *testing.T) {
+	srv := openai.Server{}
+	require.Equal(t, openai.API_URL+"/embeddings", srv.URL(openai.EPEmbeddings))
+	require.True(t, srv.Allow("any-model"))
+	require.Equal(t, openai.DefaultChatModel, srv.Model(openai.DefaultChatModel))
+
+	srv = openai.Server{
+		BaseURL: "http://localhost:8000/v1/",
+		Models:  []string{"llama3", "nomic-embed-text"},
+	}
+	require.Equal(t, "http://localhost:8000/v1/chat/completions", srv.URL(openai.EPChatCompletions))
+	require.True(t, srv.Allow("llama3"))
+	require.False(t, srv.Allow(openai.DefaultChatModel))
+	require.Equal(t, "llama3", srv.Model(openai.DefaultChatModel))
+
+	type testCase struct {
+		Name       string
+		AuthHeader string
+		APIKey     string
+		Header     http.Header
+	}
+
+	tcs := []testCase{
+		{
+			Name:   "default",
+			APIKey: TEST_API_KEY,
+			Header: http.Header{"Authorization": {"Bearer " + TEST_API_KEY}},
+		},
+		{
+			Name:       "authorization",
+			AuthHeader: "authorization",
+			APIKey:     TEST_API_KEY,
+			Header:     http.Header{"Authorization": {"Bearer " + TEST_API_KEY}},
+		},
+		{
+			Name:       "custom header",
+			AuthHeader: "X-Api-Key",
+			APIKey:     TEST_API_KEY,
+			Header:     http.Header{"X-Api-Key": {TEST_API_KEY}},
+		},
+		{
+			Name:       "empty key",
+			AuthHeader: "X-Api-Key",
+			Header:     http.Header{},
+		},
+	}
+
+	for i := range tcs {
+		tc := tcs[i]
+		t.Run(tc.Name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, "http://localhost", nil)
+			require.NoError(t, err)
+			openai.Server{AuthHeader: tc.AuthHeader}.SetAuth(req, tc.APIKey)
+			require.Equal(t, tc.Header, req.Header)
+		})
+	}
+}
+
+func TestAnalyzerWithCompatibleServer(t *testing.T) {
+	f, err := os.ReadFile("./example_response/embeddings.json")
+	require.NoError(t, err)
+
+	models := []string{}
+	mux := http.NewServeMux()
+	handle := func(endpoint string, resp []byte) {
+		mux.HandleFunc("/local/v1/"+endpoint, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			if r.Header.Get("X-Api-Key") != TEST_API_KEY || r.Header.Get("Authorization") != "" {
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(`{"error": {"message": "invalid api key", "type": "invalid_request_error"}}`))
+				return
+			}
+
+			body := map[string]any{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			models = append(models, body["model"].(string))
+			w.WriteHeader(http.StatusOK)
+			w.Write(resp)
+		})
+	}
+	handle(openai.EPEmbeddings, f)
+	handle(openai.EPChatCompletions, []byte(`{
+    "id": "chatcmpl-local",
+    "object": "chat.completion",
+    "model": "llama3",
+    "choices": [{"index": 0, "message": {"role": "assistant", "content": "[4]"}, "finish_reason": "stop"}]
+}`))
+
+	srvr := httptest.NewServer(mux)
+	defer srvr.Close()
+
+	anlz, err := cli.AnalyzerRepo.Get("openai")
+	require.NoError(t, err)
+
+	client := &http.Client{Timeout: 3 * time.Second}
+	cred := cli.Credential{
+		Key:        TEST_API_KEY,
+		BaseURL:    srvr.URL + "/local/v1",
+		AuthHeader: "X-Api-Key",
+		Models:     []string{"llama3", openai.EmbeddingModelAda002},
+	}
+	opt := &option.AnalyzerOption{APIName: "openai"}
+
+	embds, err := anlz.Embed(context.Background(), client, cred, opt, "text")
+	require.NoError(t, err)
+	require.Len(t, embds, 1)
+
+	sentiments, err := anlz.ClassifySentiment(context.Background(), client, cred, opt, "text")
+	require.NoError(t, err)
+	require.Equal(t, []cli.SentimentResult{{Sentiment: model.SentimentPositive}}, sentiments)
+	require.Equal(t, []string{openai.EmbeddingModelAda002, "llama3"}, models)
+
+	// the models name the chat models, the embedding model is still requested
+	// but never substituted, since the vectors of different models are not
+	// comparable
+	cred.Models = []string{"llama3"}
+	embds, err = anlz.Embed(context.Background(), client, cred, opt, "text")
+	require.NoError(t, err)
+	require.Len(t, embds, 1)
+	require.Equal(t, []string{openai.EmbeddingModelAda002, "llama3", openai.EmbeddingModelAda002}, models)
+
+	cred.Key = TEST_ERROR_API_KEY
+	_, err = anlz.ClassifySentiment(context.Background(), client, cred, opt, "text")
+	require.Error(t, err)
+}
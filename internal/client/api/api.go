@@ -33,6 +33,14 @@ func IsLastPageToken(token NextPageToken) bool {
 	return token.Equal(StrLastPageToken) || token.Equal(IntLastPageToken)
 }
 
+// FirstPageToken returns the token of the first page of the same kind as token
+func FirstPageToken(token NextPageToken) NextPageToken {
+	if _, ok := token.(StrNextPageToken); ok {
+		return StrNextPageToken("")
+	}
+	return IntNextPageToken(1)
+}
+
 const QueryOriPageKey = true
 
 func QueryOriginalPageContext(ctx context.Context) context.Context {
@@ -9,6 +9,7 @@ import (
 	"sort"
 	"time"
 
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client/option"
 	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/server/service"
 	"github.com/google/uuid"
 	"github.com/oklog/ulid/v2"
@@ -50,13 +51,13 @@ func (a SortById) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
 func (a SortById) Less(i, j int) bool { return a[i].Id.String() < a[j].Id.String() }
 
 type PreviewCache struct {
-	IsDone          bool                   `json:"is_done"          redis:"is_done"`
-	Query           CacheQuery             `json:"query"            redis:"query"`
-	CreatedAt       time.Time              `json:"created_at"       redis:"created_at"`
-	NewsItem        []NewsPreview          `json:"news_item"        redis:"news_item"`
-	SelectedAll     bool                   `json:"selected_all"     redis:"selected_all"`
-	SelectedNId     []string               `json:"selected_nid"     redis:"selected_nid"`
-	AnalyzerOptions service.AnalyzerOption `json:"analyzer_options" redis:"analyzer_options"`
+	IsDone          bool                  `json:"is_done"          redis:"is_done"`
+	Query           CacheQuery            `json:"query"            redis:"query"`
+	CreatedAt       time.Time             `json:"created_at"       redis:"created_at"`
+	NewsItem        []NewsPreview         `json:"news_item"        redis:"news_item"`
+	SelectedAll     bool                  `json:"selected_all"     redis:"selected_all"`
+	SelectedNId     []string              `json:"selected_nid"     redis:"selected_nid"`
+	AnalyzerOptions option.AnalyzerOption `json:"analyzer_options" redis:"analyzer_options"`
 }
 
 func (cache PreviewCache) String() string {
@@ -158,6 +159,15 @@ func (cq *CacheQuery) AddRandomSalt(l int) error {
 	return nil
 }
 
+// ToReplayQuery returns a copy of the query that starts from the first page.
+// The api key is removed and should be set again before sending the query.
+func (cq CacheQuery) ToReplayQuery() CacheQuery {
+	cq.API.Key = ""
+	cq.Salt = ""
+	cq.NextPage = FirstPageToken(cq.NextPage)
+	return cq
+}
+
 func (cq *CacheQuery) UnmarshalJSON(data []byte) error {
 	type InnerCacheQuery CacheQuery
 	tmp := struct {
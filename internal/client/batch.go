@@ -0,0 +1,126 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"unicode"
+
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client/option"
+)
+
+// the tokens taken by the [^] and [$] around each text
+const wrapTokens = 4
+
+// Article is a text to be analyzed and the id of its news. Paragraphs are the
+// paragraphs of the text, which is chunked along them for embedding, or nil
+// if the text is a single paragraph.
+type Article struct {
+	NewsId     int64
+	Text       string
+	Paragraphs []string
+}
+
+// ParagraphsOrText returns the paragraphs of the article, or the text if there
+// is none.
+func (a Article) ParagraphsOrText() []string {
+	if len(a.Paragraphs) == 0 {
+		return []string{a.Text}
+	}
+	return a.Paragraphs
+}
+
+// EstimateTokens returns a rough, rather overestimated, number of the tokens of
+// text. A CJK character takes about a token while an English word of 4 letters
+// does, which is close enough for packing articles without a tokenizer.
+func EstimateTokens(text string) int {
+	nCJK, nOther := 0, 0
+	for _, r := range text {
+		switch {
+		case unicode.IsSpace(r):
+		case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul):
+			nCJK++
+		default:
+			nOther++
+		}
+	}
+	return nCJK + (nOther+3)/4
+}
+
+// Batcher packs articles into sentiment analysis requests.
+type Batcher struct {
+	// the max estimated tokens of the texts in a request, the prompt excluded.
+	// Every article is sent alone if it is not positive.
+	Budget int
+	// the max number of articles in a request, unlimited if it is not positive
+	MaxSize int
+}
+
+// Pack splits the articles into batches, in order, of which the estimated
+// tokens do not exceed the budget. An article over the budget by itself is
+// packed alone.
+func (b Batcher) Pack(articles ...Article) [][]Article {
+	batches := [][]Article{}
+	batch, tokens := []Article{}, 0
+	for _, a := range articles {
+		n := EstimateTokens(a.Text) + wrapTokens
+		full := b.Budget <= 0 || tokens+n > b.Budget ||
+			(b.MaxSize > 0 && len(batch) >= b.MaxSize)
+		if len(batch) > 0 && full {
+			batches = append(batches, batch)
+			batch, tokens = []Article{}, 0
+		}
+		batch = append(batch, a)
+		tokens += n
+	}
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+	return batches
+}
+
+// ClassifySentiment classifies the articles in batches with anlz and returns
+// the result or the error of each news, which is the error of the batch or
+// the one of the text in its result. A batch of which the response does
+// not match the articles is split in halves and retried, down to a single
+// article, since models lose count on long lists now and then.
+func (b Batcher) ClassifySentiment(ctx context.Context, anlz Analyzer, cli *http.Client, cred Credential,
+	opt *option.AnalyzerOption, articles ...Article) (map[int64]SentimentResult, map[int64]error) {
+	sentiments := make(map[int64]SentimentResult, len(articles))
+	errs := map[int64]error{}
+
+	var classify func(batch []Article)
+	classify = func(batch []Article) {
+		texts := make([]string, len(batch))
+		for i, a := range batch {
+			texts[i] = a.Text
+		}
+
+		results, err := anlz.ClassifySentiment(ctx, cli, cred, opt, texts...)
+		if err == nil && len(results) != len(batch) {
+			err = ErrResponseLengthMismatch
+		}
+
+		if errors.Is(err, ErrResponseLengthMismatch) && len(batch) > 1 && ctx.Err() == nil {
+			classify(batch[:len(batch)/2])
+			classify(batch[len(batch)/2:])
+			return
+		}
+
+		for i, a := range batch {
+			switch {
+			case err != nil:
+				errs[a.NewsId] = err
+			case results[i].Err != nil:
+				errs[a.NewsId] = results[i].Err
+			default:
+				sentiments[a.NewsId] = results[i]
+			}
+		}
+	}
+
+	for _, batch := range b.Pack(articles...) {
+		classify(batch)
+	}
+	return sentiments, errs
+}
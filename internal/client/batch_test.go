@@ -0,0 +1,162 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client/option"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+// countingAnalyzer scores a text by its length and drops the last score of
+// batches larger than maxSize, as a model losing count does. The text failOn
+// fails alone, as a provider classifying the texts one by one does.
+type countingAnalyzer struct {
+	maxSize int
+	err     error
+	failOn  string
+	batches [][]string
+}
+
+func (a *countingAnalyzer) ModelInfo() client.ModelInfo {
+	return client.ModelInfo{Name: "counting"}
+}
+
+func (a *countingAnalyzer) Embed(ctx context.Context, cli *http.Client, cred client.Credential,
+	opt *option.AnalyzerOption, texts ...string) ([][]float32, error) {
+	return nil, nil
+}
+
+func (a *countingAnalyzer) ClassifySentiment(ctx context.Context, cli *http.Client, cred client.Credential,
+	opt *option.AnalyzerOption, texts ...string) ([]client.SentimentResult, error) {
+	a.batches = append(a.batches, texts)
+	if a.err != nil {
+		return nil, a.err
+	}
+
+	results := make([]client.SentimentResult, 0, len(texts))
+	for _, text := range texts {
+		if text == a.failOn {
+			results = append(results, client.SentimentResult{Err: errors.New("rate limited")})
+			continue
+		}
+		results = append(results, client.SentimentResult{
+			Sentiment: model.Sentiments[len(text)-1],
+			Rationale: text,
+		})
+	}
+	if len(texts) > a.maxSize {
+		results = results[:len(texts)-1]
+	}
+	return results, nil
+}
+
+func TestEstimateTokens(t *testing.T) {
+	require.Equal(t, 0, client.EstimateTokens(""))
+	require.Equal(t, 4, client.EstimateTokens("台積電營"))
+	require.Equal(t, 4, client.EstimateTokens("I love this movie"))
+	require.Equal(t, 4, client.EstimateTokens("台積電 TSMC"))
+}
+
+func TestPack(t *testing.T) {
+	articles := []client.Article{
+		{NewsId: 1, Text: strings.Repeat("好", 10)},
+		{NewsId: 2, Text: strings.Repeat("好", 10)},
+		{NewsId: 3, Text: strings.Repeat("好", 30)},
+		{NewsId: 4, Text: strings.Repeat("好", 5)},
+	}
+
+	ids := func(batches [][]client.Article) [][]int64 {
+		out := [][]int64{}
+		for _, batch := range batches {
+			bIds := []int64{}
+			for _, a := range batch {
+				bIds = append(bIds, a.NewsId)
+			}
+			out = append(out, bIds)
+		}
+		return out
+	}
+
+	type testCase struct {
+		Name    string
+		Batcher client.Batcher
+		Batches [][]int64
+	}
+
+	tcs := []testCase{
+		{Name: "no budget", Batcher: client.Batcher{}, Batches: [][]int64{{1}, {2}, {3}, {4}}},
+		{Name: "budget", Batcher: client.Batcher{Budget: 30}, Batches: [][]int64{{1, 2}, {3}, {4}}},
+		{Name: "large budget", Batcher: client.Batcher{Budget: 1000}, Batches: [][]int64{{1, 2, 3, 4}}},
+		{Name: "max size", Batcher: client.Batcher{Budget: 1000, MaxSize: 3}, Batches: [][]int64{{1, 2, 3}, {4}}},
+	}
+
+	for i := range tcs {
+		tc := tcs[i]
+		t.Run(tc.Name, func(t *testing.T) {
+			require.Equal(t, tc.Batches, ids(tc.Batcher.Pack(articles...)))
+		})
+	}
+	require.Empty(t, client.Batcher{Budget: 1000}.Pack())
+}
+
+func TestBatcherClassifySentiment(t *testing.T) {
+	articles := []client.Article{
+		{NewsId: 11, Text: "a"},
+		{NewsId: 12, Text: "bb"},
+		{NewsId: 13, Text: "ccc"},
+		{NewsId: 14, Text: "dddd"},
+		{NewsId: 15, Text: "eeeee"},
+	}
+	batcher := client.Batcher{Budget: 1000}
+
+	// the batch of 5 is split into 2 and 3, and the latter into 1 and 2
+	anlz := &countingAnalyzer{maxSize: 2}
+	sentiments, errs := batcher.ClassifySentiment(context.Background(), anlz, nil, client.Credential{}, nil, articles...)
+	require.Empty(t, errs)
+	require.Equal(t, map[int64]client.SentimentResult{
+		11: {Sentiment: model.SentimentVeryNegative, Rationale: "a"},
+		12: {Sentiment: model.SentimentNegative, Rationale: "bb"},
+		13: {Sentiment: model.SentimentNeutral, Rationale: "ccc"},
+		14: {Sentiment: model.SentimentPositive, Rationale: "dddd"},
+		15: {Sentiment: model.SentimentVeryPositive, Rationale: "eeeee"},
+	}, sentiments)
+	require.Equal(t, [][]string{
+		{"a", "bb", "ccc", "dddd", "eeeee"},
+		{"a", "bb"},
+		{"ccc", "dddd", "eeeee"},
+		{"ccc"},
+		{"dddd", "eeeee"},
+	}, anlz.batches)
+
+	// a single article can not be split any further
+	anlz = &countingAnalyzer{maxSize: 0}
+	sentiments, errs = batcher.ClassifySentiment(context.Background(), anlz, nil, client.Credential{}, nil, articles[:2]...)
+	require.Empty(t, sentiments)
+	require.Len(t, errs, 2)
+	require.ErrorIs(t, errs[11], client.ErrResponseLengthMismatch)
+	require.ErrorIs(t, errs[12], client.ErrResponseLengthMismatch)
+
+	// other errors are not retried
+	anlz = &countingAnalyzer{maxSize: 5, err: errors.New("rate limited")}
+	sentiments, errs = batcher.ClassifySentiment(context.Background(), anlz, nil, client.Credential{}, nil, articles...)
+	require.Empty(t, sentiments)
+	require.Len(t, errs, len(articles))
+	require.Len(t, anlz.batches, 1)
+
+	// the error of a text does not discard the results of the others
+	anlz = &countingAnalyzer{maxSize: 5, failOn: "bb"}
+	sentiments, errs = batcher.ClassifySentiment(context.Background(), anlz, nil, client.Credential{}, nil, articles[:3]...)
+	require.Equal(t, map[int64]client.SentimentResult{
+		11: {Sentiment: model.SentimentVeryNegative, Rationale: "a"},
+		13: {Sentiment: model.SentimentNeutral, Rationale: "ccc"},
+	}, sentiments)
+	require.Len(t, errs, 1)
+	require.EqualError(t, errs[12], "rate limited")
+	require.Len(t, anlz.batches, 1)
+}
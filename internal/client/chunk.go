@@ -0,0 +1,150 @@
+package client
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Chunk packs the paragraphs, in order, into chunks of which the estimated
+// tokens do not exceed maxTokens, so that no part of a long article is left
+// to the truncation of the provider. A paragraph over the limit is split at
+// the ends of its sentences, a sentence over the limit at its spaces and a
+// word over the limit wherever it reaches the limit. The paragraphs form a
+// single chunk if maxTokens is not positive.
+func Chunk(maxTokens int, paragraphs ...string) []string {
+	if maxTokens <= 0 {
+		return []string{strings.Join(paragraphs, "\n")}
+	}
+
+	chunks := []string{}
+	sb, tokens := strings.Builder{}, 0
+	for _, p := range paragraphs {
+		for i, piece := range splitParagraph(maxTokens, p) {
+			n := EstimateTokens(piece)
+			if sb.Len() > 0 && tokens+n > maxTokens {
+				chunks = append(chunks, sb.String())
+				sb.Reset()
+				tokens = 0
+			}
+			// the pieces of a paragraph are joined as they were
+			if sb.Len() > 0 && i == 0 {
+				sb.WriteString("\n")
+			}
+			sb.WriteString(piece)
+			tokens += n
+		}
+	}
+	if sb.Len() > 0 || len(chunks) == 0 {
+		chunks = append(chunks, sb.String())
+	}
+	return chunks
+}
+
+// splitParagraph splits the paragraph into pieces of at most maxTokens, which
+// are as large as the sentences, the words or the characters of it allow.
+func splitParagraph(maxTokens int, paragraph string) []string {
+	if EstimateTokens(paragraph) <= maxTokens {
+		return []string{paragraph}
+	}
+
+	pieces := []string{}
+	for _, s := range splitSentences(paragraph) {
+		if EstimateTokens(s) <= maxTokens {
+			pieces = append(pieces, s)
+			continue
+		}
+		for _, w := range strings.SplitAfter(s, " ") {
+			if EstimateTokens(w) <= maxTokens {
+				pieces = append(pieces, w)
+				continue
+			}
+			pieces = append(pieces, splitTokens(maxTokens, w)...)
+		}
+	}
+	return pieces
+}
+
+// splitSentences splits text after the full stops, question and exclamation
+// marks, of which a period only ends a sentence if a space follows it.
+func splitSentences(text string) []string {
+	sentences := []string{}
+	runes := []rune(text)
+	start := 0
+	for i, r := range runes {
+		end := false
+		switch r {
+		case '。', '！', '？', '!', '?', '；':
+			end = true
+		case '.':
+			end = i+1 == len(runes) || unicode.IsSpace(runes[i+1])
+		}
+		if end {
+			sentences = append(sentences, string(runes[start:i+1]))
+			start = i + 1
+		}
+	}
+	if start < len(runes) {
+		sentences = append(sentences, string(runes[start:]))
+	}
+	return sentences
+}
+
+// splitTokens splits text into pieces of which the estimated tokens are at
+// most maxTokens, counted the same way as EstimateTokens.
+func splitTokens(maxTokens int, text string) []string {
+	pieces := []string{}
+	start, nCJK, nOther := 0, 0, 0
+	for i, r := range text {
+		cjk, other := 0, 0
+		switch {
+		case unicode.IsSpace(r):
+		case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul):
+			cjk = 1
+		default:
+			other = 1
+		}
+		if nCJK+cjk+(nOther+other+3)/4 > maxTokens && i > start {
+			pieces = append(pieces, text[start:i])
+			start, nCJK, nOther = i, 0, 0
+		}
+		nCJK, nOther = nCJK+cjk, nOther+other
+	}
+	if start < len(text) {
+		pieces = append(pieces, text[start:])
+	}
+	return pieces
+}
+
+// Pool returns the mean of the vectors of the chunks of a text weighted by
+// weights, or the plain mean if weights is nil. It returns nil if there is no
+// vector.
+func Pool(vectors [][]float32, weights []float64) []float32 {
+	if len(vectors) == 0 {
+		return nil
+	}
+	if len(vectors) == 1 {
+		return vectors[0]
+	}
+
+	sum := make([]float64, len(vectors[0]))
+	total := 0.0
+	for i, v := range vectors {
+		w := 1.0
+		if weights != nil {
+			w = weights[i]
+		}
+		for j := range sum {
+			sum[j] += w * float64(v[j])
+		}
+		total += w
+	}
+
+	pooled := make([]float32, len(sum))
+	if total == 0 {
+		return pooled
+	}
+	for j := range sum {
+		pooled[j] = float32(sum[j] / total)
+	}
+	return pooled
+}
@@ -0,0 +1,76 @@
+package client_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChunk(t *testing.T) {
+	title := "台股崩盤"
+	p1 := strings.Repeat("好", 6)
+	p2 := strings.Repeat("壞", 3) + "。" + strings.Repeat("跌", 4) + "。"
+
+	type testCase struct {
+		Name       string
+		MaxTokens  int
+		Paragraphs []string
+		Chunks     []string
+	}
+
+	tcs := []testCase{
+		{
+			Name:       "no limit",
+			Paragraphs: []string{title, p1, p2},
+			Chunks:     []string{title + "\n" + p1 + "\n" + p2},
+		},
+		{
+			Name:       "paragraphs",
+			MaxTokens:  10,
+			Paragraphs: []string{title, p1, p2},
+			Chunks:     []string{title + "\n" + p1, "壞壞壞。跌跌跌跌。"},
+		},
+		{
+			Name:       "sentences",
+			MaxTokens:  6,
+			Paragraphs: []string{title, p2},
+			Chunks:     []string{title, "壞壞壞。", "跌跌跌跌。"},
+		},
+		{
+			Name:       "long sentence",
+			MaxTokens:  4,
+			Paragraphs: []string{p1},
+			Chunks:     []string{"好好好好", "好好"},
+		},
+		{
+			Name:       "english",
+			MaxTokens:  5,
+			Paragraphs: []string{"Stocks fell sharply. Investors panicked today."},
+			Chunks:     []string{"Stocks fell sharply. ", "Investors panicked ", "today."},
+		},
+	}
+
+	for i := range tcs {
+		tc := tcs[i]
+		t.Run(tc.Name, func(t *testing.T) {
+			chunks := client.Chunk(tc.MaxTokens, tc.Paragraphs...)
+			require.Equal(t, tc.Chunks, chunks)
+			for _, c := range chunks {
+				if tc.MaxTokens > 0 {
+					require.LessOrEqual(t, client.EstimateTokens(c), tc.MaxTokens)
+				}
+			}
+		})
+	}
+	require.Equal(t, []string{""}, client.Chunk(10))
+}
+
+func TestPool(t *testing.T) {
+	vectors := [][]float32{{1, 0}, {0, 1}}
+	require.Equal(t, []float32{0.5, 0.5}, client.Pool(vectors, nil))
+	require.Equal(t, []float32{0.75, 0.25}, client.Pool(vectors, []float64{3, 1}))
+	require.Equal(t, []float32{1, 0}, client.Pool(vectors[:1], nil))
+	require.Nil(t, client.Pool(nil, nil))
+}
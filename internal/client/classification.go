@@ -0,0 +1,78 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client/option"
+)
+
+// ClassificationPrompt returns the system prompt of the task, which lists the
+// labels and the examples, and asks for a JSON object of ClassificationResults
+// of the texts wrapped by WrapText.
+func ClassificationPrompt(task *option.ClassificationOptions) string {
+	sb := strings.Builder{}
+	sb.WriteString("Your task is to classify a set of statements. Each statement will be enclosed with the symbols [^] and [$]. ")
+	if task.Question != "" {
+		sb.WriteString(task.Question)
+		sb.WriteString(" ")
+	}
+	sb.WriteString("Give each statement exactly one of the following labels:\n")
+	for _, l := range task.Labels {
+		if l.Description != "" {
+			fmt.Fprintf(&sb, "- %s: %s\n", l.Name, l.Description)
+		} else {
+			fmt.Fprintf(&sb, "- %s\n", l.Name)
+		}
+	}
+
+	if len(task.Examples) > 0 {
+		sb.WriteString("For instance:\n")
+		for _, e := range task.Examples {
+			fmt.Fprintf(&sb, "[^]%s[$] is labeled %s\n", e.Text, e.Label)
+		}
+	}
+
+	label, _ := json.Marshal(task.Labels[0].Name)
+	fmt.Fprintf(&sb, "Please present your responses in a JSON object, of which results lists an object "+
+		"for each statement in order: id is the position of the statement starting from 1, label is the "+
+		"label exactly as listed, confidence is how confident you are in the label from 0 to 1, and "+
+		"rationale explains the label in one short sentence in the language of the statement. Respond "+
+		"without repeating the provided sentences, for example "+
+		`{"results": [{"id": 1, "label": %s, "confidence": 0.8, "rationale": "..."}]}.`, label)
+	return sb.String()
+}
+
+// ClassificationResult is the label of a text in the response of a provider.
+type ClassificationResult struct {
+	Id         int      `json:"id"`
+	Label      string   `json:"label"`
+	Confidence *float32 `json:"confidence"`
+	Rationale  string   `json:"rationale"`
+}
+
+// ToAnnotations matches the results to n texts. The results of which the id is
+// out of range or the label is not one of the task are dropped, and only the
+// first one of a text is kept.
+func ToAnnotations(task *option.ClassificationOptions, n int, results []ClassificationResult) []Annotation {
+	labels := map[string]bool{}
+	for _, l := range task.Labels {
+		labels[l.Name] = true
+	}
+
+	annotations := make([]Annotation, n)
+	for _, r := range results {
+		r.Label = strings.TrimSpace(r.Label)
+		if r.Id < 1 || r.Id > n || !labels[r.Label] || annotations[r.Id-1].Label != "" {
+			continue
+		}
+
+		a := Annotation{Label: r.Label, Rationale: r.Rationale}
+		if c := r.Confidence; c != nil && *c >= 0 && *c <= 1 {
+			a.Confidence = c
+		}
+		annotations[r.Id-1] = a
+	}
+	return annotations
+}
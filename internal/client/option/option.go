@@ -0,0 +1,174 @@
+// Package option holds the options a job is analyzed with, which are shared by
+// the services storing the jobs and the clients of the analyzers.
+package option
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type AnalyzerOption struct {
+	IsTestData               bool   `                  json:"is_test_data,omitempty" redis:"is_test_data"`
+	APIName                  string `form:"api"        json:"api"                    redis:"api"`
+	APIId                    int    `form:"llm-api-id" json:"id,omitempty"           redis:"id"`
+	EmbeddingOptions         `json:"embedding-options,omitempty"                     redis:"embedding-options"`
+	SentimentAnalysisOptions `json:"sentiment-analysis-options,omitempty"            redis:"sentiment-analysis-options"`
+	ClassificationOptions    `json:"classification-options,omitempty"                redis:"classification-options"`
+	// the responses are requested from the provider even if they are cached,
+	// and the news analyzed by other jobs are analyzed again
+	NoCache bool `form:"no-cache" json:"no_cache,omitempty" redis:"no_cache"`
+}
+
+func (opt AnalyzerOption) String() string {
+	return opt.ToString("", "    ")
+}
+
+func (opt AnalyzerOption) ToString(prefix, indent string) string {
+	b, _ := json.MarshalIndent(opt, prefix, indent)
+	return string(b)
+}
+
+// the ways the vectors of the chunks of a news are pooled into its vector
+const (
+	// the mean of the chunks
+	PoolingMean = "mean"
+	// the mean of the chunks weighted by their tokens
+	PoolingLength = "length"
+)
+
+type EmbeddingOptions struct {
+	Embedding      bool   `form:"do-embedding"    json:"embedding"             redis:"embedding"`
+	InputType      string `form:"input-type"      json:"input_type,omitempty" redis:"input_type"`
+	EmbeddingModel string `form:"embedding-model" json:"embedding_model"       redis:"embedding_model"`
+	// PoolingMean if empty
+	Pooling string `form:"pooling" json:"pooling,omitempty" redis:"pooling" validate:"omitempty,oneof=mean length"`
+}
+
+type SentimentAnalysisOptions struct {
+	Sentiment bool   `form:"do-sentiment" json:"sentiment"              redis:"sentiment"`
+	MaxTokens int    `form:"max-tokens"   json:"max_tokens,omitempty"  redis:"max_tokens"`
+	Truncate  string `form:"truncate"     json:"truncate,omitempty"    redis:"truncate"`
+	// the people, parties or organisations of which the sentiment toward is
+	// classified, set by ParseEntities
+	Entities []string `form:"-"           json:"entities,omitempty"    redis:"entities"`
+	// the version of the prompt of the owner used instead of the one of the
+	// analyzer, nil for the latter
+	Prompt *PromptVersion `form:"-"      json:"prompt,omitempty"      redis:"prompt"`
+	// the rendered Prompt, which is set by the runner and never stored
+	PromptText string `form:"-"         json:"-"                     redis:"-"`
+}
+
+// PromptVersion is a version of a prompt of a user, which never changes once
+// it is created.
+type PromptVersion struct {
+	ID      int64 `json:"id"`
+	Version int32 `json:"version"`
+}
+
+// SentimentPromptOr returns the rendered prompt of the owner, or dflt if there
+// is none.
+func (opt SentimentAnalysisOptions) SentimentPromptOr(dflt string) string {
+	if opt.PromptText == "" {
+		return dflt
+	}
+	return opt.PromptText
+}
+
+// ParseEntities splits s on commas, ideographic commas and new lines into
+// Entities, empty and repeated names are skipped.
+func (opt *SentimentAnalysisOptions) ParseEntities(s string) {
+	opt.Entities = []string{}
+	seen := map[string]bool{}
+	for _, e := range strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == '，' || r == '、' || r == '\n'
+	}) {
+		if e = strings.TrimSpace(e); e != "" && !seen[e] {
+			seen[e] = true
+			opt.Entities = append(opt.Entities, e)
+		}
+	}
+}
+
+// ClassificationOptions is a custom classification task, such as the stance or
+// the frame of the news, which gives every article one of the labels. There is
+// no task if Task is empty. It is set by ParseClassification.
+type ClassificationOptions struct {
+	Task     string                  `form:"-" json:"task,omitempty"     redis:"task"     validate:"max=64"`
+	Question string                  `form:"-" json:"question,omitempty" redis:"question" validate:"max=1024"`
+	Labels   []ClassificationLabel   `form:"-" json:"labels,omitempty"   redis:"labels"   validate:"max=20,dive"`
+	Examples []ClassificationExample `form:"-" json:"examples,omitempty" redis:"examples" validate:"max=20,dive"`
+}
+
+type ClassificationLabel struct {
+	Name        string `json:"name"                  validate:"required,max=64"`
+	Description string `json:"description,omitempty" validate:"max=256"`
+}
+
+// ClassificationExample is a few-shot example of the task.
+type ClassificationExample struct {
+	Text  string `json:"text"  validate:"required,max=1024"`
+	Label string `json:"label" validate:"required,max=64"`
+}
+
+// ParseClassification sets the task from the fields of the analyzer form. Each
+// line of labels is a label and its optional description separated by a
+// colon, and each line of examples is a label and a text likewise. A task
+// needs at least 2 labels, and an example of an unknown label is an error.
+func (opt *ClassificationOptions) ParseClassification(task, question, labels, examples string) error {
+	*opt = ClassificationOptions{
+		Task:     strings.TrimSpace(task),
+		Question: strings.TrimSpace(question),
+		Labels:   []ClassificationLabel{},
+		Examples: []ClassificationExample{},
+	}
+
+	seen := map[string]bool{}
+	for _, line := range strings.Split(labels, "\n") {
+		name, desc := splitLabel(line)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		opt.Labels = append(opt.Labels, ClassificationLabel{Name: name, Description: desc})
+	}
+
+	for _, line := range strings.Split(examples, "\n") {
+		label, text := splitLabel(line)
+		if label == "" && text == "" {
+			continue
+		}
+		if !seen[label] || text == "" {
+			return fmt.Errorf("invalid example %q, it should be one of the labels and a text", strings.TrimSpace(line))
+		}
+		opt.Examples = append(opt.Examples, ClassificationExample{Text: text, Label: label})
+	}
+
+	switch {
+	case opt.Task == "" && len(opt.Labels) > 0:
+		return fmt.Errorf("the classification task needs a name")
+	case opt.Task != "" && len(opt.Labels) < 2:
+		return fmt.Errorf("the classification task needs at least 2 labels")
+	}
+	return nil
+}
+
+// Hash returns the hex encoded sha256 of the task, its question, labels and
+// examples, which tells the annotations of one definition of a task from those
+// of another with the same name.
+func (opt ClassificationOptions) Hash() string {
+	b, _ := json.Marshal(opt)
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// splitLabel splits a line at the first colon, either half or full width.
+func splitLabel(line string) (string, string) {
+	label, rest := line, ""
+	if i := strings.IndexAny(line, ":："); i >= 0 {
+		label, rest = line[:i], strings.TrimLeft(line[i:], ":：")
+	}
+	return strings.TrimSpace(label), strings.TrimSpace(rest)
+}
@@ -0,0 +1,75 @@
+package option_test
+
+import (
+	"testing"
+
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client/option"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseClassification(t *testing.T) {
+	type testCase struct {
+		Name     string
+		Task     string
+		Labels   string
+		Examples string
+		Opt      option.ClassificationOptions
+		Err      bool
+	}
+
+	tcs := []testCase{
+		{
+			Name: "no task",
+			Opt: option.ClassificationOptions{
+				Labels:   []option.ClassificationLabel{},
+				Examples: []option.ClassificationExample{},
+			},
+		},
+		{
+			Name:     "stance",
+			Task:     " stance ",
+			Labels:   "support: 支持核電\nsupport\noppose：反對核電\n\nneutral",
+			Examples: "oppose: 核廢料無處可去",
+			Opt: option.ClassificationOptions{
+				Task: "stance",
+				Labels: []option.ClassificationLabel{
+					{Name: "support", Description: "支持核電"},
+					{Name: "oppose", Description: "反對核電"},
+					{Name: "neutral"},
+				},
+				Examples: []option.ClassificationExample{
+					{Text: "核廢料無處可去", Label: "oppose"},
+				},
+			},
+		},
+		{Name: "no name", Labels: "support\noppose", Err: true},
+		{Name: "one label", Task: "stance", Labels: "support", Err: true},
+		{Name: "unknown example label", Task: "stance", Labels: "support\noppose", Examples: "neutral: 天氣晴", Err: true},
+		{Name: "example without text", Task: "stance", Labels: "support\noppose", Examples: "support", Err: true},
+	}
+
+	for i := range tcs {
+		tc := tcs[i]
+		t.Run(tc.Name, func(t *testing.T) {
+			var opt option.ClassificationOptions
+			err := opt.ParseClassification(tc.Task, "", tc.Labels, tc.Examples)
+			if tc.Err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.Opt, opt)
+		})
+	}
+}
+
+func TestClassificationOptionsHash(t *testing.T) {
+	var stance, other option.ClassificationOptions
+	require.NoError(t, stance.ParseClassification("stance", "", "support\noppose", ""))
+	require.NoError(t, other.ParseClassification("stance", "", "support\noppose\nneutral", ""))
+
+	h := stance.Hash()
+	require.Len(t, h, 64)
+	require.Equal(t, h, stance.Hash())
+	require.NotEqual(t, h, other.Hash())
+}
@@ -0,0 +1,93 @@
+package client
+
+import (
+	"context"
+	"sync"
+)
+
+// the operations of which the usage is recorded
+const (
+	OperationEmbedding       = "embedding"
+	OperationSentiment       = "sentiment"
+	OperationEntitySentiment = "entity_sentiment"
+	OperationClassification  = "classification"
+)
+
+// Usage is the tokens billed for a call to a provider.
+type Usage struct {
+	Operation    string
+	Model        string
+	InputTokens  int
+	OutputTokens int
+}
+
+// Meter collects the usage of the calls made with the contexts from
+// WithMeter. It is safe for concurrent use.
+type Meter struct {
+	mu     sync.Mutex
+	usages []Usage
+}
+
+type meterKey struct{}
+
+// WithMeter returns a copy of ctx in which the analyzers record the usage of
+// their calls to m.
+func WithMeter(ctx context.Context, m *Meter) context.Context {
+	return context.WithValue(ctx, meterKey{}, m)
+}
+
+// RecordUsage records u to the meter of ctx, it does nothing if there is no
+// meter.
+func RecordUsage(ctx context.Context, u Usage) {
+	m, ok := ctx.Value(meterKey{}).(*Meter)
+	if !ok || m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.usages = append(m.usages, u)
+}
+
+// Take returns the usages recorded since the last Take, in order.
+func (m *Meter) Take() []Usage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	usages := m.usages
+	m.usages = nil
+	return usages
+}
+
+// the output tokens assumed for the result of an article, which is a score, a
+// confidence and a short rationale
+const estimatedOutputTokens = 64
+
+// EstimateUsage returns a rough estimate of the usage of analyzing texts with
+// the Analyzer of info and the embedding model, with the texts packed into
+// requests as b does. There is no sentiment usage if the Analyzer sends no
+// prompt.
+func (b Batcher) EstimateUsage(info ModelInfo, embeddingModel string, texts ...string) []Usage {
+	articles := make([]Article, len(texts))
+	embd := Usage{Operation: OperationEmbedding, Model: embeddingModel}
+	for i, text := range texts {
+		articles[i] = Article{Text: text}
+		embd.InputTokens += EstimateTokens(text)
+	}
+
+	if info.ChatModel == "" {
+		return []Usage{embd}
+	}
+
+	chat := Usage{
+		Operation:    OperationSentiment,
+		Model:        info.ChatModel,
+		OutputTokens: len(texts) * estimatedOutputTokens,
+	}
+	prompt := EstimateTokens(info.SentimentPrompt)
+	for _, batch := range b.Pack(articles...) {
+		chat.InputTokens += prompt
+		for _, a := range batch {
+			chat.InputTokens += EstimateTokens(a.Text) + wrapTokens
+		}
+	}
+	return []Usage{chat, embd}
+}
@@ -0,0 +1,47 @@
+package client_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMeter(t *testing.T) {
+	// nothing is recorded without a meter
+	client.RecordUsage(context.Background(), client.Usage{Model: "gpt-4o-mini", InputTokens: 10})
+
+	meter := &client.Meter{}
+	ctx := client.WithMeter(context.Background(), meter)
+	client.RecordUsage(ctx, client.Usage{Operation: client.OperationSentiment, Model: "gpt-4o-mini", InputTokens: 10, OutputTokens: 2})
+	client.RecordUsage(ctx, client.Usage{Operation: client.OperationEmbedding, Model: "text-embedding-ada-002", InputTokens: 5})
+	require.Equal(t, []client.Usage{
+		{Operation: client.OperationSentiment, Model: "gpt-4o-mini", InputTokens: 10, OutputTokens: 2},
+		{Operation: client.OperationEmbedding, Model: "text-embedding-ada-002", InputTokens: 5},
+	}, meter.Take())
+	require.Empty(t, meter.Take())
+}
+
+func TestEstimateUsage(t *testing.T) {
+	info := client.ModelInfo{SentimentPrompt: "Score the statements.", ChatModel: "gpt-4o-mini"}
+	texts := []string{"a short news", "another short news", "更長一點的新聞"}
+
+	// 2 batches, the prompt is sent with each of them
+	b := client.Batcher{Budget: 16}
+	usages := b.EstimateUsage(info, "text-embedding-ada-002", texts...)
+	require.Len(t, usages, 2)
+	require.Equal(t, client.OperationSentiment, usages[0].Operation)
+	require.Equal(t, "gpt-4o-mini", usages[0].Model)
+	require.Equal(t, 2*5+(3+4+7)+3*4, usages[0].InputTokens)
+	require.Equal(t, 3*64, usages[0].OutputTokens)
+	require.Equal(t, client.Usage{
+		Operation: client.OperationEmbedding, Model: "text-embedding-ada-002", InputTokens: 3 + 4 + 7,
+	}, usages[1])
+
+	// an analyzer sending no prompt only embeds the texts
+	usages = b.EstimateUsage(client.ModelInfo{}, "char-ngram-256", texts...)
+	require.Equal(t, []client.Usage{
+		{Operation: client.OperationEmbedding, Model: "char-ngram-256", InputTokens: 3 + 4 + 7},
+	}, usages)
+}
@@ -9,6 +9,7 @@ import (
 	"context"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const cleanUpAPIKey = `-- name: CleanUpAPIKey :execrows
@@ -26,21 +27,31 @@ func (q *Queries) CleanUpAPIKey(ctx context.Context) (int64, error) {
 
 const createAPIKey = `-- name: CreateAPIKey :one
 INSERT INTO apikeys (
-    owner, api_id, key
+    owner, api_id, key, base_url, models, auth_header
 ) VALUES (
-    $1, $2, $3
+    $1, $2, $3, $4, $5, $6
 )
 RETURNING id
 `
 
 type CreateAPIKeyParams struct {
-	Owner uuid.UUID `json:"owner"`
-	ApiID int16     `json:"api_id"`
-	Key   string    `json:"key"`
+	Owner      uuid.UUID   `json:"owner"`
+	ApiID      int16       `json:"api_id"`
+	Key        string      `json:"key"`
+	BaseUrl    pgtype.Text `json:"base_url"`
+	Models     []string    `json:"models"`
+	AuthHeader pgtype.Text `json:"auth_header"`
 }
 
 func (q *Queries) CreateAPIKey(ctx context.Context, arg *CreateAPIKeyParams) (int32, error) {
-	row := q.db.QueryRow(ctx, createAPIKey, arg.Owner, arg.ApiID, arg.Key)
+	row := q.db.QueryRow(ctx, createAPIKey,
+		arg.Owner,
+		arg.ApiID,
+		arg.Key,
+		arg.BaseUrl,
+		arg.Models,
+		arg.AuthHeader,
+	)
 	var id int32
 	err := row.Scan(&id)
 	return id, err
@@ -67,7 +78,7 @@ func (q *Queries) DeleteAPIKey(ctx context.Context, arg *DeleteAPIKeyParams) (in
 }
 
 const getAPIKey = `-- name: GetAPIKey :one
-SELECT id, owner, api_id, key 
+SELECT id, owner, api_id, key, base_url, models, auth_header
   FROM apikeys
  WHERE owner = $1 
    AND api_id = $2
@@ -80,10 +91,13 @@ type GetAPIKeyParams struct {
 }
 
 type GetAPIKeyRow struct {
-	ID    int32     `json:"id"`
-	Owner uuid.UUID `json:"owner"`
-	ApiID int16     `json:"api_id"`
-	Key   string    `json:"key"`
+	ID         int32       `json:"id"`
+	Owner      uuid.UUID   `json:"owner"`
+	ApiID      int16       `json:"api_id"`
+	Key        string      `json:"key"`
+	BaseUrl    pgtype.Text `json:"base_url"`
+	Models     []string    `json:"models"`
+	AuthHeader pgtype.Text `json:"auth_header"`
 }
 
 func (q *Queries) GetAPIKey(ctx context.Context, arg *GetAPIKeyParams) (*GetAPIKeyRow, error) {
@@ -94,19 +108,23 @@ func (q *Queries) GetAPIKey(ctx context.Context, arg *GetAPIKeyParams) (*GetAPIK
 		&i.Owner,
 		&i.ApiID,
 		&i.Key,
+		&i.BaseUrl,
+		&i.Models,
+		&i.AuthHeader,
 	)
 	return &i, err
 }
 
 const listAPIKey = `-- name: ListAPIKey :many
 WITH k AS (
-  SELECT id, owner, api_id, key
+  SELECT id, owner, api_id, key, base_url, models, auth_header
     FROM apikeys
    WHERE owner = $1
      AND deleted_at IS NULL
 )
 SELECT k.id AS api_key_id, k.owner, k.key, 
-       a.id AS api_id, a.type, a.name, a.image, a.icon
+       a.id AS api_id, a.type, a.name, a.image, a.icon,
+       k.base_url, k.models, k.auth_header
   FROM apis AS a
  INNER JOIN k
     ON a.id = k.api_id
@@ -114,14 +132,17 @@ SELECT k.id AS api_key_id, k.owner, k.key,
 `
 
 type ListAPIKeyRow struct {
-	ApiKeyID int32     `json:"api_key_id"`
-	Owner    uuid.UUID `json:"owner"`
-	Key      string    `json:"key"`
-	ApiID    int16     `json:"api_id"`
-	Type     ApiType   `json:"type"`
-	Name     string    `json:"name"`
-	Image    string    `json:"image"`
-	Icon     string    `json:"icon"`
+	ApiKeyID   int32       `json:"api_key_id"`
+	Owner      uuid.UUID   `json:"owner"`
+	Key        string      `json:"key"`
+	ApiID      int16       `json:"api_id"`
+	Type       ApiType     `json:"type"`
+	Name       string      `json:"name"`
+	Image      string      `json:"image"`
+	Icon       string      `json:"icon"`
+	BaseUrl    pgtype.Text `json:"base_url"`
+	Models     []string    `json:"models"`
+	AuthHeader pgtype.Text `json:"auth_header"`
 }
 
 func (q *Queries) ListAPIKey(ctx context.Context, owner uuid.UUID) ([]*ListAPIKeyRow, error) {
@@ -142,6 +163,9 @@ func (q *Queries) ListAPIKey(ctx context.Context, owner uuid.UUID) ([]*ListAPIKe
 			&i.Name,
 			&i.Image,
 			&i.Icon,
+			&i.BaseUrl,
+			&i.Models,
+			&i.AuthHeader,
 		); err != nil {
 			return nil, err
 		}
@@ -157,17 +181,23 @@ const updateAPIKey = `-- name: UpdateAPIKey :execrows
 UPDATE apikeys
    SET key = $1,
        api_id = $3,
+       base_url = $4,
+       models = $5,
+       auth_header = $6,
        updated_at = CURRENT_TIMESTAMP
  WHERE owner = $2
-   AND api_id = $4
+   AND api_id = $7
    AND deleted_at IS NULL
 `
 
 type UpdateAPIKeyParams struct {
-	Key      string    `json:"key"`
-	Owner    uuid.UUID `json:"owner"`
-	OldApiID int16     `json:"old_api_id"`
-	NewApiID int16     `json:"new_api_id"`
+	Key        string      `json:"key"`
+	Owner      uuid.UUID   `json:"owner"`
+	OldApiID   int16       `json:"old_api_id"`
+	BaseUrl    pgtype.Text `json:"base_url"`
+	Models     []string    `json:"models"`
+	AuthHeader pgtype.Text `json:"auth_header"`
+	NewApiID   int16       `json:"new_api_id"`
 }
 
 func (q *Queries) UpdateAPIKey(ctx context.Context, arg *UpdateAPIKeyParams) (int64, error) {
@@ -175,6 +205,9 @@ func (q *Queries) UpdateAPIKey(ctx context.Context, arg *UpdateAPIKeyParams) (in
 		arg.Key,
 		arg.Owner,
 		arg.OldApiID,
+		arg.BaseUrl,
+		arg.Models,
+		arg.AuthHeader,
 		arg.NewApiID,
 	)
 	if err != nil {
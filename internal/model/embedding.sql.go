@@ -8,9 +8,112 @@ package model
 import (
 	"context"
 
+	"github.com/jackc/pgx/v5/pgtype"
 	pgv "github.com/pgvector/pgvector-go"
 )
 
+const compareJobSentiments = `-- name: CompareJobSentiments :many
+SELECT
+    n.id AS news_id,
+    n.title,
+    n.link,
+    ea.sentiment AS sentiment_a,
+    eb.sentiment AS sentiment_b
+FROM newsjobs AS nja
+    INNER JOIN newsjobs AS njb ON nja.news_id = njb.news_id
+    INNER JOIN news AS n ON nja.news_id = n.id
+    LEFT JOIN job_items AS jia ON jia.job_id = nja.job_id AND jia.news_id = n.id
+    LEFT JOIN LATERAL (
+        SELECT sentiment
+        FROM embeddings AS e
+        WHERE
+            e.news_id = n.id
+            AND e.model = $1
+            AND e.deleted_at IS NULL
+            -- the embedding the job got, or the latest one for the news done
+            -- before the job items recorded it
+            AND (
+                e.id = jia.embedding_id
+                OR (
+                    jia.embedding_id IS NULL
+                    AND (jia.id IS NULL OR jia.stage = 'done')
+                )
+            )
+        ORDER BY e.id DESC
+        LIMIT 1
+    ) AS ea ON true
+    LEFT JOIN job_items AS jib ON jib.job_id = njb.job_id AND jib.news_id = n.id
+    LEFT JOIN LATERAL (
+        SELECT sentiment
+        FROM embeddings AS e
+        WHERE
+            e.news_id = n.id
+            AND e.model = $2
+            AND e.deleted_at IS NULL
+            -- the embedding the job got, or the latest one for the news done
+            -- before the job items recorded it
+            AND (
+                e.id = jib.embedding_id
+                OR (
+                    jib.embedding_id IS NULL
+                    AND (jib.id IS NULL OR jib.stage = 'done')
+                )
+            )
+        ORDER BY e.id DESC
+        LIMIT 1
+    ) AS eb ON true
+WHERE
+    nja.job_id = $3
+    AND njb.job_id = $4
+ORDER BY n.id
+`
+
+type CompareJobSentimentsParams struct {
+	ModelA string `json:"model_a"`
+	ModelB string `json:"model_b"`
+	JobA   int64  `json:"job_a"`
+	JobB   int64  `json:"job_b"`
+}
+
+type CompareJobSentimentsRow struct {
+	NewsID     int64         `json:"news_id"`
+	Title      string        `json:"title"`
+	Link       string        `json:"link"`
+	SentimentA NullSentiment `json:"sentiment_a"`
+	SentimentB NullSentiment `json:"sentiment_b"`
+}
+
+func (q *Queries) CompareJobSentiments(ctx context.Context, arg *CompareJobSentimentsParams) ([]*CompareJobSentimentsRow, error) {
+	rows, err := q.db.Query(ctx, compareJobSentiments,
+		arg.ModelA,
+		arg.ModelB,
+		arg.JobA,
+		arg.JobB,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*CompareJobSentimentsRow
+	for rows.Next() {
+		var i CompareJobSentimentsRow
+		if err := rows.Scan(
+			&i.NewsID,
+			&i.Title,
+			&i.Link,
+			&i.SentimentA,
+			&i.SentimentB,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const createEmbedding = `-- name: CreateEmbedding :one
 INSERT INTO
     embeddings (
@@ -18,6 +121,10 @@ INSERT INTO
         model,
         embedding,
         sentiment,
+        score,
+        confidence,
+        rationale,
+        prompt_id,
         created_at,
         updated_at
     )
@@ -26,16 +133,24 @@ VALUES (
         $2,
         $3,
         $4,
+        $5,
+        $6,
+        $7,
+        $8,
         CURRENT_TIMESTAMP,
         CURRENT_TIMESTAMP
     ) RETURNING id
 `
 
 type CreateEmbeddingParams struct {
-	NewsID    int64      `json:"news_id"`
-	Model     string     `json:"model"`
-	Embedding pgv.Vector `json:"embedding"`
-	Sentiment Sentiment  `json:"sentiment"`
+	NewsID     int64         `json:"news_id"`
+	Model      string        `json:"model"`
+	Embedding  pgv.Vector    `json:"embedding"`
+	Sentiment  Sentiment     `json:"sentiment"`
+	Score      pgtype.Int2   `json:"score"`
+	Confidence pgtype.Float4 `json:"confidence"`
+	Rationale  pgtype.Text   `json:"rationale"`
+	PromptID   pgtype.Int8   `json:"prompt_id"`
 }
 
 func (q *Queries) CreateEmbedding(ctx context.Context, arg *CreateEmbeddingParams) (int64, error) {
@@ -44,6 +159,10 @@ func (q *Queries) CreateEmbedding(ctx context.Context, arg *CreateEmbeddingParam
 		arg.Model,
 		arg.Embedding,
 		arg.Sentiment,
+		arg.Score,
+		arg.Confidence,
+		arg.Rationale,
+		arg.PromptID,
 	)
 	var id int64
 	err := row.Scan(&id)
@@ -57,25 +176,83 @@ SELECT
     e.news_id,
     e.model,
     e.embedding,
-    e.sentiment
-FROM embeddings AS e
-    INNER JOIN newsjobs AS nj ON e.news_id = nj.news_id
+    e.sentiment,
+    COALESCE(e.score, sentiment_score(e.sentiment)):: smallint AS score,
+    e.confidence,
+    e.rationale,
+    n.title,
+    n.link,
+    n.source,
+    n.publish_at,
+    n.language
+FROM newsjobs AS nj
+    INNER JOIN news AS n ON nj.news_id = n.id
+    LEFT JOIN job_items AS ji ON ji.job_id = nj.job_id AND ji.news_id = n.id
+    INNER JOIN LATERAL (
+        SELECT
+            id,
+            news_id,
+            model,
+            embedding,
+            sentiment,
+            score,
+            confidence,
+            rationale
+        FROM embeddings
+        WHERE
+            news_id = n.id
+            AND model = $2
+            AND deleted_at IS NULL
+            -- the embedding the job got, or the latest one for the news done
+            -- before the job items recorded it
+            AND (
+                id = ji.embedding_id
+                OR (
+                    ji.embedding_id IS NULL
+                    AND (ji.id IS NULL OR ji.stage = 'done')
+                )
+            )
+        ORDER BY id DESC
+        LIMIT 1
+    ) AS e ON true
 WHERE
     nj.job_id = $1
-    AND e.deleted_at IS NULL
+    AND e.id > $3:: bigint
+ORDER BY e.id
+LIMIT $4:: int
 `
 
+type GetEmbeddingByJobIdParams struct {
+	JobID int64  `json:"job_id"`
+	Model string `json:"model"`
+	Next  int64  `json:"next"`
+	N     int32  `json:"n"`
+}
+
 type GetEmbeddingByJobIdRow struct {
-	ID        int64      `json:"id"`
-	JobID     int64      `json:"job_id"`
-	NewsID    int64      `json:"news_id"`
-	Model     string     `json:"model"`
-	Embedding pgv.Vector `json:"embedding"`
-	Sentiment Sentiment  `json:"sentiment"`
+	ID         int64              `json:"id"`
+	JobID      int64              `json:"job_id"`
+	NewsID     int64              `json:"news_id"`
+	Model      string             `json:"model"`
+	Embedding  pgv.Vector         `json:"embedding"`
+	Sentiment  Sentiment          `json:"sentiment"`
+	Score      int16              `json:"score"`
+	Confidence pgtype.Float4      `json:"confidence"`
+	Rationale  pgtype.Text        `json:"rationale"`
+	Title      string             `json:"title"`
+	Link       string             `json:"link"`
+	Source     string             `json:"source"`
+	PublishAt  pgtype.Timestamptz `json:"publish_at"`
+	Language   pgtype.Text        `json:"language"`
 }
 
-func (q *Queries) GetEmbeddingByJobId(ctx context.Context, jobID int64) ([]*GetEmbeddingByJobIdRow, error) {
-	rows, err := q.db.Query(ctx, getEmbeddingByJobId, jobID)
+func (q *Queries) GetEmbeddingByJobId(ctx context.Context, arg *GetEmbeddingByJobIdParams) ([]*GetEmbeddingByJobIdRow, error) {
+	rows, err := q.db.Query(ctx, getEmbeddingByJobId,
+		arg.JobID,
+		arg.Model,
+		arg.Next,
+		arg.N,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -90,6 +267,14 @@ func (q *Queries) GetEmbeddingByJobId(ctx context.Context, jobID int64) ([]*GetE
 			&i.Model,
 			&i.Embedding,
 			&i.Sentiment,
+			&i.Score,
+			&i.Confidence,
+			&i.Rationale,
+			&i.Title,
+			&i.Link,
+			&i.Source,
+			&i.PublishAt,
+			&i.Language,
 		); err != nil {
 			return nil, err
 		}
@@ -112,12 +297,14 @@ FROM embeddings
 WHERE
     news_id = ANY($2:: int [])
     AND model = $1
+    AND prompt_id IS NOT DISTINCT FROM $3
     AND deleted_at IS NULL
 `
 
 type GetEmbeddingByNewsIdsAndModelParams struct {
-	Model   string  `json:"model"`
-	NewsIds []int32 `json:"news_ids"`
+	Model    string      `json:"model"`
+	NewsIds  []int32     `json:"news_ids"`
+	PromptID pgtype.Int8 `json:"prompt_id"`
 }
 
 type GetEmbeddingByNewsIdsAndModelRow struct {
@@ -129,7 +316,7 @@ type GetEmbeddingByNewsIdsAndModelRow struct {
 }
 
 func (q *Queries) GetEmbeddingByNewsIdsAndModel(ctx context.Context, arg *GetEmbeddingByNewsIdsAndModelParams) ([]*GetEmbeddingByNewsIdsAndModelRow, error) {
-	rows, err := q.db.Query(ctx, getEmbeddingByNewsIdsAndModel, arg.Model, arg.NewsIds)
+	rows, err := q.db.Query(ctx, getEmbeddingByNewsIdsAndModel, arg.Model, arg.NewsIds, arg.PromptID)
 	if err != nil {
 		return nil, err
 	}
@@ -153,3 +340,175 @@ func (q *Queries) GetEmbeddingByNewsIdsAndModel(ctx context.Context, arg *GetEmb
 	}
 	return items, nil
 }
+
+const getJobSentimentStats = `-- name: GetJobSentimentStats :many
+WITH analyzed AS (
+        SELECT
+            DISTINCT ON (n.id) n.id,
+            n.source,
+            n.category,
+            (n.publish_at AT TIME ZONE 'UTC'):: date AS publish_day,
+            COALESCE(n.language, '') AS language,
+            e.sentiment,
+            COALESCE(e.score, sentiment_score(e.sentiment)) AS score
+        FROM newsjobs AS nj
+            INNER JOIN news AS n ON nj.news_id = n.id
+            LEFT JOIN job_items AS ji ON ji.job_id = nj.job_id AND ji.news_id = n.id
+            INNER JOIN embeddings AS e ON e.news_id = n.id
+        WHERE
+            nj.job_id = $1:: bigint
+            AND e.model = $2:: text
+            AND e.deleted_at IS NULL
+            -- the embedding the job got, or the latest one for the news done
+            -- before the job items recorded it
+            AND (
+                e.id = ji.embedding_id
+                OR (
+                    ji.embedding_id IS NULL
+                    AND (ji.id IS NULL OR ji.stage = 'done')
+                )
+            )
+        ORDER BY n.id, e.id DESC
+    )
+SELECT (
+        CASE
+            WHEN GROUPING(a.source) = 0 THEN 'source'
+            WHEN GROUPING(a.category) = 0 THEN 'category'
+            WHEN GROUPING(a.publish_day) = 0 THEN 'publish_day'
+            WHEN GROUPING(a.language) = 0 THEN 'language'
+            ELSE 'overall'
+        END
+    ):: text AS dimension, (
+        CASE
+            WHEN GROUPING(a.source) = 0 THEN a.source
+            WHEN GROUPING(a.category) = 0 THEN a.category
+            WHEN GROUPING(a.publish_day) = 0 THEN a.publish_day:: text
+            WHEN GROUPING(a.language) = 0 THEN a.language
+            ELSE ''
+        END
+    ):: text AS bucket,
+    COUNT(*) AS n_news,
+    COUNT(*) FILTER (
+        WHERE
+            a.sentiment = 'very_positive'
+    ) AS n_very_positive,
+    COUNT(*) FILTER (
+        WHERE
+            a.sentiment = 'positive'
+    ) AS n_positive,
+    COUNT(*) FILTER (
+        WHERE
+            a.sentiment = 'neutral'
+    ) AS n_neutral,
+    COUNT(*) FILTER (
+        WHERE
+            a.sentiment = 'negative'
+    ) AS n_negative,
+    COUNT(*) FILTER (
+        WHERE
+            a.sentiment = 'very_negative'
+    ) AS n_very_negative,
+    COALESCE(
+        COUNT(*) FILTER (
+            WHERE
+                a.sentiment = 'very_positive'
+        ):: float8 / NULLIF(COUNT(*), 0),
+        0
+    ):: float8 AS very_positive_ratio,
+    COALESCE(
+        COUNT(*) FILTER (
+            WHERE
+                a.sentiment = 'positive'
+        ):: float8 / NULLIF(COUNT(*), 0),
+        0
+    ):: float8 AS positive_ratio,
+    COALESCE(
+        COUNT(*) FILTER (
+            WHERE
+                a.sentiment = 'neutral'
+        ):: float8 / NULLIF(COUNT(*), 0),
+        0
+    ):: float8 AS neutral_ratio,
+    COALESCE(
+        COUNT(*) FILTER (
+            WHERE
+                a.sentiment = 'negative'
+        ):: float8 / NULLIF(COUNT(*), 0),
+        0
+    ):: float8 AS negative_ratio,
+    COALESCE(
+        COUNT(*) FILTER (
+            WHERE
+                a.sentiment = 'very_negative'
+        ):: float8 / NULLIF(COUNT(*), 0),
+        0
+    ):: float8 AS very_negative_ratio,
+    COALESCE(AVG(a.score), 0):: float8 AS mean_score
+FROM analyzed AS a
+GROUP BY
+    GROUPING SETS (
+        (),
+        (a.source),
+        (a.category),
+        (a.publish_day),
+        (a.language)
+    )
+ORDER BY dimension, bucket
+`
+
+type GetJobSentimentStatsParams struct {
+	JobID int64  `json:"job_id"`
+	Model string `json:"model"`
+}
+
+type GetJobSentimentStatsRow struct {
+	Dimension         string  `json:"dimension"`
+	Bucket            string  `json:"bucket"`
+	NNews             int64   `json:"n_news"`
+	NVeryPositive     int64   `json:"n_very_positive"`
+	NPositive         int64   `json:"n_positive"`
+	NNeutral          int64   `json:"n_neutral"`
+	NNegative         int64   `json:"n_negative"`
+	NVeryNegative     int64   `json:"n_very_negative"`
+	VeryPositiveRatio float64 `json:"very_positive_ratio"`
+	PositiveRatio     float64 `json:"positive_ratio"`
+	NeutralRatio      float64 `json:"neutral_ratio"`
+	NegativeRatio     float64 `json:"negative_ratio"`
+	VeryNegativeRatio float64 `json:"very_negative_ratio"`
+	MeanScore         float64 `json:"mean_score"`
+}
+
+func (q *Queries) GetJobSentimentStats(ctx context.Context, arg *GetJobSentimentStatsParams) ([]*GetJobSentimentStatsRow, error) {
+	rows, err := q.db.Query(ctx, getJobSentimentStats, arg.JobID, arg.Model)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*GetJobSentimentStatsRow
+	for rows.Next() {
+		var i GetJobSentimentStatsRow
+		if err := rows.Scan(
+			&i.Dimension,
+			&i.Bucket,
+			&i.NNews,
+			&i.NVeryPositive,
+			&i.NPositive,
+			&i.NNeutral,
+			&i.NNegative,
+			&i.NVeryNegative,
+			&i.VeryPositiveRatio,
+			&i.PositiveRatio,
+			&i.NeutralRatio,
+			&i.NegativeRatio,
+			&i.VeryNegativeRatio,
+			&i.MeanScore,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -25,6 +25,56 @@ func (q *Queries) CleanUpJobs(ctx context.Context) (int64, error) {
 	return result.RowsAffected(), nil
 }
 
+const cloneJob = `-- name: CloneJob :one
+
+INSERT INTO
+    jobs (
+        ulid,
+        owner,
+        status,
+        src_api_id,
+        src_query,
+        llm_api_id,
+        llm_query,
+        cloned_from
+    )
+SELECT
+    $1,
+    j.owner,
+    'created',
+    j.src_api_id,
+    j.src_query,
+    $2,
+    $3,
+    j.id
+FROM jobs AS j
+WHERE
+    j.owner = $4
+    AND j.id = $5
+    AND j.deleted_at IS NULL RETURNING id
+`
+
+type CloneJobParams struct {
+	Ulid     string    `json:"ulid"`
+	LlmApiID int16     `json:"llm_api_id"`
+	LlmQuery []byte    `json:"llm_query"`
+	Owner    uuid.UUID `json:"owner"`
+	ID       int64     `json:"id"`
+}
+
+func (q *Queries) CloneJob(ctx context.Context, arg *CloneJobParams) (int64, error) {
+	row := q.db.QueryRow(ctx, cloneJob,
+		arg.Ulid,
+		arg.LlmApiID,
+		arg.LlmQuery,
+		arg.Owner,
+		arg.ID,
+	)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
 const countJob = `-- name: CountJob :many
 
 SELECT status, COUNT(*) AS n_job
@@ -69,18 +119,20 @@ INSERT INTO
         src_api_id,
         src_query,
         llm_api_id,
-        llm_query
+        llm_query,
+        parent_id
     )
-VALUES ($1, $2, 'created', $3, $4, $5, $6) RETURNING id
+VALUES ($1, $2, 'created', $3, $4, $5, $6, $7) RETURNING id
 `
 
 type CreateJobParams struct {
-	Ulid     string    `json:"ulid"`
-	Owner    uuid.UUID `json:"owner"`
-	SrcApiID int16     `json:"src_api_id"`
-	SrcQuery string    `json:"src_query"`
-	LlmApiID int16     `json:"llm_api_id"`
-	LlmQuery []byte    `json:"llm_query"`
+	Ulid     string      `json:"ulid"`
+	Owner    uuid.UUID   `json:"owner"`
+	SrcApiID int16       `json:"src_api_id"`
+	SrcQuery string      `json:"src_query"`
+	LlmApiID int16       `json:"llm_api_id"`
+	LlmQuery []byte      `json:"llm_query"`
+	ParentID pgtype.Int8 `json:"parent_id"`
 }
 
 func (q *Queries) CreateJob(ctx context.Context, arg *CreateJobParams) (int64, error) {
@@ -91,6 +143,7 @@ func (q *Queries) CreateJob(ctx context.Context, arg *CreateJobParams) (int64, e
 		arg.SrcQuery,
 		arg.LlmApiID,
 		arg.LlmQuery,
+		arg.ParentID,
 	)
 	var id int64
 	err := row.Scan(&id)
@@ -330,6 +383,40 @@ func (q *Queries) GetJobByOwnerFilterByJIds(ctx context.Context, arg *GetJobByOw
 	return items, nil
 }
 
+const getJobQuotaUsage = `-- name: GetJobQuotaUsage :one
+
+SELECT
+    COUNT(*) FILTER (
+        WHERE
+            status IN ('created', 'running')
+            AND deleted_at IS NULL
+    ) AS n_running,
+    COUNT(*) FILTER (
+        WHERE
+            created_at >= $1
+            AND parent_id IS NULL
+    ) AS n_today
+FROM jobs
+WHERE owner = $2
+`
+
+type GetJobQuotaUsageParams struct {
+	Since pgtype.Timestamptz `json:"since"`
+	Owner uuid.UUID          `json:"owner"`
+}
+
+type GetJobQuotaUsageRow struct {
+	NRunning int64 `json:"n_running"`
+	NToday   int64 `json:"n_today"`
+}
+
+func (q *Queries) GetJobQuotaUsage(ctx context.Context, arg *GetJobQuotaUsageParams) (*GetJobQuotaUsageRow, error) {
+	row := q.db.QueryRow(ctx, getJobQuotaUsage, arg.Since, arg.Owner)
+	var i GetJobQuotaUsageRow
+	err := row.Scan(&i.NRunning, &i.NToday)
+	return &i, err
+}
+
 const getJobsByJobId = `-- name: GetJobsByJobId :one
 
 SELECT
@@ -340,6 +427,9 @@ SELECT
     j.src_query,
     allm.name AS analyzer,
     j.llm_query,
+    j.partial,
+    j.parent_id,
+    j.cloned_from,
     j.created_at,
     j.updated_at
 FROM jobs AS j
@@ -357,15 +447,18 @@ type GetJobsByJobIdParams struct {
 }
 
 type GetJobsByJobIdRow struct {
-	ID        int64              `json:"id"`
-	Owner     uuid.UUID          `json:"owner"`
-	Status    JobStatus          `json:"status"`
-	NewsSrc   string             `json:"news_src"`
-	SrcQuery  string             `json:"src_query"`
-	Analyzer  string             `json:"analyzer"`
-	LlmQuery  []byte             `json:"llm_query"`
-	CreatedAt pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+	ID         int64              `json:"id"`
+	Owner      uuid.UUID          `json:"owner"`
+	Status     JobStatus          `json:"status"`
+	NewsSrc    string             `json:"news_src"`
+	SrcQuery   string             `json:"src_query"`
+	Analyzer   string             `json:"analyzer"`
+	LlmQuery   []byte             `json:"llm_query"`
+	Partial    bool               `json:"partial"`
+	ParentID   pgtype.Int8        `json:"parent_id"`
+	ClonedFrom pgtype.Int8        `json:"cloned_from"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt  pgtype.Timestamptz `json:"updated_at"`
 }
 
 func (q *Queries) GetJobsByJobId(ctx context.Context, arg *GetJobsByJobIdParams) (*GetJobsByJobIdRow, error) {
@@ -379,6 +472,9 @@ func (q *Queries) GetJobsByJobId(ctx context.Context, arg *GetJobsByJobIdParams)
 		&i.SrcQuery,
 		&i.Analyzer,
 		&i.LlmQuery,
+		&i.Partial,
+		&i.ParentID,
+		&i.ClonedFrom,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -570,10 +666,29 @@ WITH ranked_jobs AS (
                 ORDER BY
                     id ASC
             ) as rn
-        FROM jobs
+        FROM jobs AS j
         WHERE
             deleted_at IS NULL
             AND status = 'created'
+            -- a job put back while its items wait for their next attempt is
+            -- skipped until one of them is due
+            AND (
+                NOT EXISTS (
+                    SELECT 1
+                    FROM job_items AS ji
+                    WHERE
+                        ji.job_id = j.id
+                        AND ji.stage IN ('pending', 'retrying')
+                )
+                OR EXISTS (
+                    SELECT 1
+                    FROM job_items AS ji
+                    WHERE
+                        ji.job_id = j.id
+                        AND ji.stage IN ('pending', 'retrying')
+                        AND ji.next_attempt_at <= CURRENT_TIMESTAMP
+                )
+            )
     )
 SELECT
     id,
@@ -617,6 +732,31 @@ func (q *Queries) GetOldestNCreatedJobsForEachUser(ctx context.Context, n int32)
 	return items, nil
 }
 
+const markJobPartial = `-- name: MarkJobPartial :execrows
+
+UPDATE jobs
+SET
+    partial = true,
+    updated_at = CURRENT_TIMESTAMP
+WHERE
+    id = $1
+    AND owner = $2
+    AND deleted_at IS NULL
+`
+
+type MarkJobPartialParams struct {
+	ID    int64     `json:"id"`
+	Owner uuid.UUID `json:"owner"`
+}
+
+func (q *Queries) MarkJobPartial(ctx context.Context, arg *MarkJobPartialParams) (int64, error) {
+	result, err := q.db.Exec(ctx, markJobPartial, arg.ID, arg.Owner)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const updateJobByULID = `-- name: UpdateJobByULID :execrows
 
 UPDATE jobs
@@ -676,3 +816,36 @@ func (q *Queries) UpdateJobStatus(ctx context.Context, arg *UpdateJobStatusParam
 	}
 	return result.RowsAffected(), nil
 }
+
+const updateJobStatusFrom = `-- name: UpdateJobStatusFrom :execrows
+
+UPDATE jobs
+SET
+    status = $1,
+    updated_at = CURRENT_TIMESTAMP
+WHERE
+    id = $2
+    AND owner = $3
+    AND status:: text = ANY($4:: text [])
+    AND deleted_at IS NULL
+`
+
+type UpdateJobStatusFromParams struct {
+	ToStatus   JobStatus `json:"to_status"`
+	ID         int64     `json:"id"`
+	Owner      uuid.UUID `json:"owner"`
+	FromStatus []string  `json:"from_status"`
+}
+
+func (q *Queries) UpdateJobStatusFrom(ctx context.Context, arg *UpdateJobStatusFromParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateJobStatusFrom,
+		arg.ToStatus,
+		arg.ID,
+		arg.Owner,
+		arg.FromStatus,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
@@ -37,6 +37,51 @@ func (m *MockStore) EXPECT() *MockStoreMockRecorder {
 	return m.recorder
 }
 
+// AddStoryNews mocks base method.
+func (m *MockStore) AddStoryNews(arg0 context.Context, arg1 *model.AddStoryNewsParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddStoryNews", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddStoryNews indicates an expected call of AddStoryNews.
+func (mr *MockStoreMockRecorder) AddStoryNews(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddStoryNews", reflect.TypeOf((*MockStore)(nil).AddStoryNews), arg0, arg1)
+}
+
+// ClaimJobSchedule mocks base method.
+func (m *MockStore) ClaimJobSchedule(arg0 context.Context, arg1 *model.ClaimJobScheduleParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimJobSchedule", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimJobSchedule indicates an expected call of ClaimJobSchedule.
+func (mr *MockStoreMockRecorder) ClaimJobSchedule(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimJobSchedule", reflect.TypeOf((*MockStore)(nil).ClaimJobSchedule), arg0, arg1)
+}
+
+// ClaimWebhookDelivery mocks base method.
+func (m *MockStore) ClaimWebhookDelivery(arg0 context.Context, arg1 *model.ClaimWebhookDeliveryParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimWebhookDelivery", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimWebhookDelivery indicates an expected call of ClaimWebhookDelivery.
+func (mr *MockStoreMockRecorder) ClaimWebhookDelivery(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimWebhookDelivery", reflect.TypeOf((*MockStore)(nil).ClaimWebhookDelivery), arg0, arg1)
+}
+
 // CleanUpAPIKey mocks base method.
 func (m *MockStore) CleanUpAPIKey(arg0 context.Context) (int64, error) {
 	m.ctrl.T.Helper()
@@ -82,6 +127,21 @@ func (mr *MockStoreMockRecorder) CleanUpJobs(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CleanUpJobs", reflect.TypeOf((*MockStore)(nil).CleanUpJobs), arg0)
 }
 
+// CleanUpLLMResponses mocks base method.
+func (m *MockStore) CleanUpLLMResponses(arg0 context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CleanUpLLMResponses", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CleanUpLLMResponses indicates an expected call of CleanUpLLMResponses.
+func (mr *MockStoreMockRecorder) CleanUpLLMResponses(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CleanUpLLMResponses", reflect.TypeOf((*MockStore)(nil).CleanUpLLMResponses), arg0)
+}
+
 // CleanUpUsers mocks base method.
 func (m *MockStore) CleanUpUsers(arg0 context.Context) (int64, error) {
 	m.ctrl.T.Helper()
@@ -97,6 +157,36 @@ func (mr *MockStoreMockRecorder) CleanUpUsers(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CleanUpUsers", reflect.TypeOf((*MockStore)(nil).CleanUpUsers), arg0)
 }
 
+// CloneJob mocks base method.
+func (m *MockStore) CloneJob(arg0 context.Context, arg1 *model.CloneJobParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloneJob", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CloneJob indicates an expected call of CloneJob.
+func (mr *MockStoreMockRecorder) CloneJob(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloneJob", reflect.TypeOf((*MockStore)(nil).CloneJob), arg0, arg1)
+}
+
+// CloneNewsJobs mocks base method.
+func (m *MockStore) CloneNewsJobs(arg0 context.Context, arg1 *model.CloneNewsJobsParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloneNewsJobs", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CloneNewsJobs indicates an expected call of CloneNewsJobs.
+func (mr *MockStoreMockRecorder) CloneNewsJobs(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloneNewsJobs", reflect.TypeOf((*MockStore)(nil).CloneNewsJobs), arg0, arg1)
+}
+
 // Close mocks base method.
 func (m *MockStore) Close(arg0 context.Context) error {
 	m.ctrl.T.Helper()
@@ -111,6 +201,21 @@ func (mr *MockStoreMockRecorder) Close(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockStore)(nil).Close), arg0)
 }
 
+// CompareJobSentiments mocks base method.
+func (m *MockStore) CompareJobSentiments(arg0 context.Context, arg1 *model.CompareJobSentimentsParams) ([]*model.CompareJobSentimentsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompareJobSentiments", arg0, arg1)
+	ret0, _ := ret[0].([]*model.CompareJobSentimentsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CompareJobSentiments indicates an expected call of CompareJobSentiments.
+func (mr *MockStoreMockRecorder) CompareJobSentiments(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompareJobSentiments", reflect.TypeOf((*MockStore)(nil).CompareJobSentiments), arg0, arg1)
+}
+
 // CountEndpoint mocks base method.
 func (m *MockStore) CountEndpoint(arg0 context.Context) (int64, error) {
 	m.ctrl.T.Helper()
@@ -216,6 +321,64 @@ func (mr *MockStoreMockRecorder) CreateJob(arg0, arg1 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateJob", reflect.TypeOf((*MockStore)(nil).CreateJob), arg0, arg1)
 }
 
+// CreateJobItems mocks base method.
+func (m *MockStore) CreateJobItems(arg0 context.Context, arg1 int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateJobItems", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateJobItems indicates an expected call of CreateJobItems.
+func (mr *MockStoreMockRecorder) CreateJobItems(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateJobItems", reflect.TypeOf((*MockStore)(nil).CreateJobItems), arg0, arg1)
+}
+
+// CreateJobProgress mocks base method.
+func (m *MockStore) CreateJobProgress(arg0 context.Context, arg1 *model.CreateJobProgressParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateJobProgress", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateJobProgress indicates an expected call of CreateJobProgress.
+func (mr *MockStoreMockRecorder) CreateJobProgress(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateJobProgress", reflect.TypeOf((*MockStore)(nil).CreateJobProgress), arg0, arg1)
+}
+
+// CreateJobSchedule mocks base method.
+func (m *MockStore) CreateJobSchedule(arg0 context.Context, arg1 *model.CreateJobScheduleParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateJobSchedule", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateJobSchedule indicates an expected call of CreateJobSchedule.
+func (mr *MockStoreMockRecorder) CreateJobSchedule(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateJobSchedule", reflect.TypeOf((*MockStore)(nil).CreateJobSchedule), arg0, arg1)
+}
+
+// CreateJobUsage mocks base method.
+func (m *MockStore) CreateJobUsage(arg0 context.Context, arg1 *model.CreateJobUsageParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateJobUsage", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateJobUsage indicates an expected call of CreateJobUsage.
+func (mr *MockStoreMockRecorder) CreateJobUsage(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateJobUsage", reflect.TypeOf((*MockStore)(nil).CreateJobUsage), arg0, arg1)
+}
+
 // CreateKeyword mocks base method.
 func (m *MockStore) CreateKeyword(arg0 context.Context, arg1 *model.CreateKeywordParams) (int64, error) {
 	m.ctrl.T.Helper()
@@ -276,6 +439,36 @@ func (mr *MockStoreMockRecorder) CreateNewsJob(arg0, arg1 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateNewsJob", reflect.TypeOf((*MockStore)(nil).CreateNewsJob), arg0, arg1)
 }
 
+// CreatePrompt mocks base method.
+func (m *MockStore) CreatePrompt(arg0 context.Context, arg1 *model.CreatePromptParams) (*model.CreatePromptRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePrompt", arg0, arg1)
+	ret0, _ := ret[0].(*model.CreatePromptRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePrompt indicates an expected call of CreatePrompt.
+func (mr *MockStoreMockRecorder) CreatePrompt(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePrompt", reflect.TypeOf((*MockStore)(nil).CreatePrompt), arg0, arg1)
+}
+
+// CreateStory mocks base method.
+func (m *MockStore) CreateStory(arg0 context.Context, arg1 *model.CreateStoryParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateStory", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateStory indicates an expected call of CreateStory.
+func (mr *MockStoreMockRecorder) CreateStory(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateStory", reflect.TypeOf((*MockStore)(nil).CreateStory), arg0, arg1)
+}
+
 // CreateUser mocks base method.
 func (m *MockStore) CreateUser(arg0 context.Context, arg1 *model.CreateUserParams) (uuid.UUID, error) {
 	m.ctrl.T.Helper()
@@ -291,6 +484,36 @@ func (mr *MockStoreMockRecorder) CreateUser(arg0, arg1 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockStore)(nil).CreateUser), arg0, arg1)
 }
 
+// CreateWebhook mocks base method.
+func (m *MockStore) CreateWebhook(arg0 context.Context, arg1 *model.CreateWebhookParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhook", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWebhook indicates an expected call of CreateWebhook.
+func (mr *MockStoreMockRecorder) CreateWebhook(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhook", reflect.TypeOf((*MockStore)(nil).CreateWebhook), arg0, arg1)
+}
+
+// CreateWebhookSecret mocks base method.
+func (m *MockStore) CreateWebhookSecret(arg0 context.Context, arg1 *model.CreateWebhookSecretParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhookSecret", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWebhookSecret indicates an expected call of CreateWebhookSecret.
+func (mr *MockStoreMockRecorder) CreateWebhookSecret(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhookSecret", reflect.TypeOf((*MockStore)(nil).CreateWebhookSecret), arg0, arg1)
+}
+
 // DeleteAPI mocks base method.
 func (m *MockStore) DeleteAPI(arg0 context.Context, arg1 int16) (int64, error) {
 	m.ctrl.T.Helper()
@@ -321,6 +544,21 @@ func (mr *MockStoreMockRecorder) DeleteAPIKey(arg0, arg1 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAPIKey", reflect.TypeOf((*MockStore)(nil).DeleteAPIKey), arg0, arg1)
 }
 
+// DeleteEmbeddingChunksFrom mocks base method.
+func (m *MockStore) DeleteEmbeddingChunksFrom(arg0 context.Context, arg1 *model.DeleteEmbeddingChunksFromParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteEmbeddingChunksFrom", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteEmbeddingChunksFrom indicates an expected call of DeleteEmbeddingChunksFrom.
+func (mr *MockStoreMockRecorder) DeleteEmbeddingChunksFrom(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteEmbeddingChunksFrom", reflect.TypeOf((*MockStore)(nil).DeleteEmbeddingChunksFrom), arg0, arg1)
+}
+
 // DeleteEndpoint mocks base method.
 func (m *MockStore) DeleteEndpoint(arg0 context.Context, arg1 int32) (int64, error) {
 	m.ctrl.T.Helper()
@@ -351,6 +589,21 @@ func (mr *MockStoreMockRecorder) DeleteJob(arg0, arg1 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteJob", reflect.TypeOf((*MockStore)(nil).DeleteJob), arg0, arg1)
 }
 
+// DeleteJobSchedule mocks base method.
+func (m *MockStore) DeleteJobSchedule(arg0 context.Context, arg1 *model.DeleteJobScheduleParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteJobSchedule", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteJobSchedule indicates an expected call of DeleteJobSchedule.
+func (mr *MockStoreMockRecorder) DeleteJobSchedule(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteJobSchedule", reflect.TypeOf((*MockStore)(nil).DeleteJobSchedule), arg0, arg1)
+}
+
 // DeleteKeyword mocks base method.
 func (m *MockStore) DeleteKeyword(arg0 context.Context, arg1 string) (int64, error) {
 	m.ctrl.T.Helper()
@@ -411,6 +664,21 @@ func (mr *MockStoreMockRecorder) DeleteUser(arg0, arg1 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockStore)(nil).DeleteUser), arg0, arg1)
 }
 
+// DeleteWebhook mocks base method.
+func (m *MockStore) DeleteWebhook(arg0 context.Context, arg1 *model.DeleteWebhookParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhook", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteWebhook indicates an expected call of DeleteWebhook.
+func (mr *MockStoreMockRecorder) DeleteWebhook(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhook", reflect.TypeOf((*MockStore)(nil).DeleteWebhook), arg0, arg1)
+}
+
 // DoCacheToStoreTx mocks base method.
 func (m *MockStore) DoCacheToStoreTx(arg0 context.Context, arg1 *model.CacheToStoreTXParams) (*model.CacheToStoreTXResult, error) {
 	m.ctrl.T.Helper()
@@ -440,6 +708,21 @@ func (mr *MockStoreMockRecorder) DoCheckAndUpdateUserPasswordTx(arg0, arg1 inter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DoCheckAndUpdateUserPasswordTx", reflect.TypeOf((*MockStore)(nil).DoCheckAndUpdateUserPasswordTx), arg0, arg1)
 }
 
+// DoCloneJobTx mocks base method.
+func (m *MockStore) DoCloneJobTx(arg0 context.Context, arg1 *model.CloneJobTxParams) (*model.CloneJobTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DoCloneJobTx", arg0, arg1)
+	ret0, _ := ret[0].(*model.CloneJobTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DoCloneJobTx indicates an expected call of DoCloneJobTx.
+func (mr *MockStoreMockRecorder) DoCloneJobTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DoCloneJobTx", reflect.TypeOf((*MockStore)(nil).DoCloneJobTx), arg0, arg1)
+}
+
 // DoCountUserJobTx mocks base method.
 func (m *MockStore) DoCountUserJobTx(arg0 context.Context, arg1 uuid.UUID) (*model.CountUserJobTxResult, error) {
 	m.ctrl.T.Helper()
@@ -470,6 +753,36 @@ func (mr *MockStoreMockRecorder) DoCreateOrUpdateAPIKeyTx(arg0, arg1 interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DoCreateOrUpdateAPIKeyTx", reflect.TypeOf((*MockStore)(nil).DoCreateOrUpdateAPIKeyTx), arg0, arg1)
 }
 
+// DoCreateWebhookTx mocks base method.
+func (m *MockStore) DoCreateWebhookTx(arg0 context.Context, arg1 *model.CreateWebhookTxParams) (*model.CreateWebhookTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DoCreateWebhookTx", arg0, arg1)
+	ret0, _ := ret[0].(*model.CreateWebhookTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DoCreateWebhookTx indicates an expected call of DoCreateWebhookTx.
+func (mr *MockStoreMockRecorder) DoCreateWebhookTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DoCreateWebhookTx", reflect.TypeOf((*MockStore)(nil).DoCreateWebhookTx), arg0, arg1)
+}
+
+// DoRequeueJobItemsTx mocks base method.
+func (m *MockStore) DoRequeueJobItemsTx(arg0 context.Context, arg1 *model.RequeueJobItemsTxParams) (*model.RequeueJobItemsTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DoRequeueJobItemsTx", arg0, arg1)
+	ret0, _ := ret[0].(*model.RequeueJobItemsTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DoRequeueJobItemsTx indicates an expected call of DoRequeueJobItemsTx.
+func (mr *MockStoreMockRecorder) DoRequeueJobItemsTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DoRequeueJobItemsTx", reflect.TypeOf((*MockStore)(nil).DoRequeueJobItemsTx), arg0, arg1)
+}
+
 // ExecTx mocks base method.
 func (m *MockStore) ExecTx(arg0 context.Context, arg1 model.QueryCallBackFun) error {
 	m.ctrl.T.Helper()
@@ -514,6 +827,21 @@ func (mr *MockStoreMockRecorder) GetAPIKey(arg0, arg1 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKey", reflect.TypeOf((*MockStore)(nil).GetAPIKey), arg0, arg1)
 }
 
+// GetAnnotatedNewsIds mocks base method.
+func (m *MockStore) GetAnnotatedNewsIds(arg0 context.Context, arg1 *model.GetAnnotatedNewsIdsParams) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAnnotatedNewsIds", arg0, arg1)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAnnotatedNewsIds indicates an expected call of GetAnnotatedNewsIds.
+func (mr *MockStoreMockRecorder) GetAnnotatedNewsIds(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAnnotatedNewsIds", reflect.TypeOf((*MockStore)(nil).GetAnnotatedNewsIds), arg0, arg1)
+}
+
 // GetContentById mocks base method.
 func (m *MockStore) GetContentById(arg0 context.Context, arg1 []int32) ([]*model.GetContentByIdRow, error) {
 	m.ctrl.T.Helper()
@@ -529,8 +857,53 @@ func (mr *MockStoreMockRecorder) GetContentById(arg0, arg1 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetContentById", reflect.TypeOf((*MockStore)(nil).GetContentById), arg0, arg1)
 }
 
+// GetDueJobItems mocks base method.
+func (m *MockStore) GetDueJobItems(arg0 context.Context, arg1 int64) ([]*model.GetDueJobItemsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDueJobItems", arg0, arg1)
+	ret0, _ := ret[0].([]*model.GetDueJobItemsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDueJobItems indicates an expected call of GetDueJobItems.
+func (mr *MockStoreMockRecorder) GetDueJobItems(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDueJobItems", reflect.TypeOf((*MockStore)(nil).GetDueJobItems), arg0, arg1)
+}
+
+// GetDueJobSchedules mocks base method.
+func (m *MockStore) GetDueJobSchedules(arg0 context.Context, arg1 int32) ([]*model.GetDueJobSchedulesRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDueJobSchedules", arg0, arg1)
+	ret0, _ := ret[0].([]*model.GetDueJobSchedulesRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDueJobSchedules indicates an expected call of GetDueJobSchedules.
+func (mr *MockStoreMockRecorder) GetDueJobSchedules(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDueJobSchedules", reflect.TypeOf((*MockStore)(nil).GetDueJobSchedules), arg0, arg1)
+}
+
+// GetDueWebhookDeliveries mocks base method.
+func (m *MockStore) GetDueWebhookDeliveries(arg0 context.Context, arg1 int32) ([]*model.GetDueWebhookDeliveriesRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDueWebhookDeliveries", arg0, arg1)
+	ret0, _ := ret[0].([]*model.GetDueWebhookDeliveriesRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDueWebhookDeliveries indicates an expected call of GetDueWebhookDeliveries.
+func (mr *MockStoreMockRecorder) GetDueWebhookDeliveries(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDueWebhookDeliveries", reflect.TypeOf((*MockStore)(nil).GetDueWebhookDeliveries), arg0, arg1)
+}
+
 // GetEmbeddingByJobId mocks base method.
-func (m *MockStore) GetEmbeddingByJobId(arg0 context.Context, arg1 int64) ([]*model.GetEmbeddingByJobIdRow, error) {
+func (m *MockStore) GetEmbeddingByJobId(arg0 context.Context, arg1 *model.GetEmbeddingByJobIdParams) ([]*model.GetEmbeddingByJobIdRow, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetEmbeddingByJobId", arg0, arg1)
 	ret0, _ := ret[0].([]*model.GetEmbeddingByJobIdRow)
@@ -559,6 +932,36 @@ func (mr *MockStoreMockRecorder) GetEmbeddingByNewsIdsAndModel(arg0, arg1 interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEmbeddingByNewsIdsAndModel", reflect.TypeOf((*MockStore)(nil).GetEmbeddingByNewsIdsAndModel), arg0, arg1)
 }
 
+// GetEmbeddingModel mocks base method.
+func (m *MockStore) GetEmbeddingModel(arg0 context.Context, arg1 string) (*model.GetEmbeddingModelRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEmbeddingModel", arg0, arg1)
+	ret0, _ := ret[0].(*model.GetEmbeddingModelRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEmbeddingModel indicates an expected call of GetEmbeddingModel.
+func (mr *MockStoreMockRecorder) GetEmbeddingModel(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEmbeddingModel", reflect.TypeOf((*MockStore)(nil).GetEmbeddingModel), arg0, arg1)
+}
+
+// GetJobAnnotationStats mocks base method.
+func (m *MockStore) GetJobAnnotationStats(arg0 context.Context, arg1 *model.GetJobAnnotationStatsParams) ([]*model.GetJobAnnotationStatsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJobAnnotationStats", arg0, arg1)
+	ret0, _ := ret[0].([]*model.GetJobAnnotationStatsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJobAnnotationStats indicates an expected call of GetJobAnnotationStats.
+func (mr *MockStoreMockRecorder) GetJobAnnotationStats(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJobAnnotationStats", reflect.TypeOf((*MockStore)(nil).GetJobAnnotationStats), arg0, arg1)
+}
+
 // GetJobByOwnerFilterByJIdAndStatus mocks base method.
 func (m *MockStore) GetJobByOwnerFilterByJIdAndStatus(arg0 context.Context, arg1 *model.GetJobByOwnerFilterByJIdAndStatusParams) ([]*model.GetJobByOwnerFilterByJIdAndStatusRow, error) {
 	m.ctrl.T.Helper()
@@ -604,56 +1007,161 @@ func (mr *MockStoreMockRecorder) GetJobByOwnerFilterByJIds(arg0, arg1 interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJobByOwnerFilterByJIds", reflect.TypeOf((*MockStore)(nil).GetJobByOwnerFilterByJIds), arg0, arg1)
 }
 
-// GetJobsByJobId mocks base method.
-func (m *MockStore) GetJobsByJobId(arg0 context.Context, arg1 *model.GetJobsByJobIdParams) (*model.GetJobsByJobIdRow, error) {
+// GetJobEntitySentimentStats mocks base method.
+func (m *MockStore) GetJobEntitySentimentStats(arg0 context.Context, arg1 *model.GetJobEntitySentimentStatsParams) ([]*model.GetJobEntitySentimentStatsRow, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetJobsByJobId", arg0, arg1)
-	ret0, _ := ret[0].(*model.GetJobsByJobIdRow)
+	ret := m.ctrl.Call(m, "GetJobEntitySentimentStats", arg0, arg1)
+	ret0, _ := ret[0].([]*model.GetJobEntitySentimentStatsRow)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetJobsByJobId indicates an expected call of GetJobsByJobId.
-func (mr *MockStoreMockRecorder) GetJobsByJobId(arg0, arg1 interface{}) *gomock.Call {
+// GetJobEntitySentimentStats indicates an expected call of GetJobEntitySentimentStats.
+func (mr *MockStoreMockRecorder) GetJobEntitySentimentStats(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJobsByJobId", reflect.TypeOf((*MockStore)(nil).GetJobsByJobId), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJobEntitySentimentStats", reflect.TypeOf((*MockStore)(nil).GetJobEntitySentimentStats), arg0, arg1)
 }
 
-// GetJobsByOwner mocks base method.
-func (m *MockStore) GetJobsByOwner(arg0 context.Context, arg1 *model.GetJobsByOwnerParams) ([]*model.GetJobsByOwnerRow, error) {
+// GetJobItemsByStage mocks base method.
+func (m *MockStore) GetJobItemsByStage(arg0 context.Context, arg1 *model.GetJobItemsByStageParams) ([]*model.GetJobItemsByStageRow, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetJobsByOwner", arg0, arg1)
-	ret0, _ := ret[0].([]*model.GetJobsByOwnerRow)
+	ret := m.ctrl.Call(m, "GetJobItemsByStage", arg0, arg1)
+	ret0, _ := ret[0].([]*model.GetJobItemsByStageRow)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetJobsByOwner indicates an expected call of GetJobsByOwner.
-func (mr *MockStoreMockRecorder) GetJobsByOwner(arg0, arg1 interface{}) *gomock.Call {
+// GetJobItemsByStage indicates an expected call of GetJobItemsByStage.
+func (mr *MockStoreMockRecorder) GetJobItemsByStage(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJobsByOwner", reflect.TypeOf((*MockStore)(nil).GetJobsByOwner), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJobItemsByStage", reflect.TypeOf((*MockStore)(nil).GetJobItemsByStage), arg0, arg1)
 }
 
-// GetJobsByOwnerFilterByStatus mocks base method.
-func (m *MockStore) GetJobsByOwnerFilterByStatus(arg0 context.Context, arg1 *model.GetJobsByOwnerFilterByStatusParams) ([]*model.GetJobsByOwnerFilterByStatusRow, error) {
+// GetJobProgress mocks base method.
+func (m *MockStore) GetJobProgress(arg0 context.Context, arg1 *model.GetJobProgressParams) (*model.GetJobProgressRow, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetJobsByOwnerFilterByStatus", arg0, arg1)
-	ret0, _ := ret[0].([]*model.GetJobsByOwnerFilterByStatusRow)
+	ret := m.ctrl.Call(m, "GetJobProgress", arg0, arg1)
+	ret0, _ := ret[0].(*model.GetJobProgressRow)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetJobsByOwnerFilterByStatus indicates an expected call of GetJobsByOwnerFilterByStatus.
-func (mr *MockStoreMockRecorder) GetJobsByOwnerFilterByStatus(arg0, arg1 interface{}) *gomock.Call {
+// GetJobProgress indicates an expected call of GetJobProgress.
+func (mr *MockStoreMockRecorder) GetJobProgress(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJobsByOwnerFilterByStatus", reflect.TypeOf((*MockStore)(nil).GetJobsByOwnerFilterByStatus), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJobProgress", reflect.TypeOf((*MockStore)(nil).GetJobProgress), arg0, arg1)
 }
 
-// GetKeywordsByNewsId mocks base method.
-func (m *MockStore) GetKeywordsByNewsId(arg0 context.Context, arg1 []int32) ([]string, error) {
+// GetJobQuotaUsage mocks base method.
+func (m *MockStore) GetJobQuotaUsage(arg0 context.Context, arg1 *model.GetJobQuotaUsageParams) (*model.GetJobQuotaUsageRow, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetKeywordsByNewsId", arg0, arg1)
-	ret0, _ := ret[0].([]string)
+	ret := m.ctrl.Call(m, "GetJobQuotaUsage", arg0, arg1)
+	ret0, _ := ret[0].(*model.GetJobQuotaUsageRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJobQuotaUsage indicates an expected call of GetJobQuotaUsage.
+func (mr *MockStoreMockRecorder) GetJobQuotaUsage(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJobQuotaUsage", reflect.TypeOf((*MockStore)(nil).GetJobQuotaUsage), arg0, arg1)
+}
+
+// GetJobSchedule mocks base method.
+func (m *MockStore) GetJobSchedule(arg0 context.Context, arg1 *model.GetJobScheduleParams) (*model.GetJobScheduleRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJobSchedule", arg0, arg1)
+	ret0, _ := ret[0].(*model.GetJobScheduleRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJobSchedule indicates an expected call of GetJobSchedule.
+func (mr *MockStoreMockRecorder) GetJobSchedule(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJobSchedule", reflect.TypeOf((*MockStore)(nil).GetJobSchedule), arg0, arg1)
+}
+
+// GetJobSentimentStats mocks base method.
+func (m *MockStore) GetJobSentimentStats(arg0 context.Context, arg1 *model.GetJobSentimentStatsParams) ([]*model.GetJobSentimentStatsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJobSentimentStats", arg0, arg1)
+	ret0, _ := ret[0].([]*model.GetJobSentimentStatsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJobSentimentStats indicates an expected call of GetJobSentimentStats.
+func (mr *MockStoreMockRecorder) GetJobSentimentStats(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJobSentimentStats", reflect.TypeOf((*MockStore)(nil).GetJobSentimentStats), arg0, arg1)
+}
+
+// GetJobUsage mocks base method.
+func (m *MockStore) GetJobUsage(arg0 context.Context, arg1 *model.GetJobUsageParams) ([]*model.GetJobUsageRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJobUsage", arg0, arg1)
+	ret0, _ := ret[0].([]*model.GetJobUsageRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJobUsage indicates an expected call of GetJobUsage.
+func (mr *MockStoreMockRecorder) GetJobUsage(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJobUsage", reflect.TypeOf((*MockStore)(nil).GetJobUsage), arg0, arg1)
+}
+
+// GetJobsByJobId mocks base method.
+func (m *MockStore) GetJobsByJobId(arg0 context.Context, arg1 *model.GetJobsByJobIdParams) (*model.GetJobsByJobIdRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJobsByJobId", arg0, arg1)
+	ret0, _ := ret[0].(*model.GetJobsByJobIdRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJobsByJobId indicates an expected call of GetJobsByJobId.
+func (mr *MockStoreMockRecorder) GetJobsByJobId(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJobsByJobId", reflect.TypeOf((*MockStore)(nil).GetJobsByJobId), arg0, arg1)
+}
+
+// GetJobsByOwner mocks base method.
+func (m *MockStore) GetJobsByOwner(arg0 context.Context, arg1 *model.GetJobsByOwnerParams) ([]*model.GetJobsByOwnerRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJobsByOwner", arg0, arg1)
+	ret0, _ := ret[0].([]*model.GetJobsByOwnerRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJobsByOwner indicates an expected call of GetJobsByOwner.
+func (mr *MockStoreMockRecorder) GetJobsByOwner(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJobsByOwner", reflect.TypeOf((*MockStore)(nil).GetJobsByOwner), arg0, arg1)
+}
+
+// GetJobsByOwnerFilterByStatus mocks base method.
+func (m *MockStore) GetJobsByOwnerFilterByStatus(arg0 context.Context, arg1 *model.GetJobsByOwnerFilterByStatusParams) ([]*model.GetJobsByOwnerFilterByStatusRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJobsByOwnerFilterByStatus", arg0, arg1)
+	ret0, _ := ret[0].([]*model.GetJobsByOwnerFilterByStatusRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJobsByOwnerFilterByStatus indicates an expected call of GetJobsByOwnerFilterByStatus.
+func (mr *MockStoreMockRecorder) GetJobsByOwnerFilterByStatus(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJobsByOwnerFilterByStatus", reflect.TypeOf((*MockStore)(nil).GetJobsByOwnerFilterByStatus), arg0, arg1)
+}
+
+// GetKeywordsByNewsId mocks base method.
+func (m *MockStore) GetKeywordsByNewsId(arg0 context.Context, arg1 []int32) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetKeywordsByNewsId", arg0, arg1)
+	ret0, _ := ret[0].([]string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -664,6 +1172,21 @@ func (mr *MockStoreMockRecorder) GetKeywordsByNewsId(arg0, arg1 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKeywordsByNewsId", reflect.TypeOf((*MockStore)(nil).GetKeywordsByNewsId), arg0, arg1)
 }
 
+// GetLLMResponse mocks base method.
+func (m *MockStore) GetLLMResponse(arg0 context.Context, arg1 *model.GetLLMResponseParams) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLLMResponse", arg0, arg1)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLLMResponse indicates an expected call of GetLLMResponse.
+func (mr *MockStoreMockRecorder) GetLLMResponse(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLLMResponse", reflect.TypeOf((*MockStore)(nil).GetLLMResponse), arg0, arg1)
+}
+
 // GetLastJobId mocks base method.
 func (m *MockStore) GetLastJobId(arg0 context.Context, arg1 uuid.UUID) ([]*model.GetLastJobIdRow, error) {
 	m.ctrl.T.Helper()
@@ -709,6 +1232,96 @@ func (mr *MockStoreMockRecorder) GetLogByUserIdNext(arg0, arg1 interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLogByUserIdNext", reflect.TypeOf((*MockStore)(nil).GetLogByUserIdNext), arg0, arg1)
 }
 
+// GetNearestNewsIds mocks base method.
+func (m *MockStore) GetNearestNewsIds(arg0 context.Context, arg1 *model.GetNearestNewsIdsParams) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNearestNewsIds", arg0, arg1)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNearestNewsIds indicates an expected call of GetNearestNewsIds.
+func (mr *MockStoreMockRecorder) GetNearestNewsIds(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNearestNewsIds", reflect.TypeOf((*MockStore)(nil).GetNearestNewsIds), arg0, arg1)
+}
+
+// GetNearestNewsIds1024 mocks base method.
+func (m *MockStore) GetNearestNewsIds1024(arg0 context.Context, arg1 *model.GetNearestNewsIds1024Params) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNearestNewsIds1024", arg0, arg1)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNearestNewsIds1024 indicates an expected call of GetNearestNewsIds1024.
+func (mr *MockStoreMockRecorder) GetNearestNewsIds1024(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNearestNewsIds1024", reflect.TypeOf((*MockStore)(nil).GetNearestNewsIds1024), arg0, arg1)
+}
+
+// GetNearestNewsIds1536 mocks base method.
+func (m *MockStore) GetNearestNewsIds1536(arg0 context.Context, arg1 *model.GetNearestNewsIds1536Params) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNearestNewsIds1536", arg0, arg1)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNearestNewsIds1536 indicates an expected call of GetNearestNewsIds1536.
+func (mr *MockStoreMockRecorder) GetNearestNewsIds1536(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNearestNewsIds1536", reflect.TypeOf((*MockStore)(nil).GetNearestNewsIds1536), arg0, arg1)
+}
+
+// GetNearestNewsIds256 mocks base method.
+func (m *MockStore) GetNearestNewsIds256(arg0 context.Context, arg1 *model.GetNearestNewsIds256Params) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNearestNewsIds256", arg0, arg1)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNearestNewsIds256 indicates an expected call of GetNearestNewsIds256.
+func (mr *MockStoreMockRecorder) GetNearestNewsIds256(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNearestNewsIds256", reflect.TypeOf((*MockStore)(nil).GetNearestNewsIds256), arg0, arg1)
+}
+
+// GetNearestNewsIds384 mocks base method.
+func (m *MockStore) GetNearestNewsIds384(arg0 context.Context, arg1 *model.GetNearestNewsIds384Params) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNearestNewsIds384", arg0, arg1)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNearestNewsIds384 indicates an expected call of GetNearestNewsIds384.
+func (mr *MockStoreMockRecorder) GetNearestNewsIds384(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNearestNewsIds384", reflect.TypeOf((*MockStore)(nil).GetNearestNewsIds384), arg0, arg1)
+}
+
+// GetNearestStory mocks base method.
+func (m *MockStore) GetNearestStory(arg0 context.Context, arg1 *model.GetNearestStoryParams) (*model.GetNearestStoryRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNearestStory", arg0, arg1)
+	ret0, _ := ret[0].(*model.GetNearestStoryRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNearestStory indicates an expected call of GetNearestStory.
+func (mr *MockStoreMockRecorder) GetNearestStory(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNearestStory", reflect.TypeOf((*MockStore)(nil).GetNearestStory), arg0, arg1)
+}
+
 // GetNewsByJob mocks base method.
 func (m *MockStore) GetNewsByJob(arg0 context.Context) ([]*model.GetNewsByJobRow, error) {
 	m.ctrl.T.Helper()
@@ -724,6 +1337,21 @@ func (mr *MockStoreMockRecorder) GetNewsByJob(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNewsByJob", reflect.TypeOf((*MockStore)(nil).GetNewsByJob), arg0)
 }
 
+// GetNewsByJobId mocks base method.
+func (m *MockStore) GetNewsByJobId(arg0 context.Context, arg1 int64) ([]*model.GetNewsByJobIdRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNewsByJobId", arg0, arg1)
+	ret0, _ := ret[0].([]*model.GetNewsByJobIdRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNewsByJobId indicates an expected call of GetNewsByJobId.
+func (mr *MockStoreMockRecorder) GetNewsByJobId(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNewsByJobId", reflect.TypeOf((*MockStore)(nil).GetNewsByJobId), arg0, arg1)
+}
+
 // GetNewsByKeywords mocks base method.
 func (m *MockStore) GetNewsByKeywords(arg0 context.Context, arg1 []string) ([]*model.GetNewsByKeywordsRow, error) {
 	m.ctrl.T.Helper()
@@ -755,10 +1383,10 @@ func (mr *MockStoreMockRecorder) GetNewsByMD5Hash(arg0, arg1 interface{}) *gomoc
 }
 
 // GetNewsByMD5Hashs mocks base method.
-func (m *MockStore) GetNewsByMD5Hashs(arg0 context.Context, arg1 []string) ([]int64, error) {
+func (m *MockStore) GetNewsByMD5Hashs(arg0 context.Context, arg1 []string) ([]*model.GetNewsByMD5HashsRow, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetNewsByMD5Hashs", arg0, arg1)
-	ret0, _ := ret[0].([]int64)
+	ret0, _ := ret[0].([]*model.GetNewsByMD5HashsRow)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -784,6 +1412,21 @@ func (mr *MockStoreMockRecorder) GetNewsPublishBetween(arg0, arg1 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNewsPublishBetween", reflect.TypeOf((*MockStore)(nil).GetNewsPublishBetween), arg0, arg1)
 }
 
+// GetNextJobItemAttempt mocks base method.
+func (m *MockStore) GetNextJobItemAttempt(arg0 context.Context, arg1 int64) (pgtype.Timestamptz, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNextJobItemAttempt", arg0, arg1)
+	ret0, _ := ret[0].(pgtype.Timestamptz)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNextJobItemAttempt indicates an expected call of GetNextJobItemAttempt.
+func (mr *MockStoreMockRecorder) GetNextJobItemAttempt(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNextJobItemAttempt", reflect.TypeOf((*MockStore)(nil).GetNextJobItemAttempt), arg0, arg1)
+}
+
 // GetOldestNCreatedJobsForEachUser mocks base method.
 func (m *MockStore) GetOldestNCreatedJobsForEachUser(arg0 context.Context, arg1 int32) ([]*model.GetOldestNCreatedJobsForEachUserRow, error) {
 	m.ctrl.T.Helper()
@@ -799,6 +1442,66 @@ func (mr *MockStoreMockRecorder) GetOldestNCreatedJobsForEachUser(arg0, arg1 int
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOldestNCreatedJobsForEachUser", reflect.TypeOf((*MockStore)(nil).GetOldestNCreatedJobsForEachUser), arg0, arg1)
 }
 
+// GetPrompt mocks base method.
+func (m *MockStore) GetPrompt(arg0 context.Context, arg1 *model.GetPromptParams) (*model.Prompt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPrompt", arg0, arg1)
+	ret0, _ := ret[0].(*model.Prompt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPrompt indicates an expected call of GetPrompt.
+func (mr *MockStoreMockRecorder) GetPrompt(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPrompt", reflect.TypeOf((*MockStore)(nil).GetPrompt), arg0, arg1)
+}
+
+// GetStory mocks base method.
+func (m *MockStore) GetStory(arg0 context.Context, arg1 int64) (*model.Story, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStory", arg0, arg1)
+	ret0, _ := ret[0].(*model.Story)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStory indicates an expected call of GetStory.
+func (mr *MockStoreMockRecorder) GetStory(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStory", reflect.TypeOf((*MockStore)(nil).GetStory), arg0, arg1)
+}
+
+// GetStoryNews mocks base method.
+func (m *MockStore) GetStoryNews(arg0 context.Context, arg1 int64) ([]*model.GetStoryNewsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStoryNews", arg0, arg1)
+	ret0, _ := ret[0].([]*model.GetStoryNewsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStoryNews indicates an expected call of GetStoryNews.
+func (mr *MockStoreMockRecorder) GetStoryNews(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStoryNews", reflect.TypeOf((*MockStore)(nil).GetStoryNews), arg0, arg1)
+}
+
+// GetUnclusteredNews mocks base method.
+func (m *MockStore) GetUnclusteredNews(arg0 context.Context, arg1 int32) ([]*model.GetUnclusteredNewsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUnclusteredNews", arg0, arg1)
+	ret0, _ := ret[0].([]*model.GetUnclusteredNewsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUnclusteredNews indicates an expected call of GetUnclusteredNews.
+func (mr *MockStoreMockRecorder) GetUnclusteredNews(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUnclusteredNews", reflect.TypeOf((*MockStore)(nil).GetUnclusteredNews), arg0, arg1)
+}
+
 // GetUserAuth mocks base method.
 func (m *MockStore) GetUserAuth(arg0 context.Context, arg1 string) (*model.GetUserAuthRow, error) {
 	m.ctrl.T.Helper()
@@ -814,6 +1517,21 @@ func (mr *MockStoreMockRecorder) GetUserAuth(arg0, arg1 interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserAuth", reflect.TypeOf((*MockStore)(nil).GetUserAuth), arg0, arg1)
 }
 
+// GetWebhookSecret mocks base method.
+func (m *MockStore) GetWebhookSecret(arg0 context.Context, arg1 uuid.UUID) (*model.GetWebhookSecretRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookSecret", arg0, arg1)
+	ret0, _ := ret[0].(*model.GetWebhookSecretRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookSecret indicates an expected call of GetWebhookSecret.
+func (mr *MockStoreMockRecorder) GetWebhookSecret(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookSecret", reflect.TypeOf((*MockStore)(nil).GetWebhookSecret), arg0, arg1)
+}
+
 // HardDeleteUser mocks base method.
 func (m *MockStore) HardDeleteUser(arg0 context.Context, arg1 uuid.UUID) (int64, error) {
 	m.ctrl.T.Helper()
@@ -829,6 +1547,20 @@ func (mr *MockStoreMockRecorder) HardDeleteUser(arg0, arg1 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HardDeleteUser", reflect.TypeOf((*MockStore)(nil).HardDeleteUser), arg0, arg1)
 }
 
+// IncrJobProgress mocks base method.
+func (m *MockStore) IncrJobProgress(arg0 context.Context, arg1 *model.IncrJobProgressParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrJobProgress", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IncrJobProgress indicates an expected call of IncrJobProgress.
+func (mr *MockStoreMockRecorder) IncrJobProgress(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrJobProgress", reflect.TypeOf((*MockStore)(nil).IncrJobProgress), arg0, arg1)
+}
+
 // ListAPI mocks base method.
 func (m *MockStore) ListAPI(arg0 context.Context, arg1 int32) ([]*model.ListAPIRow, error) {
 	m.ctrl.T.Helper()
@@ -904,6 +1636,21 @@ func (mr *MockStoreMockRecorder) ListEndpointByOwner(arg0, arg1 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEndpointByOwner", reflect.TypeOf((*MockStore)(nil).ListEndpointByOwner), arg0, arg1)
 }
 
+// ListPrompts mocks base method.
+func (m *MockStore) ListPrompts(arg0 context.Context, arg1 uuid.UUID) ([]*model.ListPromptsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPrompts", arg0, arg1)
+	ret0, _ := ret[0].([]*model.ListPromptsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPrompts indicates an expected call of ListPrompts.
+func (mr *MockStoreMockRecorder) ListPrompts(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPrompts", reflect.TypeOf((*MockStore)(nil).ListPrompts), arg0, arg1)
+}
+
 // ListRecentNNews mocks base method.
 func (m *MockStore) ListRecentNNews(arg0 context.Context, arg1 int32) ([]*model.ListRecentNNewsRow, error) {
 	m.ctrl.T.Helper()
@@ -919,6 +1666,171 @@ func (mr *MockStoreMockRecorder) ListRecentNNews(arg0, arg1 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRecentNNews", reflect.TypeOf((*MockStore)(nil).ListRecentNNews), arg0, arg1)
 }
 
+// ListStories mocks base method.
+func (m *MockStore) ListStories(arg0 context.Context, arg1 *model.ListStoriesParams) ([]*model.ListStoriesRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListStories", arg0, arg1)
+	ret0, _ := ret[0].([]*model.ListStoriesRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListStories indicates an expected call of ListStories.
+func (mr *MockStoreMockRecorder) ListStories(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListStories", reflect.TypeOf((*MockStore)(nil).ListStories), arg0, arg1)
+}
+
+// ListWebhookDeliveries mocks base method.
+func (m *MockStore) ListWebhookDeliveries(arg0 context.Context, arg1 *model.ListWebhookDeliveriesParams) ([]*model.ListWebhookDeliveriesRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebhookDeliveries", arg0, arg1)
+	ret0, _ := ret[0].([]*model.ListWebhookDeliveriesRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebhookDeliveries indicates an expected call of ListWebhookDeliveries.
+func (mr *MockStoreMockRecorder) ListWebhookDeliveries(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebhookDeliveries", reflect.TypeOf((*MockStore)(nil).ListWebhookDeliveries), arg0, arg1)
+}
+
+// ListWebhooks mocks base method.
+func (m *MockStore) ListWebhooks(arg0 context.Context, arg1 uuid.UUID) ([]*model.ListWebhooksRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebhooks", arg0, arg1)
+	ret0, _ := ret[0].([]*model.ListWebhooksRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebhooks indicates an expected call of ListWebhooks.
+func (mr *MockStoreMockRecorder) ListWebhooks(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebhooks", reflect.TypeOf((*MockStore)(nil).ListWebhooks), arg0, arg1)
+}
+
+// LockUser mocks base method.
+func (m *MockStore) LockUser(arg0 context.Context, arg1 uuid.UUID) (uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockUser", arg0, arg1)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LockUser indicates an expected call of LockUser.
+func (mr *MockStoreMockRecorder) LockUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockUser", reflect.TypeOf((*MockStore)(nil).LockUser), arg0, arg1)
+}
+
+// MarkJobItemDone mocks base method.
+func (m *MockStore) MarkJobItemDone(arg0 context.Context, arg1 *model.MarkJobItemDoneParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkJobItemDone", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkJobItemDone indicates an expected call of MarkJobItemDone.
+func (mr *MockStoreMockRecorder) MarkJobItemDone(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkJobItemDone", reflect.TypeOf((*MockStore)(nil).MarkJobItemDone), arg0, arg1)
+}
+
+// MarkJobItemFailed mocks base method.
+func (m *MockStore) MarkJobItemFailed(arg0 context.Context, arg1 *model.MarkJobItemFailedParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkJobItemFailed", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkJobItemFailed indicates an expected call of MarkJobItemFailed.
+func (mr *MockStoreMockRecorder) MarkJobItemFailed(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkJobItemFailed", reflect.TypeOf((*MockStore)(nil).MarkJobItemFailed), arg0, arg1)
+}
+
+// MarkJobPartial mocks base method.
+func (m *MockStore) MarkJobPartial(arg0 context.Context, arg1 *model.MarkJobPartialParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkJobPartial", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkJobPartial indicates an expected call of MarkJobPartial.
+func (mr *MockStoreMockRecorder) MarkJobPartial(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkJobPartial", reflect.TypeOf((*MockStore)(nil).MarkJobPartial), arg0, arg1)
+}
+
+// MarkWebhookDeliveryDelivered mocks base method.
+func (m *MockStore) MarkWebhookDeliveryDelivered(arg0 context.Context, arg1 *model.MarkWebhookDeliveryDeliveredParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkWebhookDeliveryDelivered", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkWebhookDeliveryDelivered indicates an expected call of MarkWebhookDeliveryDelivered.
+func (mr *MockStoreMockRecorder) MarkWebhookDeliveryDelivered(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkWebhookDeliveryDelivered", reflect.TypeOf((*MockStore)(nil).MarkWebhookDeliveryDelivered), arg0, arg1)
+}
+
+// MarkWebhookDeliveryFailed mocks base method.
+func (m *MockStore) MarkWebhookDeliveryFailed(arg0 context.Context, arg1 *model.MarkWebhookDeliveryFailedParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkWebhookDeliveryFailed", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkWebhookDeliveryFailed indicates an expected call of MarkWebhookDeliveryFailed.
+func (mr *MockStoreMockRecorder) MarkWebhookDeliveryFailed(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkWebhookDeliveryFailed", reflect.TypeOf((*MockStore)(nil).MarkWebhookDeliveryFailed), arg0, arg1)
+}
+
+// RequeueJobItems mocks base method.
+func (m *MockStore) RequeueJobItems(arg0 context.Context, arg1 *model.RequeueJobItemsParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequeueJobItems", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequeueJobItems indicates an expected call of RequeueJobItems.
+func (mr *MockStoreMockRecorder) RequeueJobItems(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequeueJobItems", reflect.TypeOf((*MockStore)(nil).RequeueJobItems), arg0, arg1)
+}
+
+// RotateWebhookSecret mocks base method.
+func (m *MockStore) RotateWebhookSecret(arg0 context.Context, arg1 *model.RotateWebhookSecretParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateWebhookSecret", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RotateWebhookSecret indicates an expected call of RotateWebhookSecret.
+func (mr *MockStoreMockRecorder) RotateWebhookSecret(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateWebhookSecret", reflect.TypeOf((*MockStore)(nil).RotateWebhookSecret), arg0, arg1)
+}
+
 // UpdateAPI mocks base method.
 func (m *MockStore) UpdateAPI(arg0 context.Context, arg1 *model.UpdateAPIParams) (int64, error) {
 	m.ctrl.T.Helper()
@@ -964,6 +1876,21 @@ func (mr *MockStoreMockRecorder) UpdateJobByULID(arg0, arg1 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateJobByULID", reflect.TypeOf((*MockStore)(nil).UpdateJobByULID), arg0, arg1)
 }
 
+// UpdateJobSchedule mocks base method.
+func (m *MockStore) UpdateJobSchedule(arg0 context.Context, arg1 *model.UpdateJobScheduleParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateJobSchedule", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateJobSchedule indicates an expected call of UpdateJobSchedule.
+func (mr *MockStoreMockRecorder) UpdateJobSchedule(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateJobSchedule", reflect.TypeOf((*MockStore)(nil).UpdateJobSchedule), arg0, arg1)
+}
+
 // UpdateJobStatus mocks base method.
 func (m *MockStore) UpdateJobStatus(arg0 context.Context, arg1 *model.UpdateJobStatusParams) (int64, error) {
 	m.ctrl.T.Helper()
@@ -979,6 +1906,21 @@ func (mr *MockStoreMockRecorder) UpdateJobStatus(arg0, arg1 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateJobStatus", reflect.TypeOf((*MockStore)(nil).UpdateJobStatus), arg0, arg1)
 }
 
+// UpdateJobStatusFrom mocks base method.
+func (m *MockStore) UpdateJobStatusFrom(arg0 context.Context, arg1 *model.UpdateJobStatusFromParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateJobStatusFrom", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateJobStatusFrom indicates an expected call of UpdateJobStatusFrom.
+func (mr *MockStoreMockRecorder) UpdateJobStatusFrom(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateJobStatusFrom", reflect.TypeOf((*MockStore)(nil).UpdateJobStatusFrom), arg0, arg1)
+}
+
 // UpdatePassword mocks base method.
 func (m *MockStore) UpdatePassword(arg0 context.Context, arg1 *model.UpdatePasswordParams) (int64, error) {
 	m.ctrl.T.Helper()
@@ -993,3 +1935,63 @@ func (mr *MockStoreMockRecorder) UpdatePassword(arg0, arg1 interface{}) *gomock.
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePassword", reflect.TypeOf((*MockStore)(nil).UpdatePassword), arg0, arg1)
 }
+
+// UpsertAnnotation mocks base method.
+func (m *MockStore) UpsertAnnotation(arg0 context.Context, arg1 *model.UpsertAnnotationParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertAnnotation", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertAnnotation indicates an expected call of UpsertAnnotation.
+func (mr *MockStoreMockRecorder) UpsertAnnotation(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertAnnotation", reflect.TypeOf((*MockStore)(nil).UpsertAnnotation), arg0, arg1)
+}
+
+// UpsertEmbeddingChunk mocks base method.
+func (m *MockStore) UpsertEmbeddingChunk(arg0 context.Context, arg1 *model.UpsertEmbeddingChunkParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertEmbeddingChunk", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertEmbeddingChunk indicates an expected call of UpsertEmbeddingChunk.
+func (mr *MockStoreMockRecorder) UpsertEmbeddingChunk(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertEmbeddingChunk", reflect.TypeOf((*MockStore)(nil).UpsertEmbeddingChunk), arg0, arg1)
+}
+
+// UpsertEntitySentiment mocks base method.
+func (m *MockStore) UpsertEntitySentiment(arg0 context.Context, arg1 *model.UpsertEntitySentimentParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertEntitySentiment", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertEntitySentiment indicates an expected call of UpsertEntitySentiment.
+func (mr *MockStoreMockRecorder) UpsertEntitySentiment(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertEntitySentiment", reflect.TypeOf((*MockStore)(nil).UpsertEntitySentiment), arg0, arg1)
+}
+
+// UpsertLLMResponse mocks base method.
+func (m *MockStore) UpsertLLMResponse(arg0 context.Context, arg1 *model.UpsertLLMResponseParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertLLMResponse", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertLLMResponse indicates an expected call of UpsertLLMResponse.
+func (mr *MockStoreMockRecorder) UpsertLLMResponse(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertLLMResponse", reflect.TypeOf((*MockStore)(nil).UpsertLLMResponse), arg0, arg1)
+}
@@ -100,6 +100,50 @@ func (ns NullEventType) Value() (driver.Value, error) {
 	return string(ns.EventType), nil
 }
 
+type JobItemStage string
+
+const (
+	JobItemStagePending  JobItemStage = "pending"
+	JobItemStageRetrying JobItemStage = "retrying"
+	JobItemStageDone     JobItemStage = "done"
+	JobItemStageDead     JobItemStage = "dead"
+)
+
+func (e *JobItemStage) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = JobItemStage(s)
+	case string:
+		*e = JobItemStage(s)
+	default:
+		return fmt.Errorf("unsupported scan type for JobItemStage: %T", src)
+	}
+	return nil
+}
+
+type NullJobItemStage struct {
+	JobItemStage JobItemStage `json:"job_item_stage"`
+	Valid        bool         `json:"valid"` // Valid is true if JobItemStage is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullJobItemStage) Scan(value interface{}) error {
+	if value == nil {
+		ns.JobItemStage, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.JobItemStage.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullJobItemStage) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.JobItemStage), nil
+}
+
 type JobStatus string
 
 const (
@@ -190,9 +234,11 @@ func (ns NullRole) Value() (driver.Value, error) {
 type Sentiment string
 
 const (
-	SentimentPositive Sentiment = "positive"
-	SentimentNeutral  Sentiment = "neutral"
-	SentimentNegative Sentiment = "negative"
+	SentimentVeryPositive Sentiment = "very_positive"
+	SentimentPositive     Sentiment = "positive"
+	SentimentNeutral      Sentiment = "neutral"
+	SentimentNegative     Sentiment = "negative"
+	SentimentVeryNegative Sentiment = "very_negative"
 )
 
 func (e *Sentiment) Scan(src interface{}) error {
@@ -230,6 +276,64 @@ func (ns NullSentiment) Value() (driver.Value, error) {
 	return string(ns.Sentiment), nil
 }
 
+type WebhookDeliveryStage string
+
+const (
+	WebhookDeliveryStagePending   WebhookDeliveryStage = "pending"
+	WebhookDeliveryStageRetrying  WebhookDeliveryStage = "retrying"
+	WebhookDeliveryStageDelivered WebhookDeliveryStage = "delivered"
+	WebhookDeliveryStageFailed    WebhookDeliveryStage = "failed"
+)
+
+func (e *WebhookDeliveryStage) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = WebhookDeliveryStage(s)
+	case string:
+		*e = WebhookDeliveryStage(s)
+	default:
+		return fmt.Errorf("unsupported scan type for WebhookDeliveryStage: %T", src)
+	}
+	return nil
+}
+
+type NullWebhookDeliveryStage struct {
+	WebhookDeliveryStage WebhookDeliveryStage `json:"webhook_delivery_stage"`
+	Valid                bool                 `json:"valid"` // Valid is true if WebhookDeliveryStage is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullWebhookDeliveryStage) Scan(value interface{}) error {
+	if value == nil {
+		ns.WebhookDeliveryStage, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.WebhookDeliveryStage.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullWebhookDeliveryStage) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.WebhookDeliveryStage), nil
+}
+
+type Annotation struct {
+	ID         int64              `json:"id"`
+	NewsID     int64              `json:"news_id"`
+	Task       string             `json:"task"`
+	Label      string             `json:"label"`
+	Model      string             `json:"model"`
+	Confidence pgtype.Float4      `json:"confidence"`
+	Rationale  pgtype.Text        `json:"rationale"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt  pgtype.Timestamptz `json:"updated_at"`
+	Owner      uuid.UUID          `json:"owner"`
+	TaskHash   pgtype.Text        `json:"task_hash"`
+}
+
 type Api struct {
 	ID          int16              `json:"id"`
 	Name        string             `json:"name"`
@@ -243,24 +347,48 @@ type Api struct {
 }
 
 type Apikey struct {
-	ID        int32              `json:"id"`
-	Owner     uuid.UUID          `json:"owner"`
-	ApiID     int16              `json:"api_id"`
-	Key       string             `json:"key"`
-	CreatedAt pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
-	DeletedAt pgtype.Timestamptz `json:"deleted_at"`
+	ID         int32              `json:"id"`
+	Owner      uuid.UUID          `json:"owner"`
+	ApiID      int16              `json:"api_id"`
+	Key        string             `json:"key"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt  pgtype.Timestamptz `json:"updated_at"`
+	DeletedAt  pgtype.Timestamptz `json:"deleted_at"`
+	BaseUrl    pgtype.Text        `json:"base_url"`
+	Models     []string           `json:"models"`
+	AuthHeader pgtype.Text        `json:"auth_header"`
 }
 
 type Embedding struct {
-	ID        int64              `json:"id"`
-	Model     string             `json:"model"`
-	NewsID    int64              `json:"news_id"`
-	Embedding pgv.Vector         `json:"embedding"`
-	Sentiment Sentiment          `json:"sentiment"`
+	ID         int64              `json:"id"`
+	Model      string             `json:"model"`
+	NewsID     int64              `json:"news_id"`
+	Embedding  pgv.Vector         `json:"embedding"`
+	Sentiment  Sentiment          `json:"sentiment"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt  pgtype.Timestamptz `json:"updated_at"`
+	DeletedAt  pgtype.Timestamptz `json:"deleted_at"`
+	Score      pgtype.Int2        `json:"score"`
+	Confidence pgtype.Float4      `json:"confidence"`
+	Rationale  pgtype.Text        `json:"rationale"`
+	PromptID   pgtype.Int8        `json:"prompt_id"`
+}
+
+type EmbeddingChunk struct {
+	NewsID     int64              `json:"news_id"`
+	Model      string             `json:"model"`
+	ChunkIndex int16              `json:"chunk_index"`
+	NTokens    int32              `json:"n_tokens"`
+	Embedding  pgv.Vector         `json:"embedding"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+}
+
+type EmbeddingModel struct {
+	Name      string             `json:"name"`
+	Provider  string             `json:"provider"`
+	Dim       int32              `json:"dim"`
+	Metric    string             `json:"metric"`
 	CreatedAt pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
-	DeletedAt pgtype.Timestamptz `json:"deleted_at"`
 }
 
 type Endpoint struct {
@@ -273,18 +401,82 @@ type Endpoint struct {
 	DeletedAt    pgtype.Timestamptz `json:"deleted_at"`
 }
 
+type EntitySentiment struct {
+	ID         int64              `json:"id"`
+	NewsID     int64              `json:"news_id"`
+	Entity     string             `json:"entity"`
+	Model      string             `json:"model"`
+	Sentiment  Sentiment          `json:"sentiment"`
+	Score      int16              `json:"score"`
+	Confidence pgtype.Float4      `json:"confidence"`
+	Rationale  pgtype.Text        `json:"rationale"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt  pgtype.Timestamptz `json:"updated_at"`
+	Owner      uuid.UUID          `json:"owner"`
+	PromptID   pgtype.Int8        `json:"prompt_id"`
+}
+
 type Job struct {
-	ID        int64              `json:"id"`
-	Ulid      string             `json:"ulid"`
-	Owner     uuid.UUID          `json:"owner"`
-	Status    JobStatus          `json:"status"`
-	SrcApiID  int16              `json:"src_api_id"`
-	SrcQuery  string             `json:"src_query"`
-	LlmApiID  int16              `json:"llm_api_id"`
-	LlmQuery  []byte             `json:"llm_query"`
-	CreatedAt pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
-	DeletedAt pgtype.Timestamptz `json:"deleted_at"`
+	ID         int64              `json:"id"`
+	Ulid       string             `json:"ulid"`
+	Owner      uuid.UUID          `json:"owner"`
+	Status     JobStatus          `json:"status"`
+	SrcApiID   int16              `json:"src_api_id"`
+	SrcQuery   string             `json:"src_query"`
+	LlmApiID   int16              `json:"llm_api_id"`
+	LlmQuery   []byte             `json:"llm_query"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt  pgtype.Timestamptz `json:"updated_at"`
+	DeletedAt  pgtype.Timestamptz `json:"deleted_at"`
+	Partial    bool               `json:"partial"`
+	ParentID   pgtype.Int8        `json:"parent_id"`
+	ClonedFrom pgtype.Int8        `json:"cloned_from"`
+}
+
+type JobItem struct {
+	ID            int64              `json:"id"`
+	JobID         int64              `json:"job_id"`
+	NewsID        int64              `json:"news_id"`
+	Stage         JobItemStage       `json:"stage"`
+	Attempts      int32              `json:"attempts"`
+	LastError     pgtype.Text        `json:"last_error"`
+	NextAttemptAt pgtype.Timestamptz `json:"next_attempt_at"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt     pgtype.Timestamptz `json:"updated_at"`
+	EmbeddingID   pgtype.Int8        `json:"embedding_id"`
+}
+
+type JobProgress struct {
+	JobID           int64              `json:"job_id"`
+	Total           int32              `json:"total"`
+	Parsed          int32              `json:"parsed"`
+	LangDetected    int32              `json:"lang_detected"`
+	Embedded        int32              `json:"embedded"`
+	SentimentScored int32              `json:"sentiment_scored"`
+	Failed          int32              `json:"failed"`
+	UpdatedAt       pgtype.Timestamptz `json:"updated_at"`
+}
+
+type JobSchedule struct {
+	JobID         int64              `json:"job_id"`
+	CronExpr      string             `json:"cron_expr"`
+	SrcCacheQuery []byte             `json:"src_cache_query"`
+	NextRunAt     pgtype.Timestamptz `json:"next_run_at"`
+	LastRunAt     pgtype.Timestamptz `json:"last_run_at"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt     pgtype.Timestamptz `json:"updated_at"`
+}
+
+type JobUsage struct {
+	ID           int64              `json:"id"`
+	JobID        int64              `json:"job_id"`
+	Provider     string             `json:"provider"`
+	Model        string             `json:"model"`
+	Operation    string             `json:"operation"`
+	InputTokens  int32              `json:"input_tokens"`
+	OutputTokens int32              `json:"output_tokens"`
+	Cost         pgtype.Float8      `json:"cost"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
 }
 
 type Keyword struct {
@@ -293,6 +485,17 @@ type Keyword struct {
 	Keyword string `json:"keyword"`
 }
 
+type LlmResponse struct {
+	Provider  string             `json:"provider"`
+	Model     string             `json:"model"`
+	Operation string             `json:"operation"`
+	PromptID  int64              `json:"prompt_id"`
+	InputHash string             `json:"input_hash"`
+	Response  []byte             `json:"response"`
+	ExpiresAt pgtype.Timestamptz `json:"expires_at"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
 type Log struct {
 	ID        int64              `json:"id"`
 	UserID    uuid.UUID          `json:"user_id"`
@@ -324,11 +527,39 @@ type Newsjob struct {
 	NewsID int64 `json:"news_id"`
 }
 
+type Prompt struct {
+	ID        int64              `json:"id"`
+	Owner     uuid.UUID          `json:"owner"`
+	Provider  string             `json:"provider"`
+	Task      string             `json:"task"`
+	Version   int32              `json:"version"`
+	Body      string             `json:"body"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
 type SchemaMigration struct {
 	Version int64 `json:"version"`
 	Dirty   bool  `json:"dirty"`
 }
 
+type Story struct {
+	ID             int64              `json:"id"`
+	Model          string             `json:"model"`
+	Title          string             `json:"title"`
+	FirstPublishAt pgtype.Timestamptz `json:"first_publish_at"`
+	LastPublishAt  pgtype.Timestamptz `json:"last_publish_at"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt      pgtype.Timestamptz `json:"updated_at"`
+}
+
+type StoryNews struct {
+	StoryID    int64              `json:"story_id"`
+	NewsID     int64              `json:"news_id"`
+	Model      string             `json:"model"`
+	Similarity float32            `json:"similarity"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+}
+
 type User struct {
 	ID                uuid.UUID          `json:"id"`
 	Password          []byte             `json:"password"`
@@ -342,3 +573,32 @@ type User struct {
 	DeletedAt         pgtype.Timestamptz `json:"deleted_at"`
 	PasswordUpdatedAt pgtype.Timestamptz `json:"password_updated_at"`
 }
+
+type Webhook struct {
+	ID        int64              `json:"id"`
+	Owner     uuid.UUID          `json:"owner"`
+	Url       string             `json:"url"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type WebhookDelivery struct {
+	ID            int64                `json:"id"`
+	WebhookID     int64                `json:"webhook_id"`
+	JobID         int64                `json:"job_id"`
+	Status        JobStatus            `json:"status"`
+	Payload       []byte               `json:"payload"`
+	Stage         WebhookDeliveryStage `json:"stage"`
+	Attempts      int32                `json:"attempts"`
+	ResponseCode  pgtype.Int4          `json:"response_code"`
+	LastError     pgtype.Text          `json:"last_error"`
+	NextAttemptAt pgtype.Timestamptz   `json:"next_attempt_at"`
+	CreatedAt     pgtype.Timestamptz   `json:"created_at"`
+	UpdatedAt     pgtype.Timestamptz   `json:"updated_at"`
+}
+
+type WebhookSecret struct {
+	Owner     uuid.UUID          `json:"owner"`
+	Secret    string             `json:"secret"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
@@ -286,22 +286,27 @@ func (q *Queries) GetNewsByMD5Hash(ctx context.Context, md5Hash string) (*GetNew
 
 const getNewsByMD5Hashs = `-- name: GetNewsByMD5Hashs :many
 
-SELECT id FROM news WHERE md5_hash = ANY($1:: string [])
+SELECT id, md5_hash FROM news WHERE md5_hash = ANY($1:: text [])
 `
 
-func (q *Queries) GetNewsByMD5Hashs(ctx context.Context, md5Hash []string) ([]int64, error) {
+type GetNewsByMD5HashsRow struct {
+	ID      int64  `json:"id"`
+	Md5Hash string `json:"md5_hash"`
+}
+
+func (q *Queries) GetNewsByMD5Hashs(ctx context.Context, md5Hash []string) ([]*GetNewsByMD5HashsRow, error) {
 	rows, err := q.db.Query(ctx, getNewsByMD5Hashs, md5Hash)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []int64
+	var items []*GetNewsByMD5HashsRow
 	for rows.Next() {
-		var id int64
-		if err := rows.Scan(&id); err != nil {
+		var i GetNewsByMD5HashsRow
+		if err := rows.Scan(&i.ID, &i.Md5Hash); err != nil {
 			return nil, err
 		}
-		items = append(items, id)
+		items = append(items, &i)
 	}
 	if err := rows.Err(); err != nil {
 		return nil, err
@@ -7,7 +7,31 @@ package model
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const cloneNewsJobs = `-- name: CloneNewsJobs :execrows
+INSERT INTO newsjobs (
+    job_id, news_id
 )
+SELECT $1:: bigint, news_id
+FROM newsjobs
+WHERE job_id = $2:: bigint
+`
+
+type CloneNewsJobsParams struct {
+	ToJobID   int64 `json:"to_job_id"`
+	FromJobID int64 `json:"from_job_id"`
+}
+
+func (q *Queries) CloneNewsJobs(ctx context.Context, arg *CloneNewsJobsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, cloneNewsJobs, arg.ToJobID, arg.FromJobID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
 
 const createNewsJob = `-- name: CreateNewsJob :one
 INSERT INTO newsjobs (
@@ -29,3 +53,50 @@ func (q *Queries) CreateNewsJob(ctx context.Context, arg *CreateNewsJobParams) (
 	err := row.Scan(&id)
 	return id, err
 }
+
+const getNewsByJobId = `-- name: GetNewsByJobId :many
+SELECT
+    n.id,
+    n.title,
+    n.description,
+    n.content,
+    n.language
+FROM news AS n
+    INNER JOIN newsjobs AS nj ON n.id = nj.news_id
+WHERE nj.job_id = $1
+ORDER BY n.id
+`
+
+type GetNewsByJobIdRow struct {
+	ID          int64       `json:"id"`
+	Title       string      `json:"title"`
+	Description string      `json:"description"`
+	Content     []string    `json:"content"`
+	Language    pgtype.Text `json:"language"`
+}
+
+func (q *Queries) GetNewsByJobId(ctx context.Context, jobID int64) ([]*GetNewsByJobIdRow, error) {
+	rows, err := q.db.Query(ctx, getNewsByJobId, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*GetNewsByJobIdRow
+	for rows.Next() {
+		var i GetNewsByJobIdRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Description,
+			&i.Content,
+			&i.Language,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -12,10 +12,17 @@ import (
 )
 
 type Querier interface {
+	AddStoryNews(ctx context.Context, arg *AddStoryNewsParams) (int64, error)
+	ClaimJobSchedule(ctx context.Context, arg *ClaimJobScheduleParams) (int64, error)
+	ClaimWebhookDelivery(ctx context.Context, arg *ClaimWebhookDeliveryParams) (int64, error)
 	CleanUpAPIKey(ctx context.Context) (int64, error)
 	CleanUpAPIs(ctx context.Context) (int64, error)
 	CleanUpJobs(ctx context.Context) (int64, error)
+	CleanUpLLMResponses(ctx context.Context) (int64, error)
 	CleanUpUsers(ctx context.Context) (int64, error)
+	CloneJob(ctx context.Context, arg *CloneJobParams) (int64, error)
+	CloneNewsJobs(ctx context.Context, arg *CloneNewsJobsParams) (int64, error)
+	CompareJobSentiments(ctx context.Context, arg *CompareJobSentimentsParams) ([]*CompareJobSentimentsRow, error)
 	CountEndpoint(ctx context.Context) (int64, error)
 	CountJob(ctx context.Context, owner uuid.UUID) ([]*CountJobRow, error)
 	CreateAPI(ctx context.Context, arg *CreateAPIParams) (int16, error)
@@ -23,53 +30,110 @@ type Querier interface {
 	CreateEmbedding(ctx context.Context, arg *CreateEmbeddingParams) (int64, error)
 	CreateEndpoint(ctx context.Context, arg *CreateEndpointParams) (int32, error)
 	CreateJob(ctx context.Context, arg *CreateJobParams) (int64, error)
+	CreateJobItems(ctx context.Context, jobID int64) (int64, error)
+	CreateJobProgress(ctx context.Context, arg *CreateJobProgressParams) error
+	CreateJobSchedule(ctx context.Context, arg *CreateJobScheduleParams) error
+	CreateJobUsage(ctx context.Context, arg *CreateJobUsageParams) (int64, error)
 	CreateKeyword(ctx context.Context, arg *CreateKeywordParams) (int64, error)
 	CreateLog(ctx context.Context, arg *CreateLogParams) (int64, error)
 	CreateNews(ctx context.Context, arg *CreateNewsParams) (int64, error)
 	CreateNewsJob(ctx context.Context, arg *CreateNewsJobParams) (int64, error)
+	CreatePrompt(ctx context.Context, arg *CreatePromptParams) (*CreatePromptRow, error)
+	CreateStory(ctx context.Context, arg *CreateStoryParams) (int64, error)
 	CreateUser(ctx context.Context, arg *CreateUserParams) (uuid.UUID, error)
+	CreateWebhook(ctx context.Context, arg *CreateWebhookParams) (int64, error)
+	CreateWebhookSecret(ctx context.Context, arg *CreateWebhookSecretParams) (int64, error)
 	DeleteAPI(ctx context.Context, id int16) (int64, error)
 	DeleteAPIKey(ctx context.Context, arg *DeleteAPIKeyParams) (int64, error)
+	DeleteEmbeddingChunksFrom(ctx context.Context, arg *DeleteEmbeddingChunksFromParams) (int64, error)
 	DeleteEndpoint(ctx context.Context, id int32) (int64, error)
 	DeleteJob(ctx context.Context, arg *DeleteJobParams) (int64, error)
+	DeleteJobSchedule(ctx context.Context, arg *DeleteJobScheduleParams) (int64, error)
 	DeleteKeyword(ctx context.Context, keyword string) (int64, error)
 	DeleteNews(ctx context.Context, id int64) (int64, error)
 	DeleteNewsPublishBefore(ctx context.Context, beforeTime pgtype.Timestamptz) (int64, error)
 	DeleteUser(ctx context.Context, id uuid.UUID) (int64, error)
+	DeleteWebhook(ctx context.Context, arg *DeleteWebhookParams) (int64, error)
 	GetAPI(ctx context.Context, id int16) (*Api, error)
 	GetAPIKey(ctx context.Context, arg *GetAPIKeyParams) (*GetAPIKeyRow, error)
+	GetAnnotatedNewsIds(ctx context.Context, arg *GetAnnotatedNewsIdsParams) ([]int64, error)
 	GetContentById(ctx context.Context, ids []int32) ([]*GetContentByIdRow, error)
-	GetEmbeddingByJobId(ctx context.Context, jobID int64) ([]*GetEmbeddingByJobIdRow, error)
+	GetDueJobItems(ctx context.Context, jobID int64) ([]*GetDueJobItemsRow, error)
+	GetDueJobSchedules(ctx context.Context, limit int32) ([]*GetDueJobSchedulesRow, error)
+	GetDueWebhookDeliveries(ctx context.Context, limit int32) ([]*GetDueWebhookDeliveriesRow, error)
+	GetEmbeddingByJobId(ctx context.Context, arg *GetEmbeddingByJobIdParams) ([]*GetEmbeddingByJobIdRow, error)
 	GetEmbeddingByNewsIdsAndModel(ctx context.Context, arg *GetEmbeddingByNewsIdsAndModelParams) ([]*GetEmbeddingByNewsIdsAndModelRow, error)
+	GetEmbeddingModel(ctx context.Context, name string) (*GetEmbeddingModelRow, error)
+	GetJobAnnotationStats(ctx context.Context, arg *GetJobAnnotationStatsParams) ([]*GetJobAnnotationStatsRow, error)
 	GetJobByOwnerFilterByJIdAndStatus(ctx context.Context, arg *GetJobByOwnerFilterByJIdAndStatusParams) ([]*GetJobByOwnerFilterByJIdAndStatusRow, error)
 	GetJobByOwnerFilterByJIdRange(ctx context.Context, arg *GetJobByOwnerFilterByJIdRangeParams) ([]*GetJobByOwnerFilterByJIdRangeRow, error)
 	GetJobByOwnerFilterByJIds(ctx context.Context, arg *GetJobByOwnerFilterByJIdsParams) ([]*GetJobByOwnerFilterByJIdsRow, error)
+	GetJobEntitySentimentStats(ctx context.Context, arg *GetJobEntitySentimentStatsParams) ([]*GetJobEntitySentimentStatsRow, error)
+	GetJobItemsByStage(ctx context.Context, arg *GetJobItemsByStageParams) ([]*GetJobItemsByStageRow, error)
+	GetJobProgress(ctx context.Context, arg *GetJobProgressParams) (*GetJobProgressRow, error)
+	GetJobQuotaUsage(ctx context.Context, arg *GetJobQuotaUsageParams) (*GetJobQuotaUsageRow, error)
+	GetJobSchedule(ctx context.Context, arg *GetJobScheduleParams) (*GetJobScheduleRow, error)
+	GetJobSentimentStats(ctx context.Context, arg *GetJobSentimentStatsParams) ([]*GetJobSentimentStatsRow, error)
+	GetJobUsage(ctx context.Context, arg *GetJobUsageParams) ([]*GetJobUsageRow, error)
 	GetJobsByJobId(ctx context.Context, arg *GetJobsByJobIdParams) (*GetJobsByJobIdRow, error)
 	GetJobsByOwner(ctx context.Context, arg *GetJobsByOwnerParams) ([]*GetJobsByOwnerRow, error)
 	GetJobsByOwnerFilterByStatus(ctx context.Context, arg *GetJobsByOwnerFilterByStatusParams) ([]*GetJobsByOwnerFilterByStatusRow, error)
 	GetKeywordsByNewsId(ctx context.Context, newsID []int32) ([]string, error)
+	GetLLMResponse(ctx context.Context, arg *GetLLMResponseParams) ([]byte, error)
 	GetLastJobId(ctx context.Context, owner uuid.UUID) ([]*GetLastJobIdRow, error)
 	GetLogByUserId(ctx context.Context, arg *GetLogByUserIdParams) ([]*Log, error)
 	GetLogByUserIdNext(ctx context.Context, arg *GetLogByUserIdNextParams) ([]*Log, error)
+	GetNearestNewsIds(ctx context.Context, arg *GetNearestNewsIdsParams) ([]int64, error)
+	GetNearestNewsIds1024(ctx context.Context, arg *GetNearestNewsIds1024Params) ([]int64, error)
+	GetNearestNewsIds1536(ctx context.Context, arg *GetNearestNewsIds1536Params) ([]int64, error)
+	GetNearestNewsIds256(ctx context.Context, arg *GetNearestNewsIds256Params) ([]int64, error)
+	GetNearestNewsIds384(ctx context.Context, arg *GetNearestNewsIds384Params) ([]int64, error)
+	GetNearestStory(ctx context.Context, arg *GetNearestStoryParams) (*GetNearestStoryRow, error)
 	GetNewsByJob(ctx context.Context) ([]*GetNewsByJobRow, error)
+	GetNewsByJobId(ctx context.Context, jobID int64) ([]*GetNewsByJobIdRow, error)
 	GetNewsByKeywords(ctx context.Context, keywords []string) ([]*GetNewsByKeywordsRow, error)
 	GetNewsByMD5Hash(ctx context.Context, md5Hash string) (*GetNewsByMD5HashRow, error)
-	GetNewsByMD5Hashs(ctx context.Context, md5Hash []string) ([]int64, error)
+	GetNewsByMD5Hashs(ctx context.Context, md5Hash []string) ([]*GetNewsByMD5HashsRow, error)
 	GetNewsPublishBetween(ctx context.Context, arg *GetNewsPublishBetweenParams) ([]*GetNewsPublishBetweenRow, error)
+	GetNextJobItemAttempt(ctx context.Context, jobID int64) (pgtype.Timestamptz, error)
 	GetOldestNCreatedJobsForEachUser(ctx context.Context, n int32) ([]*GetOldestNCreatedJobsForEachUserRow, error)
+	GetPrompt(ctx context.Context, arg *GetPromptParams) (*Prompt, error)
+	GetStory(ctx context.Context, id int64) (*Story, error)
+	GetStoryNews(ctx context.Context, storyID int64) ([]*GetStoryNewsRow, error)
+	GetUnclusteredNews(ctx context.Context, n int32) ([]*GetUnclusteredNewsRow, error)
 	GetUserAuth(ctx context.Context, email string) (*GetUserAuthRow, error)
+	GetWebhookSecret(ctx context.Context, owner uuid.UUID) (*GetWebhookSecretRow, error)
 	HardDeleteUser(ctx context.Context, id uuid.UUID) (int64, error)
+	IncrJobProgress(ctx context.Context, arg *IncrJobProgressParams) error
 	ListAPI(ctx context.Context, n int32) ([]*ListAPIRow, error)
 	ListAPIByType(ctx context.Context, apitype ApiType) ([]*ListAPIByTypeRow, error)
 	ListAPIKey(ctx context.Context, owner uuid.UUID) ([]*ListAPIKeyRow, error)
 	ListAllEndpoint(ctx context.Context, arg *ListAllEndpointParams) ([]*ListAllEndpointRow, error)
 	ListEndpointByOwner(ctx context.Context, owner uuid.UUID) ([]*ListEndpointByOwnerRow, error)
+	ListPrompts(ctx context.Context, owner uuid.UUID) ([]*ListPromptsRow, error)
 	ListRecentNNews(ctx context.Context, n int32) ([]*ListRecentNNewsRow, error)
+	ListStories(ctx context.Context, arg *ListStoriesParams) ([]*ListStoriesRow, error)
+	ListWebhookDeliveries(ctx context.Context, arg *ListWebhookDeliveriesParams) ([]*ListWebhookDeliveriesRow, error)
+	ListWebhooks(ctx context.Context, owner uuid.UUID) ([]*ListWebhooksRow, error)
+	LockUser(ctx context.Context, id uuid.UUID) (uuid.UUID, error)
+	MarkJobItemDone(ctx context.Context, arg *MarkJobItemDoneParams) (int64, error)
+	MarkJobItemFailed(ctx context.Context, arg *MarkJobItemFailedParams) (int64, error)
+	MarkJobPartial(ctx context.Context, arg *MarkJobPartialParams) (int64, error)
+	MarkWebhookDeliveryDelivered(ctx context.Context, arg *MarkWebhookDeliveryDeliveredParams) (int64, error)
+	MarkWebhookDeliveryFailed(ctx context.Context, arg *MarkWebhookDeliveryFailedParams) (int64, error)
+	RequeueJobItems(ctx context.Context, arg *RequeueJobItemsParams) (int64, error)
+	RotateWebhookSecret(ctx context.Context, arg *RotateWebhookSecretParams) (int64, error)
 	UpdateAPI(ctx context.Context, arg *UpdateAPIParams) (int64, error)
 	UpdateAPIKey(ctx context.Context, arg *UpdateAPIKeyParams) (int64, error)
 	UpdateJobByULID(ctx context.Context, arg *UpdateJobByULIDParams) (int64, error)
+	UpdateJobSchedule(ctx context.Context, arg *UpdateJobScheduleParams) (int64, error)
 	UpdateJobStatus(ctx context.Context, arg *UpdateJobStatusParams) (int64, error)
+	UpdateJobStatusFrom(ctx context.Context, arg *UpdateJobStatusFromParams) (int64, error)
 	UpdatePassword(ctx context.Context, arg *UpdatePasswordParams) (int64, error)
+	UpsertAnnotation(ctx context.Context, arg *UpsertAnnotationParams) (int64, error)
+	UpsertEmbeddingChunk(ctx context.Context, arg *UpsertEmbeddingChunkParams) (int64, error)
+	UpsertEntitySentiment(ctx context.Context, arg *UpsertEntitySentimentParams) (int64, error)
+	UpsertLLMResponse(ctx context.Context, arg *UpsertLLMResponseParams) (int64, error)
 }
 
 var _ Querier = (*Queries)(nil)
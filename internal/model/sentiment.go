@@ -0,0 +1,32 @@
+package model
+
+import "fmt"
+
+// Sentiments in the order of the 1-5 scale, from very negative to very positive.
+var Sentiments = []Sentiment{
+	SentimentVeryNegative,
+	SentimentNegative,
+	SentimentNeutral,
+	SentimentPositive,
+	SentimentVeryPositive,
+}
+
+// Score returns the score of the sentiment on the 1-5 scale, or 0 if the
+// sentiment is unknown. The 3-level sentiments analyzed before the scale was
+// introduced fall on 2, 3 and 4, the same as sentiment_score in the database.
+func (e Sentiment) Score() int16 {
+	for i, s := range Sentiments {
+		if e == s {
+			return int16(i + 1)
+		}
+	}
+	return 0
+}
+
+// SentimentOfScore returns the sentiment of a score on the 1-5 scale.
+func SentimentOfScore(score int) (Sentiment, error) {
+	if score < 1 || score > len(Sentiments) {
+		return "", fmt.Errorf("unknown sentiment score: %d, expect 1 to %d", score, len(Sentiments))
+	}
+	return Sentiments[score-1], nil
+}
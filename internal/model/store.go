@@ -9,6 +9,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -23,6 +24,9 @@ type Store interface {
 	DoCreateOrUpdateAPIKeyTx(ctx context.Context, params *CreateOrUpdateAPIKeyTxParams) (*CreateOrUpdateAPIKeyTxResults, error)
 	DoCountUserJobTx(ctx context.Context, owner uuid.UUID) (*CountUserJobTxResult, error)
 	DoCacheToStoreTx(ctx context.Context, params *CacheToStoreTXParams) (*CacheToStoreTXResult, error)
+	DoRequeueJobItemsTx(ctx context.Context, params *RequeueJobItemsTxParams) (*RequeueJobItemsTxResult, error)
+	DoCloneJobTx(ctx context.Context, params *CloneJobTxParams) (*CloneJobTxResult, error)
+	DoCreateWebhookTx(ctx context.Context, params *CreateWebhookTxParams) (*CreateWebhookTxResult, error)
 	Close(ctx context.Context) error
 }
 
@@ -36,9 +40,12 @@ type CheckAndUpdateUserPasswordTxParams struct {
 type QueryCallBackFun func(*Queries) error
 
 type CreateOrUpdateAPIKeyTxParams struct {
-	Owner uuid.UUID `json:"owner"`
-	ApiID int16     `json:"api_id"`
-	Key   string    `json:"key"`
+	Owner      uuid.UUID   `json:"owner"`
+	ApiID      int16       `json:"api_id"`
+	Key        string      `json:"key"`
+	BaseUrl    pgtype.Text `json:"base_url"`
+	Models     []string    `json:"models"`
+	AuthHeader pgtype.Text `json:"auth_header"`
 }
 
 type CreateOrUpdateAPIKeyTxResults struct {
@@ -105,6 +112,18 @@ func (s PGXStore) DoCacheToStoreTx(ctx context.Context, params *CacheToStoreTXPa
 	return doCacheToStoreTx(s, ctx, params)
 }
 
+func (s PGXStore) DoRequeueJobItemsTx(ctx context.Context, params *RequeueJobItemsTxParams) (*RequeueJobItemsTxResult, error) {
+	return requeueJobItemsTx(s, ctx, params)
+}
+
+func (s PGXStore) DoCloneJobTx(ctx context.Context, params *CloneJobTxParams) (*CloneJobTxResult, error) {
+	return cloneJobTx(s, ctx, params)
+}
+
+func (s PGXStore) DoCreateWebhookTx(ctx context.Context, params *CreateWebhookTxParams) (*CreateWebhookTxResult, error) {
+	return createWebhookTx(s, ctx, params)
+}
+
 type PGXPoolStore struct {
 	Querier
 	Conn *pgxpool.Pool
@@ -163,6 +182,18 @@ func (s PGXPoolStore) DoCacheToStoreTx(ctx context.Context, params *CacheToStore
 	return doCacheToStoreTx(s, ctx, params)
 }
 
+func (s PGXPoolStore) DoRequeueJobItemsTx(ctx context.Context, params *RequeueJobItemsTxParams) (*RequeueJobItemsTxResult, error) {
+	return requeueJobItemsTx(s, ctx, params)
+}
+
+func (s PGXPoolStore) DoCloneJobTx(ctx context.Context, params *CloneJobTxParams) (*CloneJobTxResult, error) {
+	return cloneJobTx(s, ctx, params)
+}
+
+func (s PGXPoolStore) DoCreateWebhookTx(ctx context.Context, params *CreateWebhookTxParams) (*CreateWebhookTxResult, error) {
+	return createWebhookTx(s, ctx, params)
+}
+
 func checkAndUpdateUserPasswordTx(s Store, ctx context.Context, params *CheckAndUpdateUserPasswordTxParams) error {
 	err := s.ExecTx(ctx, func(q *Queries) error {
 		auth, err := q.GetUserAuth(ctx, params.Email)
@@ -207,19 +238,25 @@ func createOrUpdateAPIKeyTx(s Store, ctx context.Context, params *CreateOrUpdate
 		if err != nil {
 			if errors.Is(err, pgx.ErrNoRows) {
 				result.ApiKeyId, err = q.CreateAPIKey(ctx, &CreateAPIKeyParams{
-					Owner: params.Owner,
-					ApiID: params.ApiID,
-					Key:   params.Key,
+					Owner:      params.Owner,
+					ApiID:      params.ApiID,
+					Key:        params.Key,
+					BaseUrl:    params.BaseUrl,
+					Models:     params.Models,
+					AuthHeader: params.AuthHeader,
 				})
 				result.N = 1
 			}
 		} else {
 			result.ApiKeyId = apikey.ID
 			result.N, err = q.UpdateAPIKey(ctx, &UpdateAPIKeyParams{
-				Owner:    params.Owner,
-				Key:      params.Key,
-				OldApiID: params.ApiID,
-				NewApiID: params.ApiID,
+				Owner:      params.Owner,
+				Key:        params.Key,
+				OldApiID:   params.ApiID,
+				BaseUrl:    params.BaseUrl,
+				Models:     params.Models,
+				AuthHeader: params.AuthHeader,
+				NewApiID:   params.ApiID,
 			})
 		}
 		return err
@@ -248,8 +285,29 @@ func createNewsTx(s Store, ctx context.Context, params *CreateNewsParams) (int64
 }
 
 type CacheToStoreTXParams struct {
-	CreateJobParams  *CreateJobParams
-	CreateNewsParams <-chan *CreateNewsParams
+	CreateJobParams *CreateJobParams
+	// nil if the job is not scheduled, JobID will be set to the created job
+	CreateJobScheduleParams *CreateJobScheduleParams
+	CreateNewsParams        <-chan *CreateNewsParams
+	// nil if the job is not counted in the quota of the owner
+	CheckQuota QuotaCheckFun
+	QuotaSince pgtype.Timestamptz
+}
+
+// QuotaCheckFun returns an error if a new job with nArticles articles exceeds
+// the quota of the owner, given the usage before the job.
+type QuotaCheckFun func(usage *GetJobQuotaUsageRow, nArticles int64) error
+
+// quotaUsageTx locks the owner until the end of the transaction, so that the
+// jobs of the owner are counted and created one transaction after another.
+func quotaUsageTx(q Querier, ctx context.Context, owner uuid.UUID, since pgtype.Timestamptz) (*GetJobQuotaUsageRow, error) {
+	if _, err := q.LockUser(ctx, owner); err != nil {
+		return nil, err
+	}
+	return q.GetJobQuotaUsage(ctx, &GetJobQuotaUsageParams{
+		Since: since,
+		Owner: owner,
+	})
 }
 
 type CacheToStoreTXResult struct {
@@ -277,7 +335,7 @@ type NewsJobCreateResult struct {
 	Error     *ec.Error `json:"error"`
 }
 
-func cacheToStoreTx(s Store, ctx context.Context, params *CacheToStoreTXParams) *CacheToStoreTXResult {
+func cacheToStoreTx(s Querier, ctx context.Context, params *CacheToStoreTXParams) *CacheToStoreTXResult {
 	var err error
 
 	result := &CacheToStoreTXResult{}
@@ -296,6 +354,23 @@ func cacheToStoreTx(s Store, ctx context.Context, params *CacheToStoreTXParams)
 		return result
 	}
 
+	if params.CreateJobScheduleParams != nil {
+		params.CreateJobScheduleParams.JobID = result.JobId
+		if err = s.CreateJobSchedule(ctx, params.CreateJobScheduleParams); err != nil {
+			var pgErr *pgconn.PgError
+			var ecErr *ec.Error
+			if errors.As(err, &pgErr) {
+				ecErr = ec.NewErrorFromPgErr(pgErr)
+			} else {
+				ecErr = ec.MustGetEcErr(ec.ECServerError).
+					WithMessage(err.Error())
+			}
+			ecErr.WithDetails("error while CreateJobSchedule")
+			result.JobCreateError = ecErr
+			return result
+		}
+	}
+
 	result.NewsJobCreateResults = []NewsJobCreateResult{}
 	for param := range params.CreateNewsParams {
 		row, err := s.GetNewsByMD5Hash(ctx, param.Md5Hash)
@@ -349,8 +424,24 @@ func cacheToStoreTx(s Store, ctx context.Context, params *CacheToStoreTXParams)
 func doCacheToStoreTx(s Store, ctx context.Context, params *CacheToStoreTXParams) (*CacheToStoreTXResult, error) {
 	var result *CacheToStoreTXResult
 	err := s.ExecTx(ctx, func(q *Queries) error {
-		result = cacheToStoreTx(s, ctx, params)
-		return result.Error()
+		var usage *GetJobQuotaUsageRow
+		if params.CheckQuota != nil {
+			var err error
+			usage, err = quotaUsageTx(q, ctx, params.CreateJobParams.Owner, params.QuotaSince)
+			if err != nil {
+				return err
+			}
+		}
+
+		result = cacheToStoreTx(q, ctx, params)
+		if err := result.Error(); err != nil {
+			return err
+		}
+
+		if params.CheckQuota != nil {
+			return params.CheckQuota(usage, int64(len(result.NewsJobCreateResults)))
+		}
+		return nil
 	})
 	return result, err
 }
@@ -418,3 +509,170 @@ func countUserJobTx(s Store, ctx context.Context, owner uuid.UUID) (*CountUserJo
 
 	return result, nil
 }
+
+type RequeueJobItemsTxParams struct {
+	JobID  int64     `json:"job_id"`
+	Owner  uuid.UUID `json:"owner"`
+	ItemID int64     `json:"item_id"` // 0 for all dead items of the job
+}
+
+type RequeueJobItemsTxResult struct {
+	N      int64     `json:"n"`
+	Status JobStatus `json:"status"`
+}
+
+// requeueJobItemsTx puts dead items back to pending and the job back to the
+// queue, a canceled job can not be requeued.
+func requeueJobItemsTx(s Store, ctx context.Context, params *RequeueJobItemsTxParams) (*RequeueJobItemsTxResult, error) {
+	result := &RequeueJobItemsTxResult{}
+	err := s.ExecTx(ctx, func(q *Queries) error {
+		job, err := q.GetJobsByJobId(ctx, &GetJobsByJobIdParams{
+			Owner: params.Owner,
+			ID:    params.JobID,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Status = job.Status
+		if job.Status == JobStatusCanceled {
+			return ec.MustGetEcErr(ec.ECConflict).
+				WithDetails("canceled job can not be requeued")
+		}
+
+		result.N, err = q.RequeueJobItems(ctx, &RequeueJobItemsParams{
+			JobID:  params.JobID,
+			ItemID: params.ItemID,
+		})
+		if err != nil || result.N == 0 {
+			return err
+		}
+
+		// requeued items are no longer counted as failed
+		err = q.IncrJobProgress(ctx, &IncrJobProgressParams{
+			JobID:  params.JobID,
+			Failed: -int32(result.N),
+		})
+		if err != nil {
+			return err
+		}
+
+		// a running job is put back as well, so that items requeued after
+		// the runner has fetched the due items will not be left behind
+		n, err := q.UpdateJobStatusFrom(ctx, &UpdateJobStatusFromParams{
+			ToStatus: JobStatusCreated,
+			ID:       params.JobID,
+			Owner:    params.Owner,
+			FromStatus: []string{
+				string(JobStatusRunning),
+				string(JobStatusDone),
+				string(JobStatusFailed),
+			},
+		})
+		if n > 0 {
+			result.Status = JobStatusCreated
+		}
+		return err
+	})
+	return result, err
+}
+
+type CloneJobTxParams struct {
+	Owner    uuid.UUID `json:"owner"`
+	ID       int64     `json:"id"`
+	Ulid     string    `json:"ulid"`
+	LlmApiID int16     `json:"llm_api_id"`
+	LlmQuery []byte    `json:"llm_query"`
+	// nil if the job is not counted in the quota of the owner
+	CheckQuota QuotaCheckFun      `json:"-"`
+	QuotaSince pgtype.Timestamptz `json:"-"`
+}
+
+type CloneJobTxResult struct {
+	JobId int64 `json:"job_id"`
+	NNews int64 `json:"n_news"`
+}
+
+// cloneJobTx creates a job with the news of the given job and a new analyzer.
+// The news have been parsed, so the progress of the clone starts there.
+func cloneJobTx(s Store, ctx context.Context, params *CloneJobTxParams) (*CloneJobTxResult, error) {
+	result := &CloneJobTxResult{}
+	err := s.ExecTx(ctx, func(q *Queries) error {
+		var err error
+		var usage *GetJobQuotaUsageRow
+		if params.CheckQuota != nil {
+			usage, err = quotaUsageTx(q, ctx, params.Owner, params.QuotaSince)
+			if err != nil {
+				return err
+			}
+		}
+
+		result.JobId, err = q.CloneJob(ctx, &CloneJobParams{
+			Ulid:     params.Ulid,
+			LlmApiID: params.LlmApiID,
+			LlmQuery: params.LlmQuery,
+			Owner:    params.Owner,
+			ID:       params.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.NNews, err = q.CloneNewsJobs(ctx, &CloneNewsJobsParams{
+			ToJobID:   result.JobId,
+			FromJobID: params.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		if params.CheckQuota != nil {
+			if err = params.CheckQuota(usage, result.NNews); err != nil {
+				return err
+			}
+		}
+
+		return q.CreateJobProgress(ctx, &CreateJobProgressParams{
+			JobID:        result.JobId,
+			Total:        int32(result.NNews),
+			Parsed:       int32(result.NNews),
+			LangDetected: int32(result.NNews),
+		})
+	})
+	return result, err
+}
+
+type CreateWebhookTxParams struct {
+	Owner  uuid.UUID `json:"owner"`
+	Url    string    `json:"url"`
+	Secret string    `json:"secret"`
+}
+
+type CreateWebhookTxResult struct {
+	ID        int64 `json:"id"`
+	NewSecret bool  `json:"new_secret"`
+}
+
+// createWebhookTx registers a webhook and gives the owner a signing secret if
+// they do not have one yet. An existing secret is kept.
+func createWebhookTx(s Store, ctx context.Context, params *CreateWebhookTxParams) (*CreateWebhookTxResult, error) {
+	result := &CreateWebhookTxResult{}
+	err := s.ExecTx(ctx, func(q *Queries) error {
+		var err error
+		result.ID, err = q.CreateWebhook(ctx, &CreateWebhookParams{
+			Owner: params.Owner,
+			Url:   params.Url,
+		})
+		if err != nil {
+			return err
+		}
+
+		n, err := q.CreateWebhookSecret(ctx, &CreateWebhookSecretParams{
+			Owner:  params.Owner,
+			Secret: params.Secret,
+		})
+		result.NewSecret = n > 0
+		return err
+	})
+	return result, err
+}
@@ -106,6 +106,19 @@ func (q *Queries) HardDeleteUser(ctx context.Context, id uuid.UUID) (int64, erro
 	return result.RowsAffected(), nil
 }
 
+const lockUser = `-- name: LockUser :one
+SELECT id FROM users
+ WHERE id = $1
+   AND deleted_at IS NULL
+   FOR UPDATE
+`
+
+func (q *Queries) LockUser(ctx context.Context, id uuid.UUID) (uuid.UUID, error) {
+	row := q.db.QueryRow(ctx, lockUser, id)
+	err := row.Scan(&id)
+	return id, err
+}
+
 const updatePassword = `-- name: UpdatePassword :execrows
 UPDATE users
    SET password = $1,
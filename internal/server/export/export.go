@@ -0,0 +1,258 @@
+package export
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/model"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/pkgs/npy"
+)
+
+var ErrUnknownFormat = errors.New("unknown export format")
+var ErrRowsChanged = errors.New("rows have changed during export")
+
+type Format string
+
+const (
+	FormatCSV   Format = "csv"
+	FormatJSONL Format = "jsonl"
+	FormatZip   Format = "zip"
+)
+
+// ParseFormat parses the format of an export, csv by default.
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(strings.TrimSpace(s))); f {
+	case "":
+		return FormatCSV, nil
+	case FormatCSV, FormatJSONL, FormatZip:
+		return f, nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrUnknownFormat, s)
+}
+
+func (f Format) ContentType() string {
+	switch f {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatJSONL:
+		return "application/x-ndjson"
+	case FormatZip:
+		return "application/zip"
+	}
+	return "application/octet-stream"
+}
+
+const (
+	ZipIndexFile     = "index.csv"
+	ZipEmbeddingFile = "embeddings.npy"
+)
+
+// FetchFunc returns the rows of a job whose id is greater than next, in
+// ascending id order. An empty page ends the export.
+type FetchFunc func(ctx context.Context, next int64) ([]*model.GetEmbeddingByJobIdRow, error)
+
+// Row is a news item of a job in an export.
+type Row struct {
+	NewsID    int64  `json:"news_id"`
+	Title     string `json:"title"`
+	Link      string `json:"link"`
+	Source    string `json:"source"`
+	PublishAt string `json:"publish_at"`
+	Language  string `json:"language"`
+	Sentiment string `json:"sentiment"`
+	Score     int16  `json:"score"`
+	// nil if the model does not give one
+	Confidence *float32 `json:"confidence"`
+	Rationale  string   `json:"rationale"`
+	Model      string   `json:"model"`
+}
+
+var Header = []string{
+	"news_id", "title", "link", "source", "publish_at", "language",
+	"sentiment", "score", "confidence", "rationale", "model",
+}
+
+func NewRow(r *model.GetEmbeddingByJobIdRow) Row {
+	row := Row{
+		NewsID:    r.NewsID,
+		Title:     r.Title,
+		Link:      r.Link,
+		Source:    r.Source,
+		PublishAt: r.PublishAt.Time.UTC().Format(time.RFC3339),
+		Language:  r.Language.String,
+		Sentiment: string(r.Sentiment),
+		Score:     r.Score,
+		Rationale: r.Rationale.String,
+		Model:     r.Model,
+	}
+	if r.Confidence.Valid {
+		row.Confidence = &r.Confidence.Float32
+	}
+	return row
+}
+
+// Record returns the fields of the row in the order of Header, the confidence
+// is empty if there is none.
+func (r Row) Record() []string {
+	confidence := ""
+	if r.Confidence != nil {
+		confidence = strconv.FormatFloat(float64(*r.Confidence), 'f', -1, 32)
+	}
+	return []string{
+		strconv.FormatInt(r.NewsID, 10),
+		r.Title, r.Link, r.Source, r.PublishAt, r.Language,
+		r.Sentiment, strconv.Itoa(int(r.Score)), confidence, r.Rationale, r.Model,
+	}
+}
+
+// Write writes the rows fetched page by page to w in the given format.
+func Write(ctx context.Context, w io.Writer, f Format, fetch FetchFunc) error {
+	switch f {
+	case FormatCSV:
+		return WriteCSV(ctx, w, fetch)
+	case FormatJSONL:
+		return WriteJSONL(ctx, w, fetch)
+	case FormatZip:
+		return WriteZip(ctx, w, fetch)
+	}
+	return fmt.Errorf("%w: %s", ErrUnknownFormat, f)
+}
+
+// each calls fn on each row whose id is not greater than maxId, or on every
+// row if maxId is 0. It returns the number of rows and the id of the last one.
+func each(ctx context.Context, fetch FetchFunc, maxId int64,
+	fn func(r *model.GetEmbeddingByJobIdRow) error) (n int, lastId int64, err error) {
+	for {
+		if err := ctx.Err(); err != nil {
+			return n, lastId, err
+		}
+
+		rows, err := fetch(ctx, lastId)
+		if err != nil {
+			return n, lastId, err
+		}
+		if len(rows) == 0 {
+			return n, lastId, nil
+		}
+
+		for _, r := range rows {
+			if maxId > 0 && r.ID > maxId {
+				return n, lastId, nil
+			}
+			if err := fn(r); err != nil {
+				return n, lastId, err
+			}
+			n++
+			lastId = r.ID
+		}
+	}
+}
+
+func WriteCSV(ctx context.Context, w io.Writer, fetch FetchFunc) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(Header); err != nil {
+		return err
+	}
+
+	_, _, err := each(ctx, fetch, 0, func(r *model.GetEmbeddingByJobIdRow) error {
+		return cw.Write(NewRow(r).Record())
+	})
+	if err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func WriteJSONL(ctx context.Context, w io.Writer, fetch FetchFunc) error {
+	// Encode appends a newline to each row
+	enc := json.NewEncoder(w)
+	_, _, err := each(ctx, fetch, 0, func(r *model.GetEmbeddingByJobIdRow) error {
+		return enc.Encode(NewRow(r))
+	})
+	return err
+}
+
+// WriteZip writes a zip holding the rows with their row number in the
+// embedding matrix and the matrix itself as a float32 .npy file. The rows are
+// fetched twice, since the shape of the matrix comes before its data.
+func WriteZip(ctx context.Context, w io.Writer, fetch FetchFunc) error {
+	zw := zip.NewWriter(w)
+	now := time.Now()
+
+	f, err := zw.CreateHeader(&zip.FileHeader{
+		Name:     ZipIndexFile,
+		Method:   zip.Deflate,
+		Modified: now,
+	})
+	if err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(f)
+	if err := cw.Write(append([]string{"row"}, Header...)); err != nil {
+		return err
+	}
+
+	row, nCols := 0, -1
+	nRows, lastId, err := each(ctx, fetch, 0, func(r *model.GetEmbeddingByJobIdRow) error {
+		if nCols < 0 {
+			nCols = len(r.Embedding.Slice())
+		} else if len(r.Embedding.Slice()) != nCols {
+			return fmt.Errorf("%w: embedding of news %d has %d dimensions, expected %d",
+				npy.ErrShapeMismatch, r.NewsID, len(r.Embedding.Slice()), nCols)
+		}
+		record := append([]string{strconv.Itoa(row)}, NewRow(r).Record()...)
+		row++
+		return cw.Write(record)
+	})
+	if err != nil {
+		return err
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return err
+	}
+	if nCols < 0 {
+		nCols = 0
+	}
+
+	// float32 data hardly compresses
+	f, err = zw.CreateHeader(&zip.FileHeader{
+		Name:     ZipEmbeddingFile,
+		Method:   zip.Store,
+		Modified: now,
+	})
+	if err != nil {
+		return err
+	}
+
+	fw, err := npy.NewFloat32Writer(f, nRows, nCols)
+	if err != nil {
+		return err
+	}
+
+	if nRows > 0 {
+		_, last, err := each(ctx, fetch, lastId, func(r *model.GetEmbeddingByJobIdRow) error {
+			return fw.WriteRow(r.Embedding.Slice())
+		})
+		if err != nil {
+			return err
+		}
+		if last != lastId {
+			return ErrRowsChanged
+		}
+	}
+	if err := fw.Close(); err != nil {
+		return fmt.Errorf("%w: %v", ErrRowsChanged, err)
+	}
+	return zw.Close()
+}
@@ -0,0 +1,213 @@
+package export_test
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/model"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/server/export"
+	"github.com/jackc/pgx/v5/pgtype"
+	pgv "github.com/pgvector/pgvector-go"
+	"github.com/stretchr/testify/require"
+)
+
+func newRows(n, dim int) []*model.GetEmbeddingByJobIdRow {
+	rows := make([]*model.GetEmbeddingByJobIdRow, n)
+	for i := range rows {
+		embd := make([]float32, dim)
+		for j := range embd {
+			embd[j] = float32(i) + float32(j)/10
+		}
+		rows[i] = &model.GetEmbeddingByJobIdRow{
+			// ids are not contiguous
+			ID:        int64(2*i + 1),
+			JobID:     1,
+			NewsID:    int64(100 + i),
+			Model:     "text-embedding-ada-002",
+			Embedding: pgv.NewVector(embd),
+			Sentiment: model.SentimentPositive,
+			Score:     4,
+			// only the even rows have a confidence
+			Confidence: pgtype.Float4{Float32: 0.75, Valid: i%2 == 0},
+			Rationale:  pgtype.Text{String: fmt.Sprintf("rationale, \"%d\"\nsecond line", i), Valid: true},
+			Title:      fmt.Sprintf("title, \"%d\"", i),
+			Link:       fmt.Sprintf("https://example.com/%d", i),
+			Source:     "example.com",
+			PublishAt:  pgtype.Timestamptz{Time: time.Date(2023, 12, 1, i, 0, 0, 0, time.UTC), Valid: true},
+			Language:   pgtype.Text{String: "en", Valid: true},
+		}
+	}
+	return rows
+}
+
+// pager serves rows in pages of size n and counts the queries
+type pager struct {
+	rows   []*model.GetEmbeddingByJobIdRow
+	n      int
+	nQuery int
+}
+
+func (p *pager) Fetch(ctx context.Context, next int64) ([]*model.GetEmbeddingByJobIdRow, error) {
+	p.nQuery++
+	page := []*model.GetEmbeddingByJobIdRow{}
+	for _, r := range p.rows {
+		if r.ID > next && len(page) < p.n {
+			page = append(page, r)
+		}
+	}
+	return page, nil
+}
+
+func TestParseFormat(t *testing.T) {
+	for in, out := range map[string]export.Format{
+		"":       export.FormatCSV,
+		"csv":    export.FormatCSV,
+		" JSONL": export.FormatJSONL,
+		"zip":    export.FormatZip,
+	} {
+		f, err := export.ParseFormat(in)
+		require.NoError(t, err)
+		require.Equal(t, out, f)
+	}
+
+	_, err := export.ParseFormat("xlsx")
+	require.ErrorIs(t, err, export.ErrUnknownFormat)
+}
+
+func TestWriteCSV(t *testing.T) {
+	rows := newRows(5, 3)
+	p := &pager{rows: rows, n: 2}
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, export.Write(context.Background(), buf, export.FormatCSV, p.Fetch))
+	// 3 pages and an empty one
+	require.Equal(t, 4, p.nQuery)
+
+	records, err := csv.NewReader(buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, len(rows)+1)
+	require.Equal(t, export.Header, records[0])
+	for i, r := range rows {
+		require.Equal(t, export.NewRow(r).Record(), records[i+1])
+	}
+	require.Equal(t, "2023-12-01T02:00:00Z", records[3][4])
+	require.Equal(t, []string{"4", "0.75", "rationale, \"2\"\nsecond line"}, records[3][7:10])
+	require.Equal(t, "", records[2][8])
+}
+
+func TestWriteJSONL(t *testing.T) {
+	rows := newRows(3, 3)
+	p := &pager{rows: rows, n: 10}
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, export.Write(context.Background(), buf, export.FormatJSONL, p.Fetch))
+
+	scanner := bufio.NewScanner(buf)
+	i := 0
+	for ; scanner.Scan(); i++ {
+		var row export.Row
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &row))
+		require.Equal(t, export.NewRow(rows[i]), row)
+		require.Equal(t, i%2 != 0, row.Confidence == nil)
+	}
+	require.Equal(t, len(rows), i)
+}
+
+func readZip(t *testing.T, b []byte) map[string][]byte {
+	zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	require.NoError(t, err)
+
+	files := map[string][]byte{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		files[f.Name], err = io.ReadAll(rc)
+		require.NoError(t, err)
+		rc.Close()
+	}
+	return files
+}
+
+func TestWriteZip(t *testing.T) {
+	const dim = 4
+	rows := newRows(5, dim)
+	p := &pager{rows: rows, n: 2}
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, export.Write(context.Background(), buf, export.FormatZip, p.Fetch))
+
+	files := readZip(t, buf.Bytes())
+	require.Len(t, files, 2)
+
+	records, err := csv.NewReader(bytes.NewReader(files[export.ZipIndexFile])).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, len(rows)+1)
+	require.Equal(t, append([]string{"row"}, export.Header...), records[0])
+	for i, r := range rows {
+		require.Equal(t, fmt.Sprint(i), records[i+1][0])
+		require.Equal(t, export.NewRow(r).Record(), records[i+1][1:])
+	}
+
+	b := files[export.ZipEmbeddingFile]
+	hLen := int(binary.LittleEndian.Uint16(b[8:10]))
+	require.Contains(t, string(b[10:10+hLen]), "'shape': (5, 4)")
+
+	data := b[10+hLen:]
+	require.Len(t, data, len(rows)*dim*4)
+	for i, r := range rows {
+		for j, v := range r.Embedding.Slice() {
+			k := 4 * (i*dim + j)
+			require.Equal(t, v, math.Float32frombits(binary.LittleEndian.Uint32(data[k:])))
+		}
+	}
+}
+
+func TestWriteZipEmpty(t *testing.T) {
+	p := &pager{n: 2}
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, export.WriteZip(context.Background(), buf, p.Fetch))
+
+	files := readZip(t, buf.Bytes())
+	b := files[export.ZipEmbeddingFile]
+	hLen := int(binary.LittleEndian.Uint16(b[8:10]))
+	require.Contains(t, string(b[10:10+hLen]), "'shape': (0, 0)")
+	require.Len(t, b, 10+hLen)
+}
+
+func TestWriteZipRowsChanged(t *testing.T) {
+	rows := newRows(4, 3)
+	p := &pager{rows: rows, n: 10}
+
+	// a row is deleted between the two passes
+	fetch := func(ctx context.Context, next int64) ([]*model.GetEmbeddingByJobIdRow, error) {
+		page, err := p.Fetch(ctx, next)
+		if p.nQuery > 2 && len(page) > 0 {
+			page = page[1:]
+		}
+		return page, err
+	}
+
+	err := export.WriteZip(context.Background(), io.Discard, fetch)
+	require.ErrorIs(t, err, export.ErrRowsChanged)
+}
+
+func TestWriteCanceled(t *testing.T) {
+	p := &pager{rows: newRows(3, 3), n: 1}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := export.WriteCSV(ctx, io.Discard, p.Fetch)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Zero(t, p.nQuery)
+}
@@ -0,0 +1,52 @@
+package anthropic
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	pf "github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/server/pageForm"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/server/view/object"
+	"github.com/go-playground/form"
+	val "github.com/go-playground/validator/v10"
+)
+
+const API_NAME = "Anthropic"
+
+const (
+	EPMessages string = "Messages"
+)
+
+type SentimentAnalysisOptions struct {
+	Prompt   string `form:"prompt"`
+	MaxToken int    `form:"max-token"  validate:"gt=0,lte=4096"`
+}
+
+func (f SentimentAnalysisOptions) Endpoint() string {
+	return EPMessages
+}
+
+func (f SentimentAnalysisOptions) API() string {
+	return API_NAME
+}
+
+func (f SentimentAnalysisOptions) Key() pf.PageFormRepoKey {
+	return pf.NewPageFormRepoKey(f.API(), f.Endpoint())
+}
+
+func (f SentimentAnalysisOptions) SelectionOpts() []object.SelectOpts {
+	return nil
+}
+
+func (f SentimentAnalysisOptions) String() string {
+	sb := strings.Builder{}
+	sb.WriteString("Anthropic Sentiment Anaysis Options\n")
+	sb.WriteString(fmt.Sprintf("\t- Prompt   : %s\n", f.Prompt))
+	sb.WriteString(fmt.Sprintf("\t- Max Token: %d\n", f.MaxToken))
+	return sb.String()
+}
+
+func (f SentimentAnalysisOptions) FormDecodeAndValidate(
+	decoder *form.Decoder, val *val.Validate, postForm url.Values) (pf.PageForm, error) {
+	return pf.FormDecodeAndValidate[SentimentAnalysisOptions](decoder, val, postForm)
+}
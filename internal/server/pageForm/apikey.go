@@ -1,6 +1,23 @@
 package pageform
 
+import "strings"
+
 type APIKeyPost struct {
-	ApiID int16  `           form:"api-id"  validate:"min=1"`
-	Key   string `mod:"trim" form:"api-key" validate:"min=32,max=64"`
+	ApiID      int16    `           form:"api-id"      validate:"min=1"`
+	Key        string   `mod:"trim" form:"api-key"     validate:"max=256"`
+	BaseURL    string   `mod:"trim" form:"base-url"    validate:"omitempty,max=256,http_url"`
+	AuthHeader string   `mod:"trim" form:"auth-header" validate:"omitempty,max=64"`
+	ModelsStr  string   `mod:"trim" form:"models"`
+	Models     []string `           form:"-"`
+}
+
+// ParseModels splits the comma separated ModelsStr into Models, empty names
+// are skipped.
+func (ap *APIKeyPost) ParseModels() {
+	ap.Models = []string{}
+	for _, m := range strings.Split(ap.ModelsStr, ",") {
+		if m = strings.TrimSpace(m); m != "" {
+			ap.Models = append(ap.Models, m)
+		}
+	}
 }
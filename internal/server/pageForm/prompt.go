@@ -0,0 +1,7 @@
+package pageform
+
+type PromptPost struct {
+	Provider string `mod:"trim" form:"provider" validate:"required,max=32"`
+	Task     string `mod:"trim" form:"task"     validate:"required,oneof=sentiment"`
+	Body     string `form:"body"                validate:"required,max=8192"`
+}
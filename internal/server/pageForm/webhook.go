@@ -0,0 +1,5 @@
+package pageform
+
+type WebhookPost struct {
+	Url string `mod:"trim" form:"webhook-url" validate:"required,max=2048,http_url"`
+}
@@ -4,34 +4,34 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
-	"net/url"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	// http client
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client"
 	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client/api"
-	cohere "github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client/api/Cohere"
-	openai "github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client/api/OpenAI"
-
-	// grpc client
-	ldcli "github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/grpc/languageDetector"
-	newsparser "github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/grpc/newsParser"
-	ldpf "github.com/ChiaYuChang/NewsSentimentAnalyzer/proto/language_detector"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client/option"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/model"
 
 	// http server
 	pageform "github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/server/pageForm"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/server/runner"
 	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/server/service"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/server/validator"
 	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/server/view"
 	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/server/view/object"
 	"github.com/go-chi/chi/v5"
 
 	"github.com/ChiaYuChang/NewsSentimentAnalyzer/global"
 	ec "github.com/ChiaYuChang/NewsSentimentAnalyzer/pkgs/errorCode"
+	tokenmaker "github.com/ChiaYuChang/NewsSentimentAnalyzer/pkgs/tokenMaker"
 	"github.com/jackc/pgerrcode"
-	"github.com/pemistahl/lingua-go"
+	"github.com/jackc/pgx/v5"
 	"github.com/redis/go-redis/v9"
 	// pgv "github.com/pgvector/pgvector-go"
 )
@@ -43,21 +43,173 @@ func (repo APIRepo) GetAnalyzer(w http.ResponseWriter, req *http.Request) {
 			HeadConent: view.SharedHeadContent(),
 			Title:      "analyzer",
 		},
-		Prompt:  map[string]string{},
 		Version: "v1",
 	}
-	pageData.Prompt["openai-sentiment"] = openai.SentimentAnalysisPrompt
-	pageData.Prompt["cohere-sentiment"] = cohere.SentimentAnalysisPrompt
+
+	apis, err := repo.Service.API().List(req.Context(), 100)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		ecErr := ec.MustGetEcErr(ec.ECServerError)
+		ecErr.WithDetails(err.Error())
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+	pageData.Analyzers = analyzerForms(apis)
+
+	// the prompts are optional, the default ones are used without them
+	if userInfo, ok := req.Context().Value(global.CtxUserInfo).(tokenmaker.Payload); ok {
+		prompts, err := repo.Service.Prompt().List(req.Context(), userInfo.GetUserID())
+		if err != nil {
+			global.Logger.Error().Err(err).Msg("error while listing prompts")
+		}
+		fillPromptOpts(pageData.Analyzers, prompts)
+	}
+
+	// the estimate is left out if the preview cannot be read
+	if res, err := repo.Cache.JSONGet(pcid, "."); err == nil {
+		var cache api.PreviewCache
+		if err := json.Unmarshal(res.([]byte), &cache); err == nil {
+			b := client.Batcher{
+				Budget:  global.AppVar.JobRunner.BatchBudget,
+				MaxSize: global.AppVar.JobRunner.BatchSize,
+			}
+			fillEstimates(pageData.Analyzers, cache.SelectedItems(), b, global.AppVar.Pricing)
+		}
+	}
 
 	repo.Cache.ExpireGT(context.Background(), pcid, global.CacheExpireDefault)
 
-	err := repo.View.ExecuteTemplate(w, "analyzer.gotmpl", pageData)
+	err = repo.View.ExecuteTemplate(w, "analyzer.gotmpl", pageData)
 	if err != nil {
 		global.Logger.Error().Err(err).Msg("Failed to execute template")
 	}
 	return
 }
 
+// analyzerForms returns the options of the language model APIs that have a
+// registered analyzer, the analyzers are registered under the lower case names
+// of the APIs.
+func analyzerForms(apis []*model.ListAPIRow) []object.AnalyzerForm {
+	forms := []object.AnalyzerForm{}
+	for _, api := range apis {
+		if api.Type != model.ApiTypeLanguageModel {
+			continue
+		}
+		anlz, err := client.AnalyzerRepo.Get(strings.ToLower(api.Name))
+		if err != nil {
+			continue
+		}
+
+		info := anlz.ModelInfo()
+		form := object.AnalyzerForm{
+			API:        info.Name,
+			Title:      api.Name,
+			APIId:      api.ID,
+			Prompt:     info.SentimentPrompt,
+			InputTypes: info.InputTypes,
+			Truncates:  info.Truncates,
+			Offline:    info.Offline,
+		}
+		_, form.Entities = anlz.(client.EntityAnalyzer)
+		_, form.Classification = anlz.(client.Classifier)
+		for _, mdl := range info.EmbeddingModels {
+			if mdl.Legacy {
+				continue
+			}
+			form.EmbeddingModels = append(form.EmbeddingModels, object.AnalyzerModelOpt{
+				Name:     mdl.Name,
+				Dim:      mdl.Dim,
+				Selected: mdl.Name == info.DefaultEmbeddingModel,
+			})
+		}
+		forms = append(forms, form)
+	}
+	return forms
+}
+
+// fillPromptOpts adds the sentiment prompts to the forms of their providers,
+// the rows are in the order of ListPrompts, so the latest version comes first.
+func fillPromptOpts(forms []object.AnalyzerForm, prompts []*model.ListPromptsRow) {
+	for i := range forms {
+		for _, p := range prompts {
+			if p.Provider == forms[i].API && p.Task == service.PromptTaskSentiment {
+				forms[i].Prompts = append(forms[i].Prompts, object.AnalyzerPromptOpt{
+					ID:        p.ID,
+					Version:   p.Version,
+					CreatedAt: p.CreatedAt.Time.UTC().Format(time.DateTime),
+				})
+			}
+		}
+	}
+}
+
+// fillEstimates adds the estimated usage and cost of analyzing the news to
+// the forms of the analyzers that are not offline. The texts are those the
+// runner sends, except that the content is not parsed yet, and are batched by
+// b as the runner does.
+func fillEstimates(forms []object.AnalyzerForm, news map[string]api.NewsPreview,
+	b client.Batcher, prices global.PriceOptions) {
+	ids := make([]string, 0, len(news))
+	for id := range news {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	texts := make([]string, len(ids))
+	for i, id := range ids {
+		n := news[id]
+		if n.Content != "" {
+			texts[i] = n.Title + "\n" + n.Content
+		} else {
+			texts[i] = n.Title + "\n" + n.Description
+		}
+	}
+
+	for i := range forms {
+		anlz, err := client.AnalyzerRepo.Get(forms[i].API)
+		if err != nil || forms[i].Offline {
+			continue
+		}
+
+		info := anlz.ModelInfo()
+		est := &object.AnalyzerEstimate{NArticles: len(texts)}
+		for _, u := range b.EstimateUsage(info, info.DefaultEmbeddingModel, texts...) {
+			est.InputTokens += u.InputTokens
+			est.OutputTokens += u.OutputTokens
+			cost, ok := prices.Cost(u.Model, u.InputTokens, u.OutputTokens)
+			if !ok {
+				est.Unpriced = append(est.Unpriced, u.Model)
+				continue
+			}
+			est.Cost += cost
+		}
+		forms[i].Estimate = est
+	}
+}
+
+// isOffline reports whether the language model API has a registered analyzer
+// that needs no API key.
+func isOffline(apiname string) bool {
+	anlz, err := client.AnalyzerRepo.Get(strings.ToLower(apiname))
+	return err == nil && anlz.ModelInfo().Offline
+}
+
+// promptProviders returns the registered analyzers that send a prompt, which
+// are the ones that are not offline.
+func promptProviders() []object.PromptProvider {
+	providers := []object.PromptProvider{}
+	for _, name := range client.AnalyzerRepo.Names() {
+		anlz, _ := client.AnalyzerRepo.Get(name)
+		if info := anlz.ModelInfo(); !info.Offline {
+			providers = append(providers, object.PromptProvider{
+				Name:          name,
+				DefaultPrompt: info.SentimentPrompt,
+			})
+		}
+	}
+	return providers
+}
+
 func (repo APIRepo) PostAnalyzer(w http.ResponseWriter, req *http.Request) {
 	resp := pageform.PreviewPostResp{}
 	pcid := chi.URLParam(req, "pcid")
@@ -102,7 +254,7 @@ func (repo APIRepo) PostAnalyzer(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	var fdata service.AnalyzerOption
+	var fdata option.AnalyzerOption
 	err = repo.FormDecoder.Decode(&fdata, req.PostForm)
 	if err != nil {
 		global.Logger.Error().Err(err).Msg("Failed to decode form")
@@ -116,6 +268,71 @@ func (repo APIRepo) PostAnalyzer(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	if err := validator.Validate.Struct(fdata.EmbeddingOptions); err != nil {
+		resp.WithEcError(ec.MustGetEcErr(ec.ECBadRequest).
+			WithMessage("invalid embedding options").
+			WithDetails(err.Error())).
+			WithRedirectURL(global.AppVar.App.RoutePattern.ErrorPage["bad-request"])
+		w.WriteHeader(resp.HttpStatusCode())
+		b, _ := json.Marshal(resp)
+		w.Write(b)
+		return
+	}
+
+	fdata.ParseEntities(req.PostForm.Get("entities"))
+	if err := validator.Validate.Var(fdata.Entities, "max=20,dive,max=64"); err != nil {
+		resp.WithEcError(ec.MustGetEcErr(ec.ECBadRequest).
+			WithMessage("invalid entities").
+			WithDetails("at most 20 entities of at most 64 characters")).
+			WithRedirectURL(global.AppVar.App.RoutePattern.ErrorPage["bad-request"])
+		w.WriteHeader(resp.HttpStatusCode())
+		b, _ := json.Marshal(resp)
+		w.Write(b)
+		return
+	}
+
+	err = fdata.ParseClassification(req.PostForm.Get("task"), req.PostForm.Get("question"),
+		req.PostForm.Get("labels"), req.PostForm.Get("examples"))
+	if err == nil {
+		err = validator.Validate.Struct(fdata.ClassificationOptions)
+	}
+	if err != nil {
+		resp.WithEcError(ec.MustGetEcErr(ec.ECBadRequest).
+			WithMessage("invalid classification task").
+			WithDetails(err.Error())).
+			WithRedirectURL(global.AppVar.App.RoutePattern.ErrorPage["bad-request"])
+		w.WriteHeader(resp.HttpStatusCode())
+		b, _ := json.Marshal(resp)
+		w.Write(b)
+		return
+	}
+
+	if pid, _ := strconv.ParseInt(req.PostForm.Get("prompt-id"), 10, 64); pid > 0 {
+		if fdata.Prompt, err = repo.promptVersion(req, pid, fdata.APIName); err != nil {
+			resp.WithEcError(ec.MustGetEcErr(ec.ECBadRequest).
+				WithMessage("invalid prompt").
+				WithDetails(err.Error())).
+				WithRedirectURL(global.AppVar.App.RoutePattern.ErrorPage["bad-request"])
+			w.WriteHeader(resp.HttpStatusCode())
+			b, _ := json.Marshal(resp)
+			w.Write(b)
+			return
+		}
+	}
+
+	// an empty schedule means the job runs only once
+	cronExpr := strings.TrimSpace(req.PostForm.Get("schedule"))
+	if err := validator.Validate.Var(cronExpr, "omitempty,max=64,cron_expr"); err != nil {
+		resp.WithEcError(ec.MustGetEcErr(ec.ECBadRequest).
+			WithMessage("invalid schedule").
+			WithDetails(cronExpr)).
+			WithRedirectURL(global.AppVar.App.RoutePattern.ErrorPage["bad-request"])
+		w.WriteHeader(resp.HttpStatusCode())
+		b, _ := json.Marshal(resp)
+		w.Write(b)
+		return
+	}
+
 	_, err = repo.Cache.JSONSet(pcid, ".analyzer_options", fdata)
 	if err != nil {
 		global.Logger.Error().Err(err).Msg("Failed to write cache")
@@ -128,7 +345,20 @@ func (repo APIRepo) PostAnalyzer(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	jid, ecErr := repo.CacheToStore(pcid, aid, fdata.APIId)
+	role := tokenmaker.RUnknown
+	if userInfo, ok := req.Context().Value(global.CtxUserInfo).(tokenmaker.Payload); ok {
+		role = userInfo.GetRole()
+	}
+
+	jid, ecErr := repo.CacheToStore(pcid, aid, fdata.APIId, cronExpr, global.AppVar.Quota.Of(role.String()))
+	if ecErr != nil && ecErr.ErrorCode == ec.ECQuotaExceeded {
+		// tell the user which limit was hit, the request could be resent later
+		resp.WithEcError(ecErr)
+		w.WriteHeader(ecErr.HttpStatusCode)
+		b, _ := json.Marshal(resp)
+		w.Write(b)
+		return
+	}
 	if ecErr != nil {
 		resp.WithEcError(ecErr).
 			WithOutDetails().
@@ -147,7 +377,32 @@ func (repo APIRepo) PostAnalyzer(w http.ResponseWriter, req *http.Request) {
 	return
 }
 
-func (repo APIRepo) CacheToStore(pcid string, aid, lid int) (int, *ec.Error) {
+// promptVersion returns the version of the prompt pid of the user, which must
+// be a sentiment prompt of the analyzer apiname.
+func (repo APIRepo) promptVersion(req *http.Request, pid int64, apiname string) (*option.PromptVersion, error) {
+	userInfo, ok := req.Context().Value(global.CtxUserInfo).(tokenmaker.Payload)
+	if !ok {
+		return nil, errors.New("user information not found")
+	}
+
+	prompt, err := repo.Service.Prompt().Get(req.Context(), &service.PromptGetRequest{
+		Owner: userInfo.GetUserID(),
+		ID:    pid,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if prompt.Provider != apiname || prompt.Task != service.PromptTaskSentiment {
+		return nil, fmt.Errorf("prompt %d is not a sentiment prompt of %s", pid, apiname)
+	}
+	return &option.PromptVersion{ID: prompt.ID, Version: prompt.Version}, nil
+}
+
+// CacheToStore stores the selected news of the preview cache as a new job. The
+// job replays its query on cronExpr if cronExpr is not empty. The job is
+// rejected with ECQuotaExceeded if it exceeds the quota of the user.
+func (repo APIRepo) CacheToStore(pcid string, aid, lid int, cronExpr string, quota global.QuotaOption) (int, *ec.Error) {
 	// save cache to premint storage
 	res, err := repo.Cache.JSONGet(pcid, ".")
 	if err != nil {
@@ -167,66 +422,41 @@ func (repo APIRepo) CacheToStore(pcid string, aid, lid int) (int, *ec.Error) {
 
 	selectedItem := cache.SelectedItems()
 	global.Logger.Info().Int("n", len(selectedItem)).Msg("OK")
-	ldcli.MustGetLanguageDetectorClient()
 
-	reqChan := make(chan *ldpf.LanguageDetectRequest)
-	respChan, err, errChan := ldcli.MustGetLanguageDetectorClient().DetectLanguage(context.TODO(), reqChan)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// the counters are final before cnChan is closed, and DoCacheToStoreTx
+	// returns without error only after cnChan has been drained
+	cnChan, parsed, err := runner.ParseNewsPreviews(ctx, selectedItem)
 	if err != nil {
 		return 0, ec.MustGetEcErr(ec.ECBadRequest).
 			WithDetails("error while detect language").
 			WithMessage(err.Error())
 	}
 
-	go func(errChan <-chan error) {
-		for err := range errChan {
-			global.Logger.Error().Err(err).Msg("error while detect language")
-		}
-	}(errChan)
-
-	go func(reqChan chan<- *ldpf.LanguageDetectRequest) {
-		defer close(reqChan)
-		thr := float64(.9)
-		for _, item := range selectedItem {
-			reqChan <- &ldpf.LanguageDetectRequest{
-				Id:        item.Id.String(),
-				Text:      item.Title,
-				Threshold: &thr,
-			}
-		}
-	}(reqChan)
-
-	cnChan := make(chan *service.NewsCreateRequest, 10)
-	go func(respChan <-chan *ldpf.LanguageDetectResponse,
-		cnChan chan<- *service.NewsCreateRequest) {
-		defer close(cnChan)
-		cli, _ := newsparser.GetNewsParserClient()
-		i := int64(0)
-		for resp := range respChan {
-			prev := selectedItem[resp.GetId()]
-			u, _ := url.Parse(prev.Link)
-			_, guid, err := cli.GetGUID(context.TODO(), i, u.String())
-			if err != nil {
-				global.Logger.Error().
-					Err(err).
-					Str("url", u.String()).
-					Msg("error while GetGUID")
-				continue
-			}
-			lang := lingua.Language(int(resp.GetLanguage())).IsoCode639_1()
-			cnChan <- prev.ToNewsCreateRequest(guid, lang.String(), u.Host, nil)
-			i++
-		}
-	}(respChan, cnChan)
+	var srcCacheQuery []byte
+	if cronExpr != "" {
+		srcCacheQuery, _ = json.Marshal(cache.Query.ToReplayQuery())
+	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
 	cache.AnalyzerOptions.APIId = 0 // omit analyzer api id
-	result, err := repo.Service.TX().DoCacheToStoreTx(
-		ctx, cache.Query.UserId, strings.TrimSuffix(
+	result, err := repo.Service.TX().DoCacheToStoreTx(ctx, &service.CacheToStoreTxRequest{
+		Owner: cache.Query.UserId,
+		Ulid: strings.TrimSuffix(
 			strings.TrimPrefix(pcid, global.PREVIEW_CACHE_KEY_PREFIX),
 			global.PREVIEW_CACHE_KEY_SUFFIX),
-		int16(aid), cache.Query.RawQuery, int16(lid),
-		cache.AnalyzerOptions.ToString("", ""), cnChan)
+		SrcId:         int16(aid),
+		SrcQuery:      cache.Query.RawQuery,
+		LlmId:         int16(lid),
+		LlmQuery:      cache.AnalyzerOptions.ToString("", ""),
+		CronExpr:      cronExpr,
+		SrcCacheQuery: srcCacheQuery,
+		// counted and stored in the same transaction, so that concurrent
+		// requests of the user can not exceed the quota together
+		Quota: &quota,
+		Since: startOfToday(),
+	}, cnChan)
 
 	if err != nil {
 		if ecErr, ok := err.(*ec.Error); ok {
@@ -238,6 +468,21 @@ func (repo APIRepo) CacheToStore(pcid string, aid, lid int) (int, *ec.Error) {
 		}
 	}
 
+	err = repo.Service.Job().CreateProgress(ctx, &service.JobCreateProgressRequest{
+		JobId:        result.JobId,
+		Total:        int32(len(selectedItem)),
+		Parsed:       parsed.Parsed.Load(),
+		LangDetected: parsed.LangDetected.Load(),
+		Failed:       int32(len(selectedItem)) - parsed.Parsed.Load(),
+	})
+	if err != nil {
+		// the job has been stored, progress is not worth failing the request
+		global.Logger.Error().
+			Err(err).
+			Int64("job_id", result.JobId).
+			Msg("error while CreateProgress")
+	}
+
 	repo.Cache.JSONSet(pcid, ".is_done", true)
 	_, err = repo.Cache.ExpireLT(context.Background(), pcid, 1*time.Minute).Result()
 	if err != nil {
@@ -248,3 +493,10 @@ func (repo APIRepo) CacheToStore(pcid string, aid, lid int) (int, *ec.Error) {
 
 	return int(result.JobId), nil
 }
+
+// startOfToday returns the midnight of today, from which the daily quota is
+// counted.
+func startOfToday() time.Time {
+	y, m, d := time.Now().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+}
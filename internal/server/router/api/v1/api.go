@@ -1,17 +1,23 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/ChiaYuChang/NewsSentimentAnalyzer/global"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client/option"
 	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/model"
 	cm "github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/server/cookieMaker"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/server/export"
 	pageform "github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/server/pageForm"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/server/runner"
 	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/server/service"
 	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/server/validator"
 	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/server/view"
@@ -25,6 +31,7 @@ import (
 	"github.com/go-playground/mold/v4"
 	val "github.com/go-playground/validator/v10"
 	"github.com/jackc/pgx/v5"
+	"github.com/oklog/ulid/v2"
 )
 
 type APIRepo struct {
@@ -32,6 +39,7 @@ type APIRepo struct {
 	Service      service.Service
 	View         view.View
 	Cache        *cache.RedsiStore
+	Runner       *runner.Runner
 	TokenMaker   tokenmaker.TokenMaker
 	CookieMaker  *cm.CookieMaker
 	Validator    *val.Validate
@@ -41,13 +49,14 @@ type APIRepo struct {
 
 func NewAPIRepo(
 	ver string, srvc service.Service, view view.View, cache *cache.RedsiStore,
-	tokenmaker tokenmaker.TokenMaker, cookiemaker *cm.CookieMaker,
+	runner *runner.Runner, tokenmaker tokenmaker.TokenMaker, cookiemaker *cm.CookieMaker,
 	validator *val.Validate, decoder *form.Decoder, modifier *mold.Transformer) APIRepo {
 	return APIRepo{
 		Version:      ver,
 		Service:      srvc,
 		View:         view,
 		Cache:        cache,
+		Runner:       runner,
 		TokenMaker:   tokenmaker,
 		CookieMaker:  cookiemaker,
 		Validator:    validator,
@@ -87,11 +96,32 @@ func (repo APIRepo) GetWelcome(w http.ResponseWriter, req *http.Request) {
 		PageEndpoint:     strings.TrimLeft(global.AppVar.App.RoutePattern.Page["endpoints"], "/"),
 		PageChangePWD:    strings.TrimLeft(global.AppVar.App.RoutePattern.Page["change-password"], "/"),
 		PageManageAPIKey: strings.TrimLeft(global.AppVar.App.RoutePattern.Page["apikey"], "/"),
+		PageWebhook:      strings.TrimLeft(global.AppVar.App.RoutePattern.Page["webhook"], "/"),
+		PagePrompt:       strings.TrimLeft(global.AppVar.App.RoutePattern.Page["prompt"], "/"),
+		PageStory:        strings.TrimLeft(global.AppVar.App.RoutePattern.Page["story"], "/"),
 		PageSeeResult:    strings.TrimLeft(global.AppVar.App.RoutePattern.Page["job"], "/"),
 		PageAdmin:        strings.TrimLeft(global.AppVar.App.RoutePattern.Page["admin"], "/"),
 		PageSignOut:      global.AppVar.App.RoutePattern.Page["sign-out"],
 	}
 
+	quota := global.AppVar.Quota.Of(payload.GetRole().String())
+	usage, err := repo.Service.Job().GetQuotaUsage(req.Context(), &service.JobQuotaUsageRequest{
+		Owner: payload.GetUserID(),
+		Since: startOfToday(),
+	})
+	if err != nil {
+		// the quota is for information only, do not fail the page
+		global.Logger.Error().
+			Err(err).
+			Msg("error while GetQuotaUsage")
+	} else {
+		pageData.Quota = []object.QuotaItem{
+			{Name: "Running jobs", Used: usage.NRunning, Limit: quota.MaxRunningJobs},
+			{Name: "Jobs today", Used: usage.NToday, Limit: quota.MaxJobsPerDay},
+		}
+		pageData.MaxArticles = quota.MaxArticlesPerJob
+	}
+
 	if err := repo.View.ExecuteTemplate(w, "welcome.gotmpl", pageData); err != nil {
 		global.Logger.
 			Err(err).
@@ -126,7 +156,7 @@ func (repo APIRepo) GetAPIKey(w http.ResponseWriter, req *http.Request) {
 		if api.Type == model.ApiTypeSource {
 			apiOpts.Source[api.ID] = api.Name
 		}
-		if api.Type == model.ApiTypeLanguageModel {
+		if api.Type == model.ApiTypeLanguageModel && !isOffline(api.Name) {
 			apiOpts.Analyzer[api.ID] = api.Name
 		}
 	}
@@ -153,10 +183,12 @@ func (repo APIRepo) GetAPIKey(w http.ResponseWriter, req *http.Request) {
 
 	for _, a := range apikey {
 		obj := &object.APIKey{
-			ID:   a.ApiID,
-			Name: a.Name,
-			Key:  a.Key,
-			Icon: a.Icon,
+			ID:      a.ApiID,
+			Name:    a.Name,
+			Key:     a.Key,
+			Icon:    a.Icon,
+			BaseURL: a.BaseUrl.String,
+			Models:  a.Models,
 		}
 
 		switch a.Type {
@@ -246,6 +278,7 @@ func (repo APIRepo) PostAPIKey(w http.ResponseWriter, req *http.Request) {
 	}
 
 	apikey.Key = strings.TrimSpace(apikey.Key)
+	apikey.ParseModels()
 	if err := validator.Validate.Struct(apikey); err != nil {
 		ecErr := *ec.MustGetEcErr(ec.ECBadRequest)
 		ecErr.WithDetails(err.Error())
@@ -254,16 +287,33 @@ func (repo APIRepo) PostAPIKey(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	if apikey.BaseURL != "" && !repo.supportsBaseURL(req.Context(), apikey.ApiID) {
+		ecErr := ec.MustGetEcErr(ec.ECBadRequest)
+		ecErr.WithDetails("the api does not support a base url")
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
 	if results, err := repo.Service.APIKey().CreateOrUpdate(req.Context(),
 		&service.APIKeyCreateOrUpdateRequest{
-			Owner: userInfo.GetUserID(),
-			ApiID: apikey.ApiID,
-			Key:   apikey.Key,
+			Owner:      userInfo.GetUserID(),
+			ApiID:      apikey.ApiID,
+			Key:        apikey.Key,
+			BaseURL:    apikey.BaseURL,
+			AuthHeader: apikey.AuthHeader,
+			Models:     apikey.Models,
 		}); err != nil {
 		ecErr := ec.MustGetEcErr(ec.ECServerError)
+		var valErr val.ValidationErrors
+		if errors.As(err, &valErr) {
+			// e.g. the base url is not public
+			ecErr = ec.MustGetEcErr(ec.ECBadRequest)
+		}
 		ecErr.WithDetails(err.Error())
 		w.WriteHeader(ecErr.HttpStatusCode)
 		w.Write(ecErr.MustToJson())
+		return
 	} else {
 		global.Logger.Info().
 			Int16("api_id", apikey.ApiID).
@@ -275,6 +325,17 @@ func (repo APIRepo) PostAPIKey(w http.ResponseWriter, req *http.Request) {
 	return
 }
 
+// supportsBaseURL reports whether the analyzer of the api could be used on the
+// compatible servers at a base url.
+func (repo APIRepo) supportsBaseURL(ctx context.Context, apiID int16) bool {
+	api, err := repo.Service.API().Get(ctx, apiID)
+	if err != nil {
+		return false
+	}
+	anlz, err := client.AnalyzerRepo.Get(strings.ToLower(api.Name))
+	return err == nil && anlz.ModelInfo().CompatibleServers
+}
+
 func (repo APIRepo) GetEndpoints(w http.ResponseWriter, req *http.Request) {
 	userInfo, ok := req.Context().Value(global.CtxUserInfo).(tokenmaker.Payload)
 	if !ok {
@@ -559,6 +620,1330 @@ func (repo APIRepo) GetJobDetail(w http.ResponseWriter, req *http.Request) {
 	return
 }
 
+func (repo APIRepo) CancelJob(w http.ResponseWriter, req *http.Request) {
+	userInfo, ok := req.Context().Value(global.CtxUserInfo).(tokenmaker.Payload)
+	if !ok {
+		ecErr := ec.MustGetEcErr(ec.ECServerError)
+		ecErr.WithDetails("user information not found")
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	jIdStr := chi.URLParam(req, "jId")
+	jId, err := convert.StrTo(jIdStr).Int()
+	w.Header().Set("Content-Type", "application/json")
+
+	if jId <= 0 || err != nil {
+		ecErr := ec.MustGetEcErr(ec.ECBadRequest)
+		ecErr.WithDetails("jid not found")
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	n, err := repo.Service.Job().Cancel(req.Context(), &service.JobCancelRequest{
+		ID:    int64(jId),
+		Owner: userInfo.GetUserID(),
+	})
+	if err != nil {
+		ecErr := ec.MustGetEcErr(ec.ECServerError)
+		ecErr.WithDetails(err.Error())
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	if n == 0 {
+		ecErr := ec.MustGetEcErr(ec.ECConflict)
+		ecErr.WithDetails("only created or running job can be canceled")
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	if repo.Runner != nil {
+		repo.Runner.Cancel(int64(jId))
+	}
+
+	global.Logger.Info().
+		Str("name", userInfo.GetUsername()).
+		Int("job_id", jId).
+		Msg("job canceled")
+
+	jsn, _ := json.Marshal(map[string]any{
+		"job-id":     jId,
+		"job-status": model.JobStatusCanceled,
+	})
+	w.WriteHeader(http.StatusOK)
+	w.Write(jsn)
+}
+
+// GetJobProgress streams the progress of a job as server-sent events. The
+// stream ends with an "end" event once the job is finished.
+func (repo APIRepo) GetJobProgress(w http.ResponseWriter, req *http.Request) {
+	userInfo, ok := req.Context().Value(global.CtxUserInfo).(tokenmaker.Payload)
+	if !ok {
+		ecErr := ec.MustGetEcErr(ec.ECServerError)
+		ecErr.WithDetails("user information not found")
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	jIdStr := chi.URLParam(req, "jId")
+	jId, err := convert.StrTo(jIdStr).Int()
+	w.Header().Set("Content-Type", "application/json")
+
+	if jId <= 0 || err != nil {
+		ecErr := ec.MustGetEcErr(ec.ECBadRequest)
+		ecErr.WithDetails("jid not found")
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		ecErr := ec.MustGetEcErr(ec.ECServerError)
+		ecErr.WithDetails("streaming unsupported")
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	// nil if the server does not provide it, which blocks forever
+	shutdown, _ := req.Context().Value(global.CtxServerShutdown).(<-chan struct{})
+
+	ticker := time.NewTicker(global.JOB_PROGRESS_STREAM_INTERVAL)
+	defer ticker.Stop()
+
+	var last *object.JobProgress
+	for {
+		row, err := repo.Service.Job().GetProgress(req.Context(), &service.JobGetProgressRequest{
+			Owner: userInfo.GetUserID(),
+			Id:    int64(jId),
+		})
+		if err != nil {
+			if last != nil {
+				// the stream has started, the client will reconnect
+				global.Logger.Error().
+					Err(err).
+					Int("job_id", jId).
+					Msg("error while GetProgress")
+				return
+			}
+
+			ecErr := ec.MustGetEcErr(ec.ECServerError)
+			if ec.MustGetEcErr(ec.ECPgxErrNoRows).IsEqual(err) {
+				ecErr = ec.MustGetEcErr(ec.ECForbidden)
+			}
+			w.WriteHeader(ecErr.HttpStatusCode)
+			w.Write(ecErr.MustToJson())
+			return
+		}
+
+		if last == nil {
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Header().Set("Cache-Control", "no-cache")
+			w.Header().Set("Connection", "keep-alive")
+			w.WriteHeader(http.StatusOK)
+		}
+
+		prog := object.NewJobProgress(row)
+		if last == nil || prog != *last {
+			jsn, _ := json.Marshal(prog)
+			fmt.Fprintf(w, "event: progress\ndata: %s\n\n", jsn)
+			last = &prog
+		}
+
+		if prog.IsFinished() {
+			// otherwise EventSource reconnects once the stream is closed
+			fmt.Fprint(w, "event: end\ndata: {}\n\n")
+			flusher.Flush()
+			return
+		}
+		flusher.Flush()
+
+		select {
+		case <-req.Context().Done():
+			return
+		case <-shutdown:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// GetJobItems lists the items of a job in the given stage, dead items by
+// default.
+func (repo APIRepo) GetJobItems(w http.ResponseWriter, req *http.Request) {
+	userInfo, ok := req.Context().Value(global.CtxUserInfo).(tokenmaker.Payload)
+	if !ok {
+		ecErr := ec.MustGetEcErr(ec.ECServerError)
+		ecErr.WithDetails("user information not found")
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	jIdStr := chi.URLParam(req, "jId")
+	jId, err := convert.StrTo(jIdStr).Int()
+	w.Header().Set("Content-Type", "application/json")
+
+	if jId <= 0 || err != nil {
+		ecErr := ec.MustGetEcErr(ec.ECBadRequest)
+		ecErr.WithDetails("jid not found")
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	stage := req.URL.Query().Get("stage")
+	if stage == "" {
+		stage = string(model.JobItemStageDead)
+	}
+
+	rows, err := repo.Service.JobItem().GetByStage(req.Context(), &service.JobItemGetByStageRequest{
+		Owner: userInfo.GetUserID(),
+		JobId: int64(jId),
+		Stage: stage,
+	})
+	if err != nil {
+		var valErr val.ValidationErrors
+		ecErr := ec.MustGetEcErr(ec.ECServerError)
+		if errors.As(err, &valErr) {
+			ecErr = ec.MustGetEcErr(ec.ECBadRequest)
+		}
+		ecErr.WithDetails(err.Error())
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	items := make([]object.JobItem, len(rows))
+	for i, row := range rows {
+		items[i] = object.NewJobItem(row)
+	}
+
+	jsn, _ := json.Marshal(items)
+	w.WriteHeader(http.StatusOK)
+	w.Write(jsn)
+}
+
+// GetJobUsage returns the tokens used by a job and their estimated cost,
+// summed by provider, model and operation.
+func (repo APIRepo) GetJobUsage(w http.ResponseWriter, req *http.Request) {
+	userInfo, ok := req.Context().Value(global.CtxUserInfo).(tokenmaker.Payload)
+	if !ok {
+		ecErr := ec.MustGetEcErr(ec.ECServerError)
+		ecErr.WithDetails("user information not found")
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	jId, err := convert.StrTo(chi.URLParam(req, "jId")).Int()
+	if jId <= 0 || err != nil {
+		ecErr := ec.MustGetEcErr(ec.ECBadRequest)
+		ecErr.WithDetails("jid not found")
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	rows, err := repo.Service.JobUsage().Get(req.Context(), &service.GetJobUsageRequest{
+		JobId: int64(jId),
+		Owner: userInfo.GetUserID(),
+	})
+	if err != nil {
+		var valErr val.ValidationErrors
+		ecErr := ec.MustGetEcErr(ec.ECServerError)
+		if errors.As(err, &valErr) {
+			ecErr = ec.MustGetEcErr(ec.ECBadRequest)
+		}
+		ecErr.WithDetails(err.Error())
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	usages := make([]object.JobUsage, len(rows))
+	for i, row := range rows {
+		usages[i] = object.NewJobUsage(row)
+	}
+
+	jsn, _ := json.Marshal(usages)
+	w.WriteHeader(http.StatusOK)
+	w.Write(jsn)
+}
+
+// RequeueJobItems puts the dead items of a job back to the queue, either the
+// one given by iId or all of them.
+func (repo APIRepo) RequeueJobItems(w http.ResponseWriter, req *http.Request) {
+	userInfo, ok := req.Context().Value(global.CtxUserInfo).(tokenmaker.Payload)
+	if !ok {
+		ecErr := ec.MustGetEcErr(ec.ECServerError)
+		ecErr.WithDetails("user information not found")
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	jId, err := convert.StrTo(chi.URLParam(req, "jId")).Int()
+	if jId <= 0 || err != nil {
+		ecErr := ec.MustGetEcErr(ec.ECBadRequest)
+		ecErr.WithDetails("jid not found")
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	iId := 0
+	if iIdStr := chi.URLParam(req, "iId"); iIdStr != "" {
+		iId, err = convert.StrTo(iIdStr).Int()
+		if iId <= 0 || err != nil {
+			ecErr := ec.MustGetEcErr(ec.ECBadRequest)
+			ecErr.WithDetails("item id not found")
+			w.WriteHeader(ecErr.HttpStatusCode)
+			w.Write(ecErr.MustToJson())
+			return
+		}
+	}
+
+	result, err := repo.Service.JobItem().Requeue(req.Context(), &service.JobItemRequeueRequest{
+		Owner:  userInfo.GetUserID(),
+		JobId:  int64(jId),
+		ItemId: int64(iId),
+	})
+	if err != nil {
+		ecErr, ok := err.(*ec.Error)
+		if !ok {
+			ecErr = ec.MustGetEcErr(ec.ECServerError).WithDetails(err.Error())
+		} else if ec.MustGetEcErr(ec.ECPgxErrNoRows).IsEqual(ecErr) {
+			ecErr = ec.MustGetEcErr(ec.ECForbidden)
+		}
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	if result.N == 0 {
+		ecErr := ec.MustGetEcErr(ec.ECConflict)
+		ecErr.WithDetails("no dead item to be requeued")
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	global.Logger.Info().
+		Str("name", userInfo.GetUsername()).
+		Int("job_id", jId).
+		Int64("n", result.N).
+		Msg("job items requeued")
+
+	jsn, _ := json.Marshal(map[string]any{
+		"job-id":     jId,
+		"job-status": result.Status,
+		"n-requeued": result.N,
+	})
+	w.WriteHeader(http.StatusOK)
+	w.Write(jsn)
+}
+
+// GetJobSchedule gets the schedule of a job.
+func (repo APIRepo) GetJobSchedule(w http.ResponseWriter, req *http.Request) {
+	userInfo, ok := req.Context().Value(global.CtxUserInfo).(tokenmaker.Payload)
+	if !ok {
+		ecErr := ec.MustGetEcErr(ec.ECServerError)
+		ecErr.WithDetails("user information not found")
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	jId, err := convert.StrTo(chi.URLParam(req, "jId")).Int()
+	if jId <= 0 || err != nil {
+		ecErr := ec.MustGetEcErr(ec.ECBadRequest)
+		ecErr.WithDetails("jid not found")
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	row, err := repo.Service.JobSchedule().Get(req.Context(), &service.JobScheduleGetRequest{
+		Owner: userInfo.GetUserID(),
+		JobId: int64(jId),
+	})
+	if err != nil {
+		ecErr, ok := err.(*ec.Error)
+		if !ok {
+			ecErr = ec.MustGetEcErr(ec.ECServerError).WithDetails(err.Error())
+		} else if ec.MustGetEcErr(ec.ECPgxErrNoRows).IsEqual(ecErr) {
+			ecErr = ec.MustGetEcErr(ec.ECNotFound).WithDetails("job is not scheduled")
+		}
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	jsn, _ := json.Marshal(object.NewJobSchedule(row))
+	w.WriteHeader(http.StatusOK)
+	w.Write(jsn)
+}
+
+// UpdateJobSchedule changes the cron expression of a scheduled job.
+func (repo APIRepo) UpdateJobSchedule(w http.ResponseWriter, req *http.Request) {
+	userInfo, ok := req.Context().Value(global.CtxUserInfo).(tokenmaker.Payload)
+	if !ok {
+		ecErr := ec.MustGetEcErr(ec.ECServerError)
+		ecErr.WithDetails("user information not found")
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	jId, err := convert.StrTo(chi.URLParam(req, "jId")).Int()
+	if jId <= 0 || err != nil {
+		ecErr := ec.MustGetEcErr(ec.ECBadRequest)
+		ecErr.WithDetails("jid not found")
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	_ = req.ParseForm()
+	n, err := repo.Service.JobSchedule().Update(req.Context(), &service.JobScheduleUpdateRequest{
+		Owner:    userInfo.GetUserID(),
+		JobId:    int64(jId),
+		CronExpr: strings.TrimSpace(req.PostForm.Get("schedule")),
+	})
+	if err != nil {
+		var valErr val.ValidationErrors
+		ecErr, ok := err.(*ec.Error)
+		if errors.As(err, &valErr) {
+			ecErr = ec.MustGetEcErr(ec.ECBadRequest).WithDetails("invalid schedule")
+		} else if !ok {
+			ecErr = ec.MustGetEcErr(ec.ECServerError).WithDetails(err.Error())
+		}
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	if n == 0 {
+		ecErr := ec.MustGetEcErr(ec.ECNotFound)
+		ecErr.WithDetails("job is not scheduled")
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	global.Logger.Info().
+		Str("name", userInfo.GetUsername()).
+		Int("job_id", jId).
+		Msg("job schedule updated")
+
+	repo.GetJobSchedule(w, req)
+}
+
+// DeleteJobSchedule stops a job from being replayed. The jobs it has created
+// are kept.
+func (repo APIRepo) DeleteJobSchedule(w http.ResponseWriter, req *http.Request) {
+	userInfo, ok := req.Context().Value(global.CtxUserInfo).(tokenmaker.Payload)
+	if !ok {
+		ecErr := ec.MustGetEcErr(ec.ECServerError)
+		ecErr.WithDetails("user information not found")
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	jId, err := convert.StrTo(chi.URLParam(req, "jId")).Int()
+	if jId <= 0 || err != nil {
+		ecErr := ec.MustGetEcErr(ec.ECBadRequest)
+		ecErr.WithDetails("jid not found")
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	n, err := repo.Service.JobSchedule().Delete(req.Context(), &service.JobScheduleDeleteRequest{
+		Owner: userInfo.GetUserID(),
+		JobId: int64(jId),
+	})
+	if err != nil {
+		ecErr, ok := err.(*ec.Error)
+		if !ok {
+			ecErr = ec.MustGetEcErr(ec.ECServerError).WithDetails(err.Error())
+		}
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	if n == 0 {
+		ecErr := ec.MustGetEcErr(ec.ECNotFound)
+		ecErr.WithDetails("job is not scheduled")
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	global.Logger.Info().
+		Str("name", userInfo.GetUsername()).
+		Int("job_id", jId).
+		Msg("job schedule deleted")
+
+	jsn, _ := json.Marshal(map[string]any{
+		"job-id": jId,
+	})
+	w.WriteHeader(http.StatusOK)
+	w.Write(jsn)
+}
+
+// ExportJob streams the analyzed news of a job as csv, jsonl or a zip which
+// also holds the embeddings as a .npy matrix.
+func (repo APIRepo) ExportJob(w http.ResponseWriter, req *http.Request) {
+	userInfo, ok := req.Context().Value(global.CtxUserInfo).(tokenmaker.Payload)
+	if !ok {
+		ecErr := ec.MustGetEcErr(ec.ECServerError)
+		ecErr.WithDetails("user information not found")
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	jId, err := convert.StrTo(chi.URLParam(req, "jId")).Int()
+	if jId <= 0 || err != nil {
+		ecErr := ec.MustGetEcErr(ec.ECBadRequest)
+		ecErr.WithDetails("jid not found")
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	format, err := export.ParseFormat(req.URL.Query().Get("format"))
+	if err != nil {
+		ecErr := ec.MustGetEcErr(ec.ECBadRequest)
+		ecErr.WithDetails(err.Error())
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	// the embeddings are not owned by anyone, check the job instead
+	job, err := repo.Service.Job().GetDetails(req.Context(), &service.JobGetByJobIdRequest{
+		Owner: userInfo.GetUserID(),
+		Id:    int64(jId),
+	})
+	if err != nil {
+		ecErr := ec.MustGetEcErr(ec.ECServerError)
+		if ec.MustGetEcErr(ec.ECPgxErrNoRows).IsEqual(err) {
+			ecErr = ec.MustGetEcErr(ec.ECForbidden)
+		}
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	// the news may have been analyzed by other jobs with other models too
+	mdl, err := jobEmbeddingModel(job)
+	if err != nil {
+		ecErr := ec.MustGetEcErr(ec.ECServerError)
+		ecErr.WithDetails(err.Error())
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	fetch := func(ctx context.Context, next int64) ([]*model.GetEmbeddingByJobIdRow, error) {
+		return repo.Service.Embedding().GetByJobId(ctx, &service.GetEmbeddingByJobIdRequest{
+			JobId: int64(jId),
+			Model: mdl,
+			Next:  next,
+			N:     global.JOB_EXPORT_PAGE_SIZE,
+		})
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition",
+		fmt.Sprintf("attachment; filename=\"job-%d.%s\"", jId, format))
+	w.WriteHeader(http.StatusOK)
+
+	if err := export.Write(req.Context(), w, format, fetch); err != nil {
+		global.Logger.Error().
+			Err(err).
+			Int("job_id", jId).
+			Str("format", string(format)).
+			Msg("error while exporting job")
+		// the status has been sent, abort so that the client sees a broken
+		// download instead of a truncated file
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// CloneJob creates a job with the news of the given job, which will be
+// analyzed with the analyzer in the form instead of being fetched again.
+func (repo APIRepo) CloneJob(w http.ResponseWriter, req *http.Request) {
+	userInfo, ok := req.Context().Value(global.CtxUserInfo).(tokenmaker.Payload)
+	if !ok {
+		ecErr := ec.MustGetEcErr(ec.ECServerError)
+		ecErr.WithDetails("user information not found")
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	jId, err := convert.StrTo(chi.URLParam(req, "jId")).Int()
+	if jId <= 0 || err != nil {
+		ecErr := ec.MustGetEcErr(ec.ECBadRequest)
+		ecErr.WithDetails("jid not found")
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	var opt option.AnalyzerOption
+	if err = req.ParseForm(); err == nil {
+		err = repo.FormDecoder.Decode(&opt, req.PostForm)
+	}
+	if _, mErr := runner.EmbeddingModel(&opt); err != nil || mErr != nil || opt.APIId <= 0 {
+		ecErr := ec.MustGetEcErr(ec.ECBadRequest)
+		ecErr.WithDetails("invalid analyzer options")
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	lid := opt.APIId
+	opt.APIId = 0 // omit analyzer api id
+	quota := global.AppVar.Quota.Of(userInfo.GetRole().String())
+	result, err := repo.Service.Job().Clone(req.Context(), &service.JobCloneRequest{
+		Owner:    userInfo.GetUserID(),
+		ID:       int64(jId),
+		Ulid:     ulid.Make().String(),
+		LlmApiID: int16(lid),
+		LlmQuery: opt.ToString("", ""),
+		Quota:    &quota,
+		Since:    startOfToday(),
+	})
+	if err != nil {
+		var valErr val.ValidationErrors
+		ecErr, ok := err.(*ec.Error)
+		if ec.MustGetEcErr(ec.ECPgxErrNoRows).IsEqual(err) {
+			ecErr = ec.MustGetEcErr(ec.ECForbidden)
+		} else if errors.As(err, &valErr) {
+			ecErr = ec.MustGetEcErr(ec.ECBadRequest).WithDetails("invalid analyzer options")
+		} else if !ok {
+			ecErr = ec.MustGetEcErr(ec.ECServerError).WithDetails(err.Error())
+		}
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	global.Logger.Info().
+		Str("name", userInfo.GetUsername()).
+		Int("job_id", jId).
+		Int64("clone_job_id", result.JobId).
+		Int64("n_news", result.NNews).
+		Msg("job cloned")
+
+	jsn, _ := json.Marshal(map[string]any{
+		"job-id":          result.JobId,
+		"job-cloned_from": jId,
+		"job-n_news":      result.NNews,
+	})
+	w.WriteHeader(http.StatusOK)
+	w.Write(jsn)
+}
+
+// jobEmbeddingModel returns the model that the sentiments of the job are
+// stored under.
+func jobEmbeddingModel(job *model.GetJobsByJobIdRow) (string, error) {
+	opt, err := jobAnalyzerOption(job)
+	if err != nil {
+		return "", err
+	}
+	return runner.EmbeddingModel(opt)
+}
+
+// jobAnalyzerOption returns the analyzer options the job was created with.
+func jobAnalyzerOption(job *model.GetJobsByJobIdRow) (*option.AnalyzerOption, error) {
+	var opt option.AnalyzerOption
+	if err := json.Unmarshal(job.LlmQuery, &opt); err != nil {
+		return nil, err
+	}
+	return &opt, nil
+}
+
+// CompareJob lists the sentiments of the news shared by a job and the job
+// given by the query parameter with, or the job it was cloned from.
+func (repo APIRepo) CompareJob(w http.ResponseWriter, req *http.Request) {
+	userInfo, ok := req.Context().Value(global.CtxUserInfo).(tokenmaker.Payload)
+	if !ok {
+		ecErr := ec.MustGetEcErr(ec.ECServerError)
+		ecErr.WithDetails("user information not found")
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	jId, err := convert.StrTo(chi.URLParam(req, "jId")).Int()
+	if jId <= 0 || err != nil {
+		ecErr := ec.MustGetEcErr(ec.ECBadRequest)
+		ecErr.WithDetails("jid not found")
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	getJob := func(id int64) (*model.GetJobsByJobIdRow, *ec.Error) {
+		job, err := repo.Service.Job().GetDetails(req.Context(), &service.JobGetByJobIdRequest{
+			Owner: userInfo.GetUserID(),
+			Id:    id,
+		})
+		if err != nil {
+			ecErr := ec.MustGetEcErr(ec.ECServerError)
+			if ec.MustGetEcErr(ec.ECPgxErrNoRows).IsEqual(err) {
+				ecErr = ec.MustGetEcErr(ec.ECForbidden)
+			}
+			return nil, ecErr
+		}
+		return job, nil
+	}
+
+	jobA, ecErr := getJob(int64(jId))
+	if ecErr != nil {
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	withId := jobA.ClonedFrom.Int64
+	if with := req.URL.Query().Get("with"); with != "" {
+		id, err := convert.StrTo(with).Int()
+		if id <= 0 || err != nil {
+			ecErr := ec.MustGetEcErr(ec.ECBadRequest)
+			ecErr.WithDetails("invalid job to compare with")
+			w.WriteHeader(ecErr.HttpStatusCode)
+			w.Write(ecErr.MustToJson())
+			return
+		}
+		withId = int64(id)
+	}
+	if withId <= 0 {
+		ecErr := ec.MustGetEcErr(ec.ECBadRequest)
+		ecErr.WithDetails("job to compare with not found")
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	jobB, ecErr := getJob(withId)
+	if ecErr != nil {
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	mdlA, errA := jobEmbeddingModel(jobA)
+	mdlB, errB := jobEmbeddingModel(jobB)
+	if err := errors.Join(errA, errB); err != nil {
+		ecErr := ec.MustGetEcErr(ec.ECServerError)
+		ecErr.WithDetails(err.Error())
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	rows, err := repo.Service.Embedding().CompareJobSentiments(req.Context(), &service.CompareJobSentimentsRequest{
+		JobA:   jobA.ID,
+		ModelA: mdlA,
+		JobB:   jobB.ID,
+		ModelB: mdlB,
+	})
+	if err != nil {
+		ecErr, ok := err.(*ec.Error)
+		if !ok {
+			ecErr = ec.MustGetEcErr(ec.ECServerError).WithDetails(err.Error())
+		}
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	jsn, _ := json.Marshal(object.NewJobComparison(
+		object.ComparedJob{JID: jobA.ID, Analyzer: jobA.Analyzer, Model: mdlA},
+		object.ComparedJob{JID: jobB.ID, Analyzer: jobB.Analyzer, Model: mdlB},
+		rows,
+	))
+	w.WriteHeader(http.StatusOK)
+	w.Write(jsn)
+}
+
+// GetJobStats returns the sentiment distribution of the analyzed news of a
+// job, overall and grouped by source, category, publish day and language,
+// toward each of the target entities of the job, and the label distribution
+// of the classification task of the job, the latter two overall and by source.
+func (repo APIRepo) GetJobStats(w http.ResponseWriter, req *http.Request) {
+	userInfo, ok := req.Context().Value(global.CtxUserInfo).(tokenmaker.Payload)
+	if !ok {
+		ecErr := ec.MustGetEcErr(ec.ECServerError)
+		ecErr.WithDetails("user information not found")
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	jId, err := convert.StrTo(chi.URLParam(req, "jId")).Int()
+	if jId <= 0 || err != nil {
+		ecErr := ec.MustGetEcErr(ec.ECBadRequest)
+		ecErr.WithDetails("jid not found")
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	job, err := repo.Service.Job().GetDetails(req.Context(), &service.JobGetByJobIdRequest{
+		Owner: userInfo.GetUserID(),
+		Id:    int64(jId),
+	})
+	if err != nil {
+		ecErr := ec.MustGetEcErr(ec.ECServerError)
+		if ec.MustGetEcErr(ec.ECPgxErrNoRows).IsEqual(err) {
+			ecErr = ec.MustGetEcErr(ec.ECForbidden)
+		}
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	mdl, err := jobEmbeddingModel(job)
+	if err != nil {
+		ecErr := ec.MustGetEcErr(ec.ECServerError)
+		ecErr.WithDetails(err.Error())
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	rows, err := repo.Service.Embedding().GetJobSentimentStats(req.Context(), &service.GetJobSentimentStatsRequest{
+		JobId: job.ID,
+		Model: mdl,
+	})
+	if err != nil {
+		ecErr, ok := err.(*ec.Error)
+		if !ok {
+			ecErr = ec.MustGetEcErr(ec.ECServerError).WithDetails(err.Error())
+		}
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	stats := object.NewJobSentimentStats(job.ID, mdl, rows)
+	// the options have been parsed by jobEmbeddingModel
+	opt, _ := jobAnalyzerOption(job)
+	if len(opt.Entities) > 0 {
+		eReq := &service.GetJobEntitySentimentStatsRequest{
+			JobId:    job.ID,
+			Model:    mdl,
+			Entities: opt.Entities,
+			Owner:    job.Owner,
+		}
+		if opt.Prompt != nil {
+			eReq.PromptId = opt.Prompt.ID
+		}
+		eRows, err := repo.Service.EntitySentiment().GetJobStats(req.Context(), eReq)
+		if err != nil {
+			ecErr, ok := err.(*ec.Error)
+			if !ok {
+				ecErr = ec.MustGetEcErr(ec.ECServerError).WithDetails(err.Error())
+			}
+			w.WriteHeader(ecErr.HttpStatusCode)
+			w.Write(ecErr.MustToJson())
+			return
+		}
+		stats.EntitySource = object.NewEntitySentimentStats(eRows)
+	}
+	if opt.Task != "" {
+		lRows, err := repo.Service.Annotation().GetJobStats(req.Context(), &service.GetJobAnnotationStatsRequest{
+			JobId:    job.ID,
+			Model:    mdl,
+			Owner:    job.Owner,
+			TaskHash: opt.ClassificationOptions.Hash(),
+		})
+		if err != nil {
+			ecErr, ok := err.(*ec.Error)
+			if !ok {
+				ecErr = ec.MustGetEcErr(ec.ECServerError).WithDetails(err.Error())
+			}
+			w.WriteHeader(ecErr.HttpStatusCode)
+			w.Write(ecErr.MustToJson())
+			return
+		}
+		stats.Task = opt.Task
+		stats.LabelSource = object.NewLabelStats(lRows)
+	}
+
+	jsn, _ := json.Marshal(stats)
+	w.WriteHeader(http.StatusOK)
+	w.Write(jsn)
+}
+
+// GetWebhook shows the webhooks of the user, the secret for verifying the
+// payloads and the latest deliveries.
+func (repo APIRepo) GetWebhook(w http.ResponseWriter, req *http.Request) {
+	userInfo, ok := req.Context().Value(global.CtxUserInfo).(tokenmaker.Payload)
+	if !ok {
+		ecErr := ec.MustGetEcErr(ec.ECServerError)
+		ecErr.WithDetails("user information not found")
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	webhooks, err := repo.Service.Webhook().List(req.Context(), userInfo.GetUserID())
+	if err != nil {
+		ecErr := ec.MustGetEcErr(ec.ECServerError)
+		ecErr.WithDetails(err.Error())
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	// the secret is created along with the first webhook
+	secret, err := repo.Service.Webhook().GetSecret(req.Context(), userInfo.GetUserID())
+	if err != nil && !ec.MustGetEcErr(ec.ECPgxErrNoRows).IsEqual(err) {
+		ecErr := ec.MustGetEcErr(ec.ECServerError)
+		ecErr.WithDetails(err.Error())
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	deliveries, err := repo.Service.Webhook().ListDeliveries(req.Context(),
+		&service.WebhookListDeliveriesRequest{
+			Owner: userInfo.GetUserID(),
+			N:     50,
+		})
+	if err != nil {
+		ecErr := ec.MustGetEcErr(ec.ECServerError)
+		ecErr.WithDetails(err.Error())
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	pageData := object.WebhookPage{
+		Page: object.Page{
+			HeadConent: view.SharedHeadContent(),
+			Title:      "Webhook",
+		},
+		Webhooks:   make([]object.Webhook, len(webhooks)),
+		Deliveries: make([]object.WebhookDelivery, len(deliveries)),
+	}
+	if secret != nil {
+		pageData.Secret = secret.Secret
+	}
+	for i, row := range webhooks {
+		pageData.Webhooks[i] = object.NewWebhook(row)
+	}
+	for i, row := range deliveries {
+		pageData.Deliveries[i] = object.NewWebhookDelivery(row)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := repo.View.ExecuteTemplate(w, "webhook.gotmpl", pageData); err != nil {
+		global.Logger.
+			Err(err).
+			Msg("error while ExecuteTemplate webhook.gotmpl")
+	}
+}
+
+func (repo APIRepo) PostWebhook(w http.ResponseWriter, req *http.Request) {
+	userInfo, ok := req.Context().Value(global.CtxUserInfo).(tokenmaker.Payload)
+	if !ok {
+		ecErr := ec.MustGetEcErr(ec.ECServerError)
+		ecErr.WithDetails("user information not found")
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	var webhook pageform.WebhookPost
+	err := req.ParseForm()
+	if err == nil {
+		err = repo.FormDecoder.Decode(&webhook, req.PostForm)
+	}
+	if err == nil {
+		err = repo.FormModifier.Struct(req.Context(), &webhook)
+	}
+	if err == nil {
+		webhook.Url = strings.TrimSpace(webhook.Url)
+		err = repo.Validator.StructCtx(req.Context(), &webhook)
+	}
+	if err != nil {
+		ecErr := ec.MustGetEcErr(ec.ECBadRequest)
+		ecErr.WithDetails(err.Error())
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	result, err := repo.Service.Webhook().Create(req.Context(), &service.WebhookCreateRequest{
+		Owner: userInfo.GetUserID(),
+		Url:   webhook.Url,
+	})
+	if err != nil {
+		ecErr, ok := err.(*ec.Error)
+		if !ok {
+			ecErr = ec.MustGetEcErr(ec.ECServerError).WithDetails(err.Error())
+		}
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	global.Logger.Info().
+		Str("name", userInfo.GetUsername()).
+		Int64("webhook_id", result.ID).
+		Bool("new_secret", result.NewSecret).
+		Msg("webhook successfully created")
+
+	http.Redirect(w, req, req.URL.Path, http.StatusSeeOther)
+}
+
+func (repo APIRepo) DeleteWebhook(w http.ResponseWriter, req *http.Request) {
+	userInfo, ok := req.Context().Value(global.CtxUserInfo).(tokenmaker.Payload)
+	if !ok {
+		ecErr := ec.MustGetEcErr(ec.ECServerError)
+		ecErr.WithDetails("user information not found")
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	id, err := convert.StrTo(chi.URLParam(req, "id")).Int()
+	if id <= 0 || err != nil {
+		ecErr := ec.MustGetEcErr(ec.ECBadRequest)
+		ecErr.WithDetails("webhook id not found")
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	n, err := repo.Service.Webhook().Delete(req.Context(), &service.WebhookDeleteRequest{
+		Owner: userInfo.GetUserID(),
+		ID:    int64(id),
+	})
+	if err != nil {
+		ecErr := ec.MustGetEcErr(ec.ECServerError)
+		ecErr.WithDetails(err.Error())
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	if n == 0 {
+		ecErr := ec.MustGetEcErr(ec.ECForbidden)
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("OK"))
+}
+
+// RotateWebhookSecret replaces the secret of the user with a new one, the
+// payloads are signed with the new secret from then on.
+func (repo APIRepo) RotateWebhookSecret(w http.ResponseWriter, req *http.Request) {
+	userInfo, ok := req.Context().Value(global.CtxUserInfo).(tokenmaker.Payload)
+	if !ok {
+		ecErr := ec.MustGetEcErr(ec.ECServerError)
+		ecErr.WithDetails("user information not found")
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	secret, err := repo.Service.Webhook().RotateSecret(req.Context(), userInfo.GetUserID())
+	if err != nil {
+		ecErr := ec.MustGetEcErr(ec.ECServerError)
+		ecErr.WithDetails(err.Error())
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	jsn, _ := json.Marshal(map[string]string{"webhook-secret": secret})
+	w.WriteHeader(http.StatusOK)
+	w.Write(jsn)
+}
+
+// GetWebhookDeliveries returns the latest n (default 50) deliveries to the
+// webhooks of the user, the newest first.
+func (repo APIRepo) GetWebhookDeliveries(w http.ResponseWriter, req *http.Request) {
+	userInfo, ok := req.Context().Value(global.CtxUserInfo).(tokenmaker.Payload)
+	if !ok {
+		ecErr := ec.MustGetEcErr(ec.ECServerError)
+		ecErr.WithDetails("user information not found")
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	n := 50
+	if nStr := req.URL.Query().Get("n"); nStr != "" {
+		var err error
+		if n, err = convert.StrTo(nStr).Int(); err != nil {
+			ecErr := ec.MustGetEcErr(ec.ECBadRequest)
+			ecErr.WithDetails("invalid n")
+			w.WriteHeader(ecErr.HttpStatusCode)
+			w.Write(ecErr.MustToJson())
+			return
+		}
+	}
+
+	rows, err := repo.Service.Webhook().ListDeliveries(req.Context(),
+		&service.WebhookListDeliveriesRequest{
+			Owner: userInfo.GetUserID(),
+			N:     int32(n),
+		})
+	if err != nil {
+		var valErr val.ValidationErrors
+		ecErr := ec.MustGetEcErr(ec.ECServerError)
+		if errors.As(err, &valErr) {
+			ecErr = ec.MustGetEcErr(ec.ECBadRequest)
+		}
+		ecErr.WithDetails(err.Error())
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	deliveries := make([]object.WebhookDelivery, len(rows))
+	for i, row := range rows {
+		deliveries[i] = object.NewWebhookDelivery(row)
+	}
+
+	jsn, _ := json.Marshal(deliveries)
+	w.WriteHeader(http.StatusOK)
+	w.Write(jsn)
+}
+
+// GetPrompt shows every version of the prompts of the user along with the
+// default prompt of each analyzer.
+func (repo APIRepo) GetPrompt(w http.ResponseWriter, req *http.Request) {
+	userInfo, ok := req.Context().Value(global.CtxUserInfo).(tokenmaker.Payload)
+	if !ok {
+		ecErr := ec.MustGetEcErr(ec.ECServerError)
+		ecErr.WithDetails("user information not found")
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	prompts, err := repo.Service.Prompt().List(req.Context(), userInfo.GetUserID())
+	if err != nil {
+		ecErr := ec.MustGetEcErr(ec.ECServerError)
+		ecErr.WithDetails(err.Error())
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	pageData := object.PromptPage{
+		Page: object.Page{
+			HeadConent: view.SharedHeadContent(),
+			Title:      "Prompt",
+		},
+		Providers: promptProviders(),
+		Prompts:   make([]object.Prompt, len(prompts)),
+	}
+	for i, row := range prompts {
+		pageData.Prompts[i] = object.NewPrompt(row)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := repo.View.ExecuteTemplate(w, "prompt.gotmpl", pageData); err != nil {
+		global.Logger.
+			Err(err).
+			Msg("error while ExecuteTemplate prompt.gotmpl")
+	}
+}
+
+// PostPrompt stores the body as the next version of the prompt of the user for
+// the task of the provider, the former versions are kept for the jobs that
+// have used them.
+func (repo APIRepo) PostPrompt(w http.ResponseWriter, req *http.Request) {
+	userInfo, ok := req.Context().Value(global.CtxUserInfo).(tokenmaker.Payload)
+	if !ok {
+		ecErr := ec.MustGetEcErr(ec.ECServerError)
+		ecErr.WithDetails("user information not found")
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	var prompt pageform.PromptPost
+	err := req.ParseForm()
+	if err == nil {
+		err = repo.FormDecoder.Decode(&prompt, req.PostForm)
+	}
+	if err == nil {
+		err = repo.FormModifier.Struct(req.Context(), &prompt)
+	}
+	if err == nil {
+		err = repo.Validator.StructCtx(req.Context(), &prompt)
+	}
+	if err == nil && !slices.ContainsFunc(promptProviders(), func(p object.PromptProvider) bool {
+		return p.Name == prompt.Provider
+	}) {
+		err = fmt.Errorf("unknown provider: %s", prompt.Provider)
+	}
+	if err != nil {
+		ecErr := ec.MustGetEcErr(ec.ECBadRequest)
+		ecErr.WithDetails(err.Error())
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	row, err := repo.Service.Prompt().Create(req.Context(), &service.PromptCreateRequest{
+		Owner:    userInfo.GetUserID(),
+		Provider: prompt.Provider,
+		Task:     prompt.Task,
+		Body:     prompt.Body,
+	})
+	if err != nil {
+		ecErr, ok := err.(*ec.Error)
+		if !ok {
+			ecErr = ec.MustGetEcErr(ec.ECServerError).WithDetails(err.Error())
+		}
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	global.Logger.Info().
+		Str("name", userInfo.GetUsername()).
+		Int64("prompt_id", row.ID).
+		Int32("version", row.Version).
+		Msg("prompt successfully created")
+
+	http.Redirect(w, req, req.URL.Path, http.StatusSeeOther)
+}
+
+// the stories listed are the latest ones covered by at least 2 sources
+const (
+	storyMinSources = 2
+	storyListSize   = 100
+)
+
+// GetStories lists the latest stories covered by more than one source.
+func (repo APIRepo) GetStories(w http.ResponseWriter, req *http.Request) {
+	rows, err := repo.Service.Story().List(req.Context(), &service.StoryListRequest{
+		MinSources: storyMinSources,
+		N:          storyListSize,
+	})
+	if err != nil {
+		ecErr := ec.MustGetEcErr(ec.ECServerError)
+		ecErr.WithDetails(err.Error())
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	pageData := object.StoriesPage{
+		Page: object.Page{
+			HeadConent: view.SharedHeadContent(),
+			Title:      "Stories",
+		},
+		Stories: make([]object.Story, len(rows)),
+	}
+	for i, row := range rows {
+		pageData.Stories[i] = object.NewStory(row)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := repo.View.ExecuteTemplate(w, "stories.gotmpl", pageData); err != nil {
+		global.Logger.
+			Err(err).
+			Msg("error while ExecuteTemplate stories.gotmpl")
+	}
+}
+
+// GetStory shows the headline and the sentiment of every source of the story
+// side by side.
+func (repo APIRepo) GetStory(w http.ResponseWriter, req *http.Request) {
+	sId, err := convert.StrTo(chi.URLParam(req, "sId")).Int()
+	if sId <= 0 || err != nil {
+		ecErr := ec.MustGetEcErr(ec.ECBadRequest)
+		ecErr.WithDetails("invalid story id")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	story, err := repo.Service.Story().Get(req.Context(), int64(sId))
+	var news []*model.GetStoryNewsRow
+	if err == nil {
+		news, err = repo.Service.Story().GetNews(req.Context(), int64(sId))
+	}
+	if err != nil {
+		ecErr := ec.MustGetEcErr(ec.ECServerError)
+		if ec.MustGetEcErr(ec.ECPgxErrNoRows).IsEqual(err) {
+			ecErr = ec.MustGetEcErr(ec.ECNotFound)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(ecErr.HttpStatusCode)
+		w.Write(ecErr.MustToJson())
+		return
+	}
+
+	pageData := object.StoryPage{
+		Page: object.Page{
+			HeadConent: view.SharedHeadContent(),
+			Title:      "Story",
+		},
+		Story: object.Story{
+			ID:             story.ID,
+			Model:          story.Model,
+			Title:          story.Title,
+			FirstPublishAt: story.FirstPublishAt.Time.UTC().Format(time.DateTime),
+			LastPublishAt:  story.LastPublishAt.Time.UTC().Format(time.DateTime),
+			NSources:       int32(len(news)),
+		},
+		News: make([]object.StoryNews, len(news)),
+	}
+	for i, row := range news {
+		pageData.News[i] = object.NewStoryNews(row)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := repo.View.ExecuteTemplate(w, "story.gotmpl", pageData); err != nil {
+		global.Logger.
+			Err(err).
+			Msg("error while ExecuteTemplate story.gotmpl")
+	}
+}
+
 func (repo APIRepo) EndpointRepo() EndpointRepo {
 	return NewEndpointRepo(repo, validator.Validate)
 }
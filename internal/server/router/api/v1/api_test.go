@@ -1,17 +1,23 @@
 package api_test
 
 import (
+	"context"
 	"crypto/rand"
 	"crypto/tls"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/ChiaYuChang/NewsSentimentAnalyzer/global"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client/option"
 	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/model"
 	mock_model "github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/model/mockdb"
 	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/model/testtool"
@@ -24,8 +30,16 @@ import (
 	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/server/service"
 	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/server/validator"
 	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/server/view"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/server/view/object"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/pkgs/cache"
+	ec "github.com/ChiaYuChang/NewsSentimentAnalyzer/pkgs/errorCode"
 	"github.com/go-playground/form"
+	"github.com/go-playground/mold/v4"
 	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	pgv "github.com/pgvector/pgvector-go"
+	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/require"
 )
 
@@ -94,6 +108,10 @@ func TestGetWelcome(t *testing.T) {
 			SetupStore: func(t *testing.T) model.Store {
 				ctl := gomock.NewController(t)
 				store := mock_model.NewMockStore(ctl)
+				store.EXPECT().
+					GetJobQuotaUsage(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&model.GetJobQuotaUsageRow{NRunning: 1, NToday: 3}, nil)
 				return store
 			},
 			SetupServer: func(store model.Store) *chi.Mux {
@@ -127,6 +145,7 @@ func TestGetWelcome(t *testing.T) {
 				require.NoError(t, err)
 				require.Contains(t, string(body), fmt.Sprintf("<h1>Welcome %s</h1>", user.Email))
 				require.Contains(t, string(body), "<title>Welcome</title>")
+				require.Contains(t, string(body), "Remaining Quota")
 			},
 		},
 		{
@@ -220,3 +239,2232 @@ func TestGetWelcome(t *testing.T) {
 		)
 	}
 }
+
+func TestCancelJob(t *testing.T) {
+	version := "v1"
+
+	cli := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true,
+			},
+		}}
+
+	tm := middleware.NewJWTTokenMaker(opt)
+	tm.AllowFromHTTPCookie = true
+
+	user, _ := testtool.GenRdmUser()
+	bearer, err := tm.TokenMaker.MakeToken(user.Email, user.ID, tokenmaker.ParseRole(user.Role))
+	require.NoError(t, err)
+
+	type testCase struct {
+		Name       string
+		Method     string
+		JId        string
+		SetupStore func(t *testing.T) model.Store
+		StatusCode int
+	}
+
+	tcs := []testCase{
+		{
+			Name:   "Cancel created or running job",
+			Method: http.MethodPatch,
+			JId:    "10",
+			SetupStore: func(t *testing.T) model.Store {
+				ctl := gomock.NewController(t)
+				store := mock_model.NewMockStore(ctl)
+				store.
+					EXPECT().
+					UpdateJobStatusFrom(gomock.Any(), gomock.Eq(&model.UpdateJobStatusFromParams{
+						ToStatus: model.JobStatusCanceled,
+						ID:       10,
+						Owner:    user.ID,
+						FromStatus: []string{
+							string(model.JobStatusCreated),
+							string(model.JobStatusRunning),
+						},
+					})).
+					Times(1).
+					Return(int64(1), nil)
+				return store
+			},
+			StatusCode: http.StatusOK,
+		},
+		{
+			Name:   "Cancel with DELETE method",
+			Method: http.MethodDelete,
+			JId:    "12",
+			SetupStore: func(t *testing.T) model.Store {
+				ctl := gomock.NewController(t)
+				store := mock_model.NewMockStore(ctl)
+				store.
+					EXPECT().
+					UpdateJobStatusFrom(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(1), nil)
+				return store
+			},
+			StatusCode: http.StatusOK,
+		},
+		{
+			Name:   "Cancel finished job",
+			Method: http.MethodPatch,
+			JId:    "11",
+			SetupStore: func(t *testing.T) model.Store {
+				ctl := gomock.NewController(t)
+				store := mock_model.NewMockStore(ctl)
+				store.
+					EXPECT().
+					UpdateJobStatusFrom(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(0), nil)
+				return store
+			},
+			StatusCode: http.StatusConflict,
+		},
+		{
+			Name:   "Invalid job id",
+			Method: http.MethodPatch,
+			JId:    "abc",
+			SetupStore: func(t *testing.T) model.Store {
+				ctl := gomock.NewController(t)
+				return mock_model.NewMockStore(ctl)
+			},
+			StatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for i, tc := range tcs {
+		t.Run(
+			fmt.Sprintf("Case %d-%s", i+1, tc.Name),
+			func(t *testing.T) {
+				apiRepo := api.APIRepo{
+					Version:    version,
+					Service:    service.NewService(tc.SetupStore(t), validator.Validate),
+					TokenMaker: tm,
+				}
+				mux := chi.NewMux()
+				mux.Use(tm.BearerAuthenticator)
+				mux.Patch(fmt.Sprintf("/%s/job/{jId}", version), apiRepo.CancelJob)
+				mux.Delete(fmt.Sprintf("/%s/job/{jId}", version), apiRepo.CancelJob)
+				srv := httptest.NewTLSServer(mux)
+				defer srv.Close()
+
+				req, err := http.NewRequest(tc.Method, fmt.Sprintf("%s/%s/job/%s", srv.URL, version, tc.JId), nil)
+				require.NoError(t, err)
+				req.AddCookie(&http.Cookie{
+					Name:  cookiemaker.AUTH_COOKIE_KEY,
+					Value: bearer,
+					Path:  "/",
+				})
+
+				resp, err := cli.Do(req)
+				require.NoError(t, err)
+				defer resp.Body.Close()
+				require.Equal(t, tc.StatusCode, resp.StatusCode)
+			},
+		)
+	}
+}
+
+func TestGetJobProgress(t *testing.T) {
+	version := "v1"
+
+	cli := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true,
+			},
+		}}
+
+	tm := middleware.NewJWTTokenMaker(opt)
+	tm.AllowFromHTTPCookie = true
+
+	user, _ := testtool.GenRdmUser()
+	bearer, err := tm.TokenMaker.MakeToken(user.Email, user.ID, tokenmaker.ParseRole(user.Role))
+	require.NoError(t, err)
+
+	type testCase struct {
+		Name       string
+		JId        string
+		SetupStore func(t *testing.T) model.Store
+		StatusCode int
+		Events     []string
+	}
+
+	tcs := []testCase{
+		{
+			Name: "Stream until the job is done",
+			JId:  "10",
+			SetupStore: func(t *testing.T) model.Store {
+				ctl := gomock.NewController(t)
+				store := mock_model.NewMockStore(ctl)
+				params := &model.GetJobProgressParams{Owner: user.ID, ID: 10}
+				gomock.InOrder(
+					store.
+						EXPECT().
+						GetJobProgress(gomock.Any(), gomock.Eq(params)).
+						Times(1).
+						Return(&model.GetJobProgressRow{
+							JobID: 10, Status: model.JobStatusRunning,
+							Total: 3, Parsed: 3, LangDetected: 3, Embedded: 1, SentimentScored: 1,
+						}, nil),
+					store.
+						EXPECT().
+						GetJobProgress(gomock.Any(), gomock.Eq(params)).
+						Times(1).
+						Return(&model.GetJobProgressRow{
+							JobID: 10, Status: model.JobStatusDone,
+							Total: 3, Parsed: 3, LangDetected: 3, Embedded: 3, SentimentScored: 2, Failed: 1,
+						}, nil),
+				)
+				return store
+			},
+			StatusCode: http.StatusOK,
+			Events:     []string{"progress", "progress", "end"},
+		},
+		{
+			Name: "Job not found",
+			JId:  "11",
+			SetupStore: func(t *testing.T) model.Store {
+				ctl := gomock.NewController(t)
+				store := mock_model.NewMockStore(ctl)
+				store.
+					EXPECT().
+					GetJobProgress(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, pgx.ErrNoRows)
+				return store
+			},
+			StatusCode: http.StatusForbidden,
+		},
+		{
+			Name: "Invalid job id",
+			JId:  "abc",
+			SetupStore: func(t *testing.T) model.Store {
+				ctl := gomock.NewController(t)
+				return mock_model.NewMockStore(ctl)
+			},
+			StatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for i, tc := range tcs {
+		t.Run(
+			fmt.Sprintf("Case %d-%s", i+1, tc.Name),
+			func(t *testing.T) {
+				apiRepo := api.APIRepo{
+					Version:    version,
+					Service:    service.NewService(tc.SetupStore(t), validator.Validate),
+					TokenMaker: tm,
+				}
+				mux := chi.NewMux()
+				mux.Use(tm.BearerAuthenticator)
+				mux.Get(fmt.Sprintf("/%s/job/{jId}/progress", version), apiRepo.GetJobProgress)
+				srv := httptest.NewTLSServer(mux)
+				defer srv.Close()
+
+				req, err := http.NewRequest(http.MethodGet,
+					fmt.Sprintf("%s/%s/job/%s/progress", srv.URL, version, tc.JId), nil)
+				require.NoError(t, err)
+				req.AddCookie(&http.Cookie{
+					Name:  cookiemaker.AUTH_COOKIE_KEY,
+					Value: bearer,
+					Path:  "/",
+				})
+
+				resp, err := cli.Do(req)
+				require.NoError(t, err)
+				defer resp.Body.Close()
+				require.Equal(t, tc.StatusCode, resp.StatusCode)
+				if tc.StatusCode != http.StatusOK {
+					return
+				}
+				require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+				// the stream is closed after the end event
+				body, err := io.ReadAll(resp.Body)
+				require.NoError(t, err)
+
+				events := []string{}
+				for _, line := range strings.Split(string(body), "\n") {
+					if e, ok := strings.CutPrefix(line, "event: "); ok {
+						events = append(events, e)
+					}
+				}
+				require.Equal(t, tc.Events, events)
+			},
+		)
+	}
+}
+
+func TestRequeueJobItems(t *testing.T) {
+	version := "v1"
+
+	cli := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true,
+			},
+		}}
+
+	tm := middleware.NewJWTTokenMaker(opt)
+	tm.AllowFromHTTPCookie = true
+
+	user, _ := testtool.GenRdmUser()
+	bearer, err := tm.TokenMaker.MakeToken(user.Email, user.ID, tokenmaker.ParseRole(user.Role))
+	require.NoError(t, err)
+
+	type testCase struct {
+		Name       string
+		Path       string
+		SetupStore func(t *testing.T) model.Store
+		StatusCode int
+	}
+
+	tcs := []testCase{
+		{
+			Name: "Requeue all dead items",
+			Path: "10/items",
+			SetupStore: func(t *testing.T) model.Store {
+				ctl := gomock.NewController(t)
+				store := mock_model.NewMockStore(ctl)
+				store.
+					EXPECT().
+					DoRequeueJobItemsTx(gomock.Any(), gomock.Eq(&model.RequeueJobItemsTxParams{
+						JobID: 10, Owner: user.ID, ItemID: 0,
+					})).
+					Times(1).
+					Return(&model.RequeueJobItemsTxResult{N: 2, Status: model.JobStatusCreated}, nil)
+				return store
+			},
+			StatusCode: http.StatusOK,
+		},
+		{
+			Name: "Requeue a dead item",
+			Path: "10/items/3",
+			SetupStore: func(t *testing.T) model.Store {
+				ctl := gomock.NewController(t)
+				store := mock_model.NewMockStore(ctl)
+				store.
+					EXPECT().
+					DoRequeueJobItemsTx(gomock.Any(), gomock.Eq(&model.RequeueJobItemsTxParams{
+						JobID: 10, Owner: user.ID, ItemID: 3,
+					})).
+					Times(1).
+					Return(&model.RequeueJobItemsTxResult{N: 1, Status: model.JobStatusCreated}, nil)
+				return store
+			},
+			StatusCode: http.StatusOK,
+		},
+		{
+			Name: "No dead item",
+			Path: "11/items",
+			SetupStore: func(t *testing.T) model.Store {
+				ctl := gomock.NewController(t)
+				store := mock_model.NewMockStore(ctl)
+				store.
+					EXPECT().
+					DoRequeueJobItemsTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&model.RequeueJobItemsTxResult{}, nil)
+				return store
+			},
+			StatusCode: http.StatusConflict,
+		},
+		{
+			Name: "Canceled job",
+			Path: "12/items",
+			SetupStore: func(t *testing.T) model.Store {
+				ctl := gomock.NewController(t)
+				store := mock_model.NewMockStore(ctl)
+				store.
+					EXPECT().
+					DoRequeueJobItemsTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, ec.MustGetEcErr(ec.ECConflict).WithDetails("canceled job can not be requeued"))
+				return store
+			},
+			StatusCode: http.StatusConflict,
+		},
+		{
+			Name: "Job not found",
+			Path: "13/items",
+			SetupStore: func(t *testing.T) model.Store {
+				ctl := gomock.NewController(t)
+				store := mock_model.NewMockStore(ctl)
+				store.
+					EXPECT().
+					DoRequeueJobItemsTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, pgx.ErrNoRows)
+				return store
+			},
+			StatusCode: http.StatusForbidden,
+		},
+		{
+			Name: "Invalid item id",
+			Path: "10/items/abc",
+			SetupStore: func(t *testing.T) model.Store {
+				ctl := gomock.NewController(t)
+				return mock_model.NewMockStore(ctl)
+			},
+			StatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for i, tc := range tcs {
+		t.Run(
+			fmt.Sprintf("Case %d-%s", i+1, tc.Name),
+			func(t *testing.T) {
+				apiRepo := api.APIRepo{
+					Version:    version,
+					Service:    service.NewService(tc.SetupStore(t), validator.Validate),
+					TokenMaker: tm,
+				}
+				mux := chi.NewMux()
+				mux.Use(tm.BearerAuthenticator)
+				mux.Patch(fmt.Sprintf("/%s/job/{jId}/items", version), apiRepo.RequeueJobItems)
+				mux.Patch(fmt.Sprintf("/%s/job/{jId}/items/{iId}", version), apiRepo.RequeueJobItems)
+				srv := httptest.NewTLSServer(mux)
+				defer srv.Close()
+
+				req, err := http.NewRequest(http.MethodPatch,
+					fmt.Sprintf("%s/%s/job/%s", srv.URL, version, tc.Path), nil)
+				require.NoError(t, err)
+				req.AddCookie(&http.Cookie{
+					Name:  cookiemaker.AUTH_COOKIE_KEY,
+					Value: bearer,
+					Path:  "/",
+				})
+
+				resp, err := cli.Do(req)
+				require.NoError(t, err)
+				defer resp.Body.Close()
+				require.Equal(t, tc.StatusCode, resp.StatusCode)
+			},
+		)
+	}
+}
+
+func TestJobSchedule(t *testing.T) {
+	version := "v1"
+
+	cli := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true,
+			},
+		}}
+
+	tm := middleware.NewJWTTokenMaker(opt)
+	tm.AllowFromHTTPCookie = true
+
+	user, _ := testtool.GenRdmUser()
+	bearer, err := tm.TokenMaker.MakeToken(user.Email, user.ID, tokenmaker.ParseRole(user.Role))
+	require.NoError(t, err)
+
+	row := &model.GetJobScheduleRow{
+		JobID:     10,
+		CronExpr:  "0 8 * * *",
+		NextRunAt: pgtype.Timestamptz{Time: time.Now().Add(time.Hour), Valid: true},
+		NRuns:     3,
+	}
+
+	type testCase struct {
+		Name       string
+		Method     string
+		Path       string
+		Schedule   string
+		SetupStore func(t *testing.T) model.Store
+		StatusCode int
+	}
+
+	tcs := []testCase{
+		{
+			Name:   "Get schedule",
+			Method: http.MethodGet,
+			Path:   "10/schedule",
+			SetupStore: func(t *testing.T) model.Store {
+				ctl := gomock.NewController(t)
+				store := mock_model.NewMockStore(ctl)
+				store.
+					EXPECT().
+					GetJobSchedule(gomock.Any(), gomock.Eq(&model.GetJobScheduleParams{
+						Owner: user.ID, ID: 10,
+					})).
+					Times(1).
+					Return(row, nil)
+				return store
+			},
+			StatusCode: http.StatusOK,
+		},
+		{
+			Name:   "Get schedule of a job not scheduled",
+			Method: http.MethodGet,
+			Path:   "11/schedule",
+			SetupStore: func(t *testing.T) model.Store {
+				ctl := gomock.NewController(t)
+				store := mock_model.NewMockStore(ctl)
+				store.
+					EXPECT().
+					GetJobSchedule(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, pgx.ErrNoRows)
+				return store
+			},
+			StatusCode: http.StatusNotFound,
+		},
+		{
+			Name:   "Invalid job id",
+			Method: http.MethodGet,
+			Path:   "abc/schedule",
+			SetupStore: func(t *testing.T) model.Store {
+				ctl := gomock.NewController(t)
+				return mock_model.NewMockStore(ctl)
+			},
+			StatusCode: http.StatusBadRequest,
+		},
+		{
+			Name:     "Update schedule",
+			Method:   http.MethodPut,
+			Path:     "10/schedule",
+			Schedule: "0 8 * * *",
+			SetupStore: func(t *testing.T) model.Store {
+				ctl := gomock.NewController(t)
+				store := mock_model.NewMockStore(ctl)
+				gomock.InOrder(
+					store.
+						EXPECT().
+						UpdateJobSchedule(gomock.Any(), gomock.Any()).
+						Times(1).
+						DoAndReturn(func(_ context.Context, params *model.UpdateJobScheduleParams) (int64, error) {
+							require.Equal(t, user.ID, params.Owner)
+							require.Equal(t, int64(10), params.JobID)
+							require.Equal(t, "0 8 * * *", params.CronExpr)
+							require.True(t, params.NextRunAt.Time.After(time.Now()))
+							return 1, nil
+						}),
+					store.
+						EXPECT().
+						GetJobSchedule(gomock.Any(), gomock.Any()).
+						Times(1).
+						Return(row, nil),
+				)
+				return store
+			},
+			StatusCode: http.StatusOK,
+		},
+		{
+			Name:     "Invalid schedule",
+			Method:   http.MethodPut,
+			Path:     "10/schedule",
+			Schedule: "61 * * * *",
+			SetupStore: func(t *testing.T) model.Store {
+				ctl := gomock.NewController(t)
+				return mock_model.NewMockStore(ctl)
+			},
+			StatusCode: http.StatusBadRequest,
+		},
+		{
+			Name:     "Schedule which never runs",
+			Method:   http.MethodPut,
+			Path:     "10/schedule",
+			Schedule: "0 0 31 2 *",
+			SetupStore: func(t *testing.T) model.Store {
+				ctl := gomock.NewController(t)
+				return mock_model.NewMockStore(ctl)
+			},
+			StatusCode: http.StatusBadRequest,
+		},
+		{
+			Name:     "Update schedule of a job not scheduled",
+			Method:   http.MethodPut,
+			Path:     "11/schedule",
+			Schedule: "@daily",
+			SetupStore: func(t *testing.T) model.Store {
+				ctl := gomock.NewController(t)
+				store := mock_model.NewMockStore(ctl)
+				store.
+					EXPECT().
+					UpdateJobSchedule(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(0), nil)
+				return store
+			},
+			StatusCode: http.StatusNotFound,
+		},
+		{
+			Name:   "Delete schedule",
+			Method: http.MethodDelete,
+			Path:   "10/schedule",
+			SetupStore: func(t *testing.T) model.Store {
+				ctl := gomock.NewController(t)
+				store := mock_model.NewMockStore(ctl)
+				store.
+					EXPECT().
+					DeleteJobSchedule(gomock.Any(), gomock.Eq(&model.DeleteJobScheduleParams{
+						Owner: user.ID, ID: 10,
+					})).
+					Times(1).
+					Return(int64(1), nil)
+				return store
+			},
+			StatusCode: http.StatusOK,
+		},
+		{
+			Name:   "Delete schedule of a job not scheduled",
+			Method: http.MethodDelete,
+			Path:   "11/schedule",
+			SetupStore: func(t *testing.T) model.Store {
+				ctl := gomock.NewController(t)
+				store := mock_model.NewMockStore(ctl)
+				store.
+					EXPECT().
+					DeleteJobSchedule(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(0), nil)
+				return store
+			},
+			StatusCode: http.StatusNotFound,
+		},
+	}
+
+	for i, tc := range tcs {
+		t.Run(
+			fmt.Sprintf("Case %d-%s", i+1, tc.Name),
+			func(t *testing.T) {
+				apiRepo := api.APIRepo{
+					Version:    version,
+					Service:    service.NewService(tc.SetupStore(t), validator.Validate),
+					TokenMaker: tm,
+				}
+				mux := chi.NewMux()
+				mux.Use(tm.BearerAuthenticator)
+				mux.Get(fmt.Sprintf("/%s/job/{jId}/schedule", version), apiRepo.GetJobSchedule)
+				mux.Put(fmt.Sprintf("/%s/job/{jId}/schedule", version), apiRepo.UpdateJobSchedule)
+				mux.Delete(fmt.Sprintf("/%s/job/{jId}/schedule", version), apiRepo.DeleteJobSchedule)
+				srv := httptest.NewTLSServer(mux)
+				defer srv.Close()
+
+				form := url.Values{}
+				form.Set("schedule", tc.Schedule)
+				req, err := http.NewRequest(tc.Method,
+					fmt.Sprintf("%s/%s/job/%s", srv.URL, version, tc.Path),
+					strings.NewReader(form.Encode()))
+				require.NoError(t, err)
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+				req.AddCookie(&http.Cookie{
+					Name:  cookiemaker.AUTH_COOKIE_KEY,
+					Value: bearer,
+					Path:  "/",
+				})
+
+				resp, err := cli.Do(req)
+				require.NoError(t, err)
+				defer resp.Body.Close()
+				require.Equal(t, tc.StatusCode, resp.StatusCode)
+
+				if tc.StatusCode == http.StatusOK && tc.Method != http.MethodDelete {
+					var sched object.JobSchedule
+					require.NoError(t, json.NewDecoder(resp.Body).Decode(&sched))
+					require.Equal(t, row.JobID, sched.JID)
+					require.Equal(t, row.CronExpr, sched.CronExpr)
+					require.Equal(t, row.NRuns, sched.NRuns)
+				}
+			},
+		)
+	}
+}
+
+func TestExportJob(t *testing.T) {
+	version := "v1"
+
+	cli := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true,
+			},
+		}}
+
+	tm := middleware.NewJWTTokenMaker(opt)
+	tm.AllowFromHTTPCookie = true
+
+	user, _ := testtool.GenRdmUser()
+	bearer, err := tm.TokenMaker.MakeToken(user.Email, user.ID, tokenmaker.ParseRole(user.Role))
+	require.NoError(t, err)
+
+	row := &model.GetEmbeddingByJobIdRow{
+		ID:         1,
+		JobID:      10,
+		NewsID:     100,
+		Model:      "text-embedding-ada-002",
+		Embedding:  pgv.NewVector([]float32{0.1, 0.2}),
+		Sentiment:  model.SentimentNegative,
+		Score:      2,
+		Confidence: pgtype.Float4{Float32: 0.8, Valid: true},
+		Rationale:  pgtype.Text{String: "layoffs", Valid: true},
+		Title:      "title",
+		Link:       "https://example.com",
+		Source:     "example.com",
+		PublishAt:  pgtype.Timestamptz{Time: time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+		Language:   pgtype.Text{String: "en", Valid: true},
+	}
+	llmQuery := []byte(`{"api":"openai","embedding-options":{"embedding":true,"embedding_model":""}}`)
+
+	type testCase struct {
+		Name        string
+		Path        string
+		SetupStore  func(t *testing.T) model.Store
+		StatusCode  int
+		ContentType string
+		Body        string
+	}
+
+	tcs := []testCase{
+		{
+			Name: "Export as csv",
+			Path: "10/export?format=csv",
+			SetupStore: func(t *testing.T) model.Store {
+				ctl := gomock.NewController(t)
+				store := mock_model.NewMockStore(ctl)
+				gomock.InOrder(
+					store.
+						EXPECT().
+						GetJobsByJobId(gomock.Any(), gomock.Eq(&model.GetJobsByJobIdParams{
+							ID: 10, Owner: user.ID,
+						})).
+						Times(1).
+						Return(&model.GetJobsByJobIdRow{ID: 10, LlmQuery: llmQuery}, nil),
+					store.
+						EXPECT().
+						GetEmbeddingByJobId(gomock.Any(), gomock.Eq(&model.GetEmbeddingByJobIdParams{
+							JobID: 10, Model: "text-embedding-ada-002", Next: 0, N: global.JOB_EXPORT_PAGE_SIZE,
+						})).
+						Times(1).
+						Return([]*model.GetEmbeddingByJobIdRow{row}, nil),
+					store.
+						EXPECT().
+						GetEmbeddingByJobId(gomock.Any(), gomock.Eq(&model.GetEmbeddingByJobIdParams{
+							JobID: 10, Model: "text-embedding-ada-002", Next: 1, N: global.JOB_EXPORT_PAGE_SIZE,
+						})).
+						Times(1).
+						Return(nil, nil),
+				)
+				return store
+			},
+			StatusCode:  http.StatusOK,
+			ContentType: "text/csv; charset=utf-8",
+			Body: "news_id,title,link,source,publish_at,language,sentiment,score,confidence,rationale,model\n" +
+				"100,title,https://example.com,example.com,2023-12-01T00:00:00Z,en,negative,2,0.8,layoffs,text-embedding-ada-002\n",
+		},
+		{
+			// the news has also been analyzed by another job with another
+			// model, of which the embedding has a different dimension
+			Name: "Export with mixed models",
+			Path: "10/export?format=csv",
+			SetupStore: func(t *testing.T) model.Store {
+				other := *row
+				other.ID, other.Model = 2, "embed-multilingual-v3.0"
+				other.Embedding = pgv.NewVector([]float32{0.1, 0.2, 0.3})
+				other.Sentiment, other.Score = model.SentimentPositive, 4
+				rows := []*model.GetEmbeddingByJobIdRow{row, &other}
+
+				ctl := gomock.NewController(t)
+				store := mock_model.NewMockStore(ctl)
+				store.
+					EXPECT().
+					GetJobsByJobId(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&model.GetJobsByJobIdRow{ID: 10, LlmQuery: []byte(
+						`{"api":"cohere","embedding-options":{"embedding":true,"embedding_model":"embed-multilingual-v3.0"}}`)}, nil)
+				store.
+					EXPECT().
+					GetEmbeddingByJobId(gomock.Any(), gomock.Any()).
+					Times(2).
+					DoAndReturn(func(_ context.Context, params *model.GetEmbeddingByJobIdParams) ([]*model.GetEmbeddingByJobIdRow, error) {
+						page := []*model.GetEmbeddingByJobIdRow{}
+						for _, r := range rows {
+							if r.Model == params.Model && r.ID > params.Next {
+								page = append(page, r)
+							}
+						}
+						return page, nil
+					})
+				return store
+			},
+			StatusCode:  http.StatusOK,
+			ContentType: "text/csv; charset=utf-8",
+			Body: "news_id,title,link,source,publish_at,language,sentiment,score,confidence,rationale,model\n" +
+				"100,title,https://example.com,example.com,2023-12-01T00:00:00Z,en,positive,4,0.8,layoffs,embed-multilingual-v3.0\n",
+		},
+		{
+			Name: "Unknown format",
+			Path: "10/export?format=xlsx",
+			SetupStore: func(t *testing.T) model.Store {
+				ctl := gomock.NewController(t)
+				return mock_model.NewMockStore(ctl)
+			},
+			StatusCode: http.StatusBadRequest,
+		},
+		{
+			Name: "Job not found",
+			Path: "11/export?format=zip",
+			SetupStore: func(t *testing.T) model.Store {
+				ctl := gomock.NewController(t)
+				store := mock_model.NewMockStore(ctl)
+				store.
+					EXPECT().
+					GetJobsByJobId(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, pgx.ErrNoRows)
+				return store
+			},
+			StatusCode: http.StatusForbidden,
+		},
+	}
+
+	for i, tc := range tcs {
+		t.Run(
+			fmt.Sprintf("Case %d-%s", i+1, tc.Name),
+			func(t *testing.T) {
+				apiRepo := api.APIRepo{
+					Version:    version,
+					Service:    service.NewService(tc.SetupStore(t), validator.Validate),
+					TokenMaker: tm,
+				}
+				mux := chi.NewMux()
+				mux.Use(tm.BearerAuthenticator)
+				mux.Get(fmt.Sprintf("/%s/job/{jId}/export", version), apiRepo.ExportJob)
+				srv := httptest.NewTLSServer(mux)
+				defer srv.Close()
+
+				req, err := http.NewRequest(http.MethodGet,
+					fmt.Sprintf("%s/%s/job/%s", srv.URL, version, tc.Path), nil)
+				require.NoError(t, err)
+				req.AddCookie(&http.Cookie{
+					Name:  cookiemaker.AUTH_COOKIE_KEY,
+					Value: bearer,
+					Path:  "/",
+				})
+
+				resp, err := cli.Do(req)
+				require.NoError(t, err)
+				defer resp.Body.Close()
+				require.Equal(t, tc.StatusCode, resp.StatusCode)
+
+				if tc.StatusCode == http.StatusOK {
+					require.Equal(t, tc.ContentType, resp.Header.Get("Content-Type"))
+					require.Equal(t, `attachment; filename="job-10.csv"`, resp.Header.Get("Content-Disposition"))
+					body, err := io.ReadAll(resp.Body)
+					require.NoError(t, err)
+					require.Equal(t, tc.Body, string(body))
+				}
+			},
+		)
+	}
+}
+
+func TestCloneJob(t *testing.T) {
+	version := "v1"
+
+	cli := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true,
+			},
+		}}
+
+	tm := middleware.NewJWTTokenMaker(opt)
+	tm.AllowFromHTTPCookie = true
+
+	user, _ := testtool.GenRdmUser()
+	bearer, err := tm.TokenMaker.MakeToken(user.Email, user.ID, tokenmaker.ParseRole(user.Role))
+	require.NoError(t, err)
+
+	type testCase struct {
+		Name       string
+		Path       string
+		Form       url.Values
+		SetupStore func(t *testing.T) model.Store
+		StatusCode int
+	}
+
+	tcs := []testCase{
+		{
+			Name: "Clone with cohere",
+			Path: "10/clone",
+			Form: url.Values{
+				"api":             {"cohere"},
+				"llm-api-id":      {"6"},
+				"embedding-model": {"embed-multilingual-v3.0"},
+				"do-embedding":    {"true"},
+				"do-sentiment":    {"true"},
+			},
+			SetupStore: func(t *testing.T) model.Store {
+				ctl := gomock.NewController(t)
+				store := mock_model.NewMockStore(ctl)
+				store.
+					EXPECT().
+					DoCloneJobTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, params *model.CloneJobTxParams) (*model.CloneJobTxResult, error) {
+						require.Equal(t, user.ID, params.Owner)
+						require.Equal(t, int64(10), params.ID)
+						require.Equal(t, int16(6), params.LlmApiID)
+						require.Len(t, params.Ulid, 26)
+
+						var opt option.AnalyzerOption
+						require.NoError(t, json.Unmarshal(params.LlmQuery, &opt))
+						require.Equal(t, "cohere", opt.APIName)
+						require.Equal(t, "embed-multilingual-v3.0", opt.EmbeddingModel)
+						// the api id is stored in its own column
+						require.Zero(t, opt.APIId)
+						return &model.CloneJobTxResult{JobId: 12, NNews: 30}, nil
+					})
+				return store
+			},
+			StatusCode: http.StatusOK,
+		},
+		{
+			Name: "Quota exceeded",
+			Path: "10/clone",
+			Form: url.Values{
+				"api":        {"openai"},
+				"llm-api-id": {"5"},
+			},
+			SetupStore: func(t *testing.T) model.Store {
+				ctl := gomock.NewController(t)
+				store := mock_model.NewMockStore(ctl)
+				store.
+					EXPECT().
+					DoCloneJobTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, params *model.CloneJobTxParams) (*model.CloneJobTxResult, error) {
+						// the quota is checked in the transaction cloning the job
+						require.NotNil(t, params.CheckQuota)
+						require.True(t, params.QuotaSince.Valid)
+						err := params.CheckQuota(&model.GetJobQuotaUsageRow{NRunning: 2, NToday: 2}, 30)
+						require.Error(t, err)
+						return nil, err
+					})
+				return store
+			},
+			StatusCode: http.StatusTooManyRequests,
+		},
+		{
+			Name: "Unknown analyzer",
+			Path: "10/clone",
+			Form: url.Values{
+				"api":        {"bard"},
+				"llm-api-id": {"7"},
+			},
+			SetupStore: func(t *testing.T) model.Store {
+				ctl := gomock.NewController(t)
+				return mock_model.NewMockStore(ctl)
+			},
+			StatusCode: http.StatusBadRequest,
+		},
+		{
+			Name: "Missing analyzer api id",
+			Path: "10/clone",
+			Form: url.Values{
+				"api": {"openai"},
+			},
+			SetupStore: func(t *testing.T) model.Store {
+				ctl := gomock.NewController(t)
+				return mock_model.NewMockStore(ctl)
+			},
+			StatusCode: http.StatusBadRequest,
+		},
+		{
+			Name: "Job not found",
+			Path: "11/clone",
+			Form: url.Values{
+				"api":        {"openai"},
+				"llm-api-id": {"5"},
+			},
+			SetupStore: func(t *testing.T) model.Store {
+				ctl := gomock.NewController(t)
+				store := mock_model.NewMockStore(ctl)
+				store.
+					EXPECT().
+					DoCloneJobTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, pgx.ErrNoRows)
+				return store
+			},
+			StatusCode: http.StatusForbidden,
+		},
+	}
+
+	quota := global.AppVar.Quota
+	defer func() { global.AppVar.Quota = quota }()
+	global.AppVar.Quota = global.QuotaOptions{"user": {MaxRunningJobs: 2}}
+
+	for i, tc := range tcs {
+		t.Run(
+			fmt.Sprintf("Case %d-%s", i+1, tc.Name),
+			func(t *testing.T) {
+				apiRepo := api.APIRepo{
+					Version:     version,
+					Service:     service.NewService(tc.SetupStore(t), validator.Validate),
+					TokenMaker:  tm,
+					FormDecoder: form.NewDecoder(),
+				}
+				mux := chi.NewMux()
+				mux.Use(tm.BearerAuthenticator)
+				mux.Post(fmt.Sprintf("/%s/job/{jId}/clone", version), apiRepo.CloneJob)
+				srv := httptest.NewTLSServer(mux)
+				defer srv.Close()
+
+				req, err := http.NewRequest(http.MethodPost,
+					fmt.Sprintf("%s/%s/job/%s", srv.URL, version, tc.Path),
+					strings.NewReader(tc.Form.Encode()))
+				require.NoError(t, err)
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+				req.AddCookie(&http.Cookie{
+					Name:  cookiemaker.AUTH_COOKIE_KEY,
+					Value: bearer,
+					Path:  "/",
+				})
+
+				resp, err := cli.Do(req)
+				require.NoError(t, err)
+				defer resp.Body.Close()
+				require.Equal(t, tc.StatusCode, resp.StatusCode)
+
+				if tc.StatusCode == http.StatusOK {
+					var body map[string]int64
+					require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+					require.Equal(t, int64(12), body["job-id"])
+					require.Equal(t, int64(10), body["job-cloned_from"])
+					require.Equal(t, int64(30), body["job-n_news"])
+				}
+			},
+		)
+	}
+}
+
+func TestCompareJob(t *testing.T) {
+	version := "v1"
+
+	cli := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true,
+			},
+		}}
+
+	tm := middleware.NewJWTTokenMaker(opt)
+	tm.AllowFromHTTPCookie = true
+
+	user, _ := testtool.GenRdmUser()
+	bearer, err := tm.TokenMaker.MakeToken(user.Email, user.ID, tokenmaker.ParseRole(user.Role))
+	require.NoError(t, err)
+
+	src := &model.GetJobsByJobIdRow{
+		ID:       10,
+		Analyzer: "OpenAI",
+		LlmQuery: []byte(`{"api":"openai","embedding-options":{"embedding":true,"embedding_model":""}}`),
+	}
+	clone := &model.GetJobsByJobIdRow{
+		ID:         12,
+		Analyzer:   "Cohere",
+		LlmQuery:   []byte(`{"api":"cohere","embedding-options":{"embedding":true,"embedding_model":"embed-multilingual-v3.0"}}`),
+		ClonedFrom: pgtype.Int8{Int64: 10, Valid: true},
+	}
+
+	type testCase struct {
+		Name       string
+		Path       string
+		SetupStore func(t *testing.T) model.Store
+		StatusCode int
+	}
+
+	tcs := []testCase{
+		{
+			Name: "Compare with the source job",
+			Path: "12/compare",
+			SetupStore: func(t *testing.T) model.Store {
+				ctl := gomock.NewController(t)
+				store := mock_model.NewMockStore(ctl)
+				gomock.InOrder(
+					store.
+						EXPECT().
+						GetJobsByJobId(gomock.Any(), gomock.Eq(&model.GetJobsByJobIdParams{
+							ID: 12, Owner: user.ID,
+						})).
+						Times(1).
+						Return(clone, nil),
+					store.
+						EXPECT().
+						GetJobsByJobId(gomock.Any(), gomock.Eq(&model.GetJobsByJobIdParams{
+							ID: 10, Owner: user.ID,
+						})).
+						Times(1).
+						Return(src, nil),
+					store.
+						EXPECT().
+						CompareJobSentiments(gomock.Any(), gomock.Eq(&model.CompareJobSentimentsParams{
+							ModelA: "embed-multilingual-v3.0",
+							ModelB: "text-embedding-ada-002",
+							JobA:   12,
+							JobB:   10,
+						})).
+						Times(1).
+						Return([]*model.CompareJobSentimentsRow{
+							{
+								NewsID:     100,
+								Title:      "title",
+								Link:       "https://example.com",
+								SentimentA: model.NullSentiment{Sentiment: model.SentimentPositive, Valid: true},
+								SentimentB: model.NullSentiment{Sentiment: model.SentimentNegative, Valid: true},
+							},
+							{
+								NewsID:     101,
+								Title:      "not analyzed",
+								Link:       "https://example.com/101",
+								SentimentB: model.NullSentiment{Sentiment: model.SentimentNeutral, Valid: true},
+							},
+						}, nil),
+				)
+				return store
+			},
+			StatusCode: http.StatusOK,
+		},
+		{
+			Name: "Job which is not a clone",
+			Path: "10/compare",
+			SetupStore: func(t *testing.T) model.Store {
+				ctl := gomock.NewController(t)
+				store := mock_model.NewMockStore(ctl)
+				store.
+					EXPECT().
+					GetJobsByJobId(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(src, nil)
+				return store
+			},
+			StatusCode: http.StatusBadRequest,
+		},
+		{
+			Name: "Job to compare with not found",
+			Path: "12/compare?with=13",
+			SetupStore: func(t *testing.T) model.Store {
+				ctl := gomock.NewController(t)
+				store := mock_model.NewMockStore(ctl)
+				gomock.InOrder(
+					store.
+						EXPECT().
+						GetJobsByJobId(gomock.Any(), gomock.Any()).
+						Times(1).
+						Return(clone, nil),
+					store.
+						EXPECT().
+						GetJobsByJobId(gomock.Any(), gomock.Eq(&model.GetJobsByJobIdParams{
+							ID: 13, Owner: user.ID,
+						})).
+						Times(1).
+						Return(nil, pgx.ErrNoRows),
+				)
+				return store
+			},
+			StatusCode: http.StatusForbidden,
+		},
+	}
+
+	for i, tc := range tcs {
+		t.Run(
+			fmt.Sprintf("Case %d-%s", i+1, tc.Name),
+			func(t *testing.T) {
+				apiRepo := api.APIRepo{
+					Version:    version,
+					Service:    service.NewService(tc.SetupStore(t), validator.Validate),
+					TokenMaker: tm,
+				}
+				mux := chi.NewMux()
+				mux.Use(tm.BearerAuthenticator)
+				mux.Get(fmt.Sprintf("/%s/job/{jId}/compare", version), apiRepo.CompareJob)
+				srv := httptest.NewTLSServer(mux)
+				defer srv.Close()
+
+				req, err := http.NewRequest(http.MethodGet,
+					fmt.Sprintf("%s/%s/job/%s", srv.URL, version, tc.Path), nil)
+				require.NoError(t, err)
+				req.AddCookie(&http.Cookie{
+					Name:  cookiemaker.AUTH_COOKIE_KEY,
+					Value: bearer,
+					Path:  "/",
+				})
+
+				resp, err := cli.Do(req)
+				require.NoError(t, err)
+				defer resp.Body.Close()
+				require.Equal(t, tc.StatusCode, resp.StatusCode)
+
+				if tc.StatusCode == http.StatusOK {
+					var cmp object.JobComparison
+					require.NoError(t, json.NewDecoder(resp.Body).Decode(&cmp))
+					require.Equal(t, object.ComparedJob{JID: 12, Analyzer: "Cohere", Model: "embed-multilingual-v3.0"}, cmp.JobA)
+					require.Equal(t, object.ComparedJob{JID: 10, Analyzer: "OpenAI", Model: "text-embedding-ada-002"}, cmp.JobB)
+					require.Len(t, cmp.Sentiments, 2)
+					require.Equal(t, "positive", cmp.Sentiments[0].SentimentA)
+					require.Equal(t, "negative", cmp.Sentiments[0].SentimentB)
+					require.Empty(t, cmp.Sentiments[1].SentimentA)
+				}
+			},
+		)
+	}
+}
+
+func TestGetJobStats(t *testing.T) {
+	version := "v1"
+
+	cli := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true,
+			},
+		}}
+
+	tm := middleware.NewJWTTokenMaker(opt)
+	tm.AllowFromHTTPCookie = true
+
+	user, _ := testtool.GenRdmUser()
+	bearer, err := tm.TokenMaker.MakeToken(user.Email, user.ID, tokenmaker.ParseRole(user.Role))
+	require.NoError(t, err)
+
+	job := &model.GetJobsByJobIdRow{
+		ID:       10,
+		Analyzer: "OpenAI",
+		LlmQuery: []byte(`{"api":"openai","embedding-options":{"embedding":true,"embedding_model":""}}`),
+	}
+	entityJob := &model.GetJobsByJobIdRow{
+		ID:       10,
+		Owner:    user.ID,
+		Analyzer: "OpenAI",
+		LlmQuery: []byte(`{"api":"openai","embedding-options":{"embedding":true,"embedding_model":""},` +
+			`"sentiment-analysis-options":{"entities":["賴清德","國民黨"],"prompt":{"id":3,"version":2}}}`),
+	}
+	taskJob := &model.GetJobsByJobIdRow{
+		ID:       10,
+		Owner:    user.ID,
+		Analyzer: "OpenAI",
+		LlmQuery: []byte(`{"api":"openai","embedding-options":{"embedding":true,"embedding_model":""},` +
+			`"classification-options":{"task":"stance","labels":[{"name":"support"},{"name":"oppose"}]}}`),
+	}
+	taskHash := option.ClassificationOptions{
+		Task:   "stance",
+		Labels: []option.ClassificationLabel{{Name: "support"}, {Name: "oppose"}},
+	}.Hash()
+
+	rows := []*model.GetJobSentimentStatsRow{
+		{Dimension: "category", Bucket: "business", NNews: 3, NPositive: 1, NNegative: 2,
+			PositiveRatio: 1.0 / 3, NegativeRatio: 2.0 / 3, MeanScore: 8.0 / 3},
+		{Dimension: "language", Bucket: "en", NNews: 4, NVeryPositive: 1, NPositive: 1, NNeutral: 1, NNegative: 1,
+			VeryPositiveRatio: 0.25, PositiveRatio: 0.25, NeutralRatio: 0.25, NegativeRatio: 0.25, MeanScore: 3.5},
+		{Dimension: "overall", NNews: 4, NVeryPositive: 1, NPositive: 1, NNeutral: 1, NNegative: 1,
+			VeryPositiveRatio: 0.25, PositiveRatio: 0.25, NeutralRatio: 0.25, NegativeRatio: 0.25, MeanScore: 3.5},
+		{Dimension: "publish_day", Bucket: "2023-12-01", NNews: 4, NVeryPositive: 1, NPositive: 1, NNeutral: 1, NNegative: 1,
+			VeryPositiveRatio: 0.25, PositiveRatio: 0.25, NeutralRatio: 0.25, NegativeRatio: 0.25, MeanScore: 3.5},
+		{Dimension: "source", Bucket: "a.com", NNews: 1, NVeryPositive: 1, VeryPositiveRatio: 1, MeanScore: 5},
+		{Dimension: "source", Bucket: "b.com", NNews: 3, NPositive: 1, NNeutral: 1, NNegative: 1,
+			PositiveRatio: 1.0 / 3, NeutralRatio: 1.0 / 3, NegativeRatio: 1.0 / 3, MeanScore: 3},
+	}
+	entityRows := []*model.GetJobEntitySentimentStatsRow{
+		{Entity: "國民黨", NNews: 2, NNegative: 2, NegativeRatio: 1, MeanScore: 2},
+		{Entity: "國民黨", Source: "a.com", NNews: 2, NNegative: 2, NegativeRatio: 1, MeanScore: 2},
+		{Entity: "賴清德", NNews: 1, NPositive: 1, PositiveRatio: 1, MeanScore: 4},
+		{Entity: "賴清德", Source: "b.com", NNews: 1, NPositive: 1, PositiveRatio: 1, MeanScore: 4},
+	}
+
+	type testCase struct {
+		Name       string
+		Path       string
+		SetupStore func(t *testing.T) model.Store
+		StatusCode int
+		NEntity    int
+		NLabel     int
+	}
+
+	tcs := []testCase{
+		{
+			Name: "Get stats",
+			Path: "10/stats",
+			SetupStore: func(t *testing.T) model.Store {
+				ctl := gomock.NewController(t)
+				store := mock_model.NewMockStore(ctl)
+				gomock.InOrder(
+					store.
+						EXPECT().
+						GetJobsByJobId(gomock.Any(), gomock.Eq(&model.GetJobsByJobIdParams{
+							ID: 10, Owner: user.ID,
+						})).
+						Times(1).
+						Return(job, nil),
+					store.
+						EXPECT().
+						GetJobSentimentStats(gomock.Any(), gomock.Eq(&model.GetJobSentimentStatsParams{
+							JobID: 10, Model: "text-embedding-ada-002",
+						})).
+						Times(1).
+						Return(rows, nil),
+				)
+				return store
+			},
+			StatusCode: http.StatusOK,
+		},
+		{
+			Name: "Get stats with entities",
+			Path: "10/stats",
+			SetupStore: func(t *testing.T) model.Store {
+				ctl := gomock.NewController(t)
+				store := mock_model.NewMockStore(ctl)
+				gomock.InOrder(
+					store.
+						EXPECT().
+						GetJobsByJobId(gomock.Any(), gomock.Eq(&model.GetJobsByJobIdParams{
+							ID: 10, Owner: user.ID,
+						})).
+						Times(1).
+						Return(entityJob, nil),
+					store.
+						EXPECT().
+						GetJobSentimentStats(gomock.Any(), gomock.Any()).
+						Times(1).
+						Return(rows, nil),
+					store.
+						EXPECT().
+						GetJobEntitySentimentStats(gomock.Any(), gomock.Eq(&model.GetJobEntitySentimentStatsParams{
+							JobID: 10, Model: "text-embedding-ada-002", Entities: []string{"賴清德", "國民黨"},
+							Owner: user.ID, PromptID: pgtype.Int8{Int64: 3, Valid: true},
+						})).
+						Times(1).
+						Return(entityRows, nil),
+				)
+				return store
+			},
+			StatusCode: http.StatusOK,
+			NEntity:    4,
+		},
+		{
+			Name: "Get stats with a classification task",
+			Path: "10/stats",
+			SetupStore: func(t *testing.T) model.Store {
+				ctl := gomock.NewController(t)
+				store := mock_model.NewMockStore(ctl)
+				gomock.InOrder(
+					store.
+						EXPECT().
+						GetJobsByJobId(gomock.Any(), gomock.Any()).
+						Times(1).
+						Return(taskJob, nil),
+					store.
+						EXPECT().
+						GetJobSentimentStats(gomock.Any(), gomock.Any()).
+						Times(1).
+						Return(rows, nil),
+					store.
+						EXPECT().
+						GetJobAnnotationStats(gomock.Any(), gomock.Eq(&model.GetJobAnnotationStatsParams{
+							JobID: 10, Model: "text-embedding-ada-002", Owner: user.ID, TaskHash: taskHash,
+						})).
+						Times(1).
+						Return([]*model.GetJobAnnotationStatsRow{
+							{Label: "oppose", NNews: 1, Ratio: 0.25},
+							{Label: "support", NNews: 3, Ratio: 0.75},
+							{Source: "a.com", Label: "support", NNews: 1, Ratio: 1},
+						}, nil),
+				)
+				return store
+			},
+			StatusCode: http.StatusOK,
+			NLabel:     3,
+		},
+		{
+			Name: "Job not found",
+			Path: "11/stats",
+			SetupStore: func(t *testing.T) model.Store {
+				ctl := gomock.NewController(t)
+				store := mock_model.NewMockStore(ctl)
+				store.
+					EXPECT().
+					GetJobsByJobId(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, pgx.ErrNoRows)
+				return store
+			},
+			StatusCode: http.StatusForbidden,
+		},
+		{
+			Name: "Invalid job id",
+			Path: "0/stats",
+			SetupStore: func(t *testing.T) model.Store {
+				ctl := gomock.NewController(t)
+				return mock_model.NewMockStore(ctl)
+			},
+			StatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for i, tc := range tcs {
+		t.Run(
+			fmt.Sprintf("Case %d-%s", i+1, tc.Name),
+			func(t *testing.T) {
+				apiRepo := api.APIRepo{
+					Version:    version,
+					Service:    service.NewService(tc.SetupStore(t), validator.Validate),
+					TokenMaker: tm,
+				}
+				mux := chi.NewMux()
+				mux.Use(tm.BearerAuthenticator)
+				mux.Get(fmt.Sprintf("/%s/job/{jId}/stats", version), apiRepo.GetJobStats)
+				srv := httptest.NewTLSServer(mux)
+				defer srv.Close()
+
+				req, err := http.NewRequest(http.MethodGet,
+					fmt.Sprintf("%s/%s/job/%s", srv.URL, version, tc.Path), nil)
+				require.NoError(t, err)
+				req.AddCookie(&http.Cookie{
+					Name:  cookiemaker.AUTH_COOKIE_KEY,
+					Value: bearer,
+					Path:  "/",
+				})
+
+				resp, err := cli.Do(req)
+				require.NoError(t, err)
+				defer resp.Body.Close()
+				require.Equal(t, tc.StatusCode, resp.StatusCode)
+
+				if tc.StatusCode == http.StatusOK {
+					var stats object.JobSentimentStats
+					require.NoError(t, json.NewDecoder(resp.Body).Decode(&stats))
+					require.Equal(t, int64(10), stats.JID)
+					require.Equal(t, "text-embedding-ada-002", stats.Model)
+					require.Equal(t, int64(4), stats.Overall.NNews)
+					require.Equal(t, int64(1), stats.Overall.NVeryPositive)
+					require.Equal(t, 3.5, stats.Overall.MeanScore)
+					require.Len(t, stats.Source, 2)
+					require.Equal(t, "b.com", stats.Source[1].Bucket)
+					require.Len(t, stats.Category, 1)
+					require.Len(t, stats.PublishDay, 1)
+					require.Len(t, stats.Language, 1)
+					require.Equal(t, 0.25, stats.Language[0].VeryPositiveRatio)
+					require.Len(t, stats.EntitySource, tc.NEntity)
+					if tc.NEntity > 0 {
+						require.Equal(t, "國民黨", stats.EntitySource[1].Entity)
+						require.Equal(t, "a.com", stats.EntitySource[1].Bucket)
+						require.Equal(t, int64(2), stats.EntitySource[1].NNegative)
+					}
+					require.Len(t, stats.LabelSource, tc.NLabel)
+					if tc.NLabel > 0 {
+						require.Equal(t, "stance", stats.Task)
+						require.Equal(t, "support", stats.LabelSource[1].Label)
+						require.Equal(t, 0.75, stats.LabelSource[1].Ratio)
+						require.Equal(t, "a.com", stats.LabelSource[2].Bucket)
+					}
+				}
+			},
+		)
+	}
+}
+
+func TestGetJobUsage(t *testing.T) {
+	version := "v1"
+
+	cli := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true,
+			},
+		}}
+
+	tm := middleware.NewJWTTokenMaker(opt)
+	tm.AllowFromHTTPCookie = true
+
+	user, _ := testtool.GenRdmUser()
+	bearer, err := tm.TokenMaker.MakeToken(user.Email, user.ID, tokenmaker.ParseRole(user.Role))
+	require.NoError(t, err)
+
+	rows := []*model.GetJobUsageRow{
+		{Provider: "openai", Model: "gpt-4o-mini", Operation: "sentiment",
+			NCalls: 2, InputTokens: 240, OutputTokens: 60, Cost: 7.2e-5},
+		{Provider: "openai", Model: "text-embedding-ada-002", Operation: "embedding",
+			NCalls: 2, InputTokens: 978, NUnpriced: 2},
+	}
+
+	type testCase struct {
+		Name       string
+		Path       string
+		SetupStore func(t *testing.T) model.Store
+		StatusCode int
+		NUsage     int
+	}
+
+	tcs := []testCase{
+		{
+			Name: "Get usage",
+			Path: "10/usage",
+			SetupStore: func(t *testing.T) model.Store {
+				ctl := gomock.NewController(t)
+				store := mock_model.NewMockStore(ctl)
+				store.
+					EXPECT().
+					GetJobUsage(gomock.Any(), gomock.Eq(&model.GetJobUsageParams{
+						JobID: 10, Owner: user.ID,
+					})).
+					Times(1).
+					Return(rows, nil)
+				return store
+			},
+			StatusCode: http.StatusOK,
+			NUsage:     2,
+		},
+		{
+			Name: "No usage",
+			Path: "11/usage",
+			SetupStore: func(t *testing.T) model.Store {
+				ctl := gomock.NewController(t)
+				store := mock_model.NewMockStore(ctl)
+				store.
+					EXPECT().
+					GetJobUsage(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]*model.GetJobUsageRow{}, nil)
+				return store
+			},
+			StatusCode: http.StatusOK,
+		},
+		{
+			Name: "Invalid job id",
+			Path: "0/usage",
+			SetupStore: func(t *testing.T) model.Store {
+				ctl := gomock.NewController(t)
+				return mock_model.NewMockStore(ctl)
+			},
+			StatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for i, tc := range tcs {
+		t.Run(
+			fmt.Sprintf("Case %d-%s", i+1, tc.Name),
+			func(t *testing.T) {
+				apiRepo := api.APIRepo{
+					Version:    version,
+					Service:    service.NewService(tc.SetupStore(t), validator.Validate),
+					TokenMaker: tm,
+				}
+				mux := chi.NewMux()
+				mux.Use(tm.BearerAuthenticator)
+				mux.Get(fmt.Sprintf("/%s/job/{jId}/usage", version), apiRepo.GetJobUsage)
+				srv := httptest.NewTLSServer(mux)
+				defer srv.Close()
+
+				req, err := http.NewRequest(http.MethodGet,
+					fmt.Sprintf("%s/%s/job/%s", srv.URL, version, tc.Path), nil)
+				require.NoError(t, err)
+				req.AddCookie(&http.Cookie{
+					Name:  cookiemaker.AUTH_COOKIE_KEY,
+					Value: bearer,
+					Path:  "/",
+				})
+
+				resp, err := cli.Do(req)
+				require.NoError(t, err)
+				defer resp.Body.Close()
+				require.Equal(t, tc.StatusCode, resp.StatusCode)
+
+				if tc.StatusCode == http.StatusOK {
+					var usages []object.JobUsage
+					require.NoError(t, json.NewDecoder(resp.Body).Decode(&usages))
+					require.Len(t, usages, tc.NUsage)
+					if tc.NUsage > 0 {
+						require.Equal(t, "gpt-4o-mini", usages[0].Model)
+						require.Equal(t, int64(240), usages[0].InputTokens)
+						require.Equal(t, 7.2e-5, usages[0].Cost)
+						require.Equal(t, int64(2), usages[1].NUnpriced)
+					}
+				}
+			},
+		)
+	}
+}
+
+func TestWebhook(t *testing.T) {
+	view, err := view.NewView(nil, VIEWS_PATH+"/template/*.gotmpl")
+	require.NoError(t, err)
+
+	version := "v1"
+
+	cli := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return ErrRedirect
+		},
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true,
+			},
+		}}
+
+	tm := middleware.NewJWTTokenMaker(opt)
+	tm.AllowFromHTTPCookie = true
+
+	user, _ := testtool.GenRdmUser()
+	bearer, err := tm.TokenMaker.MakeToken(user.Email, user.ID, tokenmaker.ParseRole(user.Role))
+	require.NoError(t, err)
+
+	now := pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	deliveries := []*model.ListWebhookDeliveriesRow{
+		{ID: 2, WebhookID: 1, Url: "https://example.com/hook", JobID: 10,
+			Status: model.JobStatusFailed, Stage: model.WebhookDeliveryStageRetrying, Attempts: 1,
+			ResponseCode:  pgtype.Int4{Int32: 500, Valid: true},
+			LastError:     pgtype.Text{String: "unexpected status code: 500", Valid: true},
+			NextAttemptAt: now, CreatedAt: now, UpdatedAt: now},
+		{ID: 1, WebhookID: 1, Url: "https://example.com/hook", JobID: 10,
+			Status: model.JobStatusRunning, Stage: model.WebhookDeliveryStageDelivered, Attempts: 1,
+			ResponseCode:  pgtype.Int4{Int32: 200, Valid: true},
+			NextAttemptAt: now, CreatedAt: now, UpdatedAt: now},
+	}
+
+	type testCase struct {
+		Name       string
+		Method     string
+		Path       string
+		Body       url.Values
+		SetupStore func(t *testing.T) model.Store
+		StatusCode int
+		Check      func(t *testing.T, resp *http.Response)
+	}
+
+	tcs := []testCase{
+		{
+			Name:   "Get webhook page",
+			Method: http.MethodGet,
+			Path:   "webhook",
+			SetupStore: func(t *testing.T) model.Store {
+				ctl := gomock.NewController(t)
+				store := mock_model.NewMockStore(ctl)
+				store.EXPECT().
+					ListWebhooks(gomock.Any(), user.ID).
+					Times(1).
+					Return([]*model.ListWebhooksRow{{ID: 1, Url: "https://example.com/hook", CreatedAt: now}}, nil)
+				store.EXPECT().
+					GetWebhookSecret(gomock.Any(), user.ID).
+					Times(1).
+					Return(&model.GetWebhookSecretRow{Secret: "[[::WEBHOOK_SECRET::]]", UpdatedAt: now}, nil)
+				store.EXPECT().
+					ListWebhookDeliveries(gomock.Any(), &model.ListWebhookDeliveriesParams{Owner: user.ID, Limit: 50}).
+					Times(1).
+					Return(deliveries, nil)
+				return store
+			},
+			StatusCode: http.StatusOK,
+			Check: func(t *testing.T, resp *http.Response) {
+				body, err := io.ReadAll(resp.Body)
+				require.NoError(t, err)
+				require.Contains(t, string(body), "<title>Webhook</title>")
+				require.Contains(t, string(body), "https://example.com/hook")
+				require.Contains(t, string(body), "[[::WEBHOOK_SECRET::]]")
+				require.Contains(t, string(body), "unexpected status code: 500")
+			},
+		},
+		{
+			Name:   "Get webhook page without secret",
+			Method: http.MethodGet,
+			Path:   "webhook",
+			SetupStore: func(t *testing.T) model.Store {
+				ctl := gomock.NewController(t)
+				store := mock_model.NewMockStore(ctl)
+				store.EXPECT().
+					ListWebhooks(gomock.Any(), user.ID).
+					Times(1).
+					Return([]*model.ListWebhooksRow{}, nil)
+				store.EXPECT().
+					GetWebhookSecret(gomock.Any(), user.ID).
+					Times(1).
+					Return(nil, pgx.ErrNoRows)
+				store.EXPECT().
+					ListWebhookDeliveries(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]*model.ListWebhookDeliveriesRow{}, nil)
+				return store
+			},
+			StatusCode: http.StatusOK,
+		},
+		{
+			Name:   "Add webhook",
+			Method: http.MethodPost,
+			Path:   "webhook",
+			Body:   url.Values{"webhook-url": {" https://example.com/hook "}},
+			SetupStore: func(t *testing.T) model.Store {
+				ctl := gomock.NewController(t)
+				store := mock_model.NewMockStore(ctl)
+				store.EXPECT().
+					DoCreateWebhookTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, params *model.CreateWebhookTxParams) (*model.CreateWebhookTxResult, error) {
+						require.Equal(t, user.ID, params.Owner)
+						require.Equal(t, "https://example.com/hook", params.Url)
+						require.Len(t, params.Secret, 64)
+						return &model.CreateWebhookTxResult{ID: 1, NewSecret: true}, nil
+					})
+				return store
+			},
+			StatusCode: http.StatusSeeOther,
+		},
+		{
+			Name:   "Add webhook with invalid url",
+			Method: http.MethodPost,
+			Path:   "webhook",
+			Body:   url.Values{"webhook-url": {"ftp://example.com/hook"}},
+			SetupStore: func(t *testing.T) model.Store {
+				ctl := gomock.NewController(t)
+				return mock_model.NewMockStore(ctl)
+			},
+			StatusCode: http.StatusBadRequest,
+		},
+		{
+			Name:   "Delete webhook",
+			Method: http.MethodDelete,
+			Path:   "webhook/1",
+			SetupStore: func(t *testing.T) model.Store {
+				ctl := gomock.NewController(t)
+				store := mock_model.NewMockStore(ctl)
+				store.EXPECT().
+					DeleteWebhook(gomock.Any(), &model.DeleteWebhookParams{Owner: user.ID, ID: 1}).
+					Times(1).
+					Return(int64(1), nil)
+				return store
+			},
+			StatusCode: http.StatusOK,
+		},
+		{
+			Name:   "Delete webhook of others",
+			Method: http.MethodDelete,
+			Path:   "webhook/2",
+			SetupStore: func(t *testing.T) model.Store {
+				ctl := gomock.NewController(t)
+				store := mock_model.NewMockStore(ctl)
+				store.EXPECT().
+					DeleteWebhook(gomock.Any(), &model.DeleteWebhookParams{Owner: user.ID, ID: 2}).
+					Times(1).
+					Return(int64(0), nil)
+				return store
+			},
+			StatusCode: http.StatusForbidden,
+		},
+		{
+			Name:   "Rotate secret",
+			Method: http.MethodPost,
+			Path:   "webhook/secret",
+			SetupStore: func(t *testing.T) model.Store {
+				ctl := gomock.NewController(t)
+				store := mock_model.NewMockStore(ctl)
+				store.EXPECT().
+					RotateWebhookSecret(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, params *model.RotateWebhookSecretParams) (int64, error) {
+						require.Equal(t, user.ID, params.Owner)
+						require.Len(t, params.Secret, 64)
+						return 1, nil
+					})
+				return store
+			},
+			StatusCode: http.StatusOK,
+			Check: func(t *testing.T, resp *http.Response) {
+				var body map[string]string
+				require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+				require.Len(t, body["webhook-secret"], 64)
+			},
+		},
+		{
+			Name:   "List deliveries",
+			Method: http.MethodGet,
+			Path:   "webhook/deliveries?n=2",
+			SetupStore: func(t *testing.T) model.Store {
+				ctl := gomock.NewController(t)
+				store := mock_model.NewMockStore(ctl)
+				store.EXPECT().
+					ListWebhookDeliveries(gomock.Any(), &model.ListWebhookDeliveriesParams{Owner: user.ID, Limit: 2}).
+					Times(1).
+					Return(deliveries, nil)
+				return store
+			},
+			StatusCode: http.StatusOK,
+			Check: func(t *testing.T, resp *http.Response) {
+				var body []object.WebhookDelivery
+				require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+				require.Len(t, body, 2)
+				require.Equal(t, "retrying", body[0].Stage)
+				require.Equal(t, int32(500), body[0].ResponseCode)
+				require.Equal(t, "delivered", body[1].Stage)
+			},
+		},
+		{
+			Name:   "List too many deliveries",
+			Method: http.MethodGet,
+			Path:   "webhook/deliveries?n=1000",
+			SetupStore: func(t *testing.T) model.Store {
+				ctl := gomock.NewController(t)
+				return mock_model.NewMockStore(ctl)
+			},
+			StatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for i, tc := range tcs {
+		t.Run(
+			fmt.Sprintf("Case %d-%s", i+1, tc.Name),
+			func(t *testing.T) {
+				apiRepo := api.APIRepo{
+					Version:      version,
+					Service:      service.NewService(tc.SetupStore(t), validator.Validate),
+					View:         view,
+					TokenMaker:   tm,
+					Validator:    validator.Validate,
+					FormDecoder:  form.NewDecoder(),
+					FormModifier: mold.New(),
+				}
+				mux := chi.NewMux()
+				mux.Use(tm.BearerAuthenticator)
+				mux.Get(fmt.Sprintf("/%s/webhook", version), apiRepo.GetWebhook)
+				mux.Post(fmt.Sprintf("/%s/webhook", version), apiRepo.PostWebhook)
+				mux.Delete(fmt.Sprintf("/%s/webhook/{id}", version), apiRepo.DeleteWebhook)
+				mux.Post(fmt.Sprintf("/%s/webhook/secret", version), apiRepo.RotateWebhookSecret)
+				mux.Get(fmt.Sprintf("/%s/webhook/deliveries", version), apiRepo.GetWebhookDeliveries)
+				srv := httptest.NewTLSServer(mux)
+				defer srv.Close()
+
+				var body io.Reader
+				if tc.Body != nil {
+					body = strings.NewReader(tc.Body.Encode())
+				}
+				req, err := http.NewRequest(tc.Method,
+					fmt.Sprintf("%s/%s/%s", srv.URL, version, tc.Path), body)
+				require.NoError(t, err)
+				if tc.Body != nil {
+					req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+				}
+				req.AddCookie(&http.Cookie{
+					Name:  cookiemaker.AUTH_COOKIE_KEY,
+					Value: bearer,
+					Path:  "/",
+				})
+
+				resp, err := cli.Do(req)
+				if tc.StatusCode == http.StatusSeeOther {
+					require.ErrorIs(t, err, ErrRedirect)
+				} else {
+					require.NoError(t, err)
+				}
+				defer resp.Body.Close()
+				require.Equal(t, tc.StatusCode, resp.StatusCode)
+
+				if tc.Check != nil {
+					tc.Check(t, resp)
+				}
+			},
+		)
+	}
+}
+
+func TestPrompt(t *testing.T) {
+	view, err := view.NewView(nil, VIEWS_PATH+"/template/*.gotmpl")
+	require.NoError(t, err)
+
+	version := "v1"
+
+	cli := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return ErrRedirect
+		},
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true,
+			},
+		}}
+
+	tm := middleware.NewJWTTokenMaker(opt)
+	tm.AllowFromHTTPCookie = true
+
+	user, _ := testtool.GenRdmUser()
+	bearer, err := tm.TokenMaker.MakeToken(user.Email, user.ID, tokenmaker.ParseRole(user.Role))
+	require.NoError(t, err)
+
+	now := pgtype.Timestamptz{Time: time.Now(), Valid: true}
+
+	type testCase struct {
+		Name       string
+		Method     string
+		Body       url.Values
+		SetupStore func(t *testing.T) model.Store
+		StatusCode int
+		Check      func(t *testing.T, resp *http.Response)
+	}
+
+	tcs := []testCase{
+		{
+			Name:   "Get prompt page",
+			Method: http.MethodGet,
+			SetupStore: func(t *testing.T) model.Store {
+				ctl := gomock.NewController(t)
+				store := mock_model.NewMockStore(ctl)
+				store.EXPECT().
+					ListPrompts(gomock.Any(), user.ID).
+					Times(1).
+					Return([]*model.ListPromptsRow{
+						{ID: 1, Provider: "openai", Task: service.PromptTaskSentiment, Version: 1,
+							Body: "[[::PROMPT BODY::]]", CreatedAt: now},
+					}, nil)
+				return store
+			},
+			StatusCode: http.StatusOK,
+			Check: func(t *testing.T, resp *http.Response) {
+				body, err := io.ReadAll(resp.Body)
+				require.NoError(t, err)
+				require.Contains(t, string(body), "<title>Prompt</title>")
+				require.Contains(t, string(body), "[[::PROMPT BODY::]]")
+				require.Contains(t, string(body), `<option value="openai">openai</option>`)
+				// the offline analyzer sends no prompt
+				require.NotContains(t, string(body), `<option value="lexicon">`)
+			},
+		},
+		{
+			Name:   "Add prompt",
+			Method: http.MethodPost,
+			Body: url.Values{"provider": {"openai"}, "task": {service.PromptTaskSentiment},
+				"body": {"Score the statements.{{if .Truncate}} Truncated.{{end}}"}},
+			SetupStore: func(t *testing.T) model.Store {
+				ctl := gomock.NewController(t)
+				store := mock_model.NewMockStore(ctl)
+				store.EXPECT().
+					CreatePrompt(gomock.Any(), &model.CreatePromptParams{
+						Owner:    user.ID,
+						Provider: "openai",
+						Task:     service.PromptTaskSentiment,
+						Body:     "Score the statements.{{if .Truncate}} Truncated.{{end}}",
+					}).
+					Times(1).
+					Return(&model.CreatePromptRow{ID: 2, Version: 2}, nil)
+				return store
+			},
+			StatusCode: http.StatusSeeOther,
+		},
+		{
+			Name:   "Add prompt of offline analyzer",
+			Method: http.MethodPost,
+			Body:   url.Values{"provider": {"lexicon"}, "task": {service.PromptTaskSentiment}, "body": {"Score it."}},
+			SetupStore: func(t *testing.T) model.Store {
+				ctl := gomock.NewController(t)
+				return mock_model.NewMockStore(ctl)
+			},
+			StatusCode: http.StatusBadRequest,
+		},
+		{
+			Name:   "Add invalid template",
+			Method: http.MethodPost,
+			Body:   url.Values{"provider": {"openai"}, "task": {service.PromptTaskSentiment}, "body": {"Score {{.Unknown}}"}},
+			SetupStore: func(t *testing.T) model.Store {
+				ctl := gomock.NewController(t)
+				return mock_model.NewMockStore(ctl)
+			},
+			StatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for i, tc := range tcs {
+		t.Run(
+			fmt.Sprintf("Case %d-%s", i+1, tc.Name),
+			func(t *testing.T) {
+				apiRepo := api.APIRepo{
+					Version:      version,
+					Service:      service.NewService(tc.SetupStore(t), validator.Validate),
+					View:         view,
+					TokenMaker:   tm,
+					Validator:    validator.Validate,
+					FormDecoder:  form.NewDecoder(),
+					FormModifier: mold.New(),
+				}
+				mux := chi.NewMux()
+				mux.Use(tm.BearerAuthenticator)
+				mux.Get(fmt.Sprintf("/%s/prompt", version), apiRepo.GetPrompt)
+				mux.Post(fmt.Sprintf("/%s/prompt", version), apiRepo.PostPrompt)
+				srv := httptest.NewTLSServer(mux)
+				defer srv.Close()
+
+				var body io.Reader
+				if tc.Body != nil {
+					body = strings.NewReader(tc.Body.Encode())
+				}
+				req, err := http.NewRequest(tc.Method, fmt.Sprintf("%s/%s/prompt", srv.URL, version), body)
+				require.NoError(t, err)
+				if tc.Body != nil {
+					req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+				}
+				req.AddCookie(&http.Cookie{
+					Name:  cookiemaker.AUTH_COOKIE_KEY,
+					Value: bearer,
+					Path:  "/",
+				})
+
+				resp, err := cli.Do(req)
+				if tc.StatusCode == http.StatusSeeOther {
+					require.ErrorIs(t, err, ErrRedirect)
+				} else {
+					require.NoError(t, err)
+				}
+				defer resp.Body.Close()
+				require.Equal(t, tc.StatusCode, resp.StatusCode)
+
+				if tc.Check != nil {
+					tc.Check(t, resp)
+				}
+			},
+		)
+	}
+}
+
+func TestStory(t *testing.T) {
+	view, err := view.NewView(nil, VIEWS_PATH+"/template/*.gotmpl")
+	require.NoError(t, err)
+
+	version := "v1"
+
+	cli := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true,
+			},
+		}}
+
+	tm := middleware.NewJWTTokenMaker(opt)
+	tm.AllowFromHTTPCookie = true
+
+	user, _ := testtool.GenRdmUser()
+	bearer, err := tm.TokenMaker.MakeToken(user.Email, user.ID, tokenmaker.ParseRole(user.Role))
+	require.NoError(t, err)
+
+	now := pgtype.Timestamptz{Time: time.Now(), Valid: true}
+
+	type testCase struct {
+		Name       string
+		Path       string
+		SetupStore func(t *testing.T) model.Store
+		StatusCode int
+		Check      func(t *testing.T, body string)
+	}
+
+	tcs := []testCase{
+		{
+			Name: "List stories",
+			Path: "story",
+			SetupStore: func(t *testing.T) model.Store {
+				ctl := gomock.NewController(t)
+				store := mock_model.NewMockStore(ctl)
+				store.EXPECT().
+					ListStories(gomock.Any(), &model.ListStoriesParams{MinSources: 2, N: 100}).
+					Times(1).
+					Return([]*model.ListStoriesRow{
+						{ID: 7, Model: "text-embedding-3-small", Title: "[[::STORY TITLE::]]",
+							FirstPublishAt: now, LastPublishAt: now, NSources: 3},
+					}, nil)
+				return store
+			},
+			StatusCode: http.StatusOK,
+			Check: func(t *testing.T, body string) {
+				require.Contains(t, body, "<title>Stories</title>")
+				require.Contains(t, body, `<a href="story/7" class="url">[[::STORY TITLE::]]</a>`)
+			},
+		},
+		{
+			Name: "Get story",
+			Path: "story/7",
+			SetupStore: func(t *testing.T) model.Store {
+				ctl := gomock.NewController(t)
+				store := mock_model.NewMockStore(ctl)
+				store.EXPECT().
+					GetStory(gomock.Any(), int64(7)).
+					Times(1).
+					Return(&model.Story{ID: 7, Model: "text-embedding-3-small", Title: "[[::STORY TITLE::]]",
+						FirstPublishAt: now, LastPublishAt: now}, nil)
+				store.EXPECT().
+					GetStoryNews(gomock.Any(), int64(7)).
+					Times(1).
+					Return([]*model.GetStoryNewsRow{
+						{NewsID: 1, Source: "outlet-a.com", Title: "[[::HEADLINE A::]]", Link: "https://outlet-a.com/1",
+							PublishAt: now, Similarity: 1, Sentiment: model.SentimentNegative, Score: 2},
+						{NewsID: 2, Source: "outlet-b.com", Title: "[[::HEADLINE B::]]", Link: "https://outlet-b.com/2",
+							PublishAt: now, Similarity: 0.9123, Sentiment: model.SentimentPositive, Score: 4},
+					}, nil)
+				return store
+			},
+			StatusCode: http.StatusOK,
+			Check: func(t *testing.T, body string) {
+				require.Contains(t, body, "<title>Story</title>")
+				require.Contains(t, body, "Covered by 2 outlets")
+				for _, s := range []string{"outlet-a.com", "[[::HEADLINE A::]]", "negative",
+					"outlet-b.com", "[[::HEADLINE B::]]", "positive", "0.912"} {
+					require.Contains(t, body, s)
+				}
+			},
+		},
+		{
+			Name: "Story not found",
+			Path: "story/8",
+			SetupStore: func(t *testing.T) model.Store {
+				ctl := gomock.NewController(t)
+				store := mock_model.NewMockStore(ctl)
+				store.EXPECT().
+					GetStory(gomock.Any(), int64(8)).
+					Times(1).
+					Return(nil, pgx.ErrNoRows)
+				return store
+			},
+			StatusCode: http.StatusNotFound,
+		},
+		{
+			Name: "Invalid story id",
+			Path: "story/abc",
+			SetupStore: func(t *testing.T) model.Store {
+				ctl := gomock.NewController(t)
+				return mock_model.NewMockStore(ctl)
+			},
+			StatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for i, tc := range tcs {
+		t.Run(
+			fmt.Sprintf("Case %d-%s", i+1, tc.Name),
+			func(t *testing.T) {
+				apiRepo := api.APIRepo{
+					Version:    version,
+					Service:    service.NewService(tc.SetupStore(t), validator.Validate),
+					View:       view,
+					TokenMaker: tm,
+					Validator:  validator.Validate,
+				}
+				mux := chi.NewMux()
+				mux.Use(tm.BearerAuthenticator)
+				mux.Get(fmt.Sprintf("/%s/story", version), apiRepo.GetStories)
+				mux.Get(fmt.Sprintf("/%s/story/{sId}", version), apiRepo.GetStory)
+				srv := httptest.NewTLSServer(mux)
+				defer srv.Close()
+
+				req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/%s/%s", srv.URL, version, tc.Path), nil)
+				require.NoError(t, err)
+				req.AddCookie(&http.Cookie{
+					Name:  cookiemaker.AUTH_COOKIE_KEY,
+					Value: bearer,
+					Path:  "/",
+				})
+
+				resp, err := cli.Do(req)
+				require.NoError(t, err)
+				defer resp.Body.Close()
+				require.Equal(t, tc.StatusCode, resp.StatusCode)
+
+				if tc.Check != nil {
+					body, err := io.ReadAll(resp.Body)
+					require.NoError(t, err)
+					tc.Check(t, string(body))
+				}
+			},
+		)
+	}
+}
+
+func TestGetAnalyzer(t *testing.T) {
+	view, err := view.NewView(nil, VIEWS_PATH+"/template/*.gotmpl")
+	require.NoError(t, err)
+
+	version := "v1"
+
+	tm := middleware.NewJWTTokenMaker(opt)
+	tm.AllowFromHTTPCookie = true
+
+	user, _ := testtool.GenRdmUser()
+	bearer, err := tm.TokenMaker.MakeToken(user.Email, user.ID, tokenmaker.ParseRole(user.Role))
+	require.NoError(t, err)
+
+	ctl := gomock.NewController(t)
+	store := mock_model.NewMockStore(ctl)
+	store.EXPECT().
+		ListAPI(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return([]*model.ListAPIRow{
+			{ID: 1, Name: "GNews", Type: model.ApiTypeSource},
+			{ID: 5, Name: "OpenAI", Type: model.ApiTypeLanguageModel},
+			{ID: 6, Name: "Cohere", Type: model.ApiTypeLanguageModel},
+			{ID: 7, Name: "Lexicon", Type: model.ApiTypeLanguageModel},
+			{ID: 8, Name: "Anthropic", Type: model.ApiTypeLanguageModel},
+			{ID: 9, Name: "Bard", Type: model.ApiTypeLanguageModel},
+		}, nil)
+	store.EXPECT().
+		ListPrompts(gomock.Any(), user.ID).
+		Times(1).
+		Return([]*model.ListPromptsRow{
+			{ID: 4, Provider: "openai", Task: service.PromptTaskSentiment, Version: 2, Body: "Score it."},
+			{ID: 3, Provider: "openai", Task: service.PromptTaskSentiment, Version: 1, Body: "Score them."},
+		}, nil)
+
+	// the preview cache is unreachable, which only fails to extend its expiry
+	// and leaves out the estimate
+	rds := cache.NewRedsiStore(context.Background(), &redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1})
+	defer rds.Close()
+
+	apiRepo := api.APIRepo{
+		Version:     version,
+		Service:     service.NewService(store, validator.Validate),
+		View:        view,
+		Cache:       rds,
+		TokenMaker:  tm,
+		FormDecoder: form.NewDecoder(),
+	}
+	mux := chi.NewMux()
+	mux.Use(tm.BearerAuthenticator)
+	mux.Get(fmt.Sprintf("/%s/analyzer/{pcid}", version), apiRepo.GetAnalyzer)
+	srv := httptest.NewTLSServer(mux)
+	defer srv.Close()
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/%s/analyzer/pcid", srv.URL, version), nil)
+	require.NoError(t, err)
+	req.AddCookie(&http.Cookie{
+		Name:  cookiemaker.AUTH_COOKIE_KEY,
+		Value: bearer,
+		Path:  "/",
+	})
+
+	resp, err := srv.Client().Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	b, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	body := string(b)
+
+	// every language model API with a registered analyzer gets a form
+	for _, name := range client.AnalyzerRepo.Names() {
+		anlz, err := client.AnalyzerRepo.Get(name)
+		require.NoError(t, err)
+		require.Contains(t, body, fmt.Sprintf(`id="%s-option"`, name))
+		require.Contains(t, body, anlz.ModelInfo().DefaultEmbeddingModel)
+	}
+	require.Contains(t, body, `<button type="button" id="openai" class="btn llm-btn">OpenAI</button>`)
+	require.Contains(t, body, `name="llm-api-id" value="5"`)
+	require.Contains(t, body, `name="llm-api-id" value="6"`)
+	require.Contains(t, body, `name="llm-api-id" value="7"`)
+	require.Contains(t, body, `name="llm-api-id" value="8"`)
+	// the offline analyzer has no token limit
+	require.Equal(t, 3, strings.Count(body, `name="max-tokens"`))
+	// nor a prompt
+	require.Equal(t, 3, strings.Count(body, `name="prompt-id"`))
+	require.Contains(t, body, `<option value="4">version 2`)
+	require.Contains(t, body, `<option value="3">version 1`)
+	require.NotContains(t, body, "bard")
+	require.NotContains(t, body, "GNews")
+	require.NotContains(t, body, "Estimated Cost")
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/server/middleware"
 	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/server/router/api/v1"
 	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/server/router/auth"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/server/runner"
 	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/server/service"
 	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/server/validator"
 	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/server/view"
@@ -30,9 +31,13 @@ import (
 	_ "github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/server/pageForm/newsapi"
 
 	// init client side
+	_ "github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client/api/Anthropic"
+	_ "github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client/api/Cohere"
 	_ "github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client/api/GNews"
 	_ "github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client/api/GoogleCSE"
+	_ "github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client/api/Lexicon"
 	_ "github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client/api/NEWSDATA"
+	_ "github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client/api/OpenAI"
 	_ "github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client/api/newsapi"
 
 	"github.com/go-chi/chi/v5"
@@ -40,7 +45,7 @@ import (
 	"github.com/spf13/viper"
 )
 
-func NewRouter(srvc service.Service, rds *cache.RedsiStore, vw view.View,
+func NewRouter(srvc service.Service, rds *cache.RedsiStore, rnr *runner.Runner, vw view.View,
 	tmaker tm.TokenMaker, cmaker *cm.CookieMaker) *chi.Mux {
 	errHandlerRepo, err := eh.NewErrorHandlerRepo(vw.Template.Lookup("errorpage.gotmpl"), rds)
 	if err != nil {
@@ -54,7 +59,7 @@ func NewRouter(srvc service.Service, rds *cache.RedsiStore, vw view.View,
 
 	apiRepo := api.NewAPIRepo(
 		viper.GetString("APP_API_VERSION"),
-		srvc, vw, rds, tmaker, cmaker, validator.Validate, pf.Decoder, pf.Modifier)
+		srvc, vw, rds, rnr, tmaker, cmaker, validator.Validate, pf.Decoder, pf.Modifier)
 
 	epRepo := apiRepo.EndpointRepo()
 	epChan := make(chan *model.ListAllEndpointRow)
@@ -151,6 +156,18 @@ func NewRouter(srvc service.Service, rds *cache.RedsiStore, vw view.View,
 		r.Post(rp.Page["apikey"], apiRepo.PostAPIKey)
 		r.Delete(rp.Page["apikey"]+"/{id}", apiRepo.DeleteAPIKey)
 
+		r.Get(rp.Page["webhook"], apiRepo.GetWebhook)
+		r.Post(rp.Page["webhook"], apiRepo.PostWebhook)
+		r.Delete(rp.Page["webhook"]+"/{id}", apiRepo.DeleteWebhook)
+		r.Post(rp.Page["webhook"]+"/secret", apiRepo.RotateWebhookSecret)
+		r.Get(rp.Page["webhook"]+"/deliveries", apiRepo.GetWebhookDeliveries)
+
+		r.Get(rp.Page["prompt"], apiRepo.GetPrompt)
+		r.Post(rp.Page["prompt"], apiRepo.PostPrompt)
+
+		r.Get(rp.Page["story"], apiRepo.GetStories)
+		r.Get(rp.Page["story"]+"/{sId}", apiRepo.GetStory)
+
 		r.Get(rp.Page["change-password"], auth.GetChangePassword)
 		r.Patch(rp.Page["change-password"], auth.PatchChangePassword)
 
@@ -159,6 +176,20 @@ func NewRouter(srvc service.Service, rds *cache.RedsiStore, vw view.View,
 		r.Get(rp.Page["job"], apiRepo.GetJob)
 		r.Post(rp.Page["job"], apiRepo.PostJob)
 		r.Get(rp.Page["job"]+"/{jId}", apiRepo.GetJobDetail)
+		r.Get(rp.Page["job"]+"/{jId}/progress", apiRepo.GetJobProgress)
+		r.Get(rp.Page["job"]+"/{jId}/items", apiRepo.GetJobItems)
+		r.Patch(rp.Page["job"]+"/{jId}/items", apiRepo.RequeueJobItems)
+		r.Patch(rp.Page["job"]+"/{jId}/items/{iId}", apiRepo.RequeueJobItems)
+		r.Get(rp.Page["job"]+"/{jId}/export", apiRepo.ExportJob)
+		r.Post(rp.Page["job"]+"/{jId}/clone", apiRepo.CloneJob)
+		r.Get(rp.Page["job"]+"/{jId}/compare", apiRepo.CompareJob)
+		r.Get(rp.Page["job"]+"/{jId}/stats", apiRepo.GetJobStats)
+		r.Get(rp.Page["job"]+"/{jId}/usage", apiRepo.GetJobUsage)
+		r.Get(rp.Page["job"]+"/{jId}/schedule", apiRepo.GetJobSchedule)
+		r.Put(rp.Page["job"]+"/{jId}/schedule", apiRepo.UpdateJobSchedule)
+		r.Delete(rp.Page["job"]+"/{jId}/schedule", apiRepo.DeleteJobSchedule)
+		r.Patch(rp.Page["job"]+"/{jId}", apiRepo.CancelJob)
+		r.Delete(rp.Page["job"]+"/{jId}", apiRepo.CancelJob)
 
 		r.Route(
 			rp.Page["endpoints"],
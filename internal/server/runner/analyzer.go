@@ -0,0 +1,45 @@
+package runner
+
+import (
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client/option"
+
+	// language model providers
+	_ "github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client/api/Anthropic"
+	_ "github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client/api/Cohere"
+	_ "github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client/api/Lexicon"
+	_ "github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client/api/OpenAI"
+)
+
+// returned by analyzers that got nothing from the provider, which is transient
+var ErrEmptyResponse = client.ErrEmptyResponse
+
+// EmbeddingModel returns the model that the embeddings and sentiments of a job
+// analyzed with opt are stored under.
+func EmbeddingModel(opt *option.AnalyzerOption) (string, error) {
+	return client.AnalyzerRepo.EmbeddingModel(opt)
+}
+
+// newsParagraphs returns the paragraphs of the news, the title followed by
+// the content, or the description if the content has not been parsed.
+func newsParagraphs(title, description string, content []string) []string {
+	if len(content) > 0 {
+		return append([]string{title}, content...)
+	}
+	return []string{title, description}
+}
+
+// poolChunks pools the vectors of the chunks into the vector of the news in
+// the way of the job, and returns the estimated tokens of the chunks.
+func poolChunks(pooling string, chunks []string, embds [][]float32) ([]float32, []int32) {
+	tokens := make([]int32, len(chunks))
+	weights := make([]float64, len(chunks))
+	for i, c := range chunks {
+		tokens[i] = int32(client.EstimateTokens(c))
+		weights[i] = float64(tokens[i])
+	}
+	if pooling != option.PoolingLength {
+		weights = nil
+	}
+	return client.Pool(embds, weights), tokens
+}
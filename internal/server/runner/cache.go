@@ -0,0 +1,223 @@
+package runner
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/global"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client/option"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/server/service"
+	ec "github.com/ChiaYuChang/NewsSentimentAnalyzer/pkgs/errorCode"
+)
+
+// responseCache calls the provider of a job and caches the responses, so that
+// the copies of a wire story and the news analyzed again by another job are
+// not paid for twice. Nothing is cached if ttl is not positive.
+type responseCache struct {
+	rnr  *Runner
+	anlz client.Analyzer
+	cred client.Credential
+	opt  *option.AnalyzerOption
+	ttl  time.Duration
+}
+
+// newResponseCache returns the cache of the job, which caches nothing if the
+// job bypasses the cache or the analyzer is offline, since it costs nothing.
+func (rnr *Runner) newResponseCache(anlz client.Analyzer, cred client.Credential,
+	opt *option.AnalyzerOption) *responseCache {
+	c := &responseCache{rnr: rnr, anlz: anlz, cred: cred, opt: opt}
+	if !opt.NoCache && !anlz.ModelInfo().Offline {
+		c.ttl = rnr.cacheTTL
+	}
+	return c
+}
+
+// NormalizeText collapses the white spaces of text, so that the copies of a
+// story differing only in the layout share a key.
+func NormalizeText(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// InputHash returns the hex encoded SHA-256 of the normalized text and the
+// parameters the response depends on.
+func InputHash(text string, params ...string) string {
+	h := sha256.New()
+	for _, p := range params {
+		h.Write([]byte(p))
+		h.Write([]byte{0})
+	}
+	h.Write([]byte(NormalizeText(text)))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// key returns the key of the response of the operation on text. Besides the
+// prompt, the response depends on the server the credential is for and on
+// the options of the operation, params.
+func (c *responseCache) key(operation, mdl, text string, params ...string) *service.LLMResponseKey {
+	key := &service.LLMResponseKey{
+		Provider:  c.opt.APIName,
+		Model:     mdl,
+		Operation: operation,
+	}
+	if c.opt.Prompt != nil {
+		key.PromptId = c.opt.Prompt.ID
+	}
+	params = append([]string{c.cred.BaseURL, strings.Join(c.cred.Models, ",")}, params...)
+	key.InputHash = InputHash(text, params...)
+	return key
+}
+
+// get decodes the cached response of key into v and reports whether there is
+// one. A failure to read the cache is a miss.
+func (c *responseCache) get(ctx context.Context, key *service.LLMResponseKey, v any) bool {
+	if c.ttl <= 0 {
+		return false
+	}
+
+	resp, err := c.rnr.srvc.LLMResponse().Get(ctx, &service.LLMResponseGetRequest{LLMResponseKey: *key})
+	if err != nil {
+		if !ec.MustGetEcErr(ec.ECPgxErrNoRows).IsEqual(err) {
+			global.Logger.Warn().Err(err).Str("operation", key.Operation).Msg("error while getting cached response")
+		}
+		return false
+	}
+	return json.Unmarshal(resp, v) == nil
+}
+
+// set caches v as the response of key. The cache is an optimization, so an
+// error is logged rather than returned.
+func (c *responseCache) set(ctx context.Context, key *service.LLMResponseKey, v any) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	resp, err := json.Marshal(v)
+	if err == nil {
+		err = c.rnr.srvc.LLMResponse().Set(ctx, &service.LLMResponseSetRequest{
+			LLMResponseKey: *key,
+			Response:       resp,
+			TTL:            c.ttl,
+		})
+	}
+	if err != nil {
+		global.Logger.Warn().Err(err).Str("operation", key.Operation).Msg("error while caching response")
+	}
+}
+
+// sentimentKey returns the key of the sentiment of the article.
+func (c *responseCache) sentimentKey(info client.ModelInfo, article client.Article) *service.LLMResponseKey {
+	opt := c.opt.SentimentAnalysisOptions
+	params, _ := json.Marshal([]any{opt.SentimentPromptOr(info.SentimentPrompt), opt.MaxTokens, opt.Truncate})
+	return c.key(client.OperationSentiment, info.ChatModel, article.Text, string(params))
+}
+
+// classifySentiment classifies the sentiment of the articles that have no
+// cached one in batches and caches the results.
+func (c *responseCache) classifySentiment(ctx context.Context,
+	articles ...client.Article) (map[int64]client.SentimentResult, map[int64]error) {
+	info := c.anlz.ModelInfo()
+	sentiments := make(map[int64]client.SentimentResult, len(articles))
+	keys := make(map[int64]*service.LLMResponseKey, len(articles))
+	misses := []client.Article{}
+	for _, a := range articles {
+		keys[a.NewsId] = c.sentimentKey(info, a)
+		var result client.SentimentResult
+		if c.get(ctx, keys[a.NewsId], &result) {
+			sentiments[a.NewsId] = result
+			continue
+		}
+		misses = append(misses, a)
+	}
+	if len(misses) == 0 {
+		return sentiments, map[int64]error{}
+	}
+
+	results, errs := c.rnr.batcher.ClassifySentiment(ctx, c.anlz, c.rnr.client, c.cred, c.opt, misses...)
+	for id, result := range results {
+		sentiments[id] = result
+		c.set(ctx, keys[id], result)
+	}
+	return sentiments, errs
+}
+
+// the max number of chunks embedded in a request, the limit of Cohere
+const maxEmbedBatch = 96
+
+// embed embeds the chunks with the embedding model, in order, of which the
+// ones that are not cached are sent in batches.
+func (c *responseCache) embed(ctx context.Context, mdl string, chunks ...string) ([][]float32, error) {
+	embds := make([][]float32, len(chunks))
+	keys := make([]*service.LLMResponseKey, len(chunks))
+	misses := []int{}
+	for i, chunk := range chunks {
+		keys[i] = c.key(client.OperationEmbedding, mdl, chunk, c.opt.InputType, c.opt.Truncate)
+		if !c.get(ctx, keys[i], &embds[i]) {
+			misses = append(misses, i)
+		}
+	}
+
+	for len(misses) > 0 {
+		batch := misses[:min(len(misses), maxEmbedBatch)]
+		misses = misses[len(batch):]
+
+		texts := make([]string, len(batch))
+		for j, i := range batch {
+			texts[j] = chunks[i]
+		}
+		resp, err := c.anlz.Embed(ctx, c.rnr.client, c.cred, c.opt, texts...)
+		if err != nil {
+			return nil, err
+		}
+		if len(resp) != len(batch) {
+			return nil, client.ErrResponseLengthMismatch
+		}
+		for j, i := range batch {
+			embds[i] = resp[j]
+			c.set(ctx, keys[i], resp[j])
+		}
+	}
+	return embds, nil
+}
+
+// classifyEntitySentiment classifies the sentiment of the text toward the
+// entities of the job, or returns the cached results.
+func (c *responseCache) classifyEntitySentiment(ctx context.Context, entAnlz client.EntityAnalyzer,
+	text string) ([]client.EntitySentiment, error) {
+	key := c.key(client.OperationEntitySentiment, c.anlz.ModelInfo().ChatModel, text,
+		strings.Join(c.opt.Entities, "\x1f"))
+	var results []client.EntitySentiment
+	if c.get(ctx, key, &results) {
+		return results, nil
+	}
+
+	resp, err := entAnlz.ClassifyEntitySentiment(ctx, c.rnr.client, c.cred, c.opt, text)
+	if err != nil || len(resp) == 0 {
+		return nil, err
+	}
+	c.set(ctx, key, resp[0])
+	return resp[0], nil
+}
+
+// classify labels the text in the classification task of the job, or returns
+// the cached annotation.
+func (c *responseCache) classify(ctx context.Context, classifier client.Classifier,
+	text string) (*client.Annotation, error) {
+	params, _ := json.Marshal(c.opt.ClassificationOptions)
+	key := c.key(client.OperationClassification, c.anlz.ModelInfo().ChatModel, text, string(params))
+	var annotation client.Annotation
+	if c.get(ctx, key, &annotation) {
+		return &annotation, nil
+	}
+
+	resp, err := classifier.Classify(ctx, c.rnr.client, c.cred, c.opt, text)
+	if err != nil || len(resp) == 0 {
+		return nil, err
+	}
+	c.set(ctx, key, resp[0])
+	return &resp[0], nil
+}
@@ -0,0 +1,148 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/global"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/model"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/server/service"
+	ec "github.com/ChiaYuChang/NewsSentimentAnalyzer/pkgs/errorCode"
+)
+
+var ErrClustererHasStarted = errors.New("story clusterer has already started")
+
+// Clusterer groups the embedded news of different sources into stories. A
+// news joins the story of the same embedding model whose news are the most
+// similar to it on average, if the similarity reaches the threshold, the
+// story has a news published within the window of it and has no news from
+// its source yet. Otherwise, it starts a new story.
+type Clusterer struct {
+	srvc      service.Service
+	interval  time.Duration
+	batchSize int
+	threshold float64
+	window    time.Duration
+	cancel    context.CancelFunc
+	done      chan struct{}
+	once      sync.Once
+}
+
+func NewClusterer(srvc service.Service, interval time.Duration, batchSize int,
+	threshold float64, window time.Duration) *Clusterer {
+	if batchSize < 1 {
+		batchSize = 1
+	}
+	return &Clusterer{
+		srvc:      srvc,
+		interval:  interval,
+		batchSize: batchSize,
+		threshold: threshold,
+		window:    window,
+	}
+}
+
+// Start starts clustering in a new goroutine.
+func (c *Clusterer) Start() error {
+	err := ErrClustererHasStarted
+	c.once.Do(func() {
+		var ctx context.Context
+		ctx, c.cancel = context.WithCancel(context.Background())
+		c.done = make(chan struct{})
+		go c.loop(ctx)
+		err = nil
+	})
+	return err
+}
+
+// Shutdown stops clustering and waits until the running batch has finished
+// or the given context is done.
+func (c *Clusterer) Shutdown(ctx context.Context) error {
+	if c.cancel == nil {
+		return nil
+	}
+	c.cancel()
+
+	select {
+	case <-c.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("error while shutting down story clusterer: %w", ctx.Err())
+	}
+}
+
+func (c *Clusterer) loop(ctx context.Context) {
+	defer close(c.done)
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		c.RunOnce(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce clusters a batch of the news that are not in a story yet, the
+// earliest published first, so that a story starts from its first report.
+func (c *Clusterer) RunOnce(ctx context.Context) {
+	rows, err := c.srvc.Story().GetUnclustered(ctx, c.batchSize)
+	if err != nil {
+		global.Logger.Error().
+			Err(err).
+			Msg("error while GetUnclusteredNews")
+		return
+	}
+
+	for _, row := range rows {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := c.cluster(ctx, row); err != nil {
+			global.Logger.Error().
+				Err(err).
+				Int64("news_id", row.NewsID).
+				Str("model", row.Model).
+				Msg("error while clustering news")
+		}
+	}
+}
+
+func (c *Clusterer) cluster(ctx context.Context, row *model.GetUnclusteredNewsRow) error {
+	nearest, err := c.srvc.Story().Nearest(ctx, &service.StoryNearestRequest{
+		Model:     row.Model,
+		Embedding: row.Embedding.Slice(),
+		Source:    row.Source,
+		PublishAt: row.PublishAt.Time,
+		Window:    c.window,
+	})
+	if err != nil && !ec.MustGetEcErr(ec.ECPgxErrNoRows).IsEqual(err) {
+		return err
+	}
+
+	if err == nil && nearest.Similarity >= c.threshold {
+		_, err = c.srvc.Story().AddNews(ctx, &service.StoryAddNewsRequest{
+			StoryId:    nearest.StoryID,
+			NewsId:     row.NewsID,
+			Model:      row.Model,
+			Similarity: nearest.Similarity,
+			PublishAt:  row.PublishAt.Time,
+		})
+		return err
+	}
+
+	_, err = c.srvc.Story().Create(ctx, &service.StoryCreateRequest{
+		Model:     row.Model,
+		NewsId:    row.NewsID,
+		Title:     row.Title,
+		PublishAt: row.PublishAt.Time,
+	})
+	return err
+}
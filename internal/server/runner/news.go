@@ -0,0 +1,82 @@
+package runner
+
+import (
+	"context"
+	"net/url"
+	"sync/atomic"
+
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/global"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client/api"
+	ldcli "github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/grpc/languageDetector"
+	newsparser "github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/grpc/newsParser"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/server/service"
+	ldpf "github.com/ChiaYuChang/NewsSentimentAnalyzer/proto/language_detector"
+	"github.com/pemistahl/lingua-go"
+)
+
+// ParseResult counts the previews that have passed each step. The counts are
+// final once the news channel has been closed.
+type ParseResult struct {
+	LangDetected atomic.Int32
+	Parsed       atomic.Int32
+}
+
+// ParseFunc turns previews, keyed by their ids, into requests to create news.
+type ParseFunc func(ctx context.Context, items map[string]api.NewsPreview) (
+	<-chan *service.NewsCreateRequest, *ParseResult, error)
+
+// ParseNewsPreviews detects the language of the previews and gets their guid
+// via the microservices. Previews failed in any step are dropped.
+func ParseNewsPreviews(ctx context.Context, items map[string]api.NewsPreview) (
+	<-chan *service.NewsCreateRequest, *ParseResult, error) {
+	reqChan := make(chan *ldpf.LanguageDetectRequest)
+	respChan, err, errChan := ldcli.MustGetLanguageDetectorClient().DetectLanguage(ctx, reqChan)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	go func(errChan <-chan error) {
+		for err := range errChan {
+			global.Logger.Error().Err(err).Msg("error while detect language")
+		}
+	}(errChan)
+
+	go func(reqChan chan<- *ldpf.LanguageDetectRequest) {
+		defer close(reqChan)
+		thr := float64(.9)
+		for _, item := range items {
+			reqChan <- &ldpf.LanguageDetectRequest{
+				Id:        item.Id.String(),
+				Text:      item.Title,
+				Threshold: &thr,
+			}
+		}
+	}(reqChan)
+
+	result := &ParseResult{}
+	cnChan := make(chan *service.NewsCreateRequest, 10)
+	go func(respChan <-chan *ldpf.LanguageDetectResponse,
+		cnChan chan<- *service.NewsCreateRequest) {
+		defer close(cnChan)
+		cli, _ := newsparser.GetNewsParserClient()
+		i := int64(0)
+		for resp := range respChan {
+			result.LangDetected.Add(1)
+			prev := items[resp.GetId()]
+			u, _ := url.Parse(prev.Link)
+			_, guid, err := cli.GetGUID(ctx, i, u.String())
+			if err != nil {
+				global.Logger.Error().
+					Err(err).
+					Str("url", u.String()).
+					Msg("error while GetGUID")
+				continue
+			}
+			lang := lingua.Language(int(resp.GetLanguage())).IsoCode639_1()
+			cnChan <- prev.ToNewsCreateRequest(guid, lang.String(), u.Host, nil)
+			result.Parsed.Add(1)
+			i++
+		}
+	}(respChan, cnChan)
+	return cnChan, result, nil
+}
@@ -0,0 +1,281 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/global"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/model"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/server/service"
+)
+
+var ErrNotifierHasStarted = errors.New("notifier has already started")
+var ErrForbiddenAddress = errors.New("webhook address is not allowed")
+
+const (
+	HeaderWebhookSignature = "X-Webhook-Signature"
+	HeaderWebhookTimestamp = "X-Webhook-Timestamp"
+	HeaderWebhookDelivery  = "X-Webhook-Delivery"
+)
+
+// SignWebhook returns the signature of a payload sent at ts (unix seconds),
+// which is "sha256=" followed by the hex encoded HMAC-SHA256 of "<ts>.<body>"
+// keyed by the secret of the owner.
+func SignWebhook(secret string, ts int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(ts, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhook reports whether sig is the signature of the payload, in
+// constant time.
+func VerifyWebhook(secret string, ts int64, body []byte, sig string) bool {
+	return hmac.Equal([]byte(SignWebhook(secret, ts, body)), []byte(sig))
+}
+
+// NewWebhookClient returns a client that does not follow redirects and, unless
+// allowPrivate is set, refuses to connect to loopback, private and link-local
+// addresses, so that a webhook can not be used to reach internal services.
+func NewWebhookClient(timeout time.Duration, allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivate {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+				ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsMulticast() {
+				return fmt.Errorf("%w: %s", ErrForbiddenAddress, host)
+			}
+			return nil
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Transport: transport,
+		Timeout:   timeout,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// Notifier polls the queued webhook deliveries, which are queued by the
+// database whenever the status of a job changes, and posts their payloads to
+// the webhooks. Failed deliveries are retried with backoff.
+type Notifier struct {
+	srvc        service.Service
+	client      *http.Client
+	interval    time.Duration
+	nDeliveries int
+	timeout     time.Duration
+	maxAttempts int
+	backoffBase time.Duration
+	backoffMax  time.Duration
+	cancel      context.CancelFunc
+	done        chan struct{}
+	once        sync.Once
+}
+
+func NewNotifier(srvc service.Service, interval time.Duration, nDeliveries int, timeout time.Duration) *Notifier {
+	if nDeliveries < 1 {
+		nDeliveries = 1
+	}
+	return &Notifier{
+		srvc:        srvc,
+		client:      NewWebhookClient(timeout, false),
+		interval:    interval,
+		nDeliveries: nDeliveries,
+		timeout:     timeout,
+		maxAttempts: 5,
+		backoffBase: 10 * time.Second,
+		backoffMax:  time.Hour,
+	}
+}
+
+func (n *Notifier) WithHTTPClient(client *http.Client) *Notifier {
+	n.client = client
+	return n
+}
+
+// WithRetry sets how many times a delivery is attempted before it fails and
+// the exponential backoff between the attempts.
+func (n *Notifier) WithRetry(maxAttempts int, base, max time.Duration) *Notifier {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	n.maxAttempts = maxAttempts
+	n.backoffBase = base
+	n.backoffMax = max
+	return n
+}
+
+// Start starts polling in a new goroutine.
+func (n *Notifier) Start() error {
+	err := ErrNotifierHasStarted
+	n.once.Do(func() {
+		var ctx context.Context
+		ctx, n.cancel = context.WithCancel(context.Background())
+		n.done = make(chan struct{})
+		go n.loop(ctx)
+		err = nil
+	})
+	return err
+}
+
+// Shutdown stops polling and waits until the in-flight deliveries have
+// finished or the given context is done.
+func (n *Notifier) Shutdown(ctx context.Context) error {
+	if n.cancel == nil {
+		return nil
+	}
+	n.cancel()
+
+	select {
+	case <-n.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("error while shutting down webhook notifier: %w", ctx.Err())
+	}
+}
+
+func (n *Notifier) loop(ctx context.Context) {
+	defer close(n.done)
+
+	ticker := time.NewTicker(n.interval)
+	defer ticker.Stop()
+
+	for {
+		n.RunOnce(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce sends the due deliveries, the earliest first.
+func (n *Notifier) RunOnce(ctx context.Context) {
+	rows, err := n.srvc.Webhook().GetDueDeliveries(ctx, n.nDeliveries)
+	if err != nil {
+		global.Logger.Error().
+			Err(err).
+			Msg("error while GetDueWebhookDeliveries")
+		return
+	}
+
+	for _, row := range rows {
+		if ctx.Err() != nil {
+			return
+		}
+		n.deliver(ctx, row)
+	}
+}
+
+func (n *Notifier) deliver(ctx context.Context, row *model.GetDueWebhookDeliveriesRow) {
+	logger := global.Logger.With().
+		Int64("delivery_id", row.ID).
+		Int64("job_id", row.JobID).
+		Logger()
+
+	// lease the delivery for the duration of an attempt, so that it will not
+	// be sent twice by other instances
+	now := time.Now()
+	claimed, err := n.srvc.Webhook().ClaimDelivery(ctx, &service.WebhookClaimDeliveryRequest{
+		ID:            row.ID,
+		PrevAttemptAt: row.NextAttemptAt.Time,
+		NextAttemptAt: now.Add(n.timeout + n.backoffBase),
+	})
+	if err != nil {
+		logger.Error().Err(err).Msg("error while ClaimWebhookDelivery")
+		return
+	}
+	if claimed == 0 {
+		logger.Info().Msg("delivery has been claimed")
+		return
+	}
+
+	code, err := n.send(ctx, row, now)
+	if err == nil {
+		_, err = n.srvc.Webhook().MarkDelivered(ctx, &service.WebhookDeliveredRequest{
+			ID:           row.ID,
+			ResponseCode: code,
+		})
+		if err != nil {
+			logger.Error().Err(err).Msg("error while MarkWebhookDeliveryDelivered")
+		}
+		return
+	}
+
+	attempts := int(row.Attempts) + 1
+	stage := string(model.WebhookDeliveryStageRetrying)
+	if attempts >= n.maxAttempts {
+		stage = string(model.WebhookDeliveryStageFailed)
+	}
+	logger.Warn().
+		Err(err).
+		Int("attempts", attempts).
+		Str("stage", stage).
+		Msg("error while delivering webhook")
+
+	_, err = n.srvc.Webhook().MarkFailed(ctx, &service.WebhookDeliveryFailRequest{
+		ID:            row.ID,
+		Stage:         stage,
+		ResponseCode:  code,
+		LastError:     err.Error(),
+		NextAttemptAt: time.Now().Add(backoff(attempts, n.backoffBase, n.backoffMax)),
+	})
+	if err != nil {
+		logger.Error().Err(err).Msg("error while MarkWebhookDeliveryFailed")
+	}
+}
+
+// send posts the payload and returns the status code of the response, if any.
+// Responses other than 2xx are errors.
+func (n *Notifier) send(ctx context.Context, row *model.GetDueWebhookDeliveriesRow, now time.Time) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, n.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, row.Url, bytes.NewReader(row.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	ts := now.Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderWebhookSignature, SignWebhook(row.Secret, ts, row.Payload))
+	req.Header.Set(HeaderWebhookTimestamp, strconv.FormatInt(ts, 10))
+	req.Header.Set(HeaderWebhookDelivery, strconv.FormatInt(row.ID, 10))
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
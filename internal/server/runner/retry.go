@@ -0,0 +1,49 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	ec "github.com/ChiaYuChang/NewsSentimentAnalyzer/pkgs/errorCode"
+)
+
+// IsTransient reports whether a failed attempt is worth retrying, e.g. rate
+// limits, upstream server errors and network errors.
+func IsTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var ecErr *ec.Error
+	if errors.As(err, &ecErr) {
+		switch ecErr.ErrorCode {
+		case ec.ECTooManyRequests, ec.ECRequestTimeout,
+			ec.ECServerError, ec.ECServiceUnavailable,
+			ec.ECgRPCClientError:
+			return true
+		}
+		return false
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrEmptyResponse) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// backoff returns the delay before the next attempt, it doubles on each
+// attempt and is capped at max.
+func backoff(attempts int, base, max time.Duration) time.Duration {
+	d := base
+	for i := 1; i < attempts && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+	return d
+}
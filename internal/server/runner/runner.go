@@ -0,0 +1,770 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/global"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client/option"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/model"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/server/service"
+	ec "github.com/ChiaYuChang/NewsSentimentAnalyzer/pkgs/errorCode"
+	"github.com/google/uuid"
+)
+
+var ErrRunnerHasStarted = errors.New("runner has already started")
+var ErrUnknownAnalyzer = client.ErrAnalyzerNotFound
+var ErrJobCanceled = errors.New("job has been canceled")
+var errJobNotDue = errors.New("no item of the job is due")
+
+// Runner polls created jobs and executes them one at a time. Jobs are picked
+// in a round-robin fashion among owners so that a single user can not starve
+// the others.
+type Runner struct {
+	srvc        service.Service
+	client      *http.Client
+	interval    time.Duration
+	nJobs       int
+	timeout     time.Duration
+	maxAttempts int
+	backoffBase time.Duration
+	backoffMax  time.Duration
+	batcher     client.Batcher
+	prices      global.PriceOptions
+	cacheTTL    time.Duration
+	cancel      context.CancelFunc
+	done        chan struct{}
+	once        sync.Once
+	mu          sync.Mutex
+	running     map[int64]context.CancelCauseFunc
+}
+
+func NewRunner(srvc service.Service, interval time.Duration, nJobsPerUser int, timeout time.Duration) *Runner {
+	if nJobsPerUser < 1 {
+		nJobsPerUser = 1
+	}
+	return &Runner{
+		srvc:        srvc,
+		client:      http.DefaultClient,
+		interval:    interval,
+		nJobs:       nJobsPerUser,
+		timeout:     timeout,
+		maxAttempts: 5,
+		backoffBase: 2 * time.Second,
+		backoffMax:  time.Minute,
+		running:     map[int64]context.CancelCauseFunc{},
+	}
+}
+
+func (rnr *Runner) WithHTTPClient(client *http.Client) *Runner {
+	rnr.client = client
+	return rnr
+}
+
+// WithRetry sets how many times an item is attempted before it is dead and
+// the exponential backoff between the attempts.
+func (rnr *Runner) WithRetry(maxAttempts int, base, max time.Duration) *Runner {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	rnr.maxAttempts = maxAttempts
+	rnr.backoffBase = base
+	rnr.backoffMax = max
+	return rnr
+}
+
+// WithBatch packs the articles into sentiment analysis requests of which the
+// estimated tokens of the articles do not exceed budget, and there are no
+// more than maxSize articles. Every article is sent alone if budget is not
+// positive.
+func (rnr *Runner) WithBatch(budget, maxSize int) *Runner {
+	rnr.batcher = client.Batcher{Budget: budget, MaxSize: maxSize}
+	return rnr
+}
+
+// WithCache caches the responses of the providers for ttl, nothing is cached
+// if ttl is not positive.
+func (rnr *Runner) WithCache(ttl time.Duration) *Runner {
+	rnr.cacheTTL = ttl
+	return rnr
+}
+
+// WithPrices estimates the cost of the usage of the jobs with prices.
+func (rnr *Runner) WithPrices(prices global.PriceOptions) *Runner {
+	rnr.prices = prices
+	return rnr
+}
+
+// Start starts polling in a new goroutine.
+func (rnr *Runner) Start() error {
+	err := ErrRunnerHasStarted
+	rnr.once.Do(func() {
+		var ctx context.Context
+		ctx, rnr.cancel = context.WithCancel(context.Background())
+		rnr.done = make(chan struct{})
+		go rnr.loop(ctx)
+		err = nil
+	})
+	return err
+}
+
+// Shutdown stops polling and waits until the running job has been put back
+// or the given context is done.
+func (rnr *Runner) Shutdown(ctx context.Context) error {
+	if rnr.cancel == nil {
+		return nil
+	}
+	rnr.cancel()
+
+	select {
+	case <-rnr.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("error while shutting down job runner: %w", ctx.Err())
+	}
+}
+
+// Cancel stops the in-flight work of the given job. It returns false if the
+// job is not running in this runner.
+func (rnr *Runner) Cancel(jId int64) bool {
+	rnr.mu.Lock()
+	defer rnr.mu.Unlock()
+
+	cancel, ok := rnr.running[jId]
+	if ok {
+		cancel(ErrJobCanceled)
+	}
+	return ok
+}
+
+func (rnr *Runner) register(jId int64, cancel context.CancelCauseFunc) {
+	rnr.mu.Lock()
+	defer rnr.mu.Unlock()
+	rnr.running[jId] = cancel
+}
+
+func (rnr *Runner) unregister(jId int64) {
+	rnr.mu.Lock()
+	defer rnr.mu.Unlock()
+	delete(rnr.running, jId)
+}
+
+// how often the expired responses of the providers are deleted
+const cleanUpInterval = time.Hour
+
+func (rnr *Runner) loop(ctx context.Context) {
+	defer close(rnr.done)
+
+	ticker := time.NewTicker(rnr.interval)
+	defer ticker.Stop()
+
+	var cleanedAt time.Time
+	for {
+		if time.Since(cleanedAt) >= cleanUpInterval {
+			rnr.CleanUp(ctx)
+			cleanedAt = time.Now()
+		}
+		rnr.RunOnce(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CleanUp deletes the cached responses of the providers which have expired,
+// and returns how many are deleted.
+func (rnr *Runner) CleanUp(ctx context.Context) int64 {
+	n, err := rnr.srvc.LLMResponse().CleanUp(ctx)
+	if err != nil {
+		global.Logger.Error().
+			Err(err).
+			Msg("error while cleaning up llm responses")
+		return 0
+	}
+	if n > 0 {
+		global.Logger.Info().
+			Int64("n", n).
+			Msg("expired llm responses deleted")
+	}
+	return n
+}
+
+// RunOnce fetches the created jobs and executes them in round-robin order.
+func (rnr *Runner) RunOnce(ctx context.Context) {
+	rows, err := rnr.srvc.Job().GetOldestNCreatedJobsForEachUser(ctx, rnr.nJobs)
+	if err != nil {
+		global.Logger.Error().
+			Err(err).
+			Msg("error while GetOldestNCreatedJobsForEachUser")
+		return
+	}
+
+	for _, job := range RoundRobin(rows) {
+		if ctx.Err() != nil {
+			return
+		}
+		rnr.runJob(ctx, job)
+	}
+}
+
+// RoundRobin interleaves jobs by owner. Owners are ordered by their oldest
+// job and each owner's jobs are kept in ascending id order.
+func RoundRobin(rows []service.CreatedJobsRow) []service.CreatedJobsRow {
+	owners := []uuid.UUID{}
+	queues := map[uuid.UUID][]service.CreatedJobsRow{}
+	for i := len(rows) - 1; i >= 0; i-- {
+		// rows are sorted by id in descending order
+		if _, ok := queues[rows[i].Owner]; !ok {
+			owners = append(owners, rows[i].Owner)
+		}
+		queues[rows[i].Owner] = append(queues[rows[i].Owner], rows[i])
+	}
+
+	// owners should be ordered by their oldest job
+	for i := 1; i < len(owners); i++ {
+		for j := i; j > 0 && queues[owners[j]][0].ID < queues[owners[j-1]][0].ID; j-- {
+			owners[j], owners[j-1] = owners[j-1], owners[j]
+		}
+	}
+
+	jobs := make([]service.CreatedJobsRow, 0, len(rows))
+	for round := 0; len(jobs) < len(rows); round++ {
+		for _, owner := range owners {
+			if round < len(queues[owner]) {
+				jobs = append(jobs, queues[owner][round])
+			}
+		}
+	}
+	return jobs
+}
+
+func (rnr *Runner) runJob(ctx context.Context, job service.CreatedJobsRow) {
+	logger := global.Logger.With().
+		Int64("job_id", job.ID).
+		Str("owner", job.Owner.String()).
+		Logger()
+
+	// register before the status changes, so that a cancellation right after
+	// the job becomes running will not be missed
+	cancelCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	rnr.register(job.ID, cancel)
+	defer rnr.unregister(job.ID)
+
+	// the job may have been canceled after it was fetched
+	n, err := rnr.updateStatus(ctx, job, model.JobStatusRunning, model.JobStatusCreated)
+	if err != nil {
+		logger.Error().Err(err).Msg("error while updating job status to running")
+		return
+	}
+	if n == 0 {
+		logger.Info().Msg("job is no longer in created status")
+		return
+	}
+	logger.Info().Msg("job started")
+
+	jobCtx, timeoutCancel := context.WithTimeout(cancelCtx, rnr.timeout)
+	defer timeoutCancel()
+
+	nOk, nFailed, err := rnr.execute(jobCtx, job)
+
+	// the status should be updated even if ctx has been canceled
+	updateCtx, updateCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer updateCancel()
+
+	status := model.JobStatusDone
+	switch {
+	case errors.Is(context.Cause(cancelCtx), ErrJobCanceled):
+		// the status has been set by the one who canceled the job
+		status = model.JobStatusCanceled
+		if nOk > 0 {
+			_, mErr := rnr.srvc.Job().MarkPartial(updateCtx, &service.JobMarkPartialRequest{
+				ID: job.ID, Owner: job.Owner,
+			})
+			if mErr != nil {
+				logger.Error().Err(mErr).Msg("error while marking job partial")
+			}
+		}
+	case ctx.Err() != nil, errors.Is(err, errJobNotDue):
+		// the runner is shutting down or the items of the job are waiting
+		// for their next attempt, put the job back to the queue
+		status = model.JobStatusCreated
+	case err != nil, nOk == 0 && nFailed > 0:
+		status = model.JobStatusFailed
+	}
+
+	if status != model.JobStatusCanceled {
+		if _, uErr := rnr.updateStatus(updateCtx, job, status, model.JobStatusRunning); uErr != nil {
+			logger.Error().Err(uErr).Msg("error while updating job status")
+		}
+	}
+
+	logger.Info().
+		Err(err).
+		Int("n_ok", nOk).
+		Int("n_failed", nFailed).
+		Str("status", string(status)).
+		Msg("job finished")
+}
+
+// updateStatus updates the status of the job only if its current status is
+// one of from. A job that has been canceled therefore stays canceled.
+func (rnr *Runner) updateStatus(ctx context.Context, job service.CreatedJobsRow,
+	status model.JobStatus, from ...model.JobStatus) (int64, error) {
+	fromStatus := make([]string, len(from))
+	for i, f := range from {
+		fromStatus[i] = string(f)
+	}
+
+	return rnr.srvc.Job().UpdateStatusFrom(ctx, &service.JobUpdateStatusFromRequest{
+		Status: string(status),
+		From:   fromStatus,
+		ID:     job.ID,
+		Owner:  job.Owner,
+	})
+}
+
+// execute analyzes every item of the job until all of them are either done
+// or dead, and returns the number of them. Items that failed transiently are
+// retried with exponential backoff, errJobNotDue is returned if none of the
+// items left is due yet.
+func (rnr *Runner) execute(ctx context.Context, job service.CreatedJobsRow) (nOk, nFailed int, err error) {
+	opt := job.AnalyzerOptions()
+	if opt == nil {
+		return 0, 0, errors.New("error while decoding analyzer options")
+	}
+
+	anlz, err := client.AnalyzerRepo.Get(opt.APIName)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var cred client.Credential
+	if !anlz.ModelInfo().Offline {
+		key, err := rnr.srvc.APIKey().Get(ctx, &service.APIKeyGetRequest{
+			Owner: job.Owner,
+			ApiID: job.LlmApiID,
+		})
+		if err != nil {
+			return 0, 0, fmt.Errorf("error while getting api key: %w", err)
+		}
+		cred = client.Credential{
+			Key:        key.Key,
+			BaseURL:    key.BaseUrl.String,
+			AuthHeader: key.AuthHeader.String,
+			Models:     key.Models,
+		}
+	}
+
+	if opt.Prompt != nil {
+		if opt.PromptText, err = rnr.renderPrompt(ctx, job.Owner, opt); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	// the usage is stored even if the job is interrupted
+	meter := &client.Meter{}
+	ctx = client.WithMeter(ctx, meter)
+	defer rnr.storeUsage(context.WithoutCancel(ctx), job.ID, opt.APIName, meter)
+	cache := rnr.newResponseCache(anlz, cred, opt)
+
+	if _, err := rnr.srvc.JobItem().Create(ctx, job.ID); err != nil {
+		return 0, 0, fmt.Errorf("error while creating job items: %w", err)
+	}
+
+	mdl := anlz.ModelInfo().EmbeddingModelOf(opt)
+	for {
+		items, err := rnr.srvc.JobItem().GetDue(ctx, job.ID)
+		if err != nil {
+			return nOk, nFailed, fmt.Errorf("error while getting job items: %w", err)
+		}
+
+		if len(items) == 0 {
+			_, err := rnr.srvc.JobItem().GetNextAttempt(ctx, job.ID)
+			if ec.MustGetEcErr(ec.ECPgxErrNoRows).IsEqual(err) {
+				// every item is either done or dead
+				return nOk, nFailed, nil
+			}
+			if err != nil {
+				return nOk, nFailed, fmt.Errorf("error while getting next attempt: %w", err)
+			}
+
+			// the job is put back instead of waiting, so that the jobs of
+			// the other owners are run meanwhile, and it is not fetched
+			// again until one of its items is due
+			return nOk, nFailed, errJobNotDue
+		}
+
+		analyzed, err := rnr.getAnalyzed(ctx, cache, mdl, items)
+		if err != nil {
+			return nOk, nFailed, err
+		}
+		annotated, err := rnr.getAnnotated(ctx, cache, job.Owner, mdl, analyzed)
+		if err != nil {
+			return nOk, nFailed, err
+		}
+
+		articles := make([]client.Article, len(items))
+		unanalyzed := make([]client.Article, 0, len(items))
+		for i, item := range items {
+			paragraphs := newsParagraphs(item.Title, item.Description, item.Content)
+			articles[i] = client.Article{
+				NewsId:     item.NewsID,
+				Text:       strings.Join(paragraphs, "\n"),
+				Paragraphs: paragraphs,
+			}
+			if _, ok := analyzed[item.NewsID]; !ok {
+				unanalyzed = append(unanalyzed, articles[i])
+			}
+		}
+		sentiments, errs := cache.classifySentiment(ctx, unanalyzed...)
+
+		for i, item := range items {
+			if ctx.Err() != nil {
+				return nOk, nFailed, ctx.Err()
+			}
+
+			eId, ok := analyzed[item.NewsID]
+			err := errs[item.NewsID]
+			switch {
+			case ok:
+				// the embedding is reused, e.g. of news shared with another
+				// job, but the stages it does not cover are run for the job
+				err = rnr.analyzeEntities(ctx, cache, job.Owner, mdl, articles[i])
+				if err == nil && !annotated[item.NewsID] {
+					err = rnr.annotate(ctx, cache, job.Owner, mdl, articles[i])
+				}
+			case err != nil:
+				err = fmt.Errorf("error while analyzing sentiment: %w", err)
+			default:
+				eId, err = rnr.analyze(ctx, cache, job.Owner, mdl, articles[i], sentiments[item.NewsID])
+			}
+			if err != nil && ctx.Err() != nil {
+				// interrupted, the item will be attempted again if the job is put back
+				return nOk, nFailed, ctx.Err()
+			}
+
+			if err == nil {
+				rnr.settle(ctx, job.ID, item, eId, nil)
+				nOk++
+				continue
+			}
+
+			global.Logger.Warn().
+				Err(err).
+				Int64("job_id", job.ID).
+				Int64("news_id", item.NewsID).
+				Int32("attempts", item.Attempts+1).
+				Msg("error while analyzing news")
+			if dead := rnr.settle(ctx, job.ID, item, 0, err); dead {
+				nFailed++
+			}
+		}
+		rnr.storeUsage(ctx, job.ID, opt.APIName, meter)
+	}
+}
+
+// storeUsage stores the usage recorded by meter since the last time, of which
+// the cost is estimated with the prices of the runner. The usage is for
+// accounting only, so an error is logged rather than returned.
+func (rnr *Runner) storeUsage(ctx context.Context, jobID int64, provider string, meter *client.Meter) {
+	for _, u := range meter.Take() {
+		req := &service.CreateJobUsageRequest{
+			JobId:        jobID,
+			Provider:     provider,
+			Model:        u.Model,
+			Operation:    u.Operation,
+			InputTokens:  int32(u.InputTokens),
+			OutputTokens: int32(u.OutputTokens),
+		}
+		if cost, ok := rnr.prices.Cost(u.Model, u.InputTokens, u.OutputTokens); ok {
+			req.Cost = &cost
+		}
+		if _, err := rnr.srvc.JobUsage().Create(ctx, req); err != nil {
+			global.Logger.Warn().
+				Err(err).
+				Int64("job_id", jobID).
+				Str("operation", u.Operation).
+				Msg("error while storing usage")
+		}
+	}
+}
+
+// analyze embeds the article, of which the sentiment has been classified in a
+// batch, stores both and returns the id of the embedding.
+func (rnr *Runner) analyze(ctx context.Context, cache *responseCache, owner uuid.UUID, mdl string,
+	article client.Article, result client.SentimentResult) (int64, error) {
+	// the article is embedded in chunks under the input limit of the model
+	info, _ := cache.anlz.ModelInfo().EmbeddingModelNamed(mdl)
+	chunks := client.Chunk(info.MaxTokens, article.ParagraphsOrText()...)
+	embds, err := cache.embed(ctx, mdl, chunks...)
+	if err != nil {
+		return 0, fmt.Errorf("error while embedding: %w", err)
+	}
+	embd, tokens := poolChunks(cache.opt.Pooling, chunks, embds)
+
+	// the embedding is created last, since the news is skipped once it has one
+	if err := rnr.analyzeEntities(ctx, cache, owner, mdl, article); err != nil {
+		return 0, err
+	}
+	if err := rnr.annotate(ctx, cache, owner, mdl, article); err != nil {
+		return 0, err
+	}
+
+	req := &service.CreateEmbeddingChunksRequest{NewsId: article.NewsId, Model: mdl}
+	for i, e := range embds {
+		req.Chunks = append(req.Chunks, service.EmbeddingChunk{NTokens: tokens[i], Embedding: e})
+	}
+	if err := rnr.srvc.Embedding().CreateChunks(ctx, req); err != nil {
+		return 0, fmt.Errorf("error while storing chunks: %w", err)
+	}
+
+	return rnr.srvc.Embedding().Create(ctx, &service.CreateEmbeddingRequest{
+		NewsId:     article.NewsId,
+		Model:      mdl,
+		Embedding:  embd,
+		Sentiment:  result.Sentiment,
+		Score:      result.Sentiment.Score(),
+		Confidence: result.Confidence,
+		Rationale:  truncate(result.Rationale, maxRationaleLen),
+		PromptId:   promptIdOf(cache.opt),
+	})
+}
+
+// promptIdOf returns the id of the prompt of opt, or 0 if the default prompt of
+// the analyzer is used.
+func promptIdOf(opt *option.AnalyzerOption) int64 {
+	if opt.Prompt == nil {
+		return 0
+	}
+	return opt.Prompt.ID
+}
+
+// renderPrompt renders the version of the prompt of the owner recorded in the
+// options of the job, which should be of the analyzer of the job.
+func (rnr *Runner) renderPrompt(ctx context.Context, owner uuid.UUID, opt *option.AnalyzerOption) (string, error) {
+	prompt, err := rnr.srvc.Prompt().Get(ctx, &service.PromptGetRequest{
+		Owner: owner,
+		ID:    opt.Prompt.ID,
+	})
+	if err != nil {
+		return "", fmt.Errorf("error while getting prompt: %w", err)
+	}
+	if prompt.Version != opt.Prompt.Version || prompt.Provider != opt.APIName ||
+		prompt.Task != service.PromptTaskSentiment {
+		return "", fmt.Errorf("prompt %d is not version %d of a sentiment prompt of %s",
+			opt.Prompt.ID, opt.Prompt.Version, opt.APIName)
+	}
+
+	text, err := service.RenderPrompt(prompt.Body, opt)
+	if err != nil {
+		return "", fmt.Errorf("error while rendering prompt: %w", err)
+	}
+	return text, nil
+}
+
+// analyzeEntities classifies and stores the sentiment of the article toward
+// each of the entities in opt.Entities it mentions for the owner and the prompt
+// of the job. It does nothing if there is no entity or the analyzer does not
+// support entities.
+func (rnr *Runner) analyzeEntities(ctx context.Context, cache *responseCache, owner uuid.UUID, mdl string,
+	article client.Article) error {
+	entAnlz, ok := cache.anlz.(client.EntityAnalyzer)
+	if !ok || len(cache.opt.Entities) == 0 {
+		return nil
+	}
+
+	results, err := cache.classifyEntitySentiment(ctx, entAnlz, article.Text)
+	if err != nil {
+		return fmt.Errorf("error while analyzing entity sentiment: %w", err)
+	}
+
+	for _, r := range results {
+		_, err := rnr.srvc.EntitySentiment().Upsert(ctx, &service.UpsertEntitySentimentRequest{
+			NewsId:     article.NewsId,
+			Entity:     r.Entity,
+			Model:      mdl,
+			Sentiment:  r.Sentiment,
+			Confidence: r.Confidence,
+			Rationale:  truncate(r.Rationale, maxRationaleLen),
+			Owner:      owner,
+			PromptId:   promptIdOf(cache.opt),
+		})
+		if err != nil {
+			return fmt.Errorf("error while storing entity sentiment: %w", err)
+		}
+	}
+	return nil
+}
+
+// annotate labels and stores the article in the classification task of opt
+// for the owner of the job. It does nothing if there is no task or the analyzer does not support
+// classification, and stores nothing if the model gives no label.
+func (rnr *Runner) annotate(ctx context.Context, cache *responseCache, owner uuid.UUID, mdl string,
+	article client.Article) error {
+	classifier, ok := cache.anlz.(client.Classifier)
+	if !ok || cache.opt.Task == "" {
+		return nil
+	}
+
+	annotation, err := cache.classify(ctx, classifier, article.Text)
+	if err != nil {
+		return fmt.Errorf("error while classifying: %w", err)
+	}
+	if annotation == nil || annotation.Label == "" {
+		return nil
+	}
+
+	_, err = rnr.srvc.Annotation().Upsert(ctx, &service.UpsertAnnotationRequest{
+		NewsId:     article.NewsId,
+		Task:       cache.opt.Task,
+		Label:      annotation.Label,
+		Model:      mdl,
+		Confidence: annotation.Confidence,
+		Rationale:  truncate(annotation.Rationale, maxRationaleLen),
+		Owner:      owner,
+		TaskHash:   cache.opt.ClassificationOptions.Hash(),
+	})
+	if err != nil {
+		return fmt.Errorf("error while storing annotation: %w", err)
+	}
+	return nil
+}
+
+// the max number of characters of a rationale kept in the db
+const maxRationaleLen = 2048
+
+// truncate cuts s down to n characters.
+func truncate(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n])
+	}
+	return s
+}
+
+// settle records the result of an attempt on the item, of which eId is the
+// embedding the job got if it succeeded, and reports whether the item is dead.
+// It works even if the job has just been canceled, since the attempt has been
+// made anyway.
+func (rnr *Runner) settle(ctx context.Context, jId int64, item *model.GetDueJobItemsRow,
+	eId int64, err error) (dead bool) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+
+	prog := &service.JobIncrProgressRequest{JobId: jId}
+	var uErr error
+	switch {
+	case err == nil:
+		prog.Embedded, prog.SentimentScored = 1, 1
+		_, uErr = rnr.srvc.JobItem().MarkDone(ctx, &service.JobItemDoneRequest{ID: item.ID, EmbeddingId: eId})
+	case IsTransient(err) && int(item.Attempts)+1 < rnr.maxAttempts:
+		_, uErr = rnr.srvc.JobItem().MarkFailed(ctx, &service.JobItemFailRequest{
+			ID:            item.ID,
+			Stage:         string(model.JobItemStageRetrying),
+			LastError:     err.Error(),
+			NextAttemptAt: time.Now().Add(backoff(int(item.Attempts)+1, rnr.backoffBase, rnr.backoffMax)),
+		})
+		// the item is not settled yet
+		prog = nil
+	default:
+		dead = true
+		prog.Failed = 1
+		_, uErr = rnr.srvc.JobItem().MarkFailed(ctx, &service.JobItemFailRequest{
+			ID:            item.ID,
+			Stage:         string(model.JobItemStageDead),
+			LastError:     err.Error(),
+			NextAttemptAt: time.Now(),
+		})
+	}
+
+	if uErr != nil {
+		global.Logger.Error().
+			Err(uErr).
+			Int64("job_id", jId).
+			Int64("item_id", item.ID).
+			Msg("error while updating job item")
+	}
+
+	if prog != nil {
+		if pErr := rnr.srvc.Job().IncrProgress(ctx, prog); pErr != nil {
+			global.Logger.Error().
+				Err(pErr).
+				Int64("job_id", jId).
+				Msg("error while updating job progress")
+		}
+	}
+	return dead
+}
+
+// getAnalyzed returns the latest embedding of the given model of the news of
+// each item that has one, by the id of the news. Only the embeddings of which
+// the sentiment was classified with the prompt of the job are returned, and
+// none if the job bypasses the cache.
+func (rnr *Runner) getAnalyzed(ctx context.Context, cache *responseCache, mdl string,
+	items []*model.GetDueJobItemsRow) (map[int64]int64, error) {
+	analyzed := map[int64]int64{}
+	if len(items) == 0 || cache.opt.NoCache {
+		return analyzed, nil
+	}
+
+	ids := make([]int32, len(items))
+	for i, item := range items {
+		ids[i] = int32(item.NewsID)
+	}
+
+	rows, err := rnr.srvc.Embedding().GetEmbeddingByNewsIdsAndModel(ctx,
+		&service.GetEmbeddingByNewsIdsAndModelRequest{
+			Model:    mdl,
+			NewsIds:  ids,
+			PromptId: promptIdOf(cache.opt),
+		})
+	if err != nil {
+		return nil, fmt.Errorf("error while getting analyzed news: %w", err)
+	}
+
+	for _, row := range rows {
+		if row.ID > analyzed[row.NewsID] {
+			analyzed[row.NewsID] = row.ID
+		}
+	}
+	return analyzed, nil
+}
+
+// getAnnotated returns the news in analyzed which have been labelled by the
+// model for the owner in the same definition of the classification task of
+// the job, if it has one.
+func (rnr *Runner) getAnnotated(ctx context.Context, cache *responseCache, owner uuid.UUID, mdl string,
+	analyzed map[int64]int64) (map[int64]bool, error) {
+	annotated := map[int64]bool{}
+	if _, ok := cache.anlz.(client.Classifier); !ok || cache.opt.Task == "" || len(analyzed) == 0 {
+		return annotated, nil
+	}
+
+	ids := make([]int32, 0, len(analyzed))
+	for nId := range analyzed {
+		ids = append(ids, int32(nId))
+	}
+
+	nIds, err := rnr.srvc.Annotation().GetAnnotatedNewsIds(ctx, &service.GetAnnotatedNewsIdsRequest{
+		Model:    mdl,
+		NewsIds:  ids,
+		Owner:    owner,
+		TaskHash: cache.opt.ClassificationOptions.Hash(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error while getting annotated news: %w", err)
+	}
+
+	for _, nId := range nIds {
+		annotated[nId] = true
+	}
+	return annotated, nil
+}
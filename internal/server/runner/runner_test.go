@@ -0,0 +1,1838 @@
+package runner_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/global"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client/api"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client/option"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/model"
+	mock_model "github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/model/mockdb"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/server/runner"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/server/service"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/server/validator"
+	ec "github.com/ChiaYuChang/NewsSentimentAnalyzer/pkgs/errorCode"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/oklog/ulid/v2"
+	pgv "github.com/pgvector/pgvector-go"
+	"github.com/stretchr/testify/require"
+)
+
+const TEST_API_KEY = "[[::TEST_API_KEY::]]"
+
+// redirectTransport sends every request to the test server
+type redirectTransport struct {
+	URL *url.URL
+}
+
+func (rt redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req.URL.Scheme = rt.URL.Scheme
+	req.URL.Host = rt.URL.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func newCreatedJobsRow(id int64, owner uuid.UUID) service.CreatedJobsRow {
+	return service.CreatedJobsRow{
+		GetOldestNCreatedJobsForEachUserRow: &model.GetOldestNCreatedJobsForEachUserRow{
+			ID: id, Owner: owner, LlmApiID: 5,
+			LlmQuery: []byte(`{"api":"openai","embedding":true,"sentiment":true}`),
+		},
+	}
+}
+
+func TestRoundRobin(t *testing.T) {
+	u1, u2, u3 := uuid.New(), uuid.New(), uuid.New()
+
+	// sorted by id in descending order
+	rows := []service.CreatedJobsRow{
+		newCreatedJobsRow(9, u3),
+		newCreatedJobsRow(8, u1),
+		newCreatedJobsRow(7, u1),
+		newCreatedJobsRow(6, u1),
+		newCreatedJobsRow(5, u2),
+		newCreatedJobsRow(4, u3),
+		newCreatedJobsRow(2, u2),
+	}
+
+	jobs := runner.RoundRobin(rows)
+	require.Len(t, jobs, len(rows))
+
+	ids := make([]int64, len(jobs))
+	for i, job := range jobs {
+		ids[i] = job.ID
+	}
+	require.Equal(t, []int64{2, 4, 6, 5, 9, 7, 8}, ids)
+	require.Empty(t, runner.RoundRobin(nil))
+}
+
+func newOpenAIServer(t *testing.T) *httptest.Server {
+	embd, err := os.ReadFile("../../client/api/OpenAI/example_response/embeddings.json")
+	require.NoError(t, err)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/embeddings", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(embd)
+	})
+	mux.HandleFunc("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if b, _ := io.ReadAll(r.Body); bytes.Contains(b, []byte(`"name":"report_labels"`)) {
+			w.Write([]byte(`{"object": "chat.completion", "model": "gpt-4o-mini", "choices": [{"index": 0, ` +
+				`"message": {"role": "assistant", "content": "{\"results\": [{\"id\": 1, \"label\": \"economic\", ` +
+				`\"confidence\": 0.9, \"rationale\": \"It is about the market.\"}]}"}, "finish_reason": "stop"}]}`))
+			return
+		}
+		w.Write([]byte(`{
+  "id": "chatcmpl-8S69iWuRBxLDRBgMGHsjUrcvx3dnv",
+  "object": "chat.completion",
+  "created": 1701707538,
+  "model": "gpt-4o-mini",
+  "choices": [{"index": 0, "message": {"role": "assistant", "content": "{\"results\": [{\"id\": 1, \"score\": 2, \"confidence\": 0.8, \"rationale\": \"The outlook is bleak.\"}]}"}, "finish_reason": "stop"}],
+  "usage": {"prompt_tokens": 120, "completion_tokens": 30, "total_tokens": 150}
+}`))
+	})
+	return httptest.NewServer(mux)
+}
+
+func newTestClient(t *testing.T, srvr *httptest.Server) *http.Client {
+	srvrUrl, err := url.Parse(srvr.URL)
+	require.NoError(t, err)
+	return &http.Client{Transport: redirectTransport{srvrUrl}, Timeout: 3 * time.Second}
+}
+
+// expectItems expects the items of the job to be created and fetched once
+func expectItems(store *mock_model.MockStore, jId int64, items ...*model.GetDueJobItemsRow) []*gomock.Call {
+	return []*gomock.Call{
+		store.EXPECT().
+			CreateJobItems(gomock.Any(), jId).
+			Return(int64(len(items)), nil),
+		store.EXPECT().
+			GetDueJobItems(gomock.Any(), jId).
+			Return(items, nil),
+	}
+}
+
+// expectSettled expects no item of the job to be left
+func expectSettled(store *mock_model.MockStore, jId int64) []*gomock.Call {
+	return []*gomock.Call{
+		store.EXPECT().
+			GetDueJobItems(gomock.Any(), jId).
+			Return(nil, nil),
+		store.EXPECT().
+			GetNextJobItemAttempt(gomock.Any(), jId).
+			Return(pgtype.Timestamptz{}, pgx.ErrNoRows),
+	}
+}
+
+// itemDone matches the params marking the item done with any embedding
+type itemDone int64
+
+func (m itemDone) Matches(x any) bool {
+	params, ok := x.(*model.MarkJobItemDoneParams)
+	return ok && params.ID == int64(m) && params.EmbeddingID.Valid
+}
+
+func (m itemDone) String() string {
+	return fmt.Sprintf("marks item %d done", int64(m))
+}
+
+// allowUsage allows any usage of the job to be stored
+func allowUsage(store *mock_model.MockStore) {
+	store.EXPECT().
+		CreateJobUsage(gomock.Any(), gomock.Any()).
+		AnyTimes().
+		Return(int64(1), nil)
+}
+
+// embeddingDims are the dimensions of the embedding models in the registry
+var embeddingDims = map[string]int32{
+	"text-embedding-ada-002": 1536,
+	"char-ngram-hash-256":    256,
+}
+
+// allowEmbeddingChunks allows the registry to be looked up for the dimension
+// of the embedding models and the chunks of the news to be stored
+func allowEmbeddingChunks(store *mock_model.MockStore) {
+	store.EXPECT().
+		UpsertEmbeddingChunk(gomock.Any(), gomock.Any()).
+		AnyTimes().
+		Return(int64(1), nil)
+	store.EXPECT().
+		DeleteEmbeddingChunksFrom(gomock.Any(), gomock.Any()).
+		AnyTimes().
+		Return(int64(0), nil)
+	allowEmbeddingModels(store)
+}
+
+// allowEmbeddingModels allows the registry to be looked up for the dimension
+// of the embedding models
+func allowEmbeddingModels(store *mock_model.MockStore) {
+	store.EXPECT().
+		GetEmbeddingModel(gomock.Any(), gomock.Any()).
+		AnyTimes().
+		DoAndReturn(func(_ context.Context, name string) (*model.GetEmbeddingModelRow, error) {
+			dim, ok := embeddingDims[name]
+			if !ok {
+				return nil, pgx.ErrNoRows
+			}
+			return &model.GetEmbeddingModelRow{Name: name, Dim: dim, Metric: "cosine"}, nil
+		})
+}
+
+func TestRunOnce(t *testing.T) {
+	srvr := newOpenAIServer(t)
+	defer srvr.Close()
+
+	owner := uuid.New()
+	job := newCreatedJobsRow(1, owner)
+
+	ctl := gomock.NewController(t)
+	store := mock_model.NewMockStore(ctl)
+	allowEmbeddingChunks(store)
+	calls := []*gomock.Call{
+		store.EXPECT().
+			GetOldestNCreatedJobsForEachUser(gomock.Any(), int32(1)).
+			Return([]*model.GetOldestNCreatedJobsForEachUserRow{job.GetOldestNCreatedJobsForEachUserRow}, nil),
+		store.EXPECT().
+			UpdateJobStatusFrom(gomock.Any(), &model.UpdateJobStatusFromParams{
+				ToStatus: model.JobStatusRunning, ID: job.ID, Owner: owner,
+				FromStatus: []string{string(model.JobStatusCreated)},
+			}).
+			Return(int64(1), nil),
+		store.EXPECT().
+			GetAPIKey(gomock.Any(), &model.GetAPIKeyParams{Owner: owner, ApiID: 5}).
+			Return(&model.GetAPIKeyRow{ID: 1, Owner: owner, ApiID: 5, Key: TEST_API_KEY}, nil),
+	}
+	calls = append(calls, expectItems(store, job.ID,
+		&model.GetDueJobItemsRow{ID: 11, NewsID: 1, Title: "title 1", Content: []string{"content 1"}},
+		&model.GetDueJobItemsRow{ID: 12, NewsID: 2, Title: "title 2", Description: "description 2"},
+		&model.GetDueJobItemsRow{ID: 13, NewsID: 3, Title: "title 3", Description: "description 3"},
+	)...)
+	calls = append(calls,
+		store.EXPECT().
+			GetEmbeddingByNewsIdsAndModel(gomock.Any(), &model.GetEmbeddingByNewsIdsAndModelParams{
+				Model: "text-embedding-ada-002", NewsIds: []int32{1, 2, 3},
+			}).
+			Return([]*model.GetEmbeddingByNewsIdsAndModelRow{{ID: 1, NewsID: 3}}, nil),
+	)
+	for _, iId := range []int64{11, 12} {
+		calls = append(calls,
+			store.EXPECT().
+				CreateEmbedding(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, params *model.CreateEmbeddingParams) (int64, error) {
+					require.Equal(t, "text-embedding-ada-002", params.Model)
+					require.False(t, params.PromptID.Valid)
+					require.Equal(t, model.SentimentNegative, params.Sentiment)
+					require.Equal(t, int16(2), params.Score.Int16)
+					require.Equal(t, pgtype.Float4{Float32: 0.8, Valid: true}, params.Confidence)
+					require.Equal(t, pgtype.Text{String: "The outlook is bleak.", Valid: true}, params.Rationale)
+					require.Len(t, params.Embedding.Slice(), int(embeddingDims[params.Model]))
+					return params.NewsID, nil
+				}),
+			store.EXPECT().
+				MarkJobItemDone(gomock.Any(), &model.MarkJobItemDoneParams{
+					// the id of the embedding is the id of the news
+					ID: iId, EmbeddingID: pgtype.Int8{Int64: iId - 10, Valid: true},
+				}).
+				Return(int64(1), nil),
+			store.EXPECT().
+				IncrJobProgress(gomock.Any(), &model.IncrJobProgressParams{
+					JobID: job.ID, Embedded: 1, SentimentScored: 1,
+				}).
+				Return(nil),
+		)
+	}
+	calls = append(calls,
+		// news 3 has been analyzed, but it still counts in the progress
+		store.EXPECT().
+			MarkJobItemDone(gomock.Any(), &model.MarkJobItemDoneParams{
+				ID: 13, EmbeddingID: pgtype.Int8{Int64: 1, Valid: true},
+			}).
+			Return(int64(1), nil),
+		store.EXPECT().
+			IncrJobProgress(gomock.Any(), &model.IncrJobProgressParams{
+				JobID: job.ID, Embedded: 1, SentimentScored: 1,
+			}).
+			Return(nil),
+	)
+	// the sentiments of the batches come before the embeddings of the items,
+	// and only the chat model has a price
+	for _, u := range []model.CreateJobUsageParams{
+		{Model: "gpt-4o-mini", Operation: "sentiment", InputTokens: 120, OutputTokens: 30},
+		{Model: "gpt-4o-mini", Operation: "sentiment", InputTokens: 120, OutputTokens: 30},
+		{Model: "text-embedding-ada-002", Operation: "embedding", InputTokens: 489},
+		{Model: "text-embedding-ada-002", Operation: "embedding", InputTokens: 489},
+	} {
+		u := u
+		calls = append(calls,
+			store.EXPECT().
+				CreateJobUsage(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, params *model.CreateJobUsageParams) (int64, error) {
+					require.Equal(t, job.ID, params.JobID)
+					require.Equal(t, "openai", params.Provider)
+					require.Equal(t, u.Model, params.Model)
+					require.Equal(t, u.Operation, params.Operation)
+					require.Equal(t, u.InputTokens, params.InputTokens)
+					require.Equal(t, u.OutputTokens, params.OutputTokens)
+					if u.Operation == "sentiment" {
+						require.True(t, params.Cost.Valid)
+						require.InDelta(t, 3.6e-5, params.Cost.Float64, 1e-12)
+					} else {
+						require.False(t, params.Cost.Valid)
+					}
+					return 1, nil
+				}),
+		)
+	}
+	calls = append(calls, expectSettled(store, job.ID)...)
+	calls = append(calls,
+		store.EXPECT().
+			UpdateJobStatusFrom(gomock.Any(), &model.UpdateJobStatusFromParams{
+				ToStatus: model.JobStatusDone, ID: job.ID, Owner: owner,
+				FromStatus: []string{string(model.JobStatusRunning)},
+			}).
+			Return(int64(1), nil),
+	)
+	gomock.InOrder(calls...)
+
+	rnr := runner.NewRunner(service.NewService(store, validator.Validate), time.Second, 1, 3*time.Second).
+		WithHTTPClient(newTestClient(t, srvr)).
+		WithPrices(global.PriceOptions{{Model: "gpt-4o-mini", Input: 0.15, Output: 0.6}})
+	rnr.RunOnce(context.Background())
+}
+
+func TestRunOnceWithChunks(t *testing.T) {
+	srvr := newOpenAIServer(t)
+	defer srvr.Close()
+
+	// the article is over the 8191 tokens of the model, which is split into
+	// the title and the first paragraph, the second one and the last one
+	content := []string{strings.Repeat("跌", 6000), strings.Repeat("漲", 6000), strings.Repeat("平", 3000)}
+
+	type testCase struct {
+		Name    string
+		Pooling string
+		Weights []float64
+	}
+
+	tcs := []testCase{
+		{Name: "Mean", Weights: []float64{1, 1, 1}},
+		{Name: "Length", Pooling: option.PoolingLength, Weights: []float64{6002, 6000, 3000}},
+	}
+
+	for i := range tcs {
+		tc := tcs[i]
+		t.Run(tc.Name, func(t *testing.T) {
+			owner := uuid.New()
+			job := newCreatedJobsRow(1, owner)
+			opt := option.AnalyzerOption{APIName: "openai"}
+			opt.Pooling = tc.Pooling
+			job.LlmQuery, _ = json.Marshal(opt)
+
+			ctl := gomock.NewController(t)
+			store := mock_model.NewMockStore(ctl)
+			allowEmbeddingModels(store)
+			allowUsage(store)
+
+			chunks := [][]float32{}
+			calls := []*gomock.Call{
+				store.EXPECT().
+					GetOldestNCreatedJobsForEachUser(gomock.Any(), int32(1)).
+					Return([]*model.GetOldestNCreatedJobsForEachUserRow{job.GetOldestNCreatedJobsForEachUserRow}, nil),
+				store.EXPECT().
+					UpdateJobStatusFrom(gomock.Any(), gomock.Any()).
+					Return(int64(1), nil),
+				store.EXPECT().
+					GetAPIKey(gomock.Any(), gomock.Any()).
+					Return(&model.GetAPIKeyRow{ID: 1, Owner: owner, ApiID: 5, Key: TEST_API_KEY}, nil),
+			}
+			calls = append(calls, expectItems(store, job.ID,
+				&model.GetDueJobItemsRow{ID: 11, NewsID: 1, Title: "長文", Content: content},
+			)...)
+			calls = append(calls,
+				store.EXPECT().
+					GetEmbeddingByNewsIdsAndModel(gomock.Any(), gomock.Any()).
+					Return(nil, nil),
+				store.EXPECT().
+					UpsertEmbeddingChunk(gomock.Any(), gomock.Any()).
+					Times(3).
+					DoAndReturn(func(_ context.Context, params *model.UpsertEmbeddingChunkParams) (int64, error) {
+						require.Equal(t, int64(1), params.NewsID)
+						require.Equal(t, int16(len(chunks)), params.ChunkIndex)
+						require.Equal(t, []int32{6002, 6000, 3000}[params.ChunkIndex], params.NTokens)
+						require.Len(t, params.Embedding.Slice(), 1536)
+						chunks = append(chunks, params.Embedding.Slice())
+						return 1, nil
+					}),
+				// the chunks left from an earlier split are removed
+				store.EXPECT().
+					DeleteEmbeddingChunksFrom(gomock.Any(), &model.DeleteEmbeddingChunksFromParams{
+						NewsID: 1, Model: "text-embedding-ada-002", ChunkIndex: 3,
+					}).
+					Return(int64(0), nil),
+				store.EXPECT().
+					CreateEmbedding(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, params *model.CreateEmbeddingParams) (int64, error) {
+						embd := params.Embedding.Slice()
+						require.Len(t, embd, 1536)
+						for _, j := range []int{0, 100, 1535} {
+							sum, total := 0.0, 0.0
+							for k, c := range chunks {
+								sum += tc.Weights[k] * float64(c[j])
+								total += tc.Weights[k]
+							}
+							require.InDelta(t, sum/total, embd[j], 1e-6)
+						}
+						return 1, nil
+					}),
+				store.EXPECT().
+					MarkJobItemDone(gomock.Any(), itemDone(11)).
+					Return(int64(1), nil),
+				store.EXPECT().
+					IncrJobProgress(gomock.Any(), gomock.Any()).
+					Return(nil),
+			)
+			calls = append(calls, expectSettled(store, job.ID)...)
+			calls = append(calls,
+				store.EXPECT().
+					UpdateJobStatusFrom(gomock.Any(), gomock.Any()).
+					Return(int64(1), nil),
+			)
+			gomock.InOrder(calls...)
+
+			rnr := runner.NewRunner(service.NewService(store, validator.Validate), time.Second, 1, 3*time.Second).
+				WithHTTPClient(newTestClient(t, srvr))
+			rnr.RunOnce(context.Background())
+			require.Len(t, chunks, 3)
+		})
+	}
+}
+
+func TestRunOnceWithBatch(t *testing.T) {
+	srvr := newOpenAIServer(t)
+	defer srvr.Close()
+
+	// statements are scored by the number in their title, and the model
+	// loses count on 3 statements
+	batches := [][]string{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		body := struct {
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		titles := []string{}
+		scores := []int{}
+		for _, stmt := range strings.Split(body.Messages[len(body.Messages)-1].Content, "[^]")[1:] {
+			title := strings.SplitN(stmt, "\n", 2)[0]
+			n, err := strconv.Atoi(strings.TrimPrefix(title, "title "))
+			require.NoError(t, err)
+			titles = append(titles, title)
+			scores = append(scores, n+1)
+		}
+		batches = append(batches, titles)
+		if len(scores) == 3 {
+			scores = scores[:2]
+		}
+
+		content, _ := json.Marshal(scores)
+		resp, _ := json.Marshal(map[string]any{
+			"id": "chatcmpl-8S69iWuRBxLDRBgMGHsjUrcvx3dnv", "object": "chat.completion",
+			"created": 1701707538, "model": "gpt-3.5-turbo-0613",
+			"choices": []map[string]any{{
+				"index": 0, "finish_reason": "stop",
+				"message": map[string]string{"role": "assistant", "content": string(content)},
+			}},
+		})
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(resp)
+	})
+	mux.Handle("/", srvr.Config.Handler)
+	counting := httptest.NewServer(mux)
+	defer counting.Close()
+
+	owner := uuid.New()
+	job := newCreatedJobsRow(1, owner)
+
+	ctl := gomock.NewController(t)
+	store := mock_model.NewMockStore(ctl)
+	allowEmbeddingChunks(store)
+	allowUsage(store)
+	calls := []*gomock.Call{
+		store.EXPECT().
+			GetOldestNCreatedJobsForEachUser(gomock.Any(), int32(1)).
+			Return([]*model.GetOldestNCreatedJobsForEachUserRow{job.GetOldestNCreatedJobsForEachUserRow}, nil),
+		store.EXPECT().
+			UpdateJobStatusFrom(gomock.Any(), gomock.Any()).
+			Return(int64(1), nil),
+		store.EXPECT().
+			GetAPIKey(gomock.Any(), gomock.Any()).
+			Return(&model.GetAPIKeyRow{ID: 1, Owner: owner, ApiID: 5, Key: TEST_API_KEY}, nil),
+	}
+	calls = append(calls, expectItems(store, job.ID,
+		&model.GetDueJobItemsRow{ID: 11, NewsID: 1, Title: "title 1", Description: "description 1"},
+		&model.GetDueJobItemsRow{ID: 12, NewsID: 2, Title: "title 2", Description: "description 2"},
+		&model.GetDueJobItemsRow{ID: 13, NewsID: 3, Title: "title 3", Description: "description 3"},
+	)...)
+	calls = append(calls,
+		store.EXPECT().
+			GetEmbeddingByNewsIdsAndModel(gomock.Any(), gomock.Any()).
+			Return(nil, nil),
+	)
+	for _, iId := range []int64{11, 12, 13} {
+		calls = append(calls,
+			store.EXPECT().
+				CreateEmbedding(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, params *model.CreateEmbeddingParams) (int64, error) {
+					// the scores are mapped back to the news
+					require.Equal(t, int16(params.NewsID+1), params.Score.Int16)
+					return params.NewsID, nil
+				}),
+			store.EXPECT().
+				MarkJobItemDone(gomock.Any(), itemDone(iId)).
+				Return(int64(1), nil),
+			store.EXPECT().
+				IncrJobProgress(gomock.Any(), gomock.Any()).
+				Return(nil),
+		)
+	}
+	calls = append(calls, expectSettled(store, job.ID)...)
+	calls = append(calls,
+		store.EXPECT().
+			UpdateJobStatusFrom(gomock.Any(), &model.UpdateJobStatusFromParams{
+				ToStatus: model.JobStatusDone, ID: job.ID, Owner: owner,
+				FromStatus: []string{string(model.JobStatusRunning)},
+			}).
+			Return(int64(1), nil),
+	)
+	gomock.InOrder(calls...)
+
+	rnr := runner.NewRunner(service.NewService(store, validator.Validate), time.Second, 1, 3*time.Second).
+		WithHTTPClient(newTestClient(t, counting)).
+		WithBatch(1000, 10)
+	rnr.RunOnce(context.Background())
+	require.Equal(t, [][]string{
+		{"title 1", "title 2", "title 3"},
+		{"title 1"},
+		{"title 2", "title 3"},
+	}, batches)
+}
+
+func TestRetryTransientError(t *testing.T) {
+	srvr := newOpenAIServer(t)
+	defer srvr.Close()
+
+	// the first embeddings request is rate limited
+	nEmbd := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/embeddings", func(w http.ResponseWriter, r *http.Request) {
+		if nEmbd++; nEmbd == 1 {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"error": {"message": "Rate limit reached", "type": "requests", "param": null, "code": "rate_limit_exceeded"}}`))
+			return
+		}
+		srvr.Config.Handler.ServeHTTP(w, r)
+	})
+	mux.Handle("/", srvr.Config.Handler)
+	limited := httptest.NewServer(mux)
+	defer limited.Close()
+
+	owner := uuid.New()
+	job := newCreatedJobsRow(1, owner)
+	other := newCreatedJobsRow(2, uuid.New())
+	item := &model.GetDueJobItemsRow{ID: 11, NewsID: 1, Title: "title 1", Description: "description 1"}
+
+	ctl := gomock.NewController(t)
+	store := mock_model.NewMockStore(ctl)
+	allowEmbeddingChunks(store)
+	allowUsage(store)
+	calls := []*gomock.Call{
+		store.EXPECT().
+			GetOldestNCreatedJobsForEachUser(gomock.Any(), int32(1)).
+			Return([]*model.GetOldestNCreatedJobsForEachUserRow{
+				other.GetOldestNCreatedJobsForEachUserRow,
+				job.GetOldestNCreatedJobsForEachUserRow,
+			}, nil),
+		store.EXPECT().
+			UpdateJobStatusFrom(gomock.Any(), gomock.Any()).
+			Return(int64(1), nil),
+		store.EXPECT().
+			GetAPIKey(gomock.Any(), gomock.Any()).
+			Return(&model.GetAPIKeyRow{ID: 1, Owner: owner, ApiID: 5, Key: TEST_API_KEY}, nil),
+	}
+	calls = append(calls, expectItems(store, job.ID, item)...)
+	calls = append(calls,
+		store.EXPECT().
+			GetEmbeddingByNewsIdsAndModel(gomock.Any(), gomock.Any()).
+			Return(nil, nil),
+		store.EXPECT().
+			MarkJobItemFailed(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, params *model.MarkJobItemFailedParams) (int64, error) {
+				require.Equal(t, item.ID, params.ID)
+				require.Equal(t, model.JobItemStageRetrying, params.Stage)
+				require.True(t, params.LastError.Valid)
+				require.WithinDuration(t, time.Now().Add(10*time.Millisecond), params.NextAttemptAt.Time, 50*time.Millisecond)
+				return 1, nil
+			}),
+		store.EXPECT().
+			GetDueJobItems(gomock.Any(), job.ID).
+			Return(nil, nil),
+		store.EXPECT().
+			GetNextJobItemAttempt(gomock.Any(), job.ID).
+			Return(pgtype.Timestamptz{Time: time.Now().Add(10 * time.Millisecond), Valid: true}, nil),
+		// the job is put back instead of waiting for the item
+		store.EXPECT().
+			UpdateJobStatusFrom(gomock.Any(), &model.UpdateJobStatusFromParams{
+				ToStatus: model.JobStatusCreated, ID: job.ID, Owner: owner,
+				FromStatus: []string{string(model.JobStatusRunning)},
+			}).
+			Return(int64(1), nil),
+		// and the job of the other owner is run meanwhile
+		store.EXPECT().
+			UpdateJobStatusFrom(gomock.Any(), gomock.Any()).
+			Return(int64(1), nil),
+		store.EXPECT().
+			GetAPIKey(gomock.Any(), gomock.Any()).
+			Return(&model.GetAPIKeyRow{ID: 2, Owner: other.Owner, ApiID: 5, Key: TEST_API_KEY}, nil),
+	)
+	calls = append(calls, expectItems(store, other.ID)...)
+	calls = append(calls,
+		store.EXPECT().
+			GetNextJobItemAttempt(gomock.Any(), other.ID).
+			Return(pgtype.Timestamptz{}, pgx.ErrNoRows),
+		store.EXPECT().
+			UpdateJobStatusFrom(gomock.Any(), gomock.Any()).
+			Return(int64(1), nil),
+		// the job is fetched again once the item is due
+		store.EXPECT().
+			GetOldestNCreatedJobsForEachUser(gomock.Any(), int32(1)).
+			Return([]*model.GetOldestNCreatedJobsForEachUserRow{job.GetOldestNCreatedJobsForEachUserRow}, nil),
+		store.EXPECT().
+			UpdateJobStatusFrom(gomock.Any(), gomock.Any()).
+			Return(int64(1), nil),
+		store.EXPECT().
+			GetAPIKey(gomock.Any(), gomock.Any()).
+			Return(&model.GetAPIKeyRow{ID: 1, Owner: owner, ApiID: 5, Key: TEST_API_KEY}, nil),
+	)
+	calls = append(calls, expectItems(store, job.ID, &model.GetDueJobItemsRow{
+		ID: item.ID, NewsID: item.NewsID, Attempts: 1,
+		Title: item.Title, Description: item.Description,
+	})...)
+	calls = append(calls,
+		store.EXPECT().
+			GetEmbeddingByNewsIdsAndModel(gomock.Any(), gomock.Any()).
+			Return(nil, nil),
+		store.EXPECT().
+			CreateEmbedding(gomock.Any(), gomock.Any()).
+			Return(int64(1), nil),
+		store.EXPECT().
+			MarkJobItemDone(gomock.Any(), itemDone(item.ID)).
+			Return(int64(1), nil),
+		store.EXPECT().
+			IncrJobProgress(gomock.Any(), gomock.Any()).
+			Return(nil),
+	)
+	calls = append(calls, expectSettled(store, job.ID)...)
+	calls = append(calls,
+		store.EXPECT().
+			UpdateJobStatusFrom(gomock.Any(), &model.UpdateJobStatusFromParams{
+				ToStatus: model.JobStatusDone, ID: job.ID, Owner: owner,
+				FromStatus: []string{string(model.JobStatusRunning)},
+			}).
+			Return(int64(1), nil),
+	)
+	gomock.InOrder(calls...)
+
+	rnr := runner.NewRunner(service.NewService(store, validator.Validate), time.Second, 1, 3*time.Second).
+		WithHTTPClient(newTestClient(t, limited)).
+		WithRetry(3, 10*time.Millisecond, time.Second)
+	rnr.RunOnce(context.Background())
+	require.Equal(t, 1, nEmbd)
+	rnr.RunOnce(context.Background())
+	require.Equal(t, 2, nEmbd)
+}
+
+func TestDeadLetter(t *testing.T) {
+	srvr := newOpenAIServer(t)
+	defer srvr.Close()
+
+	// an unknown score will not be fixed by retrying
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{
+  "id": "chatcmpl-8S69iWuRBxLDRBgMGHsjUrcvx3dnv",
+  "object": "chat.completion",
+  "created": 1701707538,
+  "model": "gpt-3.5-turbo-0613",
+  "choices": [{"index": 0, "message": {"role": "assistant", "content": "[7]"}, "finish_reason": "stop"}]
+}`))
+	})
+	mux.Handle("/", srvr.Config.Handler)
+	broken := httptest.NewServer(mux)
+	defer broken.Close()
+
+	owner := uuid.New()
+	job := newCreatedJobsRow(1, owner)
+
+	ctl := gomock.NewController(t)
+	store := mock_model.NewMockStore(ctl)
+	allowUsage(store)
+	calls := []*gomock.Call{
+		store.EXPECT().
+			GetOldestNCreatedJobsForEachUser(gomock.Any(), int32(1)).
+			Return([]*model.GetOldestNCreatedJobsForEachUserRow{job.GetOldestNCreatedJobsForEachUserRow}, nil),
+		store.EXPECT().
+			UpdateJobStatusFrom(gomock.Any(), gomock.Any()).
+			Return(int64(1), nil),
+		store.EXPECT().
+			GetAPIKey(gomock.Any(), gomock.Any()).
+			Return(&model.GetAPIKeyRow{ID: 1, Owner: owner, ApiID: 5, Key: TEST_API_KEY}, nil),
+	}
+	calls = append(calls, expectItems(store, job.ID,
+		&model.GetDueJobItemsRow{ID: 11, NewsID: 1, Title: "title 1", Description: "description 1"})...)
+	calls = append(calls,
+		store.EXPECT().
+			GetEmbeddingByNewsIdsAndModel(gomock.Any(), gomock.Any()).
+			Return(nil, nil),
+		store.EXPECT().
+			MarkJobItemFailed(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, params *model.MarkJobItemFailedParams) (int64, error) {
+				require.Equal(t, int64(11), params.ID)
+				require.Equal(t, model.JobItemStageDead, params.Stage)
+				require.Contains(t, params.LastError.String, "unknown sentiment score")
+				return 1, nil
+			}),
+		store.EXPECT().
+			IncrJobProgress(gomock.Any(), &model.IncrJobProgressParams{JobID: job.ID, Failed: 1}).
+			Return(nil),
+	)
+	calls = append(calls, expectSettled(store, job.ID)...)
+	calls = append(calls,
+		store.EXPECT().
+			UpdateJobStatusFrom(gomock.Any(), &model.UpdateJobStatusFromParams{
+				ToStatus: model.JobStatusFailed, ID: job.ID, Owner: owner,
+				FromStatus: []string{string(model.JobStatusRunning)},
+			}).
+			Return(int64(1), nil),
+	)
+	gomock.InOrder(calls...)
+
+	rnr := runner.NewRunner(service.NewService(store, validator.Validate), time.Second, 1, 3*time.Second).
+		WithHTTPClient(newTestClient(t, broken))
+	rnr.RunOnce(context.Background())
+}
+
+func TestIsTransient(t *testing.T) {
+	for _, tc := range []struct {
+		Err       error
+		Transient bool
+	}{
+		{nil, false},
+		{ec.MustGetEcErr(ec.ECTooManyRequests), true},
+		{fmt.Errorf("error while embedding: %w", ec.MustGetEcErr(ec.ECServerError)), true},
+		{ec.MustGetEcErr(ec.ECServiceUnavailable), true},
+		{ec.MustGetEcErr(ec.ECgRPCClientError), true},
+		{ec.MustGetEcErr(ec.ECUnauthorized), false},
+		{ec.MustGetEcErr(ec.ECBadRequest), false},
+		{&url.Error{Op: "Post", URL: "https://api.openai.com", Err: errors.New("connection reset")}, true},
+		{errors.New("unknown sentiment score: 7"), false},
+	} {
+		require.Equal(t, tc.Transient, runner.IsTransient(tc.Err), tc.Err)
+	}
+}
+
+func TestCancelRunningJob(t *testing.T) {
+	srvr := newOpenAIServer(t)
+	defer srvr.Close()
+
+	owner := uuid.New()
+	job := newCreatedJobsRow(1, owner)
+
+	ctl := gomock.NewController(t)
+	store := mock_model.NewMockStore(ctl)
+	allowEmbeddingChunks(store)
+	allowUsage(store)
+	rnr := runner.NewRunner(service.NewService(store, validator.Validate), time.Second, 1, 3*time.Second).
+		WithHTTPClient(newTestClient(t, srvr))
+
+	calls := []*gomock.Call{
+		store.EXPECT().
+			GetOldestNCreatedJobsForEachUser(gomock.Any(), int32(1)).
+			Return([]*model.GetOldestNCreatedJobsForEachUserRow{job.GetOldestNCreatedJobsForEachUserRow}, nil),
+		store.EXPECT().
+			UpdateJobStatusFrom(gomock.Any(), gomock.Any()).
+			Return(int64(1), nil),
+		store.EXPECT().
+			GetAPIKey(gomock.Any(), gomock.Any()).
+			Return(&model.GetAPIKeyRow{ID: 1, Owner: owner, ApiID: 5, Key: TEST_API_KEY}, nil),
+	}
+	calls = append(calls, expectItems(store, job.ID,
+		&model.GetDueJobItemsRow{ID: 11, NewsID: 1, Title: "title 1", Description: "description 1"},
+		&model.GetDueJobItemsRow{ID: 12, NewsID: 2, Title: "title 2", Description: "description 2"},
+	)...)
+	calls = append(calls,
+		store.EXPECT().
+			GetEmbeddingByNewsIdsAndModel(gomock.Any(), gomock.Any()).
+			Return(nil, nil),
+		store.EXPECT().
+			CreateEmbedding(gomock.Any(), gomock.Any()).
+			Times(1).
+			DoAndReturn(func(_ context.Context, params *model.CreateEmbeddingParams) (int64, error) {
+				// the job is canceled while the first news is being stored
+				require.True(t, rnr.Cancel(job.ID))
+				return params.NewsID, nil
+			}),
+		// the result of the stored news is recorded even though ctx has been canceled
+		store.EXPECT().
+			MarkJobItemDone(gomock.Any(), itemDone(11)).
+			DoAndReturn(func(ctx context.Context, _ *model.MarkJobItemDoneParams) (int64, error) {
+				require.NoError(t, ctx.Err())
+				return 1, nil
+			}),
+		store.EXPECT().
+			IncrJobProgress(gomock.Any(), &model.IncrJobProgressParams{
+				JobID: job.ID, Embedded: 1, SentimentScored: 1,
+			}).
+			DoAndReturn(func(ctx context.Context, _ *model.IncrJobProgressParams) error {
+				require.NoError(t, ctx.Err())
+				return nil
+			}),
+		store.EXPECT().
+			MarkJobPartial(gomock.Any(), &model.MarkJobPartialParams{ID: job.ID, Owner: owner}).
+			Return(int64(1), nil),
+	)
+	gomock.InOrder(calls...)
+
+	rnr.RunOnce(context.Background())
+	require.False(t, rnr.Cancel(job.ID))
+}
+
+func TestRunOnceWithUnknownAnalyzer(t *testing.T) {
+	owner := uuid.New()
+	job := newCreatedJobsRow(1, owner)
+	job.LlmQuery, _ = json.Marshal(option.AnalyzerOption{APIName: "unknown"})
+
+	ctl := gomock.NewController(t)
+	store := mock_model.NewMockStore(ctl)
+	gomock.InOrder(
+		store.EXPECT().
+			GetOldestNCreatedJobsForEachUser(gomock.Any(), int32(1)).
+			Return([]*model.GetOldestNCreatedJobsForEachUserRow{job.GetOldestNCreatedJobsForEachUserRow}, nil),
+		store.EXPECT().
+			UpdateJobStatusFrom(gomock.Any(), &model.UpdateJobStatusFromParams{
+				ToStatus: model.JobStatusRunning, ID: job.ID, Owner: owner,
+				FromStatus: []string{string(model.JobStatusCreated)},
+			}).
+			Return(int64(1), nil),
+		store.EXPECT().
+			UpdateJobStatusFrom(gomock.Any(), &model.UpdateJobStatusFromParams{
+				ToStatus: model.JobStatusFailed, ID: job.ID, Owner: owner,
+				FromStatus: []string{string(model.JobStatusRunning)},
+			}).
+			Return(int64(1), nil),
+	)
+
+	rnr := runner.NewRunner(service.NewService(store, validator.Validate), time.Second, 1, 3*time.Second)
+	rnr.RunOnce(context.Background())
+}
+
+func TestRunOnceWithOfflineAnalyzer(t *testing.T) {
+	owner := uuid.New()
+	job := newCreatedJobsRow(1, owner)
+	job.LlmApiID = 7
+	job.LlmQuery, _ = json.Marshal(option.AnalyzerOption{APIName: "lexicon"})
+
+	ctl := gomock.NewController(t)
+	store := mock_model.NewMockStore(ctl)
+	allowEmbeddingChunks(store)
+	calls := []*gomock.Call{
+		store.EXPECT().
+			GetOldestNCreatedJobsForEachUser(gomock.Any(), int32(1)).
+			Return([]*model.GetOldestNCreatedJobsForEachUserRow{job.GetOldestNCreatedJobsForEachUserRow}, nil),
+		store.EXPECT().
+			UpdateJobStatusFrom(gomock.Any(), gomock.Any()).
+			Return(int64(1), nil),
+	}
+	// no api key is needed
+	calls = append(calls, expectItems(store, job.ID,
+		&model.GetDueJobItemsRow{ID: 11, NewsID: 1, Title: "台股崩盤", Description: "投資人恐慌"},
+	)...)
+	calls = append(calls,
+		store.EXPECT().
+			GetEmbeddingByNewsIdsAndModel(gomock.Any(), gomock.Any()).
+			Return(nil, nil),
+		store.EXPECT().
+			CreateEmbedding(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, params *model.CreateEmbeddingParams) (int64, error) {
+				require.Equal(t, "char-ngram-hash-256", params.Model)
+				require.Equal(t, model.SentimentVeryNegative, params.Sentiment)
+				require.Equal(t, int16(1), params.Score.Int16)
+				require.Len(t, params.Embedding.Slice(), int(embeddingDims[params.Model]))
+				return 1, nil
+			}),
+		store.EXPECT().
+			MarkJobItemDone(gomock.Any(), itemDone(11)).
+			Return(int64(1), nil),
+		store.EXPECT().
+			IncrJobProgress(gomock.Any(), gomock.Any()).
+			Return(nil),
+	)
+	calls = append(calls, expectSettled(store, job.ID)...)
+	calls = append(calls,
+		store.EXPECT().
+			UpdateJobStatusFrom(gomock.Any(), &model.UpdateJobStatusFromParams{
+				ToStatus: model.JobStatusDone, ID: job.ID, Owner: owner,
+				FromStatus: []string{string(model.JobStatusRunning)},
+			}).
+			Return(int64(1), nil),
+	)
+	gomock.InOrder(calls...)
+
+	rnr := runner.NewRunner(service.NewService(store, validator.Validate), time.Second, 1, 3*time.Second)
+	rnr.RunOnce(context.Background())
+}
+
+func TestRunOnceWithEntities(t *testing.T) {
+	owner := uuid.New()
+	job := newCreatedJobsRow(1, owner)
+	job.LlmApiID = 7
+	opt := option.AnalyzerOption{APIName: "lexicon"}
+	opt.Entities = []string{"台股", "央行"}
+	job.LlmQuery, _ = json.Marshal(opt)
+
+	ctl := gomock.NewController(t)
+	store := mock_model.NewMockStore(ctl)
+	allowEmbeddingChunks(store)
+	calls := []*gomock.Call{
+		store.EXPECT().
+			GetOldestNCreatedJobsForEachUser(gomock.Any(), int32(1)).
+			Return([]*model.GetOldestNCreatedJobsForEachUserRow{job.GetOldestNCreatedJobsForEachUserRow}, nil),
+		store.EXPECT().
+			UpdateJobStatusFrom(gomock.Any(), gomock.Any()).
+			Return(int64(1), nil),
+	}
+	calls = append(calls, expectItems(store, job.ID,
+		&model.GetDueJobItemsRow{ID: 11, NewsID: 1, Title: "台股崩盤", Description: "投資人恐慌"},
+		&model.GetDueJobItemsRow{ID: 12, NewsID: 2, Title: "台股大漲", Description: "投資人樂觀"},
+	)...)
+	calls = append(calls,
+		// news 2 has been embedded by another job without entities
+		store.EXPECT().
+			GetEmbeddingByNewsIdsAndModel(gomock.Any(), gomock.Any()).
+			Return([]*model.GetEmbeddingByNewsIdsAndModelRow{{ID: 5, NewsID: 2}}, nil),
+		// 央行 is not mentioned
+		store.EXPECT().
+			UpsertEntitySentiment(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, params *model.UpsertEntitySentimentParams) (int64, error) {
+				require.Equal(t, int64(1), params.NewsID)
+				require.Equal(t, "台股", params.Entity)
+				require.Equal(t, "char-ngram-hash-256", params.Model)
+				require.Equal(t, model.SentimentVeryNegative, params.Sentiment)
+				require.Equal(t, int16(1), params.Score)
+				require.True(t, params.Rationale.Valid)
+				require.Equal(t, owner, params.Owner)
+				require.False(t, params.PromptID.Valid)
+				return 1, nil
+			}),
+		store.EXPECT().
+			CreateEmbedding(gomock.Any(), gomock.Any()).
+			Return(int64(1), nil),
+		store.EXPECT().
+			MarkJobItemDone(gomock.Any(), itemDone(11)).
+			Return(int64(1), nil),
+		store.EXPECT().
+			IncrJobProgress(gomock.Any(), gomock.Any()).
+			Return(nil),
+		// the embedding is reused, but the entities are still analyzed
+		store.EXPECT().
+			UpsertEntitySentiment(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, params *model.UpsertEntitySentimentParams) (int64, error) {
+				require.Equal(t, int64(2), params.NewsID)
+				require.Equal(t, "台股", params.Entity)
+				require.Equal(t, owner, params.Owner)
+				return 2, nil
+			}),
+		store.EXPECT().
+			MarkJobItemDone(gomock.Any(), &model.MarkJobItemDoneParams{
+				ID: 12, EmbeddingID: pgtype.Int8{Int64: 5, Valid: true},
+			}).
+			Return(int64(1), nil),
+		store.EXPECT().
+			IncrJobProgress(gomock.Any(), gomock.Any()).
+			Return(nil),
+	)
+	calls = append(calls, expectSettled(store, job.ID)...)
+	calls = append(calls,
+		store.EXPECT().
+			UpdateJobStatusFrom(gomock.Any(), gomock.Any()).
+			Return(int64(1), nil),
+	)
+	gomock.InOrder(calls...)
+
+	rnr := runner.NewRunner(service.NewService(store, validator.Validate), time.Second, 1, 3*time.Second)
+	rnr.RunOnce(context.Background())
+}
+
+func TestRunOnceWithClassification(t *testing.T) {
+	srvr := newOpenAIServer(t)
+	defer srvr.Close()
+
+	owner := uuid.New()
+	job := newCreatedJobsRow(1, owner)
+	opt := option.AnalyzerOption{APIName: "openai"}
+	require.NoError(t, opt.ParseClassification("frame", "", "economic\nsecurity\nhumanitarian", ""))
+	job.LlmQuery, _ = json.Marshal(opt)
+
+	ctl := gomock.NewController(t)
+	store := mock_model.NewMockStore(ctl)
+	allowEmbeddingChunks(store)
+	allowUsage(store)
+	calls := []*gomock.Call{
+		store.EXPECT().
+			GetOldestNCreatedJobsForEachUser(gomock.Any(), int32(1)).
+			Return([]*model.GetOldestNCreatedJobsForEachUserRow{job.GetOldestNCreatedJobsForEachUserRow}, nil),
+		store.EXPECT().
+			UpdateJobStatusFrom(gomock.Any(), gomock.Any()).
+			Return(int64(1), nil),
+		store.EXPECT().
+			GetAPIKey(gomock.Any(), gomock.Any()).
+			Return(&model.GetAPIKeyRow{ID: 1, Owner: owner, ApiID: 5, Key: TEST_API_KEY}, nil),
+	}
+	calls = append(calls, expectItems(store, job.ID,
+		&model.GetDueJobItemsRow{ID: 11, NewsID: 1, Title: "台股崩盤", Description: "投資人恐慌"},
+		&model.GetDueJobItemsRow{ID: 12, NewsID: 2, Title: "台股大漲", Description: "投資人樂觀"},
+		&model.GetDueJobItemsRow{ID: 13, NewsID: 3, Title: "央行升息", Description: "房市降溫"},
+	)...)
+	calls = append(calls,
+		// news 2 and 3 have been embedded by other jobs, but only news 3 has
+		// been labelled in the task
+		store.EXPECT().
+			GetEmbeddingByNewsIdsAndModel(gomock.Any(), gomock.Any()).
+			Return([]*model.GetEmbeddingByNewsIdsAndModelRow{{ID: 5, NewsID: 2}, {ID: 6, NewsID: 3}}, nil),
+		store.EXPECT().
+			GetAnnotatedNewsIds(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, params *model.GetAnnotatedNewsIdsParams) ([]int64, error) {
+				require.Equal(t, "text-embedding-ada-002", params.Model)
+				require.ElementsMatch(t, []int32{2, 3}, params.NewsIds)
+				require.Equal(t, owner, params.Owner)
+				require.Equal(t, opt.ClassificationOptions.Hash(), params.TaskHash)
+				return []int64{3}, nil
+			}),
+		store.EXPECT().
+			UpsertAnnotation(gomock.Any(), &model.UpsertAnnotationParams{
+				NewsID:     1,
+				Task:       "frame",
+				Label:      "economic",
+				Model:      "text-embedding-ada-002",
+				Confidence: pgtype.Float4{Float32: 0.9, Valid: true},
+				Rationale:  pgtype.Text{String: "It is about the market.", Valid: true},
+				Owner:      owner,
+				TaskHash:   pgtype.Text{String: opt.ClassificationOptions.Hash(), Valid: true},
+			}).
+			Return(int64(1), nil),
+		store.EXPECT().
+			CreateEmbedding(gomock.Any(), gomock.Any()).
+			Return(int64(1), nil),
+		store.EXPECT().
+			MarkJobItemDone(gomock.Any(), itemDone(11)).
+			Return(int64(1), nil),
+		store.EXPECT().
+			IncrJobProgress(gomock.Any(), gomock.Any()).
+			Return(nil),
+		store.EXPECT().
+			UpsertAnnotation(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, params *model.UpsertAnnotationParams) (int64, error) {
+				require.Equal(t, int64(2), params.NewsID)
+				require.Equal(t, "frame", params.Task)
+				require.Equal(t, owner, params.Owner)
+				return 2, nil
+			}),
+		store.EXPECT().
+			MarkJobItemDone(gomock.Any(), &model.MarkJobItemDoneParams{
+				ID: 12, EmbeddingID: pgtype.Int8{Int64: 5, Valid: true},
+			}).
+			Return(int64(1), nil),
+		store.EXPECT().
+			IncrJobProgress(gomock.Any(), gomock.Any()).
+			Return(nil),
+		store.EXPECT().
+			MarkJobItemDone(gomock.Any(), &model.MarkJobItemDoneParams{
+				ID: 13, EmbeddingID: pgtype.Int8{Int64: 6, Valid: true},
+			}).
+			Return(int64(1), nil),
+		store.EXPECT().
+			IncrJobProgress(gomock.Any(), gomock.Any()).
+			Return(nil),
+	)
+	calls = append(calls, expectSettled(store, job.ID)...)
+	calls = append(calls,
+		store.EXPECT().
+			UpdateJobStatusFrom(gomock.Any(), gomock.Any()).
+			Return(int64(1), nil),
+	)
+	gomock.InOrder(calls...)
+
+	rnr := runner.NewRunner(service.NewService(store, validator.Validate), time.Second, 1, 3*time.Second).
+		WithHTTPClient(newTestClient(t, srvr))
+	rnr.RunOnce(context.Background())
+}
+
+func TestRunOnceWithPrompt(t *testing.T) {
+	// record the requests to the fake OpenAI server
+	openaiSrvr := newOpenAIServer(t)
+	defer openaiSrvr.Close()
+	bodies := []string{}
+	srvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		r.Body = io.NopCloser(bytes.NewReader(b))
+		openaiSrvr.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer srvr.Close()
+
+	owner := uuid.New()
+	prompt := &model.Prompt{
+		ID: 3, Owner: owner, Provider: "openai", Task: service.PromptTaskSentiment, Version: 2,
+		Body: "[[::CUSTOM PROMPT::]] of {{.APIName}}",
+	}
+
+	type testCase struct {
+		Name    string
+		Version int32
+		OK      bool
+	}
+
+	tcs := []testCase{
+		{Name: "OK", Version: 2, OK: true},
+		{Name: "Version mismatch", Version: 1},
+	}
+
+	for i := range tcs {
+		tc := tcs[i]
+		t.Run(tc.Name, func(t *testing.T) {
+			bodies = bodies[:0]
+			job := newCreatedJobsRow(1, owner)
+			opt := option.AnalyzerOption{APIName: "openai"}
+			opt.Prompt = &option.PromptVersion{ID: prompt.ID, Version: tc.Version}
+			job.LlmQuery, _ = json.Marshal(opt)
+
+			ctl := gomock.NewController(t)
+			store := mock_model.NewMockStore(ctl)
+			allowEmbeddingChunks(store)
+			allowUsage(store)
+			calls := []*gomock.Call{
+				store.EXPECT().
+					GetOldestNCreatedJobsForEachUser(gomock.Any(), int32(1)).
+					Return([]*model.GetOldestNCreatedJobsForEachUserRow{job.GetOldestNCreatedJobsForEachUserRow}, nil),
+				store.EXPECT().
+					UpdateJobStatusFrom(gomock.Any(), gomock.Any()).
+					Return(int64(1), nil),
+				store.EXPECT().
+					GetAPIKey(gomock.Any(), gomock.Any()).
+					Return(&model.GetAPIKeyRow{ID: 1, Owner: owner, ApiID: 5, Key: TEST_API_KEY}, nil),
+				store.EXPECT().
+					GetPrompt(gomock.Any(), &model.GetPromptParams{Owner: owner, ID: prompt.ID}).
+					Return(prompt, nil),
+			}
+			if tc.OK {
+				calls = append(calls, expectItems(store, job.ID,
+					&model.GetDueJobItemsRow{ID: 11, NewsID: 1, Title: "台股崩盤", Description: "投資人恐慌"},
+				)...)
+				calls = append(calls,
+					// the sentiments of the other prompts are not reused
+					store.EXPECT().
+						GetEmbeddingByNewsIdsAndModel(gomock.Any(), &model.GetEmbeddingByNewsIdsAndModelParams{
+							Model: "text-embedding-ada-002", NewsIds: []int32{1},
+							PromptID: pgtype.Int8{Int64: prompt.ID, Valid: true},
+						}).
+						Return(nil, nil),
+					store.EXPECT().
+						CreateEmbedding(gomock.Any(), gomock.Any()).
+						DoAndReturn(func(_ context.Context, params *model.CreateEmbeddingParams) (int64, error) {
+							require.Equal(t, pgtype.Int8{Int64: prompt.ID, Valid: true}, params.PromptID)
+							return 1, nil
+						}),
+					store.EXPECT().
+						MarkJobItemDone(gomock.Any(), itemDone(11)).
+						Return(int64(1), nil),
+					store.EXPECT().
+						IncrJobProgress(gomock.Any(), gomock.Any()).
+						Return(nil),
+				)
+				calls = append(calls, expectSettled(store, job.ID)...)
+			}
+			calls = append(calls,
+				store.EXPECT().
+					UpdateJobStatusFrom(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, params *model.UpdateJobStatusFromParams) (int64, error) {
+						if tc.OK {
+							require.Equal(t, model.JobStatusDone, params.ToStatus)
+						} else {
+							require.Equal(t, model.JobStatusFailed, params.ToStatus)
+						}
+						return 1, nil
+					}),
+			)
+			gomock.InOrder(calls...)
+
+			rnr := runner.NewRunner(service.NewService(store, validator.Validate), time.Second, 1, 3*time.Second).
+				WithHTTPClient(newTestClient(t, srvr))
+			rnr.RunOnce(context.Background())
+
+			if !tc.OK {
+				require.Empty(t, bodies)
+				return
+			}
+			// the rendered prompt replaces the default one
+			sent := strings.Join(bodies, "\n")
+			require.Contains(t, sent, `"content":"[[::CUSTOM PROMPT::]] of openai"`)
+			require.NotContains(t, sent, "As an AI specializing in language and emotion analysis")
+		})
+	}
+}
+
+func TestInputHash(t *testing.T) {
+	h := runner.InputHash("台股 崩盤\n投資人恐慌", "openai")
+	require.Len(t, h, 64)
+	// the copies of a story differing only in the layout share a key
+	require.Equal(t, h, runner.InputHash("  台股  崩盤 投資人恐慌\n", "openai"))
+	require.NotEqual(t, h, runner.InputHash("台股 崩盤\n投資人恐慌", "cohere"))
+	require.NotEqual(t, h, runner.InputHash("台股崩盤\n投資人恐慌", "openai"))
+	require.NotEqual(t, runner.InputHash("a", "b", "c"), runner.InputHash("a", "bc"))
+}
+
+func TestRunOnceWithCache(t *testing.T) {
+	// count the requests to the fake OpenAI server
+	openaiSrvr := newOpenAIServer(t)
+	defer openaiSrvr.Close()
+	nCalls := map[string]int{}
+	srvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		nCalls[r.URL.Path]++
+		openaiSrvr.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer srvr.Close()
+
+	type testCase struct {
+		Name      string
+		NoCache   bool
+		NChats    int
+		NEmbeds   int
+		Sentiment model.Sentiment
+	}
+
+	tcs := []testCase{
+		{Name: "Cached", NChats: 1, NEmbeds: 1, Sentiment: model.SentimentPositive},
+		// the fake server gives a result per request, so the batch is split
+		{Name: "Bypass cache", NoCache: true, NChats: 3, NEmbeds: 2, Sentiment: model.SentimentNegative},
+	}
+
+	for i := range tcs {
+		tc := tcs[i]
+		t.Run(tc.Name, func(t *testing.T) {
+			clear(nCalls)
+			owner := uuid.New()
+			job := newCreatedJobsRow(1, owner)
+			job.LlmQuery, _ = json.Marshal(option.AnalyzerOption{APIName: "openai", NoCache: tc.NoCache})
+
+			ctl := gomock.NewController(t)
+			store := mock_model.NewMockStore(ctl)
+			allowEmbeddingChunks(store)
+			allowUsage(store)
+			if !tc.NoCache {
+				// news 1 has been analyzed with the same model and prompt before
+				cached := map[string]bool{}
+				store.EXPECT().
+					GetLLMResponse(gomock.Any(), gomock.Any()).
+					Times(4).
+					DoAndReturn(func(_ context.Context, params *model.GetLLMResponseParams) ([]byte, error) {
+						require.Equal(t, "openai", params.Provider)
+						require.Zero(t, params.PromptID)
+						if cached[params.Operation] {
+							return nil, pgx.ErrNoRows
+						}
+						cached[params.Operation] = true
+						if params.Operation == "sentiment" {
+							require.Equal(t, "gpt-4o-mini", params.Model)
+							return []byte(`{"Sentiment":"positive","Confidence":0.9,"Rationale":"cached"}`), nil
+						}
+						require.Equal(t, "embedding", params.Operation)
+						require.Equal(t, "text-embedding-ada-002", params.Model)
+						embd := make([]float32, embeddingDims[params.Model])
+						embd[0] = 0.1
+						return json.Marshal(embd)
+					})
+				store.EXPECT().
+					UpsertLLMResponse(gomock.Any(), gomock.Any()).
+					Times(2).
+					DoAndReturn(func(_ context.Context, params *model.UpsertLLMResponseParams) (int64, error) {
+						require.Len(t, params.InputHash, 64)
+						require.True(t, params.ExpiresAt.Time.After(time.Now().Add(23*time.Hour)))
+						if params.Operation == "sentiment" {
+							require.JSONEq(t, `{"Sentiment":"negative","Confidence":0.8,"Rationale":"The outlook is bleak."}`,
+								string(params.Response))
+						}
+						return 1, nil
+					})
+			}
+
+			calls := []*gomock.Call{
+				store.EXPECT().
+					GetOldestNCreatedJobsForEachUser(gomock.Any(), int32(1)).
+					Return([]*model.GetOldestNCreatedJobsForEachUserRow{job.GetOldestNCreatedJobsForEachUserRow}, nil),
+				store.EXPECT().
+					UpdateJobStatusFrom(gomock.Any(), gomock.Any()).
+					Return(int64(1), nil),
+				store.EXPECT().
+					GetAPIKey(gomock.Any(), gomock.Any()).
+					Return(&model.GetAPIKeyRow{ID: 1, Owner: owner, ApiID: 5, Key: TEST_API_KEY}, nil),
+			}
+			calls = append(calls, expectItems(store, job.ID,
+				&model.GetDueJobItemsRow{ID: 11, NewsID: 1, Title: "title 1", Description: "description 1"},
+				&model.GetDueJobItemsRow{ID: 12, NewsID: 2, Title: "title 2", Description: "description 2"},
+			)...)
+			if !tc.NoCache {
+				// the news analyzed before are not reused if the cache is bypassed
+				calls = append(calls,
+					store.EXPECT().
+						GetEmbeddingByNewsIdsAndModel(gomock.Any(), gomock.Any()).
+						Return(nil, nil),
+				)
+			}
+			for _, iId := range []int64{11, 12} {
+				calls = append(calls,
+					store.EXPECT().
+						CreateEmbedding(gomock.Any(), gomock.Any()).
+						DoAndReturn(func(_ context.Context, params *model.CreateEmbeddingParams) (int64, error) {
+							if params.NewsID == 1 {
+								require.Equal(t, tc.Sentiment, params.Sentiment)
+							} else {
+								require.Equal(t, model.SentimentNegative, params.Sentiment)
+							}
+							require.Len(t, params.Embedding.Slice(), int(embeddingDims[params.Model]))
+							return params.NewsID, nil
+						}),
+					store.EXPECT().
+						MarkJobItemDone(gomock.Any(), itemDone(iId)).
+						Return(int64(1), nil),
+					store.EXPECT().
+						IncrJobProgress(gomock.Any(), gomock.Any()).
+						Return(nil),
+				)
+			}
+			calls = append(calls, expectSettled(store, job.ID)...)
+			calls = append(calls,
+				store.EXPECT().
+					UpdateJobStatusFrom(gomock.Any(), &model.UpdateJobStatusFromParams{
+						ToStatus: model.JobStatusDone, ID: job.ID, Owner: owner,
+						FromStatus: []string{string(model.JobStatusRunning)},
+					}).
+					Return(int64(1), nil),
+			)
+			gomock.InOrder(calls...)
+
+			rnr := runner.NewRunner(service.NewService(store, validator.Validate), time.Second, 1, 3*time.Second).
+				WithHTTPClient(newTestClient(t, srvr)).
+				WithBatch(1000, 10).
+				WithCache(24 * time.Hour)
+			rnr.RunOnce(context.Background())
+			require.Equal(t, tc.NChats, nCalls["/v1/chat/completions"])
+			require.Equal(t, tc.NEmbeds, nCalls["/v1/embeddings"])
+		})
+	}
+}
+
+func TestEmbeddingModel(t *testing.T) {
+	type testCase struct {
+		Option *option.AnalyzerOption
+		Model  string
+	}
+
+	tcs := []testCase{
+		{&option.AnalyzerOption{APIName: "openai"}, "text-embedding-ada-002"},
+		{&option.AnalyzerOption{
+			APIName:          "cohere",
+			EmbeddingOptions: option.EmbeddingOptions{EmbeddingModel: "embed-english-v3.0"},
+		}, "embed-english-v3.0"},
+	}
+
+	for _, tc := range tcs {
+		mdl, err := runner.EmbeddingModel(tc.Option)
+		require.NoError(t, err)
+		require.Equal(t, tc.Model, mdl)
+	}
+
+	_, err := runner.EmbeddingModel(&option.AnalyzerOption{APIName: "bard"})
+	require.ErrorIs(t, err, runner.ErrUnknownAnalyzer)
+	_, err = runner.EmbeddingModel(nil)
+	require.ErrorIs(t, err, runner.ErrUnknownAnalyzer)
+}
+
+func TestStartAndShutdown(t *testing.T) {
+	ctl := gomock.NewController(t)
+	store := mock_model.NewMockStore(ctl)
+	store.EXPECT().
+		GetOldestNCreatedJobsForEachUser(gomock.Any(), int32(1)).
+		MinTimes(1).
+		Return(nil, nil)
+	// the expired responses are deleted when the runner starts, and not again
+	// until an hour later
+	store.EXPECT().
+		CleanUpLLMResponses(gomock.Any()).
+		Times(1).
+		Return(int64(2), nil)
+
+	rnr := runner.NewRunner(service.NewService(store, validator.Validate), 10*time.Millisecond, 1, time.Second)
+	require.NoError(t, rnr.Start())
+	require.ErrorIs(t, rnr.Start(), runner.ErrRunnerHasStarted)
+	time.Sleep(30 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, rnr.Shutdown(ctx))
+}
+
+func TestCleanUp(t *testing.T) {
+	ctl := gomock.NewController(t)
+	store := mock_model.NewMockStore(ctl)
+	gomock.InOrder(
+		store.EXPECT().
+			CleanUpLLMResponses(gomock.Any()).
+			Return(int64(3), nil),
+		store.EXPECT().
+			CleanUpLLMResponses(gomock.Any()).
+			Return(int64(0), errors.New("connection refused")),
+	)
+
+	rnr := runner.NewRunner(service.NewService(store, validator.Validate), time.Second, 1, time.Second)
+	require.Equal(t, int64(3), rnr.CleanUp(context.Background()))
+	require.Equal(t, int64(0), rnr.CleanUp(context.Background()))
+}
+
+// the hash of a preview ignores letters and digits, so titles should differ in
+// punctuation
+func newPreview(title string) api.NewsPreview {
+	return api.NewsPreview{
+		Id:      ulid.Make(),
+		Title:   title,
+		Link:    "https://example.com/" + title,
+		PubDate: time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+// parseAll turns every preview into a request to create news
+func parseAll(parsed *map[string]api.NewsPreview) runner.ParseFunc {
+	return func(ctx context.Context, items map[string]api.NewsPreview) (
+		<-chan *service.NewsCreateRequest, *runner.ParseResult, error) {
+		*parsed = items
+		result := &runner.ParseResult{}
+		cnChan := make(chan *service.NewsCreateRequest, len(items))
+		for _, item := range items {
+			cnChan <- item.ToNewsCreateRequest(item.Id.String(), "en", "example.com", nil)
+			result.LangDetected.Add(1)
+			result.Parsed.Add(1)
+		}
+		close(cnChan)
+		return cnChan, result, nil
+	}
+}
+
+func TestSchedulerRunOnce(t *testing.T) {
+	owner := uuid.New()
+	srcQuery, err := json.Marshal(api.CacheQuery{
+		API:      api.API{Name: "NEWSDATA", Endpoint: "latest"},
+		NextPage: api.IntNextPageToken(1),
+		RawQuery: "q=taiwan",
+	})
+	require.NoError(t, err)
+
+	prevRunAt := time.Now().Add(-time.Minute).Truncate(time.Minute)
+	row := &model.GetDueJobSchedulesRow{
+		JobID:         1,
+		Owner:         owner,
+		CronExpr:      "*/5 * * * *",
+		SrcCacheQuery: srcQuery,
+		NextRunAt:     pgtype.Timestamptz{Time: prevRunAt, Valid: true},
+		SrcApiID:      2,
+		SrcQuery:      "q=taiwan",
+		LlmApiID:      5,
+		LlmQuery:      []byte(`{"api":"openai","embedding":true,"sentiment":true}`),
+	}
+
+	p1, p2, p3 := newPreview("news!"), newPreview("news?"), newPreview("news...")
+	h2, _ := api.MD5Hash(p2)
+	pages := map[api.NextPageToken]struct {
+		next     api.NextPageToken
+		previews []api.NewsPreview
+	}{
+		api.IntNextPageToken(1): {api.IntNextPageToken(2), []api.NewsPreview{p1, p2}},
+		// p1 shows up again in the next page
+		api.IntNextPageToken(2): {api.IntLastPageToken, []api.NewsPreview{p3, p1}},
+	}
+
+	nFetched := 0
+	fetch := func(cq api.CacheQuery) (api.NextPageToken, []api.NewsPreview, error) {
+		nFetched++
+		require.Equal(t, owner, cq.UserId)
+		require.Equal(t, TEST_API_KEY, cq.API.Key)
+		require.Equal(t, "NEWSDATA", cq.API.Name)
+		page, ok := pages[cq.NextPage]
+		require.True(t, ok, "unexpected page %v", cq.NextPage)
+		return page.next, page.previews, nil
+	}
+
+	ctl := gomock.NewController(t)
+	store := mock_model.NewMockStore(ctl)
+	gomock.InOrder(
+		store.EXPECT().
+			GetDueJobSchedules(gomock.Any(), int32(10)).
+			Return([]*model.GetDueJobSchedulesRow{row}, nil),
+		store.EXPECT().
+			ClaimJobSchedule(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, params *model.ClaimJobScheduleParams) (int64, error) {
+				require.Equal(t, row.JobID, params.JobID)
+				require.True(t, prevRunAt.Equal(params.PrevRunAt.Time))
+				require.True(t, params.NextRunAt.Time.After(time.Now()))
+				return 1, nil
+			}),
+		store.EXPECT().
+			GetAPIKey(gomock.Any(), &model.GetAPIKeyParams{Owner: owner, ApiID: 2}).
+			Return(&model.GetAPIKeyRow{Owner: owner, ApiID: 2, Key: TEST_API_KEY}, nil),
+		store.EXPECT().
+			GetNewsByMD5Hashs(gomock.Any(), gomock.Len(3)).
+			Return([]*model.GetNewsByMD5HashsRow{{ID: 100, Md5Hash: h2}}, nil),
+		store.EXPECT().
+			DoCacheToStoreTx(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, params *model.CacheToStoreTXParams) (*model.CacheToStoreTXResult, error) {
+				require.Equal(t, owner, params.CreateJobParams.Owner)
+				require.Equal(t, pgtype.Int8{Int64: row.JobID, Valid: true}, params.CreateJobParams.ParentID)
+				require.Equal(t, row.SrcApiID, params.CreateJobParams.SrcApiID)
+				require.Equal(t, row.LlmApiID, params.CreateJobParams.LlmApiID)
+				require.Equal(t, row.LlmQuery, params.CreateJobParams.LlmQuery)
+				require.Nil(t, params.CreateJobScheduleParams)
+
+				n := 0
+				for range params.CreateNewsParams {
+					n++
+				}
+				require.Equal(t, 2, n)
+				return &model.CacheToStoreTXResult{JobId: 2}, nil
+			}),
+		store.EXPECT().
+			CreateJobProgress(gomock.Any(), &model.CreateJobProgressParams{
+				JobID: 2, Total: 2, Parsed: 2, LangDetected: 2, Failed: 0,
+			}).
+			Return(nil),
+	)
+
+	var parsed map[string]api.NewsPreview
+	sch := runner.NewScheduler(service.NewService(store, validator.Validate), time.Minute, 10, 5, time.Second).
+		WithFetcher(fetch).
+		WithParser(parseAll(&parsed))
+	sch.RunOnce(context.Background())
+
+	require.Equal(t, 2, nFetched)
+	require.Len(t, parsed, 2)
+	require.Contains(t, parsed, p1.Id.String())
+	require.Contains(t, parsed, p3.Id.String())
+}
+
+func TestSchedulerSkip(t *testing.T) {
+	owner := uuid.New()
+	srcQuery, _ := json.Marshal(api.CacheQuery{
+		API:      api.API{Name: "NEWSDATA", Endpoint: "latest"},
+		NextPage: api.IntNextPageToken(1),
+	})
+	row := &model.GetDueJobSchedulesRow{
+		JobID:         1,
+		Owner:         owner,
+		CronExpr:      "@hourly",
+		SrcCacheQuery: srcQuery,
+		NextRunAt:     pgtype.Timestamptz{Time: time.Now().Add(-time.Hour), Valid: true},
+		SrcApiID:      2,
+		LlmApiID:      5,
+		LlmQuery:      []byte(`{}`),
+	}
+	prev := newPreview("news!")
+	hash, _ := api.MD5Hash(prev)
+
+	type testCase struct {
+		Name     string
+		Claimed  int64
+		NFetched int
+	}
+
+	tcs := []testCase{
+		// claimed by another instance
+		{Name: "claimed", Claimed: 0, NFetched: 0},
+		// every news has been stored
+		{Name: "no new news", Claimed: 1, NFetched: 1},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.Name, func(t *testing.T) {
+			nFetched := 0
+			fetch := func(cq api.CacheQuery) (api.NextPageToken, []api.NewsPreview, error) {
+				nFetched++
+				return api.IntLastPageToken, []api.NewsPreview{prev}, nil
+			}
+
+			ctl := gomock.NewController(t)
+			store := mock_model.NewMockStore(ctl)
+			store.EXPECT().
+				GetDueJobSchedules(gomock.Any(), int32(1)).
+				Return([]*model.GetDueJobSchedulesRow{row}, nil)
+			store.EXPECT().
+				ClaimJobSchedule(gomock.Any(), gomock.Any()).
+				Return(tc.Claimed, nil)
+			if tc.Claimed > 0 {
+				store.EXPECT().
+					GetAPIKey(gomock.Any(), gomock.Any()).
+					Return(&model.GetAPIKeyRow{Key: TEST_API_KEY}, nil)
+				store.EXPECT().
+					GetNewsByMD5Hashs(gomock.Any(), []string{hash}).
+					Return([]*model.GetNewsByMD5HashsRow{{ID: 100, Md5Hash: hash}}, nil)
+			}
+
+			var parsed map[string]api.NewsPreview
+			sch := runner.NewScheduler(service.NewService(store, validator.Validate), time.Minute, 1, 5, time.Second).
+				WithFetcher(fetch).
+				WithParser(parseAll(&parsed))
+			sch.RunOnce(context.Background())
+
+			require.Equal(t, tc.NFetched, nFetched)
+			require.Nil(t, parsed)
+		})
+	}
+}
+
+func TestSignWebhook(t *testing.T) {
+	secret := "[[::WEBHOOK_SECRET::]]"
+	body := []byte(`{"job_id":1,"status":"done"}`)
+	ts := time.Now().Unix()
+
+	sig := runner.SignWebhook(secret, ts, body)
+	require.Regexp(t, "^sha256=[0-9a-f]{64}$", sig)
+	require.True(t, runner.VerifyWebhook(secret, ts, body, sig))
+	require.False(t, runner.VerifyWebhook(secret, ts+1, body, sig))
+	require.False(t, runner.VerifyWebhook(secret, ts, []byte(`{"job_id":1,"status":"failed"}`), sig))
+	require.False(t, runner.VerifyWebhook("another secret", ts, body, sig))
+}
+
+func TestNotifierRunOnce(t *testing.T) {
+	secret := "[[::WEBHOOK_SECRET::]]"
+	payload := []byte(`{"event":"job.status_changed","job_id":1,"status":"done"}`)
+
+	type testCase struct {
+		Name       string
+		StatusCode int
+		Attempts   int32
+		Claimed    int64
+		Stage      model.WebhookDeliveryStage
+		NReceived  int
+	}
+
+	tcs := []testCase{
+		{Name: "delivered", StatusCode: http.StatusNoContent, Claimed: 1,
+			Stage: model.WebhookDeliveryStageDelivered, NReceived: 1},
+		{Name: "retrying", StatusCode: http.StatusInternalServerError, Claimed: 1,
+			Stage: model.WebhookDeliveryStageRetrying, NReceived: 1},
+		{Name: "failed", StatusCode: http.StatusBadGateway, Attempts: 2, Claimed: 1,
+			Stage: model.WebhookDeliveryStageFailed, NReceived: 1},
+		{Name: "claimed", StatusCode: http.StatusOK, Claimed: 0, NReceived: 0},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.Name, func(t *testing.T) {
+			nReceived := 0
+			receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				nReceived++
+				body, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+				require.Equal(t, payload, body)
+				require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+				require.Equal(t, "7", r.Header.Get(runner.HeaderWebhookDelivery))
+
+				ts, err := strconv.ParseInt(r.Header.Get(runner.HeaderWebhookTimestamp), 10, 64)
+				require.NoError(t, err)
+				require.True(t, runner.VerifyWebhook(secret, ts, body, r.Header.Get(runner.HeaderWebhookSignature)))
+				w.WriteHeader(tc.StatusCode)
+			}))
+			defer receiver.Close()
+
+			row := &model.GetDueWebhookDeliveriesRow{
+				ID:            7,
+				JobID:         1,
+				Payload:       payload,
+				Attempts:      tc.Attempts,
+				NextAttemptAt: pgtype.Timestamptz{Time: time.Now().Add(-time.Second), Valid: true},
+				Url:           receiver.URL,
+				Secret:        secret,
+			}
+
+			ctl := gomock.NewController(t)
+			store := mock_model.NewMockStore(ctl)
+			store.EXPECT().
+				GetDueWebhookDeliveries(gomock.Any(), int32(10)).
+				Return([]*model.GetDueWebhookDeliveriesRow{row}, nil)
+			store.EXPECT().
+				ClaimWebhookDelivery(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, params *model.ClaimWebhookDeliveryParams) (int64, error) {
+					require.Equal(t, row.ID, params.ID)
+					require.Equal(t, row.NextAttemptAt.Time, params.PrevAttemptAt.Time)
+					require.True(t, params.NextAttemptAt.Time.After(time.Now()))
+					return tc.Claimed, nil
+				})
+
+			switch tc.Stage {
+			case model.WebhookDeliveryStageDelivered:
+				store.EXPECT().
+					MarkWebhookDeliveryDelivered(gomock.Any(), &model.MarkWebhookDeliveryDeliveredParams{
+						ResponseCode: pgtype.Int4{Int32: int32(tc.StatusCode), Valid: true},
+						ID:           row.ID,
+					}).
+					Return(int64(1), nil)
+			case model.WebhookDeliveryStageRetrying, model.WebhookDeliveryStageFailed:
+				store.EXPECT().
+					MarkWebhookDeliveryFailed(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, params *model.MarkWebhookDeliveryFailedParams) (int64, error) {
+						require.Equal(t, row.ID, params.ID)
+						require.Equal(t, tc.Stage, params.Stage)
+						require.Equal(t, int32(tc.StatusCode), params.ResponseCode.Int32)
+						require.True(t, params.LastError.Valid)
+						require.WithinDuration(t,
+							time.Now().Add(time.Duration(1<<tc.Attempts)*time.Second),
+							params.NextAttemptAt.Time, 500*time.Millisecond)
+						return 1, nil
+					})
+			}
+
+			ntf := runner.NewNotifier(service.NewService(store, validator.Validate), time.Minute, 10, time.Second).
+				WithHTTPClient(runner.NewWebhookClient(time.Second, true)).
+				WithRetry(3, time.Second, time.Minute)
+			ntf.RunOnce(context.Background())
+			require.Equal(t, tc.NReceived, nReceived)
+		})
+	}
+}
+
+func TestWebhookClientForbidsPrivateAddress(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer receiver.Close()
+
+	_, err := runner.NewWebhookClient(time.Second, false).Get(receiver.URL)
+	require.ErrorIs(t, err, runner.ErrForbiddenAddress)
+
+	resp, err := runner.NewWebhookClient(time.Second, true).Get(receiver.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestClustererRunOnce(t *testing.T) {
+	mdl := "text-embedding-3-small"
+	publishAt := time.Now().Add(-time.Hour).Truncate(time.Second)
+	window := 48 * time.Hour
+	newRow := func(id int64, source string) *model.GetUnclusteredNewsRow {
+		embd := make([]float32, 1536)
+		embd[0], embd[1] = float32(id), 1
+		return &model.GetUnclusteredNewsRow{
+			NewsID:    id,
+			Model:     mdl,
+			Embedding: pgv.NewVector(embd),
+			Source:    source,
+			Title:     fmt.Sprintf("headline %d", id),
+			PublishAt: pgtype.Timestamptz{Time: publishAt, Valid: true},
+		}
+	}
+	// 1 is similar to story 7, 2 is not similar enough to story 8 and 3 has
+	// no candidate
+	rows := []*model.GetUnclusteredNewsRow{newRow(1, "a.com"), newRow(2, "b.com"), newRow(3, "c.com")}
+	nearest := map[int64]*model.GetNearestStoryRow{
+		1: {StoryID: 7, Similarity: 0.9},
+		2: {StoryID: 8, Similarity: 0.5},
+	}
+
+	ctl := gomock.NewController(t)
+	store := mock_model.NewMockStore(ctl)
+	store.EXPECT().
+		GetUnclusteredNews(gomock.Any(), int32(50)).
+		Times(1).
+		Return(rows, nil)
+	// the candidates are the stories of the nearest news, which are searched
+	// with the index of the size of the model
+	store.EXPECT().
+		GetNearestNewsIds1536(gomock.Any(), gomock.Any()).
+		Times(len(rows)).
+		DoAndReturn(func(_ context.Context, params *model.GetNearestNewsIds1536Params) ([]int64, error) {
+			id := int64(params.Embedding.Slice()[0])
+			require.Equal(t, mdl, params.Model)
+			require.Equal(t, int32(40), params.N)
+			return []int64{id + 100}, nil
+		})
+	store.EXPECT().
+		GetNearestStory(gomock.Any(), gomock.Any()).
+		Times(len(rows)).
+		DoAndReturn(func(_ context.Context, params *model.GetNearestStoryParams) (*model.GetNearestStoryRow, error) {
+			id := int64(params.Embedding.Slice()[0])
+			require.Equal(t, mdl, params.Model)
+			require.Equal(t, []int64{id + 100}, params.NewsIds)
+			require.Equal(t, rows[id-1].Source, params.Source)
+			require.True(t, publishAt.Add(-window).Equal(params.PublishFrom.Time))
+			require.True(t, publishAt.Add(window).Equal(params.PublishTo.Time))
+			if row, ok := nearest[id]; ok {
+				return row, nil
+			}
+			return nil, pgx.ErrNoRows
+		})
+	store.EXPECT().
+		AddStoryNews(gomock.Any(), &model.AddStoryNewsParams{
+			StoryID:    7,
+			NewsID:     1,
+			Model:      mdl,
+			Similarity: 0.9,
+			PublishAt:  rows[0].PublishAt,
+		}).
+		Times(1).
+		Return(int64(1), nil)
+	for _, id := range []int64{2, 3} {
+		store.EXPECT().
+			CreateStory(gomock.Any(), &model.CreateStoryParams{
+				Model:     mdl,
+				Title:     rows[id-1].Title,
+				PublishAt: rows[id-1].PublishAt,
+				NewsID:    id,
+			}).
+			Times(1).
+			Return(id+10, nil)
+	}
+
+	cls := runner.NewClusterer(service.NewService(store, validator.Validate), time.Minute, 50, 0.85, window)
+	cls.RunOnce(context.Background())
+}
@@ -0,0 +1,306 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/global"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client/api"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/model"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/server/service"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/pkgs/cron"
+	"github.com/oklog/ulid/v2"
+)
+
+var ErrSchedulerHasStarted = errors.New("scheduler has already started")
+
+// FetchFunc sends the query and returns the token of the next page and the
+// previews in the current page.
+type FetchFunc func(cq api.CacheQuery) (api.NextPageToken, []api.NewsPreview, error)
+
+// FetchCacheQuery sends the query via the registered handler of its api.
+func FetchCacheQuery(cq api.CacheQuery) (api.NextPageToken, []api.NewsPreview, error) {
+	handler, err := client.HandlerRepo.GetByCacheQuery(cq)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := handler.RequestFromCacheQuery(cq)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resp, err := client.HandlerRepo.Do(req, handler)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	next, previews := resp.ToNewsItemList()
+	return next, previews, nil
+}
+
+// Scheduler polls the due job schedules and replays their source query. The
+// news that have not been stored yet are attached to a new child job, which
+// will be executed by the Runner.
+type Scheduler struct {
+	srvc       service.Service
+	interval   time.Duration
+	nSchedules int
+	maxPages   int
+	timeout    time.Duration
+	fetch      FetchFunc
+	parse      ParseFunc
+	cancel     context.CancelFunc
+	done       chan struct{}
+	once       sync.Once
+}
+
+func NewScheduler(srvc service.Service, interval time.Duration, nSchedules, maxPages int, timeout time.Duration) *Scheduler {
+	if nSchedules < 1 {
+		nSchedules = 1
+	}
+	if maxPages < 1 {
+		maxPages = 1
+	}
+	return &Scheduler{
+		srvc:       srvc,
+		interval:   interval,
+		nSchedules: nSchedules,
+		maxPages:   maxPages,
+		timeout:    timeout,
+		fetch:      FetchCacheQuery,
+		parse:      ParseNewsPreviews,
+	}
+}
+
+func (s *Scheduler) WithFetcher(fetch FetchFunc) *Scheduler {
+	s.fetch = fetch
+	return s
+}
+
+func (s *Scheduler) WithParser(parse ParseFunc) *Scheduler {
+	s.parse = parse
+	return s
+}
+
+// Start starts polling in a new goroutine.
+func (s *Scheduler) Start() error {
+	err := ErrSchedulerHasStarted
+	s.once.Do(func() {
+		var ctx context.Context
+		ctx, s.cancel = context.WithCancel(context.Background())
+		s.done = make(chan struct{})
+		go s.loop(ctx)
+		err = nil
+	})
+	return err
+}
+
+// Shutdown stops polling and waits until the running schedule has finished
+// or the given context is done.
+func (s *Scheduler) Shutdown(ctx context.Context) error {
+	if s.cancel == nil {
+		return nil
+	}
+	s.cancel()
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("error while shutting down job scheduler: %w", ctx.Err())
+	}
+}
+
+func (s *Scheduler) loop(ctx context.Context) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.RunOnce(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce runs the due schedules, the earliest first.
+func (s *Scheduler) RunOnce(ctx context.Context) {
+	rows, err := s.srvc.JobSchedule().GetDue(ctx, s.nSchedules)
+	if err != nil {
+		global.Logger.Error().
+			Err(err).
+			Msg("error while GetDueJobSchedules")
+		return
+	}
+
+	for _, row := range rows {
+		if ctx.Err() != nil {
+			return
+		}
+		s.runSchedule(ctx, row)
+	}
+}
+
+func (s *Scheduler) runSchedule(ctx context.Context, row *model.GetDueJobSchedulesRow) {
+	logger := global.Logger.With().
+		Int64("job_id", row.JobID).
+		Str("owner", row.Owner.String()).
+		Logger()
+
+	sched, err := cron.Parse(row.CronExpr)
+	if err != nil {
+		logger.Error().Err(err).Msg("error while parsing cron expression")
+		return
+	}
+
+	// claim the run first, so that it will not be run twice by other
+	// instances nor retried on failure
+	now := time.Now()
+	n, err := s.srvc.JobSchedule().Claim(ctx, &service.JobScheduleClaimRequest{
+		JobId:     row.JobID,
+		PrevRunAt: row.NextRunAt.Time,
+		NextRunAt: sched.Next(now),
+	})
+	if err != nil {
+		logger.Error().Err(err).Msg("error while ClaimJobSchedule")
+		return
+	}
+	if n == 0 {
+		logger.Info().Msg("schedule has been claimed")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	jId, err := s.replay(ctx, row)
+	if err != nil {
+		logger.Error().Err(err).Msg("error while replaying query")
+		return
+	}
+
+	if jId == 0 {
+		logger.Info().Msg("no new news")
+		return
+	}
+	logger.Info().Int64("child_job_id", jId).Msg("child job created")
+}
+
+// replay sends the source query of the schedule and stores the news that have
+// not been stored yet in a new child job. It returns 0 if there are none.
+func (s *Scheduler) replay(ctx context.Context, row *model.GetDueJobSchedulesRow) (int64, error) {
+	var cq api.CacheQuery
+	if err := json.Unmarshal(row.SrcCacheQuery, &cq); err != nil {
+		return 0, fmt.Errorf("error while unmarshaling source query: %w", err)
+	}
+
+	key, err := s.srvc.APIKey().Get(ctx, &service.APIKeyGetRequest{
+		Owner: row.Owner,
+		ApiID: row.SrcApiID,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error while getting api key: %w", err)
+	}
+	cq.UserId = row.Owner
+	cq.API.Key = key.Key
+
+	previews := []api.NewsPreview{}
+	for page := 0; page < s.maxPages; page++ {
+		next, items, err := s.fetch(cq)
+		if err != nil {
+			return 0, fmt.Errorf("error while fetching page %d: %w", page+1, err)
+		}
+		previews = append(previews, items...)
+		if next == nil || api.IsLastPageToken(next) {
+			break
+		}
+		cq.NextPage = next
+	}
+
+	items, err := s.newPreviews(ctx, previews)
+	if err != nil || len(items) == 0 {
+		return 0, err
+	}
+
+	cnChan, parsed, err := s.parse(ctx, items)
+	if err != nil {
+		return 0, fmt.Errorf("error while parsing news: %w", err)
+	}
+
+	result, err := s.srvc.TX().DoCacheToStoreTx(ctx, &service.CacheToStoreTxRequest{
+		Owner:    row.Owner,
+		Ulid:     ulid.Make().String(),
+		SrcId:    row.SrcApiID,
+		SrcQuery: row.SrcQuery,
+		LlmId:    row.LlmApiID,
+		LlmQuery: string(row.LlmQuery),
+		ParentId: row.JobID,
+	}, cnChan)
+	if err != nil {
+		return 0, err
+	}
+
+	err = s.srvc.Job().CreateProgress(ctx, &service.JobCreateProgressRequest{
+		JobId:        result.JobId,
+		Total:        int32(len(items)),
+		Parsed:       parsed.Parsed.Load(),
+		LangDetected: parsed.LangDetected.Load(),
+		Failed:       int32(len(items)) - parsed.Parsed.Load(),
+	})
+	if err != nil {
+		// the job has been stored, progress is not worth failing the run
+		global.Logger.Error().
+			Err(err).
+			Int64("job_id", result.JobId).
+			Msg("error while CreateProgress")
+	}
+	return result.JobId, nil
+}
+
+// newPreviews drops the duplicated previews and those have been stored, and
+// keys the rest by their ids.
+func (s *Scheduler) newPreviews(ctx context.Context, previews []api.NewsPreview) (map[string]api.NewsPreview, error) {
+	byHash := map[string]api.NewsPreview{}
+	hashes := []string{}
+	for _, prev := range previews {
+		hash, err := api.MD5Hash(prev)
+		if err != nil {
+			continue
+		}
+		if _, ok := byHash[hash]; ok {
+			continue
+		}
+		byHash[hash] = prev
+		hashes = append(hashes, hash)
+	}
+
+	if len(hashes) == 0 {
+		return nil, nil
+	}
+
+	rows, err := s.srvc.News().GetByMD5Hashs(ctx, &service.NewsGetByMD5HashsRequest{
+		MD5Hash: hashes,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error while GetNewsByMD5Hashs: %w", err)
+	}
+	for _, row := range rows {
+		delete(byHash, row.Md5Hash)
+	}
+
+	items := make(map[string]api.NewsPreview, len(byHash))
+	for _, prev := range byHash {
+		items[prev.Id.String()] = prev
+	}
+	return items, nil
+}
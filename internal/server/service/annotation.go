@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/model"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// UpsertAnnotationRequest stores the label of a news in a classification task,
+// which replaces the one given by the same model for the same owner and
+// definition of the task, of which TaskHash is the hash, before.
+type UpsertAnnotationRequest struct {
+	NewsId     int64     `validate:"required,min=1"`
+	Task       string    `validate:"required,max=64"`
+	Label      string    `validate:"required,max=64"`
+	Model      string    `validate:"required,max=32"`
+	Confidence *float32  `validate:"omitempty,min=0,max=1"`
+	Rationale  string    `validate:"max=2048"`
+	Owner      uuid.UUID `validate:"not_uuid_nil,uuid4"`
+	TaskHash   string    `validate:"required,len=64,hexadecimal"`
+}
+
+func (req UpsertAnnotationRequest) RequestName() string {
+	return "annotation-upsert-req"
+}
+
+func (req UpsertAnnotationRequest) ToParams() (*model.UpsertAnnotationParams, error) {
+	params := &model.UpsertAnnotationParams{
+		NewsID:    req.NewsId,
+		Task:      req.Task,
+		Label:     req.Label,
+		Model:     req.Model,
+		Rationale: pgtype.Text{String: req.Rationale, Valid: req.Rationale != ""},
+		Owner:     req.Owner,
+		TaskHash:  pgtype.Text{String: req.TaskHash, Valid: true},
+	}
+	if req.Confidence != nil {
+		params.Confidence = pgtype.Float4{Float32: *req.Confidence, Valid: true}
+	}
+	return params, nil
+}
+
+func (srvc annotationService) Upsert(ctx context.Context, req *UpsertAnnotationRequest) (int64, error) {
+	if err := srvc.validate.Struct(req); err != nil {
+		return 0, err
+	}
+
+	params, _ := req.ToParams()
+	id, err := srvc.store.UpsertAnnotation(ctx, params)
+	return id, ParsePgxError(err)
+}
+
+// GetJobAnnotationStatsRequest gets the stats of the labels given for the
+// owner and the definition of the task of the job, of which TaskHash is the
+// hash.
+type GetJobAnnotationStatsRequest struct {
+	JobId    int64     `validate:"required,min=1"`
+	Model    string    `validate:"required,max=32"`
+	Owner    uuid.UUID `validate:"not_uuid_nil,uuid4"`
+	TaskHash string    `validate:"required,len=64,hexadecimal"`
+}
+
+func (req GetJobAnnotationStatsRequest) RequestName() string {
+	return "annotation-get-job-stats-req"
+}
+
+func (req GetJobAnnotationStatsRequest) ToParams() (*model.GetJobAnnotationStatsParams, error) {
+	return &model.GetJobAnnotationStatsParams{
+		JobID:    req.JobId,
+		Model:    req.Model,
+		Owner:    req.Owner,
+		TaskHash: req.TaskHash,
+	}, nil
+}
+
+// GetJobStats returns the label distribution of the analyzed news of the job
+// in the task, overall and per source.
+func (srvc annotationService) GetJobStats(ctx context.Context, req *GetJobAnnotationStatsRequest) ([]*model.GetJobAnnotationStatsRow, error) {
+	if err := srvc.validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	params, _ := req.ToParams()
+	rows, err := srvc.store.GetJobAnnotationStats(ctx, params)
+	return rows, ParsePgxError(err)
+}
+
+type GetAnnotatedNewsIdsRequest struct {
+	Model    string    `validate:"required,max=32"`
+	NewsIds  []int32   `validate:"required,min=1,dive,min=1"`
+	Owner    uuid.UUID `validate:"not_uuid_nil,uuid4"`
+	TaskHash string    `validate:"required,len=64,hexadecimal"`
+}
+
+func (req GetAnnotatedNewsIdsRequest) RequestName() string {
+	return "annotation-get-annotated-news-ids-req"
+}
+
+func (req GetAnnotatedNewsIdsRequest) ToParams() (*model.GetAnnotatedNewsIdsParams, error) {
+	return &model.GetAnnotatedNewsIdsParams{
+		Model:    req.Model,
+		NewsIds:  req.NewsIds,
+		Owner:    req.Owner,
+		TaskHash: req.TaskHash,
+	}, nil
+}
+
+// GetAnnotatedNewsIds returns the ids of the given news which have been
+// labelled by the model for the owner in the task of the hash.
+func (srvc annotationService) GetAnnotatedNewsIds(ctx context.Context, req *GetAnnotatedNewsIdsRequest) ([]int64, error) {
+	if err := srvc.validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	params, _ := req.ToParams()
+	ids, err := srvc.store.GetAnnotatedNewsIds(ctx, params)
+	return ids, ParsePgxError(err)
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/model"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 func (srvc apikeyService) Service() Service {
@@ -60,6 +61,14 @@ func (srvc apikeyService) CreateOrUpdate(ctx context.Context, r *APIKeyCreateOrU
 	if err := srvc.validate.Struct(r); err != nil {
 		return nil, err
 	}
+	// keys of the official servers have a fixed format, e.g. the ones of
+	// Anthropic are 108 characters long, while the ones of compatible servers
+	// are arbitrary or even absent
+	if r.BaseURL == "" {
+		if err := srvc.validate.Var(r.Key, "required,min=32,max=128"); err != nil {
+			return nil, err
+		}
+	}
 	params, _ := r.ToParams()
 	return srvc.store.DoCreateOrUpdateAPIKeyTx(ctx, params)
 }
@@ -76,9 +85,10 @@ func (req APIKeyCreateRequest) RequestName() string {
 
 func (req APIKeyCreateRequest) ToParams() (*model.CreateAPIKeyParams, error) {
 	return &model.CreateAPIKeyParams{
-		Owner: req.Owner,
-		ApiID: req.ApiID,
-		Key:   req.Key,
+		Owner:  req.Owner,
+		ApiID:  req.ApiID,
+		Key:    req.Key,
+		Models: []string{},
 	}, nil
 }
 
@@ -126,9 +136,12 @@ func (req APIKeyUpdateRequest) RequestName() string {
 }
 
 type APIKeyCreateOrUpdateRequest struct {
-	Owner uuid.UUID `validate:"not_uuid_nil,uuid4"`
-	ApiID int16     `validate:"required,min=1"`
-	Key   string    `validate:"required,min=32,max=64"`
+	Owner      uuid.UUID `validate:"not_uuid_nil,uuid4"`
+	ApiID      int16     `validate:"required,min=1"`
+	Key        string    `validate:"max=256"`
+	BaseURL    string    `validate:"omitempty,max=256,http_url"`
+	AuthHeader string    `validate:"omitempty,max=64,excludesall=: "`
+	Models     []string  `validate:"max=32,dive,required,max=64"`
 }
 
 func (req APIKeyCreateOrUpdateRequest) RequestName() string {
@@ -136,9 +149,16 @@ func (req APIKeyCreateOrUpdateRequest) RequestName() string {
 }
 
 func (req APIKeyCreateOrUpdateRequest) ToParams() (*model.CreateOrUpdateAPIKeyTxParams, error) {
-	return &model.CreateOrUpdateAPIKeyTxParams{
-		Owner: req.Owner,
-		ApiID: req.ApiID,
-		Key:   req.Key,
-	}, nil
+	params := &model.CreateOrUpdateAPIKeyTxParams{
+		Owner:      req.Owner,
+		ApiID:      req.ApiID,
+		Key:        req.Key,
+		BaseUrl:    pgtype.Text{String: req.BaseURL, Valid: req.BaseURL != ""},
+		AuthHeader: pgtype.Text{String: req.AuthHeader, Valid: req.AuthHeader != ""},
+		Models:     req.Models,
+	}
+	if params.Models == nil {
+		params.Models = []string{}
+	}
+	return params, nil
 }
@@ -2,16 +2,27 @@ package service
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/model"
+	ec "github.com/ChiaYuChang/NewsSentimentAnalyzer/pkgs/errorCode"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/pgvector/pgvector-go"
 )
 
+// CreateEmbeddingRequest creates an embedding, Score (1-5), Confidence (0-1)
+// and Rationale are optional and stored as null if they are not given. The
+// Embedding is stored at the size of the Model, which must be registered in
+// embedding_models.
 type CreateEmbeddingRequest struct {
-	NewsId    int64           `validate:"required,min=1"`
-	Model     string          `validate:"required,max=32"`
-	Embedding []float32       `validate:"required,max=1536"`
-	Sentiment model.Sentiment `validate:"required,oneof=positive negative neutral"`
+	NewsId     int64           `validate:"required,min=1"`
+	Model      string          `validate:"required,max=32"`
+	Embedding  []float32       `validate:"required,max=16000"`
+	Sentiment  model.Sentiment `validate:"required,oneof=very_positive positive neutral negative very_negative"`
+	Score      int16           `validate:"omitempty,min=1,max=5"`
+	Confidence *float32        `validate:"omitempty,min=0,max=1"`
+	Rationale  string          `validate:"max=2048"`
+	PromptId   int64           `validate:"omitempty,min=1"`
 }
 
 func (req CreateEmbeddingRequest) RequestName() string {
@@ -19,38 +30,163 @@ func (req CreateEmbeddingRequest) RequestName() string {
 }
 
 func (req CreateEmbeddingRequest) ToParams() (*model.CreateEmbeddingParams, error) {
-	return &model.CreateEmbeddingParams{
+	params := &model.CreateEmbeddingParams{
 		NewsID:    req.NewsId,
 		Model:     req.Model,
 		Embedding: pgvector.NewVector(req.Embedding),
 		Sentiment: req.Sentiment,
-	}, nil
+		Score:     pgtype.Int2{Int16: req.Score, Valid: req.Score != 0},
+		Rationale: pgtype.Text{String: req.Rationale, Valid: req.Rationale != ""},
+		PromptID:  pgtype.Int8{Int64: req.PromptId, Valid: req.PromptId != 0},
+	}
+	if req.Confidence != nil {
+		params.Confidence = pgtype.Float4{Float32: *req.Confidence, Valid: true}
+	}
+	return params, nil
 }
 
-// Create creates a new embedding
+// Create creates a new embedding, an embedding of an unknown model or whose
+// size is not the dimension of its model is rejected with ECBadRequest.
 func (srvc embeddingService) Create(ctx context.Context, req *CreateEmbeddingRequest) (int64, error) {
 	if err := srvc.validate.Struct(req); err != nil {
 		return 0, err
 	}
 
+	if err := srvc.checkDimension(ctx, req.Model, len(req.Embedding)); err != nil {
+		return 0, err
+	}
+
 	params, _ := req.ToParams()
 	id, err := srvc.store.CreateEmbedding(ctx, params)
 	return id, ParsePgxError(err)
 }
 
-// GetByJobId returns all embeddings by job id
-func (srvc embeddingService) GetByJobId(ctx context.Context, jobId int64) ([]*model.GetEmbeddingByJobIdRow, error) {
-	if err := srvc.validate.Var(jobId, "required,min=1"); err != nil {
+// checkDimension checks the sizes of the vectors against the dimension of the
+// embedding model in the registry.
+func (srvc embeddingService) checkDimension(ctx context.Context, name string, sizes ...int) error {
+	mdl, err := Service(srvc).EmbeddingModel().Get(ctx, name)
+	if err != nil {
+		if ec.MustGetEcErr(ec.ECPgxErrNoRows).IsEqual(err) {
+			return ec.MustGetEcErr(ec.ECBadRequest).
+				WithMessage("unknown embedding model").
+				WithDetails(name)
+		}
+		return err
+	}
+	for _, size := range sizes {
+		if int(mdl.Dim) != size {
+			return ec.MustGetEcErr(ec.ECBadRequest).
+				WithMessage("embedding dimension mismatch").
+				WithDetails(fmt.Sprintf("%s has %d dimensions, got %d", mdl.Name, mdl.Dim, size))
+		}
+	}
+	return nil
+}
+
+// EmbeddingChunk is the vector of a chunk of a news and the estimated tokens
+// of the chunk.
+type EmbeddingChunk struct {
+	NTokens   int32     `validate:"min=0"`
+	Embedding []float32 `validate:"required,max=16000"`
+}
+
+// CreateEmbeddingChunksRequest stores the vectors of the chunks of a news, in
+// order, which replace the ones stored before.
+type CreateEmbeddingChunksRequest struct {
+	NewsId int64            `validate:"required,min=1"`
+	Model  string           `validate:"required,max=32"`
+	Chunks []EmbeddingChunk `validate:"required,min=1,max=32767,dive"`
+}
+
+func (req CreateEmbeddingChunksRequest) RequestName() string {
+	return "embedding-create-chunks-req"
+}
+
+func (req CreateEmbeddingChunksRequest) ToParams() ([]*model.UpsertEmbeddingChunkParams, error) {
+	params := make([]*model.UpsertEmbeddingChunkParams, len(req.Chunks))
+	for i, c := range req.Chunks {
+		params[i] = &model.UpsertEmbeddingChunkParams{
+			NewsID:     req.NewsId,
+			Model:      req.Model,
+			ChunkIndex: int16(i),
+			NTokens:    c.NTokens,
+			Embedding:  pgvector.NewVector(c.Embedding),
+		}
+	}
+	return params, nil
+}
+
+// CreateChunks stores the vectors of the chunks of a news and removes the
+// chunks left from an earlier split of the news. A vector of which the size
+// is not the dimension of the model is rejected with ECBadRequest.
+func (srvc embeddingService) CreateChunks(ctx context.Context, req *CreateEmbeddingChunksRequest) error {
+	if err := srvc.validate.Struct(req); err != nil {
+		return err
+	}
+
+	sizes := make([]int, len(req.Chunks))
+	for i, c := range req.Chunks {
+		sizes[i] = len(c.Embedding)
+	}
+	if err := srvc.checkDimension(ctx, req.Model, sizes...); err != nil {
+		return err
+	}
+
+	params, _ := req.ToParams()
+	for _, p := range params {
+		if _, err := srvc.store.UpsertEmbeddingChunk(ctx, p); err != nil {
+			return ParsePgxError(err)
+		}
+	}
+	_, err := srvc.store.DeleteEmbeddingChunksFrom(ctx, &model.DeleteEmbeddingChunksFromParams{
+		NewsID:     req.NewsId,
+		Model:      req.Model,
+		ChunkIndex: int16(len(params)),
+	})
+	return ParsePgxError(err)
+}
+
+// GetEmbeddingByJobIdRequest gets the embeddings of the news of the job under
+// Model, the embedding model of the job.
+type GetEmbeddingByJobIdRequest struct {
+	JobId int64  `validate:"required,min=1"`
+	Model string `validate:"required,max=32"`
+	Next  int64  `validate:"min=0"`
+	N     int32  `validate:"required,min=1"`
+}
+
+func (req GetEmbeddingByJobIdRequest) RequestName() string {
+	return "embedding-get-by-job-id-req"
+}
+
+func (req GetEmbeddingByJobIdRequest) ToParams() (*model.GetEmbeddingByJobIdParams, error) {
+	return &model.GetEmbeddingByJobIdParams{
+		JobID: req.JobId,
+		Model: req.Model,
+		Next:  req.Next,
+		N:     req.N,
+	}, nil
+}
+
+// GetByJobId returns at most N embeddings of the job, with their news, whose
+// id is greater than Next in ascending id order
+func (srvc embeddingService) GetByJobId(ctx context.Context, req *GetEmbeddingByJobIdRequest) ([]*model.GetEmbeddingByJobIdRow, error) {
+	if err := srvc.validate.Struct(req); err != nil {
 		return nil, err
 	}
 
-	rows, err := srvc.store.GetEmbeddingByJobId(ctx, jobId)
+	params, _ := req.ToParams()
+	rows, err := srvc.store.GetEmbeddingByJobId(ctx, params)
 	return rows, ParsePgxError(err)
 }
 
+// GetEmbeddingByNewsIdsAndModelRequest gets the embeddings of the news whose
+// sentiment was classified with the prompt, or with the default prompt of the
+// analyzer if PromptId is 0.
 type GetEmbeddingByNewsIdsAndModelRequest struct {
-	Model   string  `validate:"required,max=32"`
-	NewsIds []int32 `validate:"required,min=1,dive,min=1"`
+	Model    string  `validate:"required,max=32"`
+	NewsIds  []int32 `validate:"required,min=1,dive,min=1"`
+	PromptId int64   `validate:"omitempty,min=1"`
 }
 
 func (req GetEmbeddingByNewsIdsAndModelRequest) RequestName() string {
@@ -59,8 +195,9 @@ func (req GetEmbeddingByNewsIdsAndModelRequest) RequestName() string {
 
 func (req GetEmbeddingByNewsIdsAndModelRequest) ToParams() (*model.GetEmbeddingByNewsIdsAndModelParams, error) {
 	return &model.GetEmbeddingByNewsIdsAndModelParams{
-		Model:   req.Model,
-		NewsIds: req.NewsIds,
+		Model:    req.Model,
+		NewsIds:  req.NewsIds,
+		PromptID: pgtype.Int8{Int64: req.PromptId, Valid: req.PromptId != 0},
 	}, nil
 }
 
@@ -73,3 +210,64 @@ func (srvc embeddingService) GetEmbeddingByNewsIdsAndModel(ctx context.Context,
 	rows, err := srvc.store.GetEmbeddingByNewsIdsAndModel(ctx, params)
 	return rows, ParsePgxError(err)
 }
+
+type CompareJobSentimentsRequest struct {
+	JobA   int64  `validate:"required,min=1"`
+	ModelA string `validate:"required,max=32"`
+	JobB   int64  `validate:"required,min=1"`
+	ModelB string `validate:"required,max=32"`
+}
+
+func (req CompareJobSentimentsRequest) RequestName() string {
+	return "embedding-compare-job-sentiments-req"
+}
+
+func (req CompareJobSentimentsRequest) ToParams() (*model.CompareJobSentimentsParams, error) {
+	return &model.CompareJobSentimentsParams{
+		ModelA: req.ModelA,
+		ModelB: req.ModelB,
+		JobA:   req.JobA,
+		JobB:   req.JobB,
+	}, nil
+}
+
+// CompareJobSentiments returns the sentiments of the news shared by the two
+// jobs, as analyzed by their own model
+func (srvc embeddingService) CompareJobSentiments(ctx context.Context, req *CompareJobSentimentsRequest) ([]*model.CompareJobSentimentsRow, error) {
+	if err := srvc.validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	params, _ := req.ToParams()
+	rows, err := srvc.store.CompareJobSentiments(ctx, params)
+	return rows, ParsePgxError(err)
+}
+
+type GetJobSentimentStatsRequest struct {
+	JobId int64  `validate:"required,min=1"`
+	Model string `validate:"required,max=32"`
+}
+
+func (req GetJobSentimentStatsRequest) RequestName() string {
+	return "embedding-get-job-sentiment-stats-req"
+}
+
+func (req GetJobSentimentStatsRequest) ToParams() (*model.GetJobSentimentStatsParams, error) {
+	return &model.GetJobSentimentStatsParams{
+		JobID: req.JobId,
+		Model: req.Model,
+	}, nil
+}
+
+// GetJobSentimentStats returns the sentiment distribution of the analyzed news
+// of the job, overall and per source, category, publish day and language. The
+// overall row is returned even if no news has been analyzed.
+func (srvc embeddingService) GetJobSentimentStats(ctx context.Context, req *GetJobSentimentStatsRequest) ([]*model.GetJobSentimentStatsRow, error) {
+	if err := srvc.validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	params, _ := req.ToParams()
+	rows, err := srvc.store.GetJobSentimentStats(ctx, params)
+	return rows, ParsePgxError(err)
+}
@@ -0,0 +1,18 @@
+package service
+
+import (
+	"context"
+
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/model"
+)
+
+// Get returns the dimension, the provider and the distance metric of the
+// embedding model, or ECPgxErrNoRows if it is not registered.
+func (srvc embeddingModelService) Get(ctx context.Context, name string) (*model.GetEmbeddingModelRow, error) {
+	if err := srvc.validate.Var(name, "required,max=32"); err != nil {
+		return nil, err
+	}
+
+	row, err := srvc.store.GetEmbeddingModel(ctx, name)
+	return row, ParsePgxError(err)
+}
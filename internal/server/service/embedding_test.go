@@ -8,8 +8,11 @@ import (
 	"testing"
 
 	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/model"
+	mock_model "github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/model/mockdb"
 	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/server/service"
 	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/server/validator"
+	ec "github.com/ChiaYuChang/NewsSentimentAnalyzer/pkgs/errorCode"
+	"github.com/golang/mock/gomock"
 	"github.com/jackc/pgx/v5"
 	"github.com/stretchr/testify/require"
 )
@@ -26,8 +29,8 @@ func TestXxx(t *testing.T) {
 	val, _ := validator.GetDefaultValidate()
 	srvc := service.NewService(model.NewPGXStore(conn), val)
 
-	embd := make([]float32, 1536)
-	for i := 0; i < 384; i++ {
+	embd := make([]float32, 384)
+	for i := range embd {
 		embd[i] = rand.Float32()
 	}
 
@@ -42,3 +45,116 @@ func TestXxx(t *testing.T) {
 	require.NoError(t, err)
 	require.NotEqual(t, 0, id)
 }
+
+func TestCreateEmbeddingDimension(t *testing.T) {
+	val, _ := validator.GetDefaultValidate()
+
+	type testCase struct {
+		Name  string
+		Model string
+		Dim   int
+		OK    bool
+	}
+
+	tcs := []testCase{
+		{Name: "Native size", Model: "embed-multilingual-light-v3.0", Dim: 384, OK: true},
+		{Name: "Padded", Model: "embed-multilingual-light-v3.0", Dim: 1536},
+		{Name: "Larger than 1536", Model: "text-embedding-3-large", Dim: 3072, OK: true},
+		{Name: "Unknown model", Model: "unknown", Dim: 384},
+	}
+
+	dims := map[string]int32{
+		"embed-multilingual-light-v3.0": 384,
+		"text-embedding-3-large":        3072,
+	}
+
+	for i := range tcs {
+		tc := tcs[i]
+		t.Run(tc.Name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			store := mock_model.NewMockStore(ctl)
+			store.EXPECT().
+				GetEmbeddingModel(gomock.Any(), tc.Model).
+				Times(1).
+				DoAndReturn(func(_ context.Context, name string) (*model.GetEmbeddingModelRow, error) {
+					dim, ok := dims[name]
+					if !ok {
+						return nil, pgx.ErrNoRows
+					}
+					return &model.GetEmbeddingModelRow{Name: name, Dim: dim, Metric: "cosine"}, nil
+				})
+			if tc.OK {
+				store.EXPECT().
+					CreateEmbedding(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, params *model.CreateEmbeddingParams) (int64, error) {
+						require.Len(t, params.Embedding.Slice(), tc.Dim)
+						return 1, nil
+					})
+			}
+
+			srvc := service.NewService(store, val)
+			id, err := srvc.Embedding().Create(context.Background(), &service.CreateEmbeddingRequest{
+				NewsId:    1,
+				Model:     tc.Model,
+				Embedding: make([]float32, tc.Dim),
+				Sentiment: model.SentimentPositive,
+			})
+			if !tc.OK {
+				require.Error(t, err)
+				require.True(t, ec.MustGetEcErr(ec.ECBadRequest).IsEqual(err))
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, int64(1), id)
+		})
+	}
+}
+
+func TestCreateEmbeddingChunks(t *testing.T) {
+	val, _ := validator.GetDefaultValidate()
+
+	ctl := gomock.NewController(t)
+	store := mock_model.NewMockStore(ctl)
+	store.EXPECT().
+		GetEmbeddingModel(gomock.Any(), "embed-multilingual-light-v3.0").
+		Times(2).
+		Return(&model.GetEmbeddingModelRow{Name: "embed-multilingual-light-v3.0", Dim: 384, Metric: "cosine"}, nil)
+	gomock.InOrder(
+		store.EXPECT().
+			UpsertEmbeddingChunk(gomock.Any(), gomock.Any()).
+			Times(2).
+			DoAndReturn(func(_ context.Context, params *model.UpsertEmbeddingChunkParams) (int64, error) {
+				require.Equal(t, int32(10*(params.ChunkIndex+1)), params.NTokens)
+				return 1, nil
+			}),
+		// the chunks left from an earlier split are removed
+		store.EXPECT().
+			DeleteEmbeddingChunksFrom(gomock.Any(), &model.DeleteEmbeddingChunksFromParams{
+				NewsID: 1, Model: "embed-multilingual-light-v3.0", ChunkIndex: 2,
+			}).
+			Return(int64(1), nil),
+	)
+
+	srvc := service.NewService(store, val)
+	err := srvc.Embedding().CreateChunks(context.Background(), &service.CreateEmbeddingChunksRequest{
+		NewsId: 1,
+		Model:  "embed-multilingual-light-v3.0",
+		Chunks: []service.EmbeddingChunk{
+			{NTokens: 10, Embedding: make([]float32, 384)},
+			{NTokens: 20, Embedding: make([]float32, 384)},
+		},
+	})
+	require.NoError(t, err)
+
+	// nothing is stored if a chunk is not of the size of the model
+	err = srvc.Embedding().CreateChunks(context.Background(), &service.CreateEmbeddingChunksRequest{
+		NewsId: 1,
+		Model:  "embed-multilingual-light-v3.0",
+		Chunks: []service.EmbeddingChunk{
+			{NTokens: 10, Embedding: make([]float32, 384)},
+			{NTokens: 20, Embedding: make([]float32, 1536)},
+		},
+	})
+	require.True(t, ec.MustGetEcErr(ec.ECBadRequest).IsEqual(err))
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/model"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// UpsertEntitySentimentRequest stores the sentiment of a news toward an
+// entity, which replaces the one given by the same model for the same owner
+// and prompt before. PromptId is 0 for the default prompt of the analyzer.
+type UpsertEntitySentimentRequest struct {
+	NewsId     int64           `validate:"required,min=1"`
+	Entity     string          `validate:"required,max=64"`
+	Model      string          `validate:"required,max=32"`
+	Sentiment  model.Sentiment `validate:"required,oneof=very_positive positive neutral negative very_negative"`
+	Confidence *float32        `validate:"omitempty,min=0,max=1"`
+	Rationale  string          `validate:"max=2048"`
+	Owner      uuid.UUID       `validate:"not_uuid_nil,uuid4"`
+	PromptId   int64           `validate:"omitempty,min=1"`
+}
+
+func (req UpsertEntitySentimentRequest) RequestName() string {
+	return "entity-sentiment-upsert-req"
+}
+
+func (req UpsertEntitySentimentRequest) ToParams() (*model.UpsertEntitySentimentParams, error) {
+	params := &model.UpsertEntitySentimentParams{
+		NewsID:    req.NewsId,
+		Entity:    req.Entity,
+		Model:     req.Model,
+		Sentiment: req.Sentiment,
+		Score:     req.Sentiment.Score(),
+		Rationale: pgtype.Text{String: req.Rationale, Valid: req.Rationale != ""},
+		Owner:     req.Owner,
+		PromptID:  pgtype.Int8{Int64: req.PromptId, Valid: req.PromptId != 0},
+	}
+	if req.Confidence != nil {
+		params.Confidence = pgtype.Float4{Float32: *req.Confidence, Valid: true}
+	}
+	return params, nil
+}
+
+func (srvc entitySentimentService) Upsert(ctx context.Context, req *UpsertEntitySentimentRequest) (int64, error) {
+	if err := srvc.validate.Struct(req); err != nil {
+		return 0, err
+	}
+
+	params, _ := req.ToParams()
+	id, err := srvc.store.UpsertEntitySentiment(ctx, params)
+	return id, ParsePgxError(err)
+}
+
+// GetJobEntitySentimentStatsRequest gets the stats of the sentiments
+// classified for the owner and the prompt of the job, or the default prompt
+// of the analyzer if PromptId is 0.
+type GetJobEntitySentimentStatsRequest struct {
+	JobId    int64     `validate:"required,min=1"`
+	Model    string    `validate:"required,max=32"`
+	Entities []string  `validate:"required,min=1,max=20,dive,required,max=64"`
+	Owner    uuid.UUID `validate:"not_uuid_nil,uuid4"`
+	PromptId int64     `validate:"omitempty,min=1"`
+}
+
+func (req GetJobEntitySentimentStatsRequest) RequestName() string {
+	return "entity-sentiment-get-job-stats-req"
+}
+
+func (req GetJobEntitySentimentStatsRequest) ToParams() (*model.GetJobEntitySentimentStatsParams, error) {
+	return &model.GetJobEntitySentimentStatsParams{
+		JobID:    req.JobId,
+		Model:    req.Model,
+		Entities: req.Entities,
+		Owner:    req.Owner,
+		PromptID: pgtype.Int8{Int64: req.PromptId, Valid: req.PromptId != 0},
+	}, nil
+}
+
+// GetJobStats returns the sentiment distribution of the analyzed news of the
+// job toward each of the entities, overall and per source.
+func (srvc entitySentimentService) GetJobStats(ctx context.Context, req *GetJobEntitySentimentStatsRequest) ([]*model.GetJobEntitySentimentStatsRow, error) {
+	if err := srvc.validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	params, _ := req.ToParams()
+	rows, err := srvc.store.GetJobEntitySentimentStats(ctx, params)
+	return rows, ParsePgxError(err)
+}
@@ -5,9 +5,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
+	"time"
 
 	"github.com/ChiaYuChang/NewsSentimentAnalyzer/global"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client/option"
 	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/model"
+	ec "github.com/ChiaYuChang/NewsSentimentAnalyzer/pkgs/errorCode"
 	"github.com/google/uuid"
 
 	"github.com/jackc/pgx/v5/pgtype"
@@ -113,6 +116,152 @@ func (r JobUpdateStatusRequest) ToParams() (*model.UpdateJobStatusParams, error)
 	}, nil
 }
 
+type JobUpdateStatusFromRequest struct {
+	Status string    `validate:"required,min=1,job_status"`
+	From   []string  `validate:"required,min=1,job_status"`
+	ID     int64     `validate:"required,min=1"`
+	Owner  uuid.UUID `validate:"not_uuid_nil,uuid4"`
+}
+
+func (r JobUpdateStatusFromRequest) RequestName() string {
+	return "job-udpate-status-from-req"
+}
+
+func (r JobUpdateStatusFromRequest) ToParams() (*model.UpdateJobStatusFromParams, error) {
+	return &model.UpdateJobStatusFromParams{
+		ToStatus:   model.JobStatus(r.Status),
+		ID:         r.ID,
+		Owner:      r.Owner,
+		FromStatus: r.From,
+	}, nil
+}
+
+type JobCancelRequest struct {
+	ID    int64     `validate:"required,min=1"`
+	Owner uuid.UUID `validate:"not_uuid_nil,uuid4"`
+}
+
+func (r JobCancelRequest) RequestName() string {
+	return "job-cancel-req"
+}
+
+func (r JobCancelRequest) ToParams() (*model.UpdateJobStatusFromParams, error) {
+	return &model.UpdateJobStatusFromParams{
+		ToStatus: model.JobStatusCanceled,
+		ID:       r.ID,
+		Owner:    r.Owner,
+		FromStatus: []string{
+			string(model.JobStatusCreated),
+			string(model.JobStatusRunning),
+		},
+	}, nil
+}
+
+type JobCloneRequest struct {
+	Owner    uuid.UUID `validate:"not_uuid_nil,uuid4"`
+	ID       int64     `validate:"required,min=1"`
+	Ulid     string    `validate:"required,len=26"`
+	LlmApiID int16     `validate:"required,min=1"`
+	LlmQuery string    `validate:"required,json"`
+	// the quota of the owner, nil if the job is not counted in any quota
+	Quota *global.QuotaOption `validate:"-"`
+	// the jobs of the owner submitted since Since are counted in the quota
+	Since time.Time `validate:"required_with=Quota"`
+}
+
+func (r JobCloneRequest) RequestName() string {
+	return "job-clone-req"
+}
+
+func (r JobCloneRequest) ToParams() (*model.CloneJobTxParams, error) {
+	params := &model.CloneJobTxParams{
+		Owner:    r.Owner,
+		ID:       r.ID,
+		Ulid:     r.Ulid,
+		LlmApiID: r.LlmApiID,
+		LlmQuery: []byte(r.LlmQuery),
+	}
+	if r.Quota != nil {
+		params.CheckQuota = quotaCheck(*r.Quota)
+		params.QuotaSince = pgtype.Timestamptz{Time: r.Since, Valid: true}
+	}
+	return params, nil
+}
+
+type JobMarkPartialRequest struct {
+	ID    int64     `validate:"required,min=1"`
+	Owner uuid.UUID `validate:"not_uuid_nil,uuid4"`
+}
+
+func (r JobMarkPartialRequest) RequestName() string {
+	return "job-mark-partial-req"
+}
+
+func (r JobMarkPartialRequest) ToParams() (*model.MarkJobPartialParams, error) {
+	return &model.MarkJobPartialParams{
+		ID:    r.ID,
+		Owner: r.Owner,
+	}, nil
+}
+
+type JobCreateProgressRequest struct {
+	JobId        int64 `validate:"required,min=1"`
+	Total        int32 `validate:"min=0"`
+	Parsed       int32 `validate:"min=0"`
+	LangDetected int32 `validate:"min=0"`
+	Failed       int32 `validate:"min=0"`
+}
+
+func (r JobCreateProgressRequest) RequestName() string {
+	return "job-create-progress-req"
+}
+
+func (r JobCreateProgressRequest) ToParams() (*model.CreateJobProgressParams, error) {
+	return &model.CreateJobProgressParams{
+		JobID:        r.JobId,
+		Total:        r.Total,
+		Parsed:       r.Parsed,
+		LangDetected: r.LangDetected,
+		Failed:       r.Failed,
+	}, nil
+}
+
+type JobIncrProgressRequest struct {
+	JobId           int64 `validate:"required,min=1"`
+	Embedded        int32 `validate:"min=0"`
+	SentimentScored int32 `validate:"min=0"`
+	Failed          int32 `validate:"min=0"`
+}
+
+func (r JobIncrProgressRequest) RequestName() string {
+	return "job-incr-progress-req"
+}
+
+func (r JobIncrProgressRequest) ToParams() (*model.IncrJobProgressParams, error) {
+	return &model.IncrJobProgressParams{
+		JobID:           r.JobId,
+		Embedded:        r.Embedded,
+		SentimentScored: r.SentimentScored,
+		Failed:          r.Failed,
+	}, nil
+}
+
+type JobGetProgressRequest struct {
+	Owner uuid.UUID `validate:"not_uuid_nil,uuid4"`
+	Id    int64     `validate:"required,min=1"`
+}
+
+func (r JobGetProgressRequest) RequestName() string {
+	return "job-get-progress-req"
+}
+
+func (r JobGetProgressRequest) ToParams() (*model.GetJobProgressParams, error) {
+	return &model.GetJobProgressParams{
+		Owner: r.Owner,
+		ID:    r.Id,
+	}, nil
+}
+
 type JobGetWithJIdRangeFilterRequest struct {
 	Owner uuid.UUID `validate:"not_uuid_nil,uuid4"`
 	FJid  int32     `validate:"min=1"`
@@ -432,6 +581,87 @@ func (srvc jobService) UpdateStatus(ctx context.Context,
 	return n, ParsePgxError(err)
 }
 
+// update job status only if its current status is one of the given status
+func (srvc jobService) UpdateStatusFrom(ctx context.Context,
+	req *JobUpdateStatusFromRequest) (int64, error) {
+	if err := srvc.validate.Struct(req); err != nil {
+		return 0, err
+	}
+
+	params, _ := req.ToParams()
+	n, err := srvc.store.UpdateJobStatusFrom(ctx, params)
+	return n, ParsePgxError(err)
+}
+
+// cancel a created or running job, n = 0 if there is no such job
+func (srvc jobService) Cancel(ctx context.Context, req *JobCancelRequest) (int64, error) {
+	if err := srvc.validate.Struct(req); err != nil {
+		return 0, err
+	}
+
+	params, _ := req.ToParams()
+	n, err := srvc.store.UpdateJobStatusFrom(ctx, params)
+	return n, ParsePgxError(err)
+}
+
+// create a job with the news of the given job to be analyzed with another
+// analyzer, pgx.ErrNoRows if there is no such job
+func (srvc jobService) Clone(ctx context.Context, req *JobCloneRequest) (*model.CloneJobTxResult, error) {
+	if err := srvc.validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	params, _ := req.ToParams()
+	result, err := srvc.store.DoCloneJobTx(ctx, params)
+	if ecErr, ok := err.(*ec.Error); ok {
+		// e.g. the quota has been exceeded
+		return result, ecErr
+	}
+	return result, ParsePgxError(err)
+}
+
+// mark a job whose results are incomplete
+func (srvc jobService) MarkPartial(ctx context.Context, req *JobMarkPartialRequest) (int64, error) {
+	if err := srvc.validate.Struct(req); err != nil {
+		return 0, err
+	}
+
+	params, _ := req.ToParams()
+	n, err := srvc.store.MarkJobPartial(ctx, params)
+	return n, ParsePgxError(err)
+}
+
+// set the counters of the parsing stage of a job
+func (srvc jobService) CreateProgress(ctx context.Context, req *JobCreateProgressRequest) error {
+	if err := srvc.validate.Struct(req); err != nil {
+		return err
+	}
+
+	params, _ := req.ToParams()
+	return ParsePgxError(srvc.store.CreateJobProgress(ctx, params))
+}
+
+// add to the counters of the analyzing stage of a job
+func (srvc jobService) IncrProgress(ctx context.Context, req *JobIncrProgressRequest) error {
+	if err := srvc.validate.Struct(req); err != nil {
+		return err
+	}
+
+	params, _ := req.ToParams()
+	return ParsePgxError(srvc.store.IncrJobProgress(ctx, params))
+}
+
+// get the progress of a job, counters are zeros if the job has no progress yet
+func (srvc jobService) GetProgress(ctx context.Context, req *JobGetProgressRequest) (*model.GetJobProgressRow, error) {
+	if err := srvc.validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	params, _ := req.ToParams()
+	row, err := srvc.store.GetJobProgress(ctx, params)
+	return row, ParsePgxError(err)
+}
+
 // clean job with deleted_at
 func (srvc jobService) CleanUp(ctx context.Context) (n int64, err error) {
 	return srvc.store.CleanUpJobs(ctx)
@@ -443,6 +673,102 @@ func (srvc jobService) Count(ctx context.Context, owner uuid.UUID) (*model.Count
 	return result, ParsePgxError(err)
 }
 
+type JobQuotaUsageRequest struct {
+	Owner uuid.UUID `validate:"not_uuid_nil,uuid4"`
+	Since time.Time `validate:"required"`
+}
+
+func (r JobQuotaUsageRequest) RequestName() string {
+	return "job-quota-usage-req"
+}
+
+func (r JobQuotaUsageRequest) ToParams() (*model.GetJobQuotaUsageParams, error) {
+	return &model.GetJobQuotaUsageParams{
+		Since: pgtype.Timestamptz{Time: r.Since, Valid: true},
+		Owner: r.Owner,
+	}, nil
+}
+
+type JobCheckQuotaRequest struct {
+	Owner     uuid.UUID          `validate:"not_uuid_nil,uuid4"`
+	Since     time.Time          `validate:"required"`
+	NArticles int                `validate:"min=0"`
+	Quota     global.QuotaOption `validate:"-"`
+}
+
+func (r JobCheckQuotaRequest) RequestName() string {
+	return "job-check-quota-req"
+}
+
+// count the running jobs and the jobs submitted since r.Since of the owner
+func (srvc jobService) GetQuotaUsage(ctx context.Context, r *JobQuotaUsageRequest) (*model.GetJobQuotaUsageRow, error) {
+	if err := srvc.validate.Struct(r); err != nil {
+		return nil, err
+	}
+	params, _ := r.ToParams()
+	row, err := srvc.store.GetJobQuotaUsage(ctx, params)
+	return row, ParsePgxError(err)
+}
+
+// check whether the owner could submit one more job with r.NArticles articles,
+// it returns an ECQuotaExceeded error if any limit of r.Quota would be exceeded
+func (srvc jobService) CheckQuota(ctx context.Context, r *JobCheckQuotaRequest) error {
+	if err := srvc.validate.Struct(r); err != nil {
+		return err
+	}
+
+	if err := checkArticleQuota(r.Quota, int64(r.NArticles)); err != nil {
+		return err
+	}
+
+	if r.Quota.MaxRunningJobs < 1 && r.Quota.MaxJobsPerDay < 1 {
+		return nil
+	}
+
+	usage, err := srvc.GetQuotaUsage(ctx, &JobQuotaUsageRequest{Owner: r.Owner, Since: r.Since})
+	if err != nil {
+		return err
+	}
+	return checkJobQuota(r.Quota, usage)
+}
+
+// quotaCheck checks a new job against quota in the transaction creating it
+func quotaCheck(quota global.QuotaOption) model.QuotaCheckFun {
+	return func(usage *model.GetJobQuotaUsageRow, nArticles int64) error {
+		if err := checkArticleQuota(quota, nArticles); err != nil {
+			return err
+		}
+		return checkJobQuota(quota, usage)
+	}
+}
+
+func checkArticleQuota(quota global.QuotaOption, nArticles int64) error {
+	if quota.MaxArticlesPerJob > 0 && nArticles > int64(quota.MaxArticlesPerJob) {
+		return ec.MustGetEcErr(ec.ECQuotaExceeded).
+			WithMessage(fmt.Sprintf("at most %d articles could be analyzed in a job, %d were selected",
+				quota.MaxArticlesPerJob, nArticles)).
+			WithDetails("max articles per job")
+	}
+	return nil
+}
+
+func checkJobQuota(quota global.QuotaOption, usage *model.GetJobQuotaUsageRow) error {
+	if quota.MaxRunningJobs > 0 && usage.NRunning >= int64(quota.MaxRunningJobs) {
+		return ec.MustGetEcErr(ec.ECQuotaExceeded).
+			WithMessage(fmt.Sprintf("at most %d jobs could be running at the same time, please wait until one of them is done",
+				quota.MaxRunningJobs)).
+			WithDetails("max running jobs")
+	}
+
+	if quota.MaxJobsPerDay > 0 && usage.NToday >= int64(quota.MaxJobsPerDay) {
+		return ec.MustGetEcErr(ec.ECQuotaExceeded).
+			WithMessage(fmt.Sprintf("at most %d jobs could be submitted a day, please try again tomorrow",
+				quota.MaxJobsPerDay)).
+			WithDetails("max jobs per day")
+	}
+	return nil
+}
+
 func (srvc jobService) GetOldestNCreatedJobsForEachUser(ctx context.Context, n int) (
 	// []*model.GetOldestNCreatedJobsForEachUserRow, error) {
 	[]CreatedJobsRow, error) {
@@ -468,7 +794,7 @@ func (r CreatedJobsRow) String() string {
 
 	obj := struct {
 		model.GetOldestNCreatedJobsForEachUserRow
-		AnalyzerOption *AnalyzerOption `json:"llm_query"`
+		AnalyzerOption *option.AnalyzerOption `json:"llm_query"`
 	}{
 		GetOldestNCreatedJobsForEachUserRow: *r.GetOldestNCreatedJobsForEachUserRow,
 		AnalyzerOption:                      r.AnalyzerOptions(),
@@ -478,37 +804,8 @@ func (r CreatedJobsRow) String() string {
 	return string(b)
 }
 
-func (r CreatedJobsRow) AnalyzerOptions() *AnalyzerOption {
-	var aopt *AnalyzerOption
+func (r CreatedJobsRow) AnalyzerOptions() *option.AnalyzerOption {
+	var aopt *option.AnalyzerOption
 	json.Unmarshal(r.LlmQuery, &aopt)
 	return aopt
 }
-
-type AnalyzerOption struct {
-	IsTestData               bool   `                  json:"is_test_data,omitempty" redis:"is_test_data"`
-	APIName                  string `form:"api"        json:"api"                    redis:"api"`
-	APIId                    int    `form:"llm-api-id" json:"id,omitempty"           redis:"id"`
-	EmbeddingOptions         `json:"embedding-options,omitempty"                     redis:"embedding-options"`
-	SentimentAnalysisOptions `json:"sentiment-analysis-options,omitempty"            redis:"sentiment-analysis-options"`
-}
-
-func (opt AnalyzerOption) String() string {
-	return opt.ToString("", "    ")
-}
-
-func (opt AnalyzerOption) ToString(prefix, indent string) string {
-	b, _ := json.MarshalIndent(opt, prefix, indent)
-	return string(b)
-}
-
-type EmbeddingOptions struct {
-	Embedding      bool   `form:"do-embedding"    json:"embedding"             redis:"embedding"`
-	InputType      string `form:"input-type"      json:"input_type,omitempty" redis:"input_type"`
-	EmbeddingModel string `form:"embedding-model" json:"embedding_model"       redis:"embedding_model"`
-}
-
-type SentimentAnalysisOptions struct {
-	Sentiment bool   `form:"do-sentiment" json:"sentiment"              redis:"sentiment"`
-	MaxTokens int    `form:"max-tokens"   json:"max_tokens,omitempty"  redis:"max_tokens"`
-	Truncate  string `form:"truncate"     json:"truncate,omitempty"    redis:"truncate"`
-}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/model"
+	ec "github.com/ChiaYuChang/NewsSentimentAnalyzer/pkgs/errorCode"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type JobItemFailRequest struct {
+	ID            int64     `validate:"required,min=1"`
+	Stage         string    `validate:"required,oneof=retrying dead"`
+	LastError     string    `validate:"-"`
+	NextAttemptAt time.Time `validate:"required"`
+}
+
+func (r JobItemFailRequest) RequestName() string {
+	return "job-item-fail-req"
+}
+
+func (r JobItemFailRequest) ToParams() (*model.MarkJobItemFailedParams, error) {
+	return &model.MarkJobItemFailedParams{
+		Stage:         model.JobItemStage(r.Stage),
+		LastError:     pgtype.Text{String: r.LastError, Valid: r.LastError != ""},
+		NextAttemptAt: pgtype.Timestamptz{Time: r.NextAttemptAt, Valid: true},
+		ID:            r.ID,
+	}, nil
+}
+
+// JobItemDoneRequest settles the item as done, EmbeddingId is the embedding
+// the job got for the news of the item.
+type JobItemDoneRequest struct {
+	ID          int64 `validate:"required,min=1"`
+	EmbeddingId int64 `validate:"required,min=1"`
+}
+
+func (r JobItemDoneRequest) RequestName() string {
+	return "job-item-done-req"
+}
+
+func (r JobItemDoneRequest) ToParams() (*model.MarkJobItemDoneParams, error) {
+	return &model.MarkJobItemDoneParams{
+		EmbeddingID: pgtype.Int8{Int64: r.EmbeddingId, Valid: true},
+		ID:          r.ID,
+	}, nil
+}
+
+type JobItemGetByStageRequest struct {
+	Owner uuid.UUID `validate:"not_uuid_nil,uuid4"`
+	JobId int64     `validate:"required,min=1"`
+	Stage string    `validate:"required,oneof=pending retrying done dead"`
+}
+
+func (r JobItemGetByStageRequest) RequestName() string {
+	return "job-item-get-by-stage-req"
+}
+
+func (r JobItemGetByStageRequest) ToParams() (*model.GetJobItemsByStageParams, error) {
+	return &model.GetJobItemsByStageParams{
+		Owner: r.Owner,
+		ID:    r.JobId,
+		Stage: model.JobItemStage(r.Stage),
+	}, nil
+}
+
+type JobItemRequeueRequest struct {
+	Owner  uuid.UUID `validate:"not_uuid_nil,uuid4"`
+	JobId  int64     `validate:"required,min=1"`
+	ItemId int64     `validate:"min=0"`
+}
+
+func (r JobItemRequeueRequest) RequestName() string {
+	return "job-item-requeue-req"
+}
+
+func (r JobItemRequeueRequest) ToParams() (*model.RequeueJobItemsTxParams, error) {
+	return &model.RequeueJobItemsTxParams{
+		JobID:  r.JobId,
+		Owner:  r.Owner,
+		ItemID: r.ItemId,
+	}, nil
+}
+
+// create an item for each news of the job, existing items are kept
+func (srvc jobItemService) Create(ctx context.Context, jobId int64) (int64, error) {
+	if err := srvc.validate.Var(jobId, "required,min=1"); err != nil {
+		return 0, err
+	}
+	n, err := srvc.store.CreateJobItems(ctx, jobId)
+	return n, ParsePgxError(err)
+}
+
+// get the pending or retrying items whose next attempt is due
+func (srvc jobItemService) GetDue(ctx context.Context, jobId int64) ([]*model.GetDueJobItemsRow, error) {
+	if err := srvc.validate.Var(jobId, "required,min=1"); err != nil {
+		return nil, err
+	}
+	rows, err := srvc.store.GetDueJobItems(ctx, jobId)
+	return rows, ParsePgxError(err)
+}
+
+// get the time of the earliest attempt among the unsettled items of the job
+func (srvc jobItemService) GetNextAttempt(ctx context.Context, jobId int64) (time.Time, error) {
+	if err := srvc.validate.Var(jobId, "required,min=1"); err != nil {
+		return time.Time{}, err
+	}
+	next, err := srvc.store.GetNextJobItemAttempt(ctx, jobId)
+	return next.Time, ParsePgxError(err)
+}
+
+func (srvc jobItemService) GetByStage(ctx context.Context, req *JobItemGetByStageRequest) (
+	[]*model.GetJobItemsByStageRow, error) {
+	if err := srvc.validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	params, _ := req.ToParams()
+	rows, err := srvc.store.GetJobItemsByStage(ctx, params)
+	return rows, ParsePgxError(err)
+}
+
+func (srvc jobItemService) MarkDone(ctx context.Context, req *JobItemDoneRequest) (int64, error) {
+	if err := srvc.validate.Struct(req); err != nil {
+		return 0, err
+	}
+
+	params, _ := req.ToParams()
+	n, err := srvc.store.MarkJobItemDone(ctx, params)
+	return n, ParsePgxError(err)
+}
+
+// record a failed attempt, the item is either retried later or dead
+func (srvc jobItemService) MarkFailed(ctx context.Context, req *JobItemFailRequest) (int64, error) {
+	if err := srvc.validate.Struct(req); err != nil {
+		return 0, err
+	}
+
+	params, _ := req.ToParams()
+	n, err := srvc.store.MarkJobItemFailed(ctx, params)
+	return n, ParsePgxError(err)
+}
+
+// put dead items back to pending, all dead items of the job are requeued if
+// ItemId is 0
+func (srvc jobItemService) Requeue(ctx context.Context, req *JobItemRequeueRequest) (
+	*model.RequeueJobItemsTxResult, error) {
+	if err := srvc.validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	params, _ := req.ToParams()
+	result, err := srvc.store.DoRequeueJobItemsTx(ctx, params)
+	if ecErr, ok := err.(*ec.Error); ok {
+		// e.g. the job has been canceled
+		return result, ecErr
+	}
+	return result, ParsePgxError(err)
+}
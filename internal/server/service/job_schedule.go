@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/model"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/pkgs/cron"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type JobScheduleGetRequest struct {
+	Owner uuid.UUID `validate:"not_uuid_nil,uuid4"`
+	JobId int64     `validate:"required,min=1"`
+}
+
+func (r JobScheduleGetRequest) RequestName() string {
+	return "job-schedule-get-req"
+}
+
+func (r JobScheduleGetRequest) ToParams() (*model.GetJobScheduleParams, error) {
+	return &model.GetJobScheduleParams{
+		Owner: r.Owner,
+		ID:    r.JobId,
+	}, nil
+}
+
+type JobScheduleUpdateRequest struct {
+	Owner    uuid.UUID `validate:"not_uuid_nil,uuid4"`
+	JobId    int64     `validate:"required,min=1"`
+	CronExpr string    `validate:"required,max=64,cron_expr"`
+}
+
+func (r JobScheduleUpdateRequest) RequestName() string {
+	return "job-schedule-update-req"
+}
+
+func (r JobScheduleUpdateRequest) ToParams() (*model.UpdateJobScheduleParams, error) {
+	s, err := cron.Parse(r.CronExpr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.UpdateJobScheduleParams{
+		CronExpr:  s.String(),
+		NextRunAt: pgtype.Timestamptz{Time: s.Next(time.Now()), Valid: true},
+		Owner:     r.Owner,
+		JobID:     r.JobId,
+	}, nil
+}
+
+type JobScheduleDeleteRequest struct {
+	Owner uuid.UUID `validate:"not_uuid_nil,uuid4"`
+	JobId int64     `validate:"required,min=1"`
+}
+
+func (r JobScheduleDeleteRequest) RequestName() string {
+	return "job-schedule-delete-req"
+}
+
+func (r JobScheduleDeleteRequest) ToParams() (*model.DeleteJobScheduleParams, error) {
+	return &model.DeleteJobScheduleParams{
+		Owner: r.Owner,
+		ID:    r.JobId,
+	}, nil
+}
+
+type JobScheduleClaimRequest struct {
+	JobId     int64     `validate:"required,min=1"`
+	PrevRunAt time.Time `validate:"required"`
+	NextRunAt time.Time `validate:"required,gtfield=PrevRunAt"`
+}
+
+func (r JobScheduleClaimRequest) RequestName() string {
+	return "job-schedule-claim-req"
+}
+
+func (r JobScheduleClaimRequest) ToParams() (*model.ClaimJobScheduleParams, error) {
+	return &model.ClaimJobScheduleParams{
+		NextRunAt: pgtype.Timestamptz{Time: r.NextRunAt, Valid: true},
+		JobID:     r.JobId,
+		PrevRunAt: pgtype.Timestamptz{Time: r.PrevRunAt, Valid: true},
+	}, nil
+}
+
+func (srvc jobScheduleService) Get(ctx context.Context, r *JobScheduleGetRequest) (*model.GetJobScheduleRow, error) {
+	if err := srvc.validate.Struct(r); err != nil {
+		return nil, err
+	}
+	params, _ := r.ToParams()
+	row, err := srvc.store.GetJobSchedule(ctx, params)
+	return row, ParsePgxError(err)
+}
+
+// change the cron expression of the schedule, the next run is counted from now
+func (srvc jobScheduleService) Update(ctx context.Context, r *JobScheduleUpdateRequest) (int64, error) {
+	if err := srvc.validate.Struct(r); err != nil {
+		return 0, err
+	}
+	params, err := r.ToParams()
+	if err != nil {
+		return 0, err
+	}
+	n, err := srvc.store.UpdateJobSchedule(ctx, params)
+	return n, ParsePgxError(err)
+}
+
+func (srvc jobScheduleService) Delete(ctx context.Context, r *JobScheduleDeleteRequest) (int64, error) {
+	if err := srvc.validate.Struct(r); err != nil {
+		return 0, err
+	}
+	params, _ := r.ToParams()
+	n, err := srvc.store.DeleteJobSchedule(ctx, params)
+	return n, ParsePgxError(err)
+}
+
+// get at most n schedules whose next run is due, the earliest first
+func (srvc jobScheduleService) GetDue(ctx context.Context, n int) ([]*model.GetDueJobSchedulesRow, error) {
+	if err := srvc.validate.Var(n, "required,min=1"); err != nil {
+		return nil, err
+	}
+	rows, err := srvc.store.GetDueJobSchedules(ctx, int32(n))
+	return rows, ParsePgxError(err)
+}
+
+// move the schedule to its next run. It returns 0 if the schedule has been
+// claimed by someone else, i.e. its next run is no longer PrevRunAt.
+func (srvc jobScheduleService) Claim(ctx context.Context, r *JobScheduleClaimRequest) (int64, error) {
+	if err := srvc.validate.Struct(r); err != nil {
+		return 0, err
+	}
+	params, _ := r.ToParams()
+	n, err := srvc.store.ClaimJobSchedule(ctx, params)
+	return n, ParsePgxError(err)
+}
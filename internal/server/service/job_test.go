@@ -2,12 +2,21 @@ package service_test
 
 import (
 	"context"
+	"net/http"
 	"os"
 	"testing"
+	"time"
 
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/global"
 	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/model"
+	mock_model "github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/model/mockdb"
 	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/server/service"
 	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/server/validator"
+	ec "github.com/ChiaYuChang/NewsSentimentAnalyzer/pkgs/errorCode"
+	val "github.com/go-playground/validator/v10"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/joho/godotenv"
 	"github.com/stretchr/testify/require"
 )
@@ -40,3 +49,93 @@ func TestGetOldestNCreatedJobsForEachUser(t *testing.T) {
 		}
 	}
 }
+
+func TestCheckQuota(t *testing.T) {
+	Validate := val.New()
+	validator.RegisterUUID(Validate)
+
+	quota := global.QuotaOption{
+		MaxRunningJobs:    2,
+		MaxArticlesPerJob: 100,
+		MaxJobsPerDay:     5,
+	}
+
+	type testCase struct {
+		Name      string
+		Quota     global.QuotaOption
+		NArticles int
+		Usage     *model.GetJobQuotaUsageRow
+		Detail    string
+	}
+
+	tcs := []testCase{
+		{
+			Name:      "OK",
+			Quota:     quota,
+			NArticles: 100,
+			Usage:     &model.GetJobQuotaUsageRow{NRunning: 1, NToday: 4},
+		},
+		{
+			Name:      "Too many articles",
+			Quota:     quota,
+			NArticles: 101,
+			Detail:    "max articles per job",
+		},
+		{
+			Name:      "Too many running jobs",
+			Quota:     quota,
+			NArticles: 10,
+			Usage:     &model.GetJobQuotaUsageRow{NRunning: 2, NToday: 2},
+			Detail:    "max running jobs",
+		},
+		{
+			Name:      "Too many jobs today",
+			Quota:     quota,
+			NArticles: 10,
+			Usage:     &model.GetJobQuotaUsageRow{NRunning: 0, NToday: 5},
+			Detail:    "max jobs per day",
+		},
+		{
+			Name:      "Unlimited",
+			NArticles: 10000,
+		},
+	}
+
+	for i := range tcs {
+		tc := tcs[i]
+		t.Run(tc.Name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			store := mock_model.NewMockStore(ctl)
+
+			req := &service.JobCheckQuotaRequest{
+				Owner:     uuid.New(),
+				Since:     time.Now().Add(-time.Hour),
+				NArticles: tc.NArticles,
+				Quota:     tc.Quota,
+			}
+			if tc.Usage != nil {
+				store.EXPECT().
+					GetJobQuotaUsage(gomock.Any(), &model.GetJobQuotaUsageParams{
+						Since: pgtype.Timestamptz{Time: req.Since, Valid: true},
+						Owner: req.Owner,
+					}).
+					Times(1).
+					Return(tc.Usage, nil)
+			}
+
+			srvc := service.NewService(store, Validate)
+			err := srvc.Job().CheckQuota(context.Background(), req)
+			if tc.Detail == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			ecErr, ok := err.(*ec.Error)
+			require.True(t, ok)
+			require.Equal(t, ec.ECQuotaExceeded, ecErr.ErrorCode)
+			require.Equal(t, http.StatusTooManyRequests, ecErr.HttpStatusCode)
+			require.Contains(t, ecErr.Details, tc.Detail)
+			require.NotEmpty(t, ecErr.Message)
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/model"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// CreateJobUsageRequest records the tokens billed for a call to a provider
+// made by a job. Cost is the estimated cost in USD, nil if the model has no
+// price.
+type CreateJobUsageRequest struct {
+	JobId        int64    `validate:"required,min=1"`
+	Provider     string   `validate:"required,max=32"`
+	Model        string   `validate:"required,max=64"`
+	Operation    string   `validate:"required,max=32"`
+	InputTokens  int32    `validate:"min=0"`
+	OutputTokens int32    `validate:"min=0"`
+	Cost         *float64 `validate:"omitempty,min=0"`
+}
+
+func (req CreateJobUsageRequest) RequestName() string {
+	return "job-usage-create-req"
+}
+
+func (req CreateJobUsageRequest) ToParams() (*model.CreateJobUsageParams, error) {
+	params := &model.CreateJobUsageParams{
+		JobID:        req.JobId,
+		Provider:     req.Provider,
+		Model:        req.Model,
+		Operation:    req.Operation,
+		InputTokens:  req.InputTokens,
+		OutputTokens: req.OutputTokens,
+	}
+	if req.Cost != nil {
+		params.Cost = pgtype.Float8{Float64: *req.Cost, Valid: true}
+	}
+	return params, nil
+}
+
+func (srvc jobUsageService) Create(ctx context.Context, req *CreateJobUsageRequest) (int64, error) {
+	if err := srvc.validate.Struct(req); err != nil {
+		return 0, err
+	}
+
+	params, _ := req.ToParams()
+	id, err := srvc.store.CreateJobUsage(ctx, params)
+	return id, ParsePgxError(err)
+}
+
+type GetJobUsageRequest struct {
+	JobId int64     `validate:"required,min=1"`
+	Owner uuid.UUID `validate:"not_uuid_nil,uuid4"`
+}
+
+func (req GetJobUsageRequest) RequestName() string {
+	return "job-usage-get-req"
+}
+
+func (req GetJobUsageRequest) ToParams() (*model.GetJobUsageParams, error) {
+	return &model.GetJobUsageParams{
+		JobID: req.JobId,
+		Owner: req.Owner,
+	}, nil
+}
+
+// Get returns the usage of the job summed by provider, model and operation.
+func (srvc jobUsageService) Get(ctx context.Context, req *GetJobUsageRequest) ([]*model.GetJobUsageRow, error) {
+	if err := srvc.validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	params, _ := req.ToParams()
+	rows, err := srvc.store.GetJobUsage(ctx, params)
+	return rows, ParsePgxError(err)
+}
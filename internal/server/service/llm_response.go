@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/model"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// LLMResponseKey identifies a cached response of a provider. PromptId is the
+// id of the version of the prompt of a user, 0 for the default prompt, and
+// InputHash is the SHA-256 of the normalized input text and the options the
+// response depends on.
+type LLMResponseKey struct {
+	Provider  string `validate:"required,max=32"`
+	Model     string `validate:"required,max=64"`
+	Operation string `validate:"required,max=32"`
+	PromptId  int64  `validate:"min=0"`
+	InputHash string `validate:"len=64,hexadecimal"`
+}
+
+type LLMResponseGetRequest struct {
+	LLMResponseKey
+}
+
+func (req LLMResponseGetRequest) RequestName() string {
+	return "llm-response-get-req"
+}
+
+func (req LLMResponseGetRequest) ToParams() (*model.GetLLMResponseParams, error) {
+	return &model.GetLLMResponseParams{
+		Provider:  req.Provider,
+		Model:     req.Model,
+		Operation: req.Operation,
+		PromptID:  req.PromptId,
+		InputHash: req.InputHash,
+	}, nil
+}
+
+// Get returns the cached response of the key, or ECPgxErrNoRows if there is
+// none or it has expired.
+func (srvc llmResponseService) Get(ctx context.Context, req *LLMResponseGetRequest) ([]byte, error) {
+	if err := srvc.validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	params, _ := req.ToParams()
+	resp, err := srvc.store.GetLLMResponse(ctx, params)
+	return resp, ParsePgxError(err)
+}
+
+// LLMResponseSetRequest caches the JSON encoded response of the key for TTL,
+// which replaces the one cached before.
+type LLMResponseSetRequest struct {
+	LLMResponseKey
+	Response []byte        `validate:"required"`
+	TTL      time.Duration `validate:"required,min=1s"`
+}
+
+func (req LLMResponseSetRequest) RequestName() string {
+	return "llm-response-set-req"
+}
+
+func (req LLMResponseSetRequest) ToParams() (*model.UpsertLLMResponseParams, error) {
+	return &model.UpsertLLMResponseParams{
+		Provider:  req.Provider,
+		Model:     req.Model,
+		Operation: req.Operation,
+		PromptID:  req.PromptId,
+		InputHash: req.InputHash,
+		Response:  req.Response,
+		ExpiresAt: pgtype.Timestamptz{Time: time.Now().Add(req.TTL), Valid: true},
+	}, nil
+}
+
+func (srvc llmResponseService) Set(ctx context.Context, req *LLMResponseSetRequest) error {
+	if err := srvc.validate.Struct(req); err != nil {
+		return err
+	}
+
+	params, _ := req.ToParams()
+	_, err := srvc.store.UpsertLLMResponse(ctx, params)
+	return ParsePgxError(err)
+}
+
+// CleanUp deletes the expired responses.
+func (srvc llmResponseService) CleanUp(ctx context.Context) (n int64, err error) {
+	return srvc.store.CleanUpLLMResponses(ctx)
+}
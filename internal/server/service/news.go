@@ -173,12 +173,12 @@ func (srvc newsService) GetByMD5Hash(ctx context.Context, r *NewsGetByMD5HashReq
 	return row, ParsePgxError(err)
 }
 
-func (srvc newsService) GetByMD5Hashs(ctx context.Context, r *NewsGetByMD5HashsRequest) ([]int64, error) {
+func (srvc newsService) GetByMD5Hashs(ctx context.Context, r *NewsGetByMD5HashsRequest) ([]*model.GetNewsByMD5HashsRow, error) {
 	if err := srvc.validate.Struct(r); err != nil {
 		return nil, err
 	}
-	ids, err := srvc.store.GetNewsByMD5Hashs(ctx, r.MD5Hash)
-	return ids, ParsePgxError(err)
+	rows, err := srvc.store.GetNewsByMD5Hashs(ctx, r.MD5Hash)
+	return rows, ParsePgxError(err)
 }
 
 func (srvc newsService) ListRecentN(ctx context.Context, r *NewsListRequest) ([]*model.ListRecentNNewsRow, error) {
@@ -188,3 +188,12 @@ func (srvc newsService) ListRecentN(ctx context.Context, r *NewsListRequest) ([]
 	rows, err := srvc.store.ListRecentNNews(ctx, r.N)
 	return rows, ParsePgxError(err)
 }
+
+// GetByJobId returns all news linked to the given job through newsjobs
+func (srvc newsService) GetByJobId(ctx context.Context, jobId int64) ([]*model.GetNewsByJobIdRow, error) {
+	if err := srvc.validate.Var(jobId, "required,min=1"); err != nil {
+		return nil, err
+	}
+	rows, err := srvc.store.GetNewsByJobId(ctx, jobId)
+	return rows, ParsePgxError(err)
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"text/template"
+
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client/option"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/model"
+	ec "github.com/ChiaYuChang/NewsSentimentAnalyzer/pkgs/errorCode"
+	"github.com/google/uuid"
+)
+
+// the tasks of which the prompt of the analyzer could be replaced
+const (
+	PromptTaskSentiment = "sentiment"
+)
+
+// RenderPrompt executes the body of a prompt, which is a text/template, with
+// the analyzer options of a job, e.g. {{.Truncate}}.
+func RenderPrompt(body string, opt *option.AnalyzerOption) (string, error) {
+	tmpl, err := template.New("prompt").Option("missingkey=error").Parse(body)
+	if err != nil {
+		return "", err
+	}
+
+	sb := strings.Builder{}
+	if err := tmpl.Execute(&sb, opt); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(sb.String()), nil
+}
+
+// PromptCreateRequest creates the next version of the prompt of the owner for
+// the task of the provider.
+type PromptCreateRequest struct {
+	Owner    uuid.UUID `validate:"not_uuid_nil,uuid4"`
+	Provider string    `validate:"required,max=32"`
+	Task     string    `validate:"required,oneof=sentiment"`
+	Body     string    `validate:"required,max=8192"`
+}
+
+func (r PromptCreateRequest) RequestName() string {
+	return "prompt-create-req"
+}
+
+func (r PromptCreateRequest) ToParams() (*model.CreatePromptParams, error) {
+	return &model.CreatePromptParams{
+		Owner:    r.Owner,
+		Provider: r.Provider,
+		Task:     r.Task,
+		Body:     r.Body,
+	}, nil
+}
+
+// Create stores the body as a new version, a body that is not a valid
+// template or fails on the default options is rejected with ECBadRequest.
+func (srvc promptService) Create(ctx context.Context, r *PromptCreateRequest) (*model.CreatePromptRow, error) {
+	if err := srvc.validate.Struct(r); err != nil {
+		return nil, err
+	}
+
+	if _, err := RenderPrompt(r.Body, &option.AnalyzerOption{APIName: r.Provider}); err != nil {
+		return nil, ec.MustGetEcErr(ec.ECBadRequest).
+			WithMessage("invalid prompt template").
+			WithDetails(err.Error())
+	}
+
+	params, _ := r.ToParams()
+	row, err := srvc.store.CreatePrompt(ctx, params)
+	return row, ParsePgxError(err)
+}
+
+type PromptGetRequest struct {
+	Owner uuid.UUID `validate:"not_uuid_nil,uuid4"`
+	ID    int64     `validate:"required,min=1"`
+}
+
+func (r PromptGetRequest) RequestName() string {
+	return "prompt-get-req"
+}
+
+func (r PromptGetRequest) ToParams() (*model.GetPromptParams, error) {
+	return &model.GetPromptParams{
+		Owner: r.Owner,
+		ID:    r.ID,
+	}, nil
+}
+
+func (srvc promptService) Get(ctx context.Context, r *PromptGetRequest) (*model.Prompt, error) {
+	if err := srvc.validate.Struct(r); err != nil {
+		return nil, err
+	}
+
+	params, _ := r.ToParams()
+	row, err := srvc.store.GetPrompt(ctx, params)
+	return row, ParsePgxError(err)
+}
+
+// List returns every version of the prompts of the owner, the latest first.
+func (srvc promptService) List(ctx context.Context, owner uuid.UUID) ([]*model.ListPromptsRow, error) {
+	if err := srvc.validate.Var(owner, "not_uuid_nil,uuid4"); err != nil {
+		return nil, err
+	}
+
+	rows, err := srvc.store.ListPrompts(ctx, owner)
+	return rows, ParsePgxError(err)
+}
@@ -0,0 +1,87 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/client/option"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/model"
+	mock_model "github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/model/mockdb"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/server/service"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/server/validator"
+	ec "github.com/ChiaYuChang/NewsSentimentAnalyzer/pkgs/errorCode"
+	val "github.com/go-playground/validator/v10"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderPrompt(t *testing.T) {
+	opt := &option.AnalyzerOption{APIName: "openai"}
+	opt.Truncate = "END"
+
+	prompt, err := service.RenderPrompt(" Score the statements of {{.APIName}}.{{if .Truncate}} Truncated at the {{.Truncate}}.{{end}}\n", opt)
+	require.NoError(t, err)
+	require.Equal(t, "Score the statements of openai. Truncated at the END.", prompt)
+
+	_, err = service.RenderPrompt("Score the statements {{.APIName", opt)
+	require.Error(t, err)
+
+	_, err = service.RenderPrompt("Score the statements {{.Unknown}}", opt)
+	require.Error(t, err)
+}
+
+func TestCreatePrompt(t *testing.T) {
+	Validate := val.New()
+	validator.RegisterUUID(Validate)
+
+	owner := uuid.New()
+
+	type testCase struct {
+		Name string
+		Body string
+		Task string
+		OK   bool
+	}
+
+	tcs := []testCase{
+		{Name: "OK", Body: "Score the statements.", Task: service.PromptTaskSentiment, OK: true},
+		{Name: "Unknown task", Body: "Score the statements.", Task: "summary"},
+		{Name: "Empty body", Task: service.PromptTaskSentiment},
+		{Name: "Invalid template", Body: "Score the statements {{.Unknown}}", Task: service.PromptTaskSentiment},
+	}
+
+	for i := range tcs {
+		tc := tcs[i]
+		t.Run(tc.Name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			store := mock_model.NewMockStore(ctl)
+			if tc.OK {
+				store.EXPECT().
+					CreatePrompt(gomock.Any(), &model.CreatePromptParams{
+						Owner: owner, Provider: "openai", Task: tc.Task, Body: tc.Body,
+					}).
+					Times(1).
+					Return(&model.CreatePromptRow{ID: 1, Version: 2}, nil)
+			}
+
+			srvc := service.NewService(store, Validate)
+			row, err := srvc.Prompt().Create(context.Background(), &service.PromptCreateRequest{
+				Owner: owner, Provider: "openai", Task: tc.Task, Body: tc.Body,
+			})
+			if !tc.OK {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, int32(2), row.Version)
+		})
+	}
+
+	// a template failing on the options is a bad request
+	srvc := service.NewService(mock_model.NewMockStore(gomock.NewController(t)), Validate)
+	_, err := srvc.Prompt().Create(context.Background(), &service.PromptCreateRequest{
+		Owner: owner, Provider: "openai", Task: service.PromptTaskSentiment, Body: "{{.Unknown}}",
+	})
+	require.True(t, ec.MustGetEcErr(ec.ECBadRequest).IsEqual(err))
+}
@@ -64,6 +64,30 @@ func (srvc Service) Job() jobService {
 	return jobService(srvc)
 }
 
+type jobItemService Service
+
+func (srvc Service) JobItem() jobItemService {
+	return jobItemService(srvc)
+}
+
+type jobUsageService Service
+
+func (srvc Service) JobUsage() jobUsageService {
+	return jobUsageService(srvc)
+}
+
+type llmResponseService Service
+
+func (srvc Service) LLMResponse() llmResponseService {
+	return llmResponseService(srvc)
+}
+
+type jobScheduleService Service
+
+func (srvc Service) JobSchedule() jobScheduleService {
+	return jobScheduleService(srvc)
+}
+
 type keywordService Service
 
 func (srvc Service) Keyword() keywordService {
@@ -94,6 +118,42 @@ func (srvc Service) Embedding() embeddingService {
 	return embeddingService(srvc)
 }
 
+type embeddingModelService Service
+
+func (srvc Service) EmbeddingModel() embeddingModelService {
+	return embeddingModelService(srvc)
+}
+
+type entitySentimentService Service
+
+func (srvc Service) EntitySentiment() entitySentimentService {
+	return entitySentimentService(srvc)
+}
+
+type annotationService Service
+
+func (srvc Service) Annotation() annotationService {
+	return annotationService(srvc)
+}
+
+type promptService Service
+
+func (srvc Service) Prompt() promptService {
+	return promptService(srvc)
+}
+
+type webhookService Service
+
+func (srvc Service) Webhook() webhookService {
+	return webhookService(srvc)
+}
+
+type storyService Service
+
+func (srvc Service) Story() storyService {
+	return storyService(srvc)
+}
+
 type txService Service
 
 func (srvc Service) TX() txService {
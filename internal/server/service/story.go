@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/model"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pgvector/pgvector-go"
+)
+
+// StoryNearestRequest looks for the story of Model that is the most similar
+// to Embedding, among the stories of the news nearest to Embedding with a news
+// published within Window of PublishAt and with no news from Source yet.
+type StoryNearestRequest struct {
+	Model     string        `validate:"required,max=32"`
+	Embedding []float32     `validate:"required,max=16000"`
+	Source    string        `validate:"required"`
+	PublishAt time.Time     `validate:"required"`
+	Window    time.Duration `validate:"required,min=1"`
+}
+
+func (r StoryNearestRequest) RequestName() string {
+	return "story-nearest-req"
+}
+
+func (r StoryNearestRequest) ToParams() (*model.GetNearestStoryParams, error) {
+	return &model.GetNearestStoryParams{
+		Embedding:   pgvector.NewVector(r.Embedding),
+		Model:       r.Model,
+		PublishFrom: pgtype.Timestamptz{Time: r.PublishAt.Add(-r.Window), Valid: true},
+		PublishTo:   pgtype.Timestamptz{Time: r.PublishAt.Add(r.Window), Valid: true},
+		Source:      r.Source,
+	}, nil
+}
+
+// StoryCreateRequest creates a story of Model of which the news is the first
+// one, the title of the news is the title of the story.
+type StoryCreateRequest struct {
+	Model     string    `validate:"required,max=32"`
+	NewsId    int64     `validate:"required,min=1"`
+	Title     string    `validate:"required"`
+	PublishAt time.Time `validate:"required"`
+}
+
+func (r StoryCreateRequest) RequestName() string {
+	return "story-create-req"
+}
+
+func (r StoryCreateRequest) ToParams() (*model.CreateStoryParams, error) {
+	return &model.CreateStoryParams{
+		Model:     r.Model,
+		Title:     r.Title,
+		PublishAt: pgtype.Timestamptz{Time: r.PublishAt, Valid: true},
+		NewsID:    r.NewsId,
+	}, nil
+}
+
+// StoryAddNewsRequest adds the news to the story, Similarity is the mean
+// cosine similarity of the news to the news of the story.
+type StoryAddNewsRequest struct {
+	StoryId    int64     `validate:"required,min=1"`
+	NewsId     int64     `validate:"required,min=1"`
+	Model      string    `validate:"required,max=32"`
+	Similarity float64   `validate:"min=-1,max=1"`
+	PublishAt  time.Time `validate:"required"`
+}
+
+func (r StoryAddNewsRequest) RequestName() string {
+	return "story-add-news-req"
+}
+
+func (r StoryAddNewsRequest) ToParams() (*model.AddStoryNewsParams, error) {
+	return &model.AddStoryNewsParams{
+		StoryID:    r.StoryId,
+		NewsID:     r.NewsId,
+		Model:      r.Model,
+		Similarity: float32(r.Similarity),
+		PublishAt:  pgtype.Timestamptz{Time: r.PublishAt, Valid: true},
+	}, nil
+}
+
+type StoryListRequest struct {
+	MinSources int `validate:"min=1"`
+	N          int `validate:"min=1,max=500"`
+}
+
+func (r StoryListRequest) RequestName() string {
+	return "story-list-req"
+}
+
+func (r StoryListRequest) ToParams() (*model.ListStoriesParams, error) {
+	return &model.ListStoriesParams{
+		MinSources: int32(r.MinSources),
+		N:          int32(r.N),
+	}, nil
+}
+
+// get at most n embedded news which are not in a story of their model yet,
+// the earliest published first
+func (srvc storyService) GetUnclustered(ctx context.Context, n int) ([]*model.GetUnclusteredNewsRow, error) {
+	if err := srvc.validate.Var(n, "required,min=1"); err != nil {
+		return nil, err
+	}
+	rows, err := srvc.store.GetUnclusteredNews(ctx, int32(n))
+	return rows, ParsePgxError(err)
+}
+
+// Nearest returns the most similar story, or ECPgxErrNoRows if there is no
+// candidate.
+func (srvc storyService) Nearest(ctx context.Context, r *StoryNearestRequest) (*model.GetNearestStoryRow, error) {
+	if err := srvc.validate.Struct(r); err != nil {
+		return nil, err
+	}
+	params, _ := r.ToParams()
+	ids, err := srvc.nearestNewsIds(ctx, params.Model, params.Embedding)
+	if err != nil {
+		return nil, ParsePgxError(err)
+	}
+	params.NewsIds = ids
+	row, err := srvc.store.GetNearestStory(ctx, params)
+	return row, ParsePgxError(err)
+}
+
+// the number of the nearest news of which the stories are compared, an hnsw
+// index returns no more than hnsw.ef_search, 40 by default, of them
+const nNearestNews = 40
+
+// nearestNewsIds searches the news nearest to embd with the hnsw index of the
+// size of the vectors of mdl, or exactly if there is no such index.
+func (srvc storyService) nearestNewsIds(ctx context.Context, mdl string, embd pgvector.Vector) ([]int64, error) {
+	switch len(embd.Slice()) {
+	case 256:
+		return srvc.store.GetNearestNewsIds256(ctx, &model.GetNearestNewsIds256Params{
+			Model: mdl, Embedding: embd, N: nNearestNews,
+		})
+	case 384:
+		return srvc.store.GetNearestNewsIds384(ctx, &model.GetNearestNewsIds384Params{
+			Model: mdl, Embedding: embd, N: nNearestNews,
+		})
+	case 1024:
+		return srvc.store.GetNearestNewsIds1024(ctx, &model.GetNearestNewsIds1024Params{
+			Model: mdl, Embedding: embd, N: nNearestNews,
+		})
+	case 1536:
+		return srvc.store.GetNearestNewsIds1536(ctx, &model.GetNearestNewsIds1536Params{
+			Model: mdl, Embedding: embd, N: nNearestNews,
+		})
+	default:
+		return srvc.store.GetNearestNewsIds(ctx, &model.GetNearestNewsIdsParams{
+			Model: mdl, Embedding: embd, N: nNearestNews,
+		})
+	}
+}
+
+func (srvc storyService) Create(ctx context.Context, r *StoryCreateRequest) (int64, error) {
+	if err := srvc.validate.Struct(r); err != nil {
+		return 0, err
+	}
+	params, _ := r.ToParams()
+	id, err := srvc.store.CreateStory(ctx, params)
+	return id, ParsePgxError(err)
+}
+
+// AddNews returns 0 if the news is in the story already.
+func (srvc storyService) AddNews(ctx context.Context, r *StoryAddNewsRequest) (int64, error) {
+	if err := srvc.validate.Struct(r); err != nil {
+		return 0, err
+	}
+	params, _ := r.ToParams()
+	n, err := srvc.store.AddStoryNews(ctx, params)
+	return n, ParsePgxError(err)
+}
+
+func (srvc storyService) Get(ctx context.Context, id int64) (*model.Story, error) {
+	if err := srvc.validate.Var(id, "required,min=1"); err != nil {
+		return nil, err
+	}
+	story, err := srvc.store.GetStory(ctx, id)
+	return story, ParsePgxError(err)
+}
+
+// get the news of the story and their sentiment, the earliest published first
+func (srvc storyService) GetNews(ctx context.Context, id int64) ([]*model.GetStoryNewsRow, error) {
+	if err := srvc.validate.Var(id, "required,min=1"); err != nil {
+		return nil, err
+	}
+	rows, err := srvc.store.GetStoryNews(ctx, id)
+	return rows, ParsePgxError(err)
+}
+
+// list the latest stories that have at least MinSources news
+func (srvc storyService) List(ctx context.Context, r *StoryListRequest) ([]*model.ListStoriesRow, error) {
+	if err := srvc.validate.Struct(r); err != nil {
+		return nil, err
+	}
+	params, _ := r.ToParams()
+	rows, err := srvc.store.ListStories(ctx, params)
+	return rows, ParsePgxError(err)
+}
@@ -2,14 +2,73 @@ package service
 
 import (
 	"context"
+	"time"
 
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/global"
 	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/model"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/pkgs/cron"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
-func (srvc txService) DoCacheToStoreTx(ctx context.Context, user uuid.UUID,
-	ulid string, srcId int16, srcQuery string, llmId int16, llmQuery string,
+type CacheToStoreTxRequest struct {
+	Owner    uuid.UUID `validate:"not_uuid_nil,uuid4"`
+	Ulid     string    `validate:"required,len=26"`
+	SrcId    int16     `validate:"required,min=1"`
+	SrcQuery string    `validate:"-"`
+	LlmId    int16     `validate:"required,min=1"`
+	LlmQuery string    `validate:"required"`
+	// the scheduled job which the job is a run of, 0 if none
+	ParentId int64 `validate:"min=0"`
+	// the cron expression to replay the query with, empty if not scheduled
+	CronExpr      string `validate:"omitempty,max=64,cron_expr"`
+	SrcCacheQuery []byte `validate:"required_with=CronExpr"`
+	// the quota of the owner, nil if the job is not counted in any quota
+	Quota *global.QuotaOption `validate:"-"`
+	// the jobs of the owner submitted since Since are counted in the quota
+	Since time.Time `validate:"required_with=Quota"`
+}
+
+func (r CacheToStoreTxRequest) RequestName() string {
+	return "cache-to-store-tx-req"
+}
+
+func (r CacheToStoreTxRequest) ToParams() (*model.CreateJobParams, *model.CreateJobScheduleParams, error) {
+	jParams := &model.CreateJobParams{
+		Owner:    r.Owner,
+		Ulid:     r.Ulid,
+		SrcApiID: r.SrcId,
+		SrcQuery: r.SrcQuery,
+		LlmApiID: r.LlmId,
+		LlmQuery: []byte(r.LlmQuery),
+		ParentID: pgtype.Int8{Int64: r.ParentId, Valid: r.ParentId > 0},
+	}
+
+	if r.CronExpr == "" {
+		return jParams, nil, nil
+	}
+
+	s, err := cron.Parse(r.CronExpr)
+	if err != nil {
+		return nil, nil, err
+	}
+	return jParams, &model.CreateJobScheduleParams{
+		CronExpr:      s.String(),
+		SrcCacheQuery: r.SrcCacheQuery,
+		NextRunAt:     pgtype.Timestamptz{Time: s.Next(time.Now()), Valid: true},
+	}, nil
+}
+
+func (srvc txService) DoCacheToStoreTx(ctx context.Context, r *CacheToStoreTxRequest,
 	cnReqChan <-chan *NewsCreateRequest) (*model.CacheToStoreTXResult, error) {
+	if err := srvc.validate.Struct(r); err != nil {
+		return nil, err
+	}
+
+	jParams, sParams, err := r.ToParams()
+	if err != nil {
+		return nil, err
+	}
 
 	cnpChan := make(chan *model.CreateNewsParams, 10)
 	go func() {
@@ -20,15 +79,14 @@ func (srvc txService) DoCacheToStoreTx(ctx context.Context, user uuid.UUID,
 		}
 	}()
 
-	return srvc.store.DoCacheToStoreTx(ctx, &model.CacheToStoreTXParams{
-		CreateJobParams: &model.CreateJobParams{
-			Owner:    user,
-			Ulid:     ulid,
-			SrcApiID: srcId,
-			SrcQuery: srcQuery,
-			LlmApiID: llmId,
-			LlmQuery: []byte(llmQuery),
-		},
-		CreateNewsParams: cnpChan,
-	})
+	params := &model.CacheToStoreTXParams{
+		CreateJobParams:         jParams,
+		CreateJobScheduleParams: sParams,
+		CreateNewsParams:        cnpChan,
+	}
+	if r.Quota != nil {
+		params.CheckQuota = quotaCheck(*r.Quota)
+		params.QuotaSince = pgtype.Timestamptz{Time: r.Since, Valid: true}
+	}
+	return srvc.store.DoCacheToStoreTx(ctx, params)
 }
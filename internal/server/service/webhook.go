@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/model"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// NewWebhookSecret returns a random secret for signing the webhook payloads,
+// 32 bytes in hex.
+func NewWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+type WebhookCreateRequest struct {
+	Owner uuid.UUID `validate:"not_uuid_nil,uuid4"`
+	Url   string    `validate:"required,max=2048,http_url"`
+}
+
+func (r WebhookCreateRequest) RequestName() string {
+	return "webhook-create-req"
+}
+
+func (r WebhookCreateRequest) ToParams() (*model.CreateWebhookTxParams, error) {
+	secret, err := NewWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+	return &model.CreateWebhookTxParams{
+		Owner:  r.Owner,
+		Url:    r.Url,
+		Secret: secret,
+	}, nil
+}
+
+type WebhookDeleteRequest struct {
+	Owner uuid.UUID `validate:"not_uuid_nil,uuid4"`
+	ID    int64     `validate:"required,min=1"`
+}
+
+func (r WebhookDeleteRequest) RequestName() string {
+	return "webhook-delete-req"
+}
+
+func (r WebhookDeleteRequest) ToParams() (*model.DeleteWebhookParams, error) {
+	return &model.DeleteWebhookParams{
+		Owner: r.Owner,
+		ID:    r.ID,
+	}, nil
+}
+
+type WebhookListDeliveriesRequest struct {
+	Owner uuid.UUID `validate:"not_uuid_nil,uuid4"`
+	N     int32     `validate:"required,min=1,max=500"`
+}
+
+func (r WebhookListDeliveriesRequest) RequestName() string {
+	return "webhook-list-deliveries-req"
+}
+
+func (r WebhookListDeliveriesRequest) ToParams() (*model.ListWebhookDeliveriesParams, error) {
+	return &model.ListWebhookDeliveriesParams{
+		Owner: r.Owner,
+		Limit: r.N,
+	}, nil
+}
+
+type WebhookClaimDeliveryRequest struct {
+	ID            int64     `validate:"required,min=1"`
+	PrevAttemptAt time.Time `validate:"required"`
+	NextAttemptAt time.Time `validate:"required,gtfield=PrevAttemptAt"`
+}
+
+func (r WebhookClaimDeliveryRequest) RequestName() string {
+	return "webhook-claim-delivery-req"
+}
+
+func (r WebhookClaimDeliveryRequest) ToParams() (*model.ClaimWebhookDeliveryParams, error) {
+	return &model.ClaimWebhookDeliveryParams{
+		NextAttemptAt: pgtype.Timestamptz{Time: r.NextAttemptAt, Valid: true},
+		ID:            r.ID,
+		PrevAttemptAt: pgtype.Timestamptz{Time: r.PrevAttemptAt, Valid: true},
+	}, nil
+}
+
+type WebhookDeliveredRequest struct {
+	ID           int64 `validate:"required,min=1"`
+	ResponseCode int   `validate:"min=100,max=599"`
+}
+
+func (r WebhookDeliveredRequest) RequestName() string {
+	return "webhook-delivered-req"
+}
+
+func (r WebhookDeliveredRequest) ToParams() (*model.MarkWebhookDeliveryDeliveredParams, error) {
+	return &model.MarkWebhookDeliveryDeliveredParams{
+		ResponseCode: pgtype.Int4{Int32: int32(r.ResponseCode), Valid: true},
+		ID:           r.ID,
+	}, nil
+}
+
+type WebhookDeliveryFailRequest struct {
+	ID            int64     `validate:"required,min=1"`
+	Stage         string    `validate:"required,oneof=retrying failed"`
+	ResponseCode  int       `validate:"omitempty,min=100,max=599"`
+	LastError     string    `validate:"-"`
+	NextAttemptAt time.Time `validate:"required"`
+}
+
+func (r WebhookDeliveryFailRequest) RequestName() string {
+	return "webhook-delivery-fail-req"
+}
+
+func (r WebhookDeliveryFailRequest) ToParams() (*model.MarkWebhookDeliveryFailedParams, error) {
+	return &model.MarkWebhookDeliveryFailedParams{
+		Stage:         model.WebhookDeliveryStage(r.Stage),
+		ResponseCode:  pgtype.Int4{Int32: int32(r.ResponseCode), Valid: r.ResponseCode != 0},
+		LastError:     pgtype.Text{String: r.LastError, Valid: r.LastError != ""},
+		NextAttemptAt: pgtype.Timestamptz{Time: r.NextAttemptAt, Valid: true},
+		ID:            r.ID,
+	}, nil
+}
+
+// register a webhook, the owner gets a signing secret on their first webhook
+func (srvc webhookService) Create(ctx context.Context, r *WebhookCreateRequest) (*model.CreateWebhookTxResult, error) {
+	if err := srvc.validate.Struct(r); err != nil {
+		return nil, err
+	}
+	params, err := r.ToParams()
+	if err != nil {
+		return nil, err
+	}
+	result, err := srvc.store.DoCreateWebhookTx(ctx, params)
+	return result, ParsePgxError(err)
+}
+
+func (srvc webhookService) List(ctx context.Context, owner uuid.UUID) ([]*model.ListWebhooksRow, error) {
+	if err := srvc.validate.Var(owner, "not_uuid_nil,uuid4"); err != nil {
+		return nil, err
+	}
+	rows, err := srvc.store.ListWebhooks(ctx, owner)
+	return rows, ParsePgxError(err)
+}
+
+func (srvc webhookService) Delete(ctx context.Context, r *WebhookDeleteRequest) (int64, error) {
+	if err := srvc.validate.Struct(r); err != nil {
+		return 0, err
+	}
+	params, _ := r.ToParams()
+	n, err := srvc.store.DeleteWebhook(ctx, params)
+	return n, ParsePgxError(err)
+}
+
+func (srvc webhookService) GetSecret(ctx context.Context, owner uuid.UUID) (*model.GetWebhookSecretRow, error) {
+	if err := srvc.validate.Var(owner, "not_uuid_nil,uuid4"); err != nil {
+		return nil, err
+	}
+	row, err := srvc.store.GetWebhookSecret(ctx, owner)
+	return row, ParsePgxError(err)
+}
+
+// replace the signing secret of the owner with a new one and return it
+func (srvc webhookService) RotateSecret(ctx context.Context, owner uuid.UUID) (string, error) {
+	if err := srvc.validate.Var(owner, "not_uuid_nil,uuid4"); err != nil {
+		return "", err
+	}
+	secret, err := NewWebhookSecret()
+	if err != nil {
+		return "", err
+	}
+	_, err = srvc.store.RotateWebhookSecret(ctx, &model.RotateWebhookSecretParams{
+		Owner:  owner,
+		Secret: secret,
+	})
+	if err != nil {
+		return "", ParsePgxError(err)
+	}
+	return secret, nil
+}
+
+// get the latest n deliveries to the webhooks of the owner, the newest first
+func (srvc webhookService) ListDeliveries(ctx context.Context, r *WebhookListDeliveriesRequest) ([]*model.ListWebhookDeliveriesRow, error) {
+	if err := srvc.validate.Struct(r); err != nil {
+		return nil, err
+	}
+	params, _ := r.ToParams()
+	rows, err := srvc.store.ListWebhookDeliveries(ctx, params)
+	return rows, ParsePgxError(err)
+}
+
+// get at most n deliveries that are due, the earliest first
+func (srvc webhookService) GetDueDeliveries(ctx context.Context, n int) ([]*model.GetDueWebhookDeliveriesRow, error) {
+	if err := srvc.validate.Var(n, "required,min=1"); err != nil {
+		return nil, err
+	}
+	rows, err := srvc.store.GetDueWebhookDeliveries(ctx, int32(n))
+	return rows, ParsePgxError(err)
+}
+
+// push the next attempt of the delivery to NextAttemptAt, so that it will not
+// be sent by other instances meanwhile. It returns 0 if the delivery has been
+// claimed by someone else.
+func (srvc webhookService) ClaimDelivery(ctx context.Context, r *WebhookClaimDeliveryRequest) (int64, error) {
+	if err := srvc.validate.Struct(r); err != nil {
+		return 0, err
+	}
+	params, _ := r.ToParams()
+	n, err := srvc.store.ClaimWebhookDelivery(ctx, params)
+	return n, ParsePgxError(err)
+}
+
+func (srvc webhookService) MarkDelivered(ctx context.Context, r *WebhookDeliveredRequest) (int64, error) {
+	if err := srvc.validate.Struct(r); err != nil {
+		return 0, err
+	}
+	params, _ := r.ToParams()
+	n, err := srvc.store.MarkWebhookDeliveryDelivered(ctx, params)
+	return n, ParsePgxError(err)
+}
+
+// record a failed attempt, the delivery is retried at NextAttemptAt unless
+// Stage is failed
+func (srvc webhookService) MarkFailed(ctx context.Context, r *WebhookDeliveryFailRequest) (int64, error) {
+	if err := srvc.validate.Struct(r); err != nil {
+		return 0, err
+	}
+	params, _ := r.ToParams()
+	n, err := srvc.store.MarkWebhookDeliveryFailed(ctx, params)
+	return n, ParsePgxError(err)
+}
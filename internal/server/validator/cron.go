@@ -0,0 +1,29 @@
+package validator
+
+import (
+	"time"
+
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/pkgs/cron"
+	val "github.com/go-playground/validator/v10"
+)
+
+type CronValidator struct{}
+
+func (cv CronValidator) Tag() string {
+	return "cron_expr"
+}
+
+func (cv CronValidator) ValFun() val.Func {
+	return func(fl val.FieldLevel) bool {
+		expr, ok := fl.Field().Interface().(string)
+		if !ok {
+			return false
+		}
+
+		// expressions that never run, e.g. 0 0 31 2 *, are rejected as well
+		s, err := cron.Parse(expr)
+		return err == nil && !s.Next(time.Now()).IsZero()
+	}
+}
+
+var CronExpr = CronValidator{}
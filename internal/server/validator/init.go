@@ -44,6 +44,7 @@ func GetDefaultValidate() (*val.Validate, error) {
 			EnmusJobStatus,
 			EnmusApiType,
 			EnmusEventType,
+			CronExpr,
 		); err != nil {
 			sv.Error = fmt.Errorf("error while register validators: %v", err)
 			return
@@ -67,6 +68,7 @@ func NewValiateWithDefault() (*val.Validate, error) {
 		EnmusJobStatus,
 		EnmusApiType,
 		EnmusEventType,
+		CronExpr,
 	); err != nil {
 		return nil, fmt.Errorf("error while register validators: %v", err)
 	}
@@ -259,3 +259,41 @@ func TestValidateUUID(t *testing.T) {
 		)
 	}
 }
+
+func TestValidateCronExpr(t *testing.T) {
+	validate := val.New()
+	validator.RegisterValidator(
+		validate,
+		validator.CronExpr,
+	)
+
+	type testCase struct {
+		Expr    string
+		IsValid bool
+	}
+
+	tcs := []testCase{
+		{"0 8 * * *", true},
+		{"@daily", true},
+		{"*/15 9-17 * * 1-5", true},
+		{"61 * * * *", false},
+		{"* * * *", false},
+		{"", false},
+		// never runs
+		{"0 0 31 2 *", false},
+	}
+
+	for i, tc := range tcs {
+		t.Run(
+			fmt.Sprintf("Case %d-%s", i+1, tc.Expr),
+			func(t *testing.T) {
+				err := validate.Var(tc.Expr, validator.CronExpr.Tag())
+				if tc.IsValid {
+					require.NoError(t, err)
+				} else {
+					require.Error(t, err)
+				}
+			},
+		)
+	}
+}
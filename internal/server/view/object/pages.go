@@ -19,9 +19,33 @@ type WelcomePage struct {
 	PageChangePWD    string
 	PageEndpoint     string
 	PageManageAPIKey string
+	PageWebhook      string
+	PagePrompt       string
+	PageStory        string
 	PageSeeResult    string
 	PageAdmin        string
 	PageSignOut      string
+	Quota            []QuotaItem
+	MaxArticles      int
+}
+
+// QuotaItem is a quota counted against the jobs of the user, a Limit of 0
+// means unlimited.
+type QuotaItem struct {
+	Name  string
+	Used  int64
+	Limit int
+}
+
+func (q QuotaItem) Remaining() string {
+	if q.Limit < 1 {
+		return "unlimited"
+	}
+	r := int64(q.Limit) - q.Used
+	if r < 0 {
+		r = 0
+	}
+	return fmt.Sprintf("%d", r)
 }
 
 type LoginPage struct {
@@ -109,6 +133,9 @@ type JobDetails struct {
 	NewsAPIQuery  string `json:"job-news_api_query"`
 	Analyzer      string `json:"job-analyzer"`
 	AnalyzerQuery string `json:"job-analyzer_query"`
+	Partial       bool   `json:"job-partial"`
+	ParentID      int64  `json:"job-parent_id,omitempty"`
+	ClonedFrom    int64  `json:"job-cloned_from,omitempty"`
 	CreatedAt     string `json:"job-created_at"`
 	UpdatedAt     string `json:"job-updated_at"`
 }
@@ -122,11 +149,435 @@ func NewJobDetails(owner string, j *model.GetJobsByJobIdRow) JobDetails {
 		NewsAPIQuery:  j.SrcQuery,
 		Analyzer:      j.Analyzer,
 		AnalyzerQuery: string(j.LlmQuery),
+		Partial:       j.Partial,
+		ParentID:      j.ParentID.Int64,
+		ClonedFrom:    j.ClonedFrom.Int64,
 		CreatedAt:     j.CreatedAt.Time.UTC().Format(time.DateTime),
 		UpdatedAt:     j.UpdatedAt.Time.UTC().Format(time.DateTime),
 	}
 }
 
+type JobSchedule struct {
+	JID       int64  `json:"job-id"`
+	CronExpr  string `json:"schedule-cron"`
+	NextRunAt string `json:"schedule-next_run_at"`
+	LastRunAt string `json:"schedule-last_run_at,omitempty"`
+	NRuns     int64  `json:"schedule-n_runs"`
+}
+
+func NewJobSchedule(s *model.GetJobScheduleRow) JobSchedule {
+	sched := JobSchedule{
+		JID:       s.JobID,
+		CronExpr:  s.CronExpr,
+		NextRunAt: s.NextRunAt.Time.UTC().Format(time.DateTime),
+		NRuns:     s.NRuns,
+	}
+	if s.LastRunAt.Valid {
+		sched.LastRunAt = s.LastRunAt.Time.UTC().Format(time.DateTime)
+	}
+	return sched
+}
+
+// JobComparison holds the sentiments of the news shared by two jobs, an empty
+// sentiment means the news has not been analyzed by the job.
+type JobComparison struct {
+	JobA       ComparedJob         `json:"compare-job_a"`
+	JobB       ComparedJob         `json:"compare-job_b"`
+	Sentiments []ComparedSentiment `json:"compare-sentiments"`
+}
+
+type ComparedJob struct {
+	JID      int64  `json:"job-id"`
+	Analyzer string `json:"job-analyzer"`
+	Model    string `json:"job-model"`
+}
+
+type ComparedSentiment struct {
+	NewsID     int64  `json:"news-id"`
+	Title      string `json:"news-title"`
+	Link       string `json:"news-link"`
+	SentimentA string `json:"sentiment-a"`
+	SentimentB string `json:"sentiment-b"`
+}
+
+func NewJobComparison(a, b ComparedJob, rows []*model.CompareJobSentimentsRow) JobComparison {
+	cmp := JobComparison{
+		JobA:       a,
+		JobB:       b,
+		Sentiments: make([]ComparedSentiment, len(rows)),
+	}
+	for i, r := range rows {
+		cmp.Sentiments[i] = ComparedSentiment{
+			NewsID:     r.NewsID,
+			Title:      r.Title,
+			Link:       r.Link,
+			SentimentA: string(r.SentimentA.Sentiment),
+			SentimentB: string(r.SentimentB.Sentiment),
+		}
+	}
+	return cmp
+}
+
+type SentimentStats struct {
+	Bucket            string  `json:"stats-bucket"`
+	NNews             int64   `json:"stats-n_news"`
+	NVeryPositive     int64   `json:"stats-n_very_positive"`
+	NPositive         int64   `json:"stats-n_positive"`
+	NNeutral          int64   `json:"stats-n_neutral"`
+	NNegative         int64   `json:"stats-n_negative"`
+	NVeryNegative     int64   `json:"stats-n_very_negative"`
+	VeryPositiveRatio float64 `json:"stats-very_positive_ratio"`
+	PositiveRatio     float64 `json:"stats-positive_ratio"`
+	NeutralRatio      float64 `json:"stats-neutral_ratio"`
+	NegativeRatio     float64 `json:"stats-negative_ratio"`
+	VeryNegativeRatio float64 `json:"stats-very_negative_ratio"`
+	MeanScore         float64 `json:"stats-mean_score"`
+}
+
+// JobSentimentStats holds the sentiment distribution of the analyzed news of
+// a job, the mean score is on the 1-5 scale, from very negative to very
+// positive.
+type JobSentimentStats struct {
+	JID        int64            `json:"job-id"`
+	Model      string           `json:"job-model"`
+	Overall    SentimentStats   `json:"stats-overall"`
+	Source     []SentimentStats `json:"stats-source"`
+	Category   []SentimentStats `json:"stats-category"`
+	PublishDay []SentimentStats `json:"stats-publish_day"`
+	Language   []SentimentStats `json:"stats-language"`
+	// empty if the job has no target entity
+	EntitySource []EntitySentimentStats `json:"stats-entity_source"`
+	// the classification task of the job, empty if there is none
+	Task        string       `json:"job-task,omitempty"`
+	LabelSource []LabelStats `json:"stats-label_source"`
+}
+
+// EntitySentimentStats is the sentiment distribution toward an entity of the
+// news of the source in Bucket, or of all the news if Bucket is empty.
+type EntitySentimentStats struct {
+	Entity string `json:"stats-entity"`
+	SentimentStats
+}
+
+func NewJobSentimentStats(jid int64, mdl string, rows []*model.GetJobSentimentStatsRow) JobSentimentStats {
+	stats := JobSentimentStats{
+		JID:          jid,
+		Model:        mdl,
+		Source:       []SentimentStats{},
+		Category:     []SentimentStats{},
+		PublishDay:   []SentimentStats{},
+		Language:     []SentimentStats{},
+		EntitySource: []EntitySentimentStats{},
+		LabelSource:  []LabelStats{},
+	}
+
+	for _, r := range rows {
+		s := SentimentStats{
+			Bucket:            r.Bucket,
+			NNews:             r.NNews,
+			NVeryPositive:     r.NVeryPositive,
+			NPositive:         r.NPositive,
+			NNeutral:          r.NNeutral,
+			NNegative:         r.NNegative,
+			NVeryNegative:     r.NVeryNegative,
+			VeryPositiveRatio: r.VeryPositiveRatio,
+			PositiveRatio:     r.PositiveRatio,
+			NeutralRatio:      r.NeutralRatio,
+			NegativeRatio:     r.NegativeRatio,
+			VeryNegativeRatio: r.VeryNegativeRatio,
+			MeanScore:         r.MeanScore,
+		}
+		switch r.Dimension {
+		case "overall":
+			stats.Overall = s
+		case "source":
+			stats.Source = append(stats.Source, s)
+		case "category":
+			stats.Category = append(stats.Category, s)
+		case "publish_day":
+			stats.PublishDay = append(stats.PublishDay, s)
+		case "language":
+			stats.Language = append(stats.Language, s)
+		}
+	}
+	return stats
+}
+
+// LabelStats is the number of the news of the source in Bucket, or of all the
+// news if Bucket is empty, labeled Label, and its ratio to the news of the
+// bucket.
+type LabelStats struct {
+	Label  string  `json:"stats-label"`
+	Bucket string  `json:"stats-bucket"`
+	NNews  int64   `json:"stats-n_news"`
+	Ratio  float64 `json:"stats-ratio"`
+}
+
+// NewLabelStats converts the rows of the label distribution of a job, in order.
+func NewLabelStats(rows []*model.GetJobAnnotationStatsRow) []LabelStats {
+	stats := make([]LabelStats, len(rows))
+	for i, r := range rows {
+		stats[i] = LabelStats{Label: r.Label, Bucket: r.Source, NNews: r.NNews, Ratio: r.Ratio}
+	}
+	return stats
+}
+
+// NewEntitySentimentStats converts the rows of the sentiment distributions
+// toward the target entities of a job, in order.
+func NewEntitySentimentStats(rows []*model.GetJobEntitySentimentStatsRow) []EntitySentimentStats {
+	stats := make([]EntitySentimentStats, len(rows))
+	for i, r := range rows {
+		stats[i] = EntitySentimentStats{
+			Entity: r.Entity,
+			SentimentStats: SentimentStats{
+				Bucket:            r.Source,
+				NNews:             r.NNews,
+				NVeryPositive:     r.NVeryPositive,
+				NPositive:         r.NPositive,
+				NNeutral:          r.NNeutral,
+				NNegative:         r.NNegative,
+				NVeryNegative:     r.NVeryNegative,
+				VeryPositiveRatio: r.VeryPositiveRatio,
+				PositiveRatio:     r.PositiveRatio,
+				NeutralRatio:      r.NeutralRatio,
+				NegativeRatio:     r.NegativeRatio,
+				VeryNegativeRatio: r.VeryNegativeRatio,
+				MeanScore:         r.MeanScore,
+			},
+		}
+	}
+	return stats
+}
+
+type JobProgress struct {
+	JID             int64  `json:"job-id"`
+	Status          string `json:"job-status"`
+	Total           int32  `json:"progress-total"`
+	Parsed          int32  `json:"progress-parsed"`
+	LangDetected    int32  `json:"progress-lang_detected"`
+	Embedded        int32  `json:"progress-embedded"`
+	SentimentScored int32  `json:"progress-sentiment_scored"`
+	Failed          int32  `json:"progress-failed"`
+	UpdatedAt       string `json:"job-updated_at"`
+}
+
+func NewJobProgress(p *model.GetJobProgressRow) JobProgress {
+	return JobProgress{
+		JID:             p.JobID,
+		Status:          StatusToClass(p.Status),
+		Total:           p.Total,
+		Parsed:          p.Parsed,
+		LangDetected:    p.LangDetected,
+		Embedded:        p.Embedded,
+		SentimentScored: p.SentimentScored,
+		Failed:          p.Failed,
+		UpdatedAt:       p.UpdatedAt.Time.UTC().Format(time.DateTime),
+	}
+}
+
+// IsFinished reports whether the progress will not change anymore
+func (p JobProgress) IsFinished() bool {
+	switch model.JobStatus(p.Status) {
+	case model.JobStatusDone, model.JobStatusFailed, model.JobStatusCanceled:
+		return true
+	}
+	return false
+}
+
+type JobItem struct {
+	ID            int64  `json:"item-id"`
+	NewsID        int64  `json:"item-news_id"`
+	Title         string `json:"item-title"`
+	Link          string `json:"item-link"`
+	Stage         string `json:"item-stage"`
+	Attempts      int32  `json:"item-attempts"`
+	LastError     string `json:"item-last_error"`
+	NextAttemptAt string `json:"item-next_attempt_at"`
+	UpdatedAt     string `json:"item-updated_at"`
+}
+
+func NewJobItem(i *model.GetJobItemsByStageRow) JobItem {
+	return JobItem{
+		ID:            i.ID,
+		NewsID:        i.NewsID,
+		Title:         i.Title,
+		Link:          i.Link,
+		Stage:         string(i.Stage),
+		Attempts:      i.Attempts,
+		LastError:     i.LastError.String,
+		NextAttemptAt: i.NextAttemptAt.Time.UTC().Format(time.DateTime),
+		UpdatedAt:     i.UpdatedAt.Time.UTC().Format(time.DateTime),
+	}
+}
+
+type JobUsage struct {
+	Provider     string  `json:"usage-provider"`
+	Model        string  `json:"usage-model"`
+	Operation    string  `json:"usage-operation"`
+	NCalls       int64   `json:"usage-n_calls"`
+	InputTokens  int64   `json:"usage-input_tokens"`
+	OutputTokens int64   `json:"usage-output_tokens"`
+	Cost         float64 `json:"usage-cost"`
+	NUnpriced    int64   `json:"usage-n_unpriced"`
+}
+
+func NewJobUsage(u *model.GetJobUsageRow) JobUsage {
+	return JobUsage{
+		Provider:     u.Provider,
+		Model:        u.Model,
+		Operation:    u.Operation,
+		NCalls:       u.NCalls,
+		InputTokens:  u.InputTokens,
+		OutputTokens: u.OutputTokens,
+		Cost:         u.Cost,
+		NUnpriced:    u.NUnpriced,
+	}
+}
+
+type WebhookPage struct {
+	Page
+	Secret     string
+	Webhooks   []Webhook
+	Deliveries []WebhookDelivery
+}
+
+type Webhook struct {
+	ID        int64  `json:"webhook-id"`
+	Url       string `json:"webhook-url"`
+	CreatedAt string `json:"webhook-created_at"`
+}
+
+func NewWebhook(w *model.ListWebhooksRow) Webhook {
+	return Webhook{
+		ID:        w.ID,
+		Url:       w.Url,
+		CreatedAt: w.CreatedAt.Time.UTC().Format(time.DateTime),
+	}
+}
+
+type WebhookDelivery struct {
+	ID            int64  `json:"delivery-id"`
+	WebhookID     int64  `json:"delivery-webhook_id"`
+	Url           string `json:"delivery-url"`
+	JobID         int64  `json:"delivery-job_id"`
+	Status        string `json:"delivery-status"`
+	Stage         string `json:"delivery-stage"`
+	Attempts      int32  `json:"delivery-attempts"`
+	ResponseCode  int32  `json:"delivery-response_code,omitempty"`
+	LastError     string `json:"delivery-last_error"`
+	NextAttemptAt string `json:"delivery-next_attempt_at"`
+	CreatedAt     string `json:"delivery-created_at"`
+	UpdatedAt     string `json:"delivery-updated_at"`
+}
+
+func NewWebhookDelivery(d *model.ListWebhookDeliveriesRow) WebhookDelivery {
+	return WebhookDelivery{
+		ID:            d.ID,
+		WebhookID:     d.WebhookID,
+		Url:           d.Url,
+		JobID:         d.JobID,
+		Status:        string(d.Status),
+		Stage:         string(d.Stage),
+		Attempts:      d.Attempts,
+		ResponseCode:  d.ResponseCode.Int32,
+		LastError:     d.LastError.String,
+		NextAttemptAt: d.NextAttemptAt.Time.UTC().Format(time.DateTime),
+		CreatedAt:     d.CreatedAt.Time.UTC().Format(time.DateTime),
+		UpdatedAt:     d.UpdatedAt.Time.UTC().Format(time.DateTime),
+	}
+}
+
+type PromptPage struct {
+	Page
+	Providers []PromptProvider
+	Prompts   []Prompt
+}
+
+// PromptProvider is an analyzer of which the prompt could be replaced, the
+// prompt it uses by default is the starting point of the first version.
+type PromptProvider struct {
+	Name          string
+	DefaultPrompt string
+}
+
+type Prompt struct {
+	ID        int64  `json:"prompt-id"`
+	Provider  string `json:"prompt-provider"`
+	Task      string `json:"prompt-task"`
+	Version   int32  `json:"prompt-version"`
+	Body      string `json:"prompt-body"`
+	CreatedAt string `json:"prompt-created_at"`
+}
+
+func NewPrompt(p *model.ListPromptsRow) Prompt {
+	return Prompt{
+		ID:        p.ID,
+		Provider:  p.Provider,
+		Task:      p.Task,
+		Version:   p.Version,
+		Body:      p.Body,
+		CreatedAt: p.CreatedAt.Time.UTC().Format(time.DateTime),
+	}
+}
+
+type StoriesPage struct {
+	Page
+	Stories []Story
+}
+
+// Story is a group of news of different sources covering the same event.
+type Story struct {
+	ID             int64  `json:"story-id"`
+	Model          string `json:"story-model"`
+	Title          string `json:"story-title"`
+	FirstPublishAt string `json:"story-first_publish_at"`
+	LastPublishAt  string `json:"story-last_publish_at"`
+	NSources       int32  `json:"story-n_sources"`
+}
+
+func NewStory(s *model.ListStoriesRow) Story {
+	return Story{
+		ID:             s.ID,
+		Model:          s.Model,
+		Title:          s.Title,
+		FirstPublishAt: s.FirstPublishAt.Time.UTC().Format(time.DateTime),
+		LastPublishAt:  s.LastPublishAt.Time.UTC().Format(time.DateTime),
+		NSources:       s.NSources,
+	}
+}
+
+// StoryPage shows the headline and the sentiment of every source of the story
+// side by side.
+type StoryPage struct {
+	Page
+	Story Story
+	News  []StoryNews
+}
+
+type StoryNews struct {
+	NewsId     int64  `json:"news-id"`
+	Source     string `json:"news-source"`
+	Title      string `json:"news-title"`
+	Link       string `json:"news-link"`
+	PublishAt  string `json:"news-publish_at"`
+	Similarity string `json:"news-similarity"`
+	Sentiment  string `json:"news-sentiment"`
+	Score      int16  `json:"news-score"`
+}
+
+func NewStoryNews(n *model.GetStoryNewsRow) StoryNews {
+	return StoryNews{
+		NewsId:     n.NewsID,
+		Source:     n.Source,
+		Title:      n.Title,
+		Link:       n.Link,
+		PublishAt:  n.PublishAt.Time.UTC().Format(time.DateTime),
+		Similarity: fmt.Sprintf("%.3f", n.Similarity),
+		Sentiment:  string(n.Sentiment),
+		Score:      n.Score,
+	}
+}
+
 type APIAdminPage struct {
 	Page
 }
@@ -230,10 +681,17 @@ type APIOption struct {
 }
 
 type APIKey struct {
-	ID   int16  `json:"id"`
-	Name string `json:"name"`
-	Icon string `json:"icon"`
-	Key  string `json:"key"`
+	ID      int16    `json:"id"`
+	Name    string   `json:"name"`
+	Icon    string   `json:"icon"`
+	Key     string   `json:"key"`
+	BaseURL string   `json:"base_url,omitempty"`
+	Models  []string `json:"models,omitempty"`
+}
+
+// ModelList returns the comma separated models served by the server of the key.
+func (apikey APIKey) ModelList() string {
+	return strings.Join(apikey.Models, ", ")
 }
 
 func (apikey APIKey) InputID() string {
@@ -356,6 +814,50 @@ type ResultSecectorPage struct {
 
 type AnalyzerPage struct {
 	Page
-	Prompt  map[string]string
-	Version string
+	Analyzers []AnalyzerForm
+	Version   string
+}
+
+// AnalyzerForm is the options of a language model provider on the analyzer
+// page, API is the name the provider is registered under.
+type AnalyzerForm struct {
+	API             string
+	Title           string
+	APIId           int16
+	EmbeddingModels []AnalyzerModelOpt
+	Prompt          string
+	InputTypes      []string
+	Truncates       []string
+	// the provider runs locally, without an API key or token limit
+	Offline bool
+	// the provider classifies the sentiment toward target entities
+	Entities bool
+	// the provider labels news in custom classification tasks
+	Classification bool
+	// the sentiment prompts of the user for the provider, the latest first
+	Prompts []AnalyzerPromptOpt
+	// the estimated usage of analyzing the selected news, nil if unknown
+	Estimate *AnalyzerEstimate
+}
+
+// AnalyzerEstimate is the estimated usage and cost in USD of a job with the
+// default embedding model. The cost excludes the models in Unpriced.
+type AnalyzerEstimate struct {
+	NArticles    int
+	InputTokens  int
+	OutputTokens int
+	Cost         float64
+	Unpriced     []string
+}
+
+type AnalyzerPromptOpt struct {
+	ID        int64
+	Version   int32
+	CreatedAt string
+}
+
+type AnalyzerModelOpt struct {
+	Name     string
+	Dim      int
+	Selected bool
 }
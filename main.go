@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -13,6 +14,7 @@ import (
 	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/model"
 	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/server/cookieMaker"
 	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/server/router"
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/server/runner"
 	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/server/service"
 	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/server/validator"
 	"github.com/ChiaYuChang/NewsSentimentAnalyzer/internal/server/view"
@@ -95,6 +97,78 @@ func main() {
 		os.Exit(1)
 	}
 
+	rnr := runner.NewRunner(
+		srvc,
+		global.AppVar.JobRunner.Interval,
+		global.AppVar.JobRunner.NJobsPerUser,
+		global.AppVar.JobRunner.Timeout,
+	).WithRetry(
+		global.AppVar.JobRunner.MaxAttempts,
+		global.AppVar.JobRunner.BackoffBase,
+		global.AppVar.JobRunner.BackoffMax,
+	).WithBatch(
+		global.AppVar.JobRunner.BatchBudget,
+		global.AppVar.JobRunner.BatchSize,
+	).WithPrices(global.AppVar.Pricing).WithCache(global.AppVar.JobRunner.CacheTTL)
+	if err := rnr.Start(); err != nil {
+		global.Logger.
+			Err(err).
+			Msg("error while starting job runner")
+		os.Exit(1)
+	}
+	global.Logger.Info().Msg("Job runner started")
+
+	sch := runner.NewScheduler(
+		srvc,
+		global.AppVar.JobScheduler.Interval,
+		global.AppVar.JobScheduler.NSchedules,
+		global.AppVar.JobScheduler.MaxPages,
+		global.AppVar.JobScheduler.Timeout,
+	)
+	if err := sch.Start(); err != nil {
+		global.Logger.
+			Err(err).
+			Msg("error while starting job scheduler")
+		os.Exit(1)
+	}
+	global.Logger.Info().Msg("Job scheduler started")
+
+	ntf := runner.NewNotifier(
+		srvc,
+		global.AppVar.Webhook.Interval,
+		global.AppVar.Webhook.NDeliveries,
+		global.AppVar.Webhook.Timeout,
+	).WithRetry(
+		global.AppVar.Webhook.MaxAttempts,
+		global.AppVar.Webhook.BackoffBase,
+		global.AppVar.Webhook.BackoffMax,
+	).WithHTTPClient(runner.NewWebhookClient(
+		global.AppVar.Webhook.Timeout,
+		global.AppVar.Webhook.AllowPrivate,
+	))
+	if err := ntf.Start(); err != nil {
+		global.Logger.
+			Err(err).
+			Msg("error while starting webhook notifier")
+		os.Exit(1)
+	}
+	global.Logger.Info().Msg("Webhook notifier started")
+
+	cls := runner.NewClusterer(
+		srvc,
+		global.AppVar.StoryClusterer.Interval,
+		global.AppVar.StoryClusterer.BatchSize,
+		global.AppVar.StoryClusterer.Threshold,
+		global.AppVar.StoryClusterer.Window,
+	)
+	if err := cls.Start(); err != nil {
+		global.Logger.
+			Err(err).
+			Msg("error while starting story clusterer")
+		os.Exit(1)
+	}
+	global.Logger.Info().Msg("Story clusterer started")
+
 	addr := fmt.Sprintf(
 		"%s:%d",
 		viper.GetString("APP_HOST"),
@@ -105,10 +179,17 @@ func main() {
 		Time("time", time.Now()).
 		Msg("Server started")
 
+	// long-lived responses, e.g. job progress streams, stop on shutdown
+	shutdownChan := make(chan struct{})
 	server := &http.Server{
 		Addr:    addr,
-		Handler: router.NewRouter(srvc, rds, vw, tm, cm),
+		Handler: router.NewRouter(srvc, rds, rnr, vw, tm, cm),
+		BaseContext: func(net.Listener) context.Context {
+			return context.WithValue(context.Background(),
+				global.CtxServerShutdown, (<-chan struct{})(shutdownChan))
+		},
 	}
+	server.RegisterOnShutdown(func() { close(shutdownChan) })
 
 	serverCtx, serverCancel := context.WithCancel(context.Background())
 	signalChan := make(chan os.Signal)
@@ -139,6 +220,19 @@ func main() {
 		}()
 
 		go func() {
+			// the job runner, scheduler, notifier and clusterer should be stopped before closing the connection
+			ec <- sch.Shutdown(shutdownCtx)
+			global.Logger.Info().Msg("Job scheduler stopped")
+
+			ec <- cls.Shutdown(shutdownCtx)
+			global.Logger.Info().Msg("Story clusterer stopped")
+
+			ec <- ntf.Shutdown(shutdownCtx)
+			global.Logger.Info().Msg("Webhook notifier stopped")
+
+			ec <- rnr.Shutdown(shutdownCtx)
+			global.Logger.Info().Msg("Job runner stopped")
+
 			ec <- srvc.Close(shutdownCtx)
 			global.Logger.Info().Msg("PostgresSQL connection closed")
 		}()
@@ -149,7 +243,7 @@ func main() {
 		}()
 
 		var ecErr *errorcode.Error
-		for i := 0; i < 3; i++ {
+		for i := 0; i < 7; i++ {
 			err := <-ec
 			if err != nil {
 				if ecErr == nil {
@@ -0,0 +1,131 @@
+package cron_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/pkgs/cron"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	type testCase struct {
+		Expr    string
+		IsValid bool
+	}
+
+	tcs := []testCase{
+		{"* * * * *", true},
+		{"0 8 * * *", true},
+		{"*/15 9-17 * * 1-5", true},
+		{"0 0 1,15 * *", true},
+		{"5/10 * * * *", true},
+		{"0 0 * * 7", true},
+		{"@daily", true},
+		{" @Hourly ", true},
+		{"", false},
+		{"* * * *", false},
+		{"* * * * * *", false},
+		{"60 * * * *", false},
+		{"* 24 * * *", false},
+		{"* * 0 * *", false},
+		{"* * * 13 *", false},
+		{"* * * * 8", false},
+		{"5-1 * * * *", false},
+		{"*/0 * * * *", false},
+		{"a * * * *", false},
+		{"@every 5m", false},
+	}
+
+	for i, tc := range tcs {
+		t.Run(
+			fmt.Sprintf("Case %d-%s", i+1, tc.Expr),
+			func(t *testing.T) {
+				s, err := cron.Parse(tc.Expr)
+				require.Equal(t, tc.IsValid, cron.IsValid(tc.Expr))
+				if !tc.IsValid {
+					require.ErrorIs(t, err, cron.ErrInvalidExpression)
+					return
+				}
+				require.NoError(t, err)
+				require.NotNil(t, s)
+			},
+		)
+	}
+}
+
+func TestNext(t *testing.T) {
+	tpe, err := time.LoadLocation("Asia/Taipei")
+	if err != nil {
+		tpe = time.FixedZone("CST", 8*60*60)
+	}
+
+	type testCase struct {
+		Expr string
+		From time.Time
+		Next time.Time
+	}
+
+	tcs := []testCase{
+		{
+			Expr: "0 8 * * *",
+			From: time.Date(2023, 12, 1, 7, 59, 30, 0, tpe),
+			Next: time.Date(2023, 12, 1, 8, 0, 0, 0, tpe),
+		},
+		{
+			// strictly after
+			Expr: "0 8 * * *",
+			From: time.Date(2023, 12, 1, 8, 0, 0, 0, tpe),
+			Next: time.Date(2023, 12, 2, 8, 0, 0, 0, tpe),
+		},
+		{
+			Expr: "*/15 * * * *",
+			From: time.Date(2023, 12, 1, 8, 16, 0, 0, tpe),
+			Next: time.Date(2023, 12, 1, 8, 30, 0, 0, tpe),
+		},
+		{
+			// friday to monday
+			Expr: "30 9 * * 1-5",
+			From: time.Date(2023, 12, 1, 10, 0, 0, 0, tpe),
+			Next: time.Date(2023, 12, 4, 9, 30, 0, 0, tpe),
+		},
+		{
+			Expr: "@monthly",
+			From: time.Date(2023, 12, 15, 0, 0, 0, 0, tpe),
+			Next: time.Date(2024, 1, 1, 0, 0, 0, 0, tpe),
+		},
+		{
+			Expr: "0 0 29 2 *",
+			From: time.Date(2023, 3, 1, 0, 0, 0, 0, tpe),
+			Next: time.Date(2024, 2, 29, 0, 0, 0, 0, tpe),
+		},
+		{
+			// either the 13th or a friday when both are restricted
+			Expr: "0 0 13 * 5",
+			From: time.Date(2023, 12, 2, 0, 0, 0, 0, tpe),
+			Next: time.Date(2023, 12, 8, 0, 0, 0, 0, tpe),
+		},
+		{
+			// 7 is sunday
+			Expr: "0 0 * * 7",
+			From: time.Date(2023, 12, 1, 0, 0, 0, 0, tpe),
+			Next: time.Date(2023, 12, 3, 0, 0, 0, 0, tpe),
+		},
+		{
+			Expr: "0 0 31 2 *",
+			From: time.Date(2023, 12, 1, 0, 0, 0, 0, tpe),
+			Next: time.Time{},
+		},
+	}
+
+	for i, tc := range tcs {
+		t.Run(
+			fmt.Sprintf("Case %d-%s", i+1, tc.Expr),
+			func(t *testing.T) {
+				next := cron.MustParse(tc.Expr).Next(tc.From)
+				require.True(t, tc.Next.Equal(next), "expected %v, got %v", tc.Next, next)
+			},
+		)
+	}
+}
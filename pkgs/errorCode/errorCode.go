@@ -37,6 +37,7 @@ const (
 // Self defined client error
 const (
 	ECInvalidParams ErrorCode = 460
+	ECQuotaExceeded ErrorCode = 461
 )
 
 // Server side error
@@ -0,0 +1,105 @@
+package npy
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+var ErrShapeMismatch = errors.New("row does not match the shape")
+
+// the magic string and format version 1.0
+var magic = []byte{0x93, 'N', 'U', 'M', 'P', 'Y', 1, 0}
+
+// the data of an array starts at a multiple of 64 bytes
+const headerAlign = 64
+
+// WriteHeader writes the header of a C ordered array with the given dtype
+// descriptor, e.g. <f4, and shape.
+func WriteHeader(w io.Writer, descr string, shape ...int) error {
+	dims := make([]string, len(shape))
+	for i, d := range shape {
+		if d < 0 {
+			return fmt.Errorf("%w: negative dimension %d", ErrShapeMismatch, d)
+		}
+		dims[i] = fmt.Sprint(d)
+	}
+
+	shapeStr := strings.Join(dims, ", ")
+	if len(shape) == 1 {
+		// a tuple with a single element
+		shapeStr += ","
+	}
+
+	header := fmt.Sprintf("{'descr': '%s', 'fortran_order': False, 'shape': (%s), }", descr, shapeStr)
+	// the header ends with a newline and is padded with spaces
+	pad := headerAlign - (len(magic)+2+len(header)+1)%headerAlign
+	if pad == headerAlign {
+		pad = 0
+	}
+	header += strings.Repeat(" ", pad) + "\n"
+	if len(header) > math.MaxUint16 {
+		return fmt.Errorf("header is too long: %d bytes", len(header))
+	}
+
+	buf := make([]byte, 0, len(magic)+2+len(header))
+	buf = append(buf, magic...)
+	buf = binary.LittleEndian.AppendUint16(buf, uint16(len(header)))
+	buf = append(buf, header...)
+	_, err := w.Write(buf)
+	return err
+}
+
+// Float32Writer writes a 2-D float32 matrix row by row, so that the matrix
+// does not need to be held in memory.
+type Float32Writer struct {
+	w     io.Writer
+	nRows int
+	nCols int
+	n     int
+	buf   []byte
+}
+
+// NewFloat32Writer writes the header of a nRows x nCols float32 matrix to w.
+func NewFloat32Writer(w io.Writer, nRows, nCols int) (*Float32Writer, error) {
+	if err := WriteHeader(w, "<f4", nRows, nCols); err != nil {
+		return nil, err
+	}
+	return &Float32Writer{
+		w:     w,
+		nRows: nRows,
+		nCols: nCols,
+		buf:   make([]byte, 4*nCols),
+	}, nil
+}
+
+// WriteRow writes the next row of the matrix.
+func (fw *Float32Writer) WriteRow(row []float32) error {
+	if len(row) != fw.nCols {
+		return fmt.Errorf("%w: expected %d columns, got %d", ErrShapeMismatch, fw.nCols, len(row))
+	}
+	if fw.n >= fw.nRows {
+		return fmt.Errorf("%w: expected %d rows", ErrShapeMismatch, fw.nRows)
+	}
+
+	for i, v := range row {
+		binary.LittleEndian.PutUint32(fw.buf[4*i:], math.Float32bits(v))
+	}
+	if _, err := fw.w.Write(fw.buf); err != nil {
+		return err
+	}
+	fw.n++
+	return nil
+}
+
+// Close reports an error if fewer rows than the shape have been written. It
+// does not close the underlying writer.
+func (fw *Float32Writer) Close() error {
+	if fw.n != fw.nRows {
+		return fmt.Errorf("%w: expected %d rows, got %d", ErrShapeMismatch, fw.nRows, fw.n)
+	}
+	return nil
+}
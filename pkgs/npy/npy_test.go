@@ -0,0 +1,60 @@
+package npy_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/ChiaYuChang/NewsSentimentAnalyzer/pkgs/npy"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteHeader(t *testing.T) {
+	type testCase struct {
+		Shape  []int
+		Header string
+	}
+
+	tcs := []testCase{
+		{[]int{2, 3}, "{'descr': '<f4', 'fortran_order': False, 'shape': (2, 3), }"},
+		{[]int{5}, "{'descr': '<f4', 'fortran_order': False, 'shape': (5,), }"},
+		{[]int{0, 1536}, "{'descr': '<f4', 'fortran_order': False, 'shape': (0, 1536), }"},
+	}
+
+	for _, tc := range tcs {
+		buf := bytes.NewBuffer(nil)
+		require.NoError(t, npy.WriteHeader(buf, "<f4", tc.Shape...))
+
+		b := buf.Bytes()
+		require.Equal(t, []byte("\x93NUMPY\x01\x00"), b[:8])
+		require.Zero(t, len(b)%64)
+
+		hLen := int(binary.LittleEndian.Uint16(b[8:10]))
+		require.Equal(t, len(b)-10, hLen)
+		require.Equal(t, byte('\n'), b[len(b)-1])
+		require.Equal(t, tc.Header, string(bytes.TrimRight(b[10:], " \n")))
+	}
+}
+
+func TestFloat32Writer(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	fw, err := npy.NewFloat32Writer(buf, 2, 3)
+	require.NoError(t, err)
+	offset := buf.Len()
+
+	require.NoError(t, fw.WriteRow([]float32{1, 2, 3}))
+	require.ErrorIs(t, fw.Close(), npy.ErrShapeMismatch)
+	require.ErrorIs(t, fw.WriteRow([]float32{1, 2}), npy.ErrShapeMismatch)
+	require.NoError(t, fw.WriteRow([]float32{4, 5, -0.5}))
+	require.ErrorIs(t, fw.WriteRow([]float32{7, 8, 9}), npy.ErrShapeMismatch)
+	require.NoError(t, fw.Close())
+
+	data := buf.Bytes()[offset:]
+	require.Len(t, data, 2*3*4)
+
+	expected := []float32{1, 2, 3, 4, 5, -0.5}
+	for i, v := range expected {
+		require.Equal(t, v, math.Float32frombits(binary.LittleEndian.Uint32(data[4*i:])))
+	}
+}
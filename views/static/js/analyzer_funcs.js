@@ -4,41 +4,41 @@ const urlParams = new URLSearchParams(window.location.search);
 const aid = urlParams.get('aid')
 const eid = urlParams.get('eid')
 
-var api_name_to_id = {
-    "openai": 5,
-    "cohere": 6
-}
-
-var llm_api = "openai";
+var llm_api = "";
 var has_sent = false;
-const useOpenAI = document.getElementById('openai');
-const useCohere = document.getElementById('cohere');
+const llmBtns = document.querySelectorAll('button.llm-btn');
+const llmOpts = document.querySelectorAll('form.llm-option');
 const doEmbedding = document.getElementById('do-embedding');
 const doSentiment = document.getElementById('do-sentiment');
-const openaiOpt = document.getElementById('openai-option');
-const cohereOpt = document.getElementById('cohere-option');
+const noCache = document.getElementById('no-cache');
+const schedule = document.getElementById('schedule');
 const embeddingOpt = document.querySelectorAll('div.option[type="embedding"]');
 const sentimentOpt = document.querySelectorAll('div.option[type="sentiment"]');
 const submitEl = document.querySelector('input[type="submit"]');
 
-document.addEventListener('DOMContentLoaded', () => {
-    useCohere.classList.add("btn-inactive");
-    cohereOpt.style.display = 'none';
-    
-    useOpenAI.addEventListener('click', () => {
-        openaiOpt.style.display = 'block';
-        useOpenAI.classList.remove("btn-inactive");
-        cohereOpt.style.display = 'none';
-        useCohere.classList.add("btn-inactive");
-        llm_api = "openai";
+function selectAnalyzer(api) {
+    llmBtns.forEach(btn => {
+        if (btn.id === api) {
+            btn.classList.remove("btn-inactive");
+        } else {
+            btn.classList.add("btn-inactive");
+        }
+    });
+    llmOpts.forEach(opt => {
+        opt.style.display = opt.id === `${api}-option` ? 'block' : 'none';
     });
+    llm_api = api;
+}
+
+document.addEventListener('DOMContentLoaded', () => {
+    if (llmBtns.length > 0) {
+        selectAnalyzer(llmBtns[0].id);
+    }
 
-    useCohere.addEventListener('click', () => {
-        openaiOpt.style.display = 'none';
-        useOpenAI.classList.add("btn-inactive");
-        cohereOpt.style.display = 'block';
-        useCohere.classList.remove("btn-inactive");
-        llm_api = "cohere";
+    llmBtns.forEach(btn => {
+        btn.addEventListener('click', () => {
+            selectAnalyzer(btn.id);
+        });
     });
 
     doEmbedding.addEventListener('change', () => {
@@ -85,11 +85,11 @@ async function submitForm() {
     }
     const api_options = document.getElementById(`${llm_api}-option`);
     const fdata = new URLSearchParams(new FormData(api_options));
-    let llm_api_id = api_name_to_id[llm_api];
 
     fdata.append("do-embedding", doEmbedding.checked);
     fdata.append("do-sentiment", doSentiment.checked);
-    fdata.append("llm-api-id", llm_api_id);
+    fdata.append("no-cache", noCache.checked);
+    fdata.append("schedule", schedule.value.trim());
 
     console.log(fdata);
 
@@ -106,6 +106,13 @@ async function submitForm() {
         if ('error' in data) {
             err = data['error'];
             switch (err['code']) {
+            case 400:
+                ShowAlertToast(
+                    message = "Invalid schedule",
+                    x = 50, y = 10, duration = 3000,
+                    destination = "",
+                )
+                break;
             case 410:
                 ShowAlertToast(
                     message = "Preview has been expired, please create a new one",
@@ -114,6 +121,13 @@ async function submitForm() {
                 )
                 has_sent = true;
                 break;
+            case 429:
+                ShowAlertToast(
+                    message = err['message'],
+                    x = 50, y = 10, duration = 5000,
+                    destination = "",
+                )
+                break;
             case 500:
                 if ('pgx_code' in err) {
                     if (err['pgx_code'] === '23505') {
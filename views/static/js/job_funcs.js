@@ -1,5 +1,6 @@
 var pagerCache = new Map();
 var detailCache = new Map();
+var progressSource = null;
 
 const urlParams = new URLSearchParams(window.location.search);
 var selectedJobId = parseInt(urlParams.get('jid'));
@@ -248,6 +249,21 @@ var detailsFields = [
         "field_name": "job-analyzer_query",
         "is_mono": true,
     },
+    {
+        "row_header": "Partial",
+        "field_name": "job-partial",
+        "is_mono": false,
+    },
+    {
+        "row_header": "Parent Job",
+        "field_name": "job-parent_id",
+        "is_mono": true,
+    },
+    {
+        "row_header": "Cloned From",
+        "field_name": "job-cloned_from",
+        "is_mono": true,
+    },
     {
         "row_header": "Created At",
         "field_name": "job-created_at",
@@ -321,6 +337,21 @@ async function getJobDetails(id) {
                 td.textContent = decodeURIComponent(data["job-news_api_query"]);
                 td.classList.add("mono")
                 break;
+            case "job-parent_id":
+            case "job-cloned_from":
+                // only jobs created by a schedule have a parent, and only
+                // cloned jobs have a source
+                if (!(f.field_name in data)) { return }
+                let a = document.createElement("a")
+                a.setAttribute("href", "#")
+                a.textContent = data[f.field_name]
+                a.addEventListener("click", (event) => {
+                    event.preventDefault();
+                    getJobDetails(data[f.field_name]);
+                })
+                td.classList.add("mono")
+                td.appendChild(a)
+                break;
             default:
                 td.textContent = data[f.field_name]
                 if (f.is_mono) {
@@ -332,4 +363,575 @@ async function getJobDetails(id) {
         tr.appendChild(td)
         dtbodyEl.appendChild(tr)
     })
+
+    const cancelBtn = document.getElementById("cancel-job");
+    if (data["job-status"] === "created" || data["job-status"] === "running") {
+        cancelBtn.removeAttribute("hidden");
+    } else {
+        cancelBtn.setAttribute("hidden", "");
+    }
+    document.querySelectorAll("#export a").forEach((a) => {
+        a.setAttribute("href", `/v1/job/${data["job-id"]}/export?format=${a.getAttribute("format")}`);
+    });
+    watchProgress(data["job-id"]);
+    getDeadItems(data["job-id"]);
+    getSchedule(data["job-id"]);
+    getComparison(data["job-id"], data["job-cloned_from"]);
+    getStats(data["job-id"]);
+    getUsage(data["job-id"]);
+}
+
+var progressFields = [
+    {
+        "row_header": "Total",
+        "field_name": "progress-total",
+    },
+    {
+        "row_header": "Parsed",
+        "field_name": "progress-parsed",
+    },
+    {
+        "row_header": "Language Detected",
+        "field_name": "progress-lang_detected",
+    },
+    {
+        "row_header": "Embedded",
+        "field_name": "progress-embedded",
+    },
+    {
+        "row_header": "Sentiment Scored",
+        "field_name": "progress-sentiment_scored",
+    },
+    {
+        "row_header": "Failed",
+        "field_name": "progress-failed",
+    },
+]
+
+// the server pushes the progress of the job until it is finished
+function watchProgress(id) {
+    if (progressSource !== null) {
+        progressSource.close();
+    }
+
+    progressSource = new EventSource(`/v1/job/${id}/progress`);
+    progressSource.addEventListener("progress", (event) => {
+        updateProgress(JSON.parse(event.data));
+    });
+    progressSource.addEventListener("end", (event) => {
+        event.target.close();
+    });
+}
+
+function updateProgress(data) {
+    const detailEl = document.getElementById("detail");
+    if (detailEl.getAttribute("job-id") !== ('' + data["job-id"])) { return }
+
+    const ptbodyEl = document.getElementById("progress-table-body")
+    ptbodyEl.replaceChildren()
+    progressFields.forEach((f) => {
+        let tr = document.createElement("tr")
+        let th = document.createElement("th")
+        th.textContent = f.row_header
+        th.setAttribute("scope", "row")
+
+        let td = document.createElement("td")
+        td.textContent = data[f.field_name]
+        td.classList.add("mono")
+
+        tr.appendChild(th)
+        tr.appendChild(td)
+        ptbodyEl.appendChild(tr)
+    })
+
+    let cached = detailCache.get(data["job-id"]);
+    if (cached === undefined || cached["job-status"] === data["job-status"]) { return }
+
+    // the status has changed, the job list and details are outdated
+    pagerCache.clear();
+    cached["job-status"] = data["job-status"];
+    document.querySelectorAll(`#detail-table-body .job-status, #job-${data["job-id"]} .job-status`)
+        .forEach((el) => {
+            el.setAttribute("status", data["job-status"]);
+            el.textContent = data["job-status"];
+        });
+    if (data["job-status"] !== "created" && data["job-status"] !== "running") {
+        document.getElementById("cancel-job").setAttribute("hidden", "");
+    }
+    getDeadItems(data["job-id"]);
+}
+
+async function getUsage(id) {
+    const usageEl = document.getElementById("usage");
+    const response = await fetch(`/v1/job/${id}/usage`);
+    if (response.status != 200) {
+        usageEl.setAttribute("hidden", "");
+        return
+    }
+
+    const usages = await response.json();
+    const detailEl = document.getElementById("detail");
+    if (detailEl.getAttribute("job-id") !== ('' + id)) { return }
+    if (usages.length === 0) {
+        usageEl.setAttribute("hidden", "");
+        return
+    }
+
+    const tbodyEl = document.getElementById("usage-table-body");
+    tbodyEl.replaceChildren();
+    let total = 0;
+    let unpriced = false;
+    usages.forEach((u) => {
+        let tr = document.createElement("tr");
+        ["usage-provider", "usage-model", "usage-operation", "usage-n_calls",
+            "usage-input_tokens", "usage-output_tokens"].forEach((f) => {
+            let td = document.createElement("td");
+            td.textContent = u[f];
+            td.classList.add("mono");
+            tr.appendChild(td);
+        });
+
+        // the cost of the calls to a model without a price is unknown
+        let cost = document.createElement("td");
+        cost.textContent = u["usage-n_unpriced"] > 0 ? "n/a" : `$${u["usage-cost"].toFixed(4)}`;
+        cost.classList.add("mono");
+        tr.appendChild(cost);
+
+        total += u["usage-cost"];
+        unpriced = unpriced || u["usage-n_unpriced"] > 0;
+        tbodyEl.appendChild(tr);
+    });
+    document.getElementById("usage-total").textContent =
+        `$${total.toFixed(4)}` + (unpriced ? " (excluding the unpriced models)" : "");
+    usageEl.removeAttribute("hidden");
+}
+
+async function getDeadItems(id) {
+    const deadEl = document.getElementById("dead-items");
+    const response = await fetch(`/v1/job/${id}/items?stage=dead`);
+    if (response.status != 200) {
+        deadEl.setAttribute("hidden", "");
+        return
+    }
+
+    const items = await response.json();
+    const detailEl = document.getElementById("detail");
+    if (detailEl.getAttribute("job-id") !== ('' + id)) { return }
+    if (items.length === 0) {
+        deadEl.setAttribute("hidden", "");
+        return
+    }
+
+    const tbodyEl = document.getElementById("dead-items-table-body");
+    tbodyEl.replaceChildren();
+    items.forEach((item) => {
+        let tr = document.createElement("tr")
+
+        let th = document.createElement("th")
+        th.textContent = item["item-id"]
+        th.classList.add("mono")
+        tr.appendChild(th)
+
+        let news = document.createElement("td")
+        let a = document.createElement("a")
+        a.setAttribute("href", item["item-link"])
+        a.setAttribute("target", "_blank")
+        a.textContent = item["item-title"]
+        news.appendChild(a)
+        tr.appendChild(news)
+
+        let attempts = document.createElement("td")
+        attempts.textContent = item["item-attempts"]
+        attempts.classList.add("mono")
+        tr.appendChild(attempts)
+
+        let lastErr = document.createElement("td")
+        lastErr.textContent = item["item-last_error"]
+        lastErr.classList.add("mono")
+        tr.appendChild(lastErr)
+
+        let updatedAt = document.createElement("td")
+        updatedAt.textContent = item["item-updated_at"]
+        updatedAt.classList.add("mono")
+        tr.appendChild(updatedAt)
+
+        let action = document.createElement("td")
+        let btn = document.createElement("button")
+        btn.setAttribute("type", "button")
+        btn.setAttribute("class", "btn btn-small")
+        btn.textContent = "Requeue"
+        btn.addEventListener("click", () => { requeueItems(item["item-id"]) })
+        action.appendChild(btn)
+        tr.appendChild(action)
+
+        tbodyEl.appendChild(tr)
+    })
+    deadEl.removeAttribute("hidden");
+}
+
+// requeue the given dead item, or all dead items of the job if itemId is omitted
+async function requeueItems(itemId) {
+    const detailEl = document.getElementById("detail");
+    const id = parseInt(detailEl.getAttribute("job-id"));
+    if (isNaN(id)) { return }
+
+    let url = `/v1/job/${id}/items`
+    if (itemId !== undefined) {
+        url += `/${itemId}`
+    }
+
+    const response = await fetch(url, { method: "PATCH" });
+    const data = await response.json();
+    if (response.status != 200) {
+        console.error("Error:", data)
+        return
+    }
+
+    // the job list and details are outdated
+    pagerCache.clear();
+    detailCache.delete(id);
+    detailEl.removeAttribute("job-id");
+    getJobDetails(id);
+
+    let el = document.querySelector(`#job-${id} .job-status`);
+    if (el !== null) {
+        el.setAttribute("status", data["job-status"]);
+        el.textContent = data["job-status"];
+    }
+}
+
+async function cancelJob() {
+    const detailEl = document.getElementById("detail");
+    const id = parseInt(detailEl.getAttribute("job-id"));
+    if (isNaN(id)) { return }
+
+    const response = await fetch(`/v1/job/${id}`, { method: "PATCH" });
+    if (response.status != 200) {
+        response.json()
+            .then(err => { console.error("Error:", err) })
+            .catch(err => { console.error("Error:", err) });
+        return
+    }
+
+    // the job list and details are outdated
+    pagerCache.clear();
+    detailCache.delete(id);
+    detailEl.removeAttribute("job-id");
+    getJobDetails(id);
+
+    let el = document.querySelector(`#job-${id} .job-status`);
+    if (el !== null) {
+        el.setAttribute("status", "canceled");
+        el.textContent = "canceled";
+    }
+}
+
+var scheduleFields = [
+    {
+        "row_header": "Cron",
+        "field_name": "schedule-cron",
+    },
+    {
+        "row_header": "Next Run At",
+        "field_name": "schedule-next_run_at",
+    },
+    {
+        "row_header": "Last Run At",
+        "field_name": "schedule-last_run_at",
+    },
+    {
+        "row_header": "Runs",
+        "field_name": "schedule-n_runs",
+    },
+]
+
+async function getSchedule(id) {
+    const scheduleEl = document.getElementById("schedule");
+    const response = await fetch(`/v1/job/${id}/schedule`);
+    if (response.status != 200) {
+        // the job is not scheduled
+        scheduleEl.setAttribute("hidden", "");
+        return
+    }
+
+    const data = await response.json();
+    const detailEl = document.getElementById("detail");
+    if (detailEl.getAttribute("job-id") !== ('' + id)) { return }
+    showSchedule(data);
+}
+
+function showSchedule(data) {
+    const tbodyEl = document.getElementById("schedule-table-body");
+    tbodyEl.replaceChildren();
+    scheduleFields.forEach((f) => {
+        let tr = document.createElement("tr")
+        let th = document.createElement("th")
+        th.textContent = f.row_header
+        th.setAttribute("scope", "row")
+
+        let td = document.createElement("td")
+        td.textContent = data[f.field_name] ?? "-"
+        td.classList.add("mono")
+
+        tr.appendChild(th)
+        tr.appendChild(td)
+        tbodyEl.appendChild(tr)
+    })
+    document.getElementById("schedule-cron").value = data["schedule-cron"];
+    document.getElementById("schedule").removeAttribute("hidden");
+}
+
+async function updateSchedule() {
+    const detailEl = document.getElementById("detail");
+    const id = parseInt(detailEl.getAttribute("job-id"));
+    if (isNaN(id)) { return }
+
+    const fdata = new URLSearchParams();
+    fdata.append("schedule", document.getElementById("schedule-cron").value.trim());
+    const response = await fetch(`/v1/job/${id}/schedule`, {
+        method: "PUT",
+        headers: {
+            "Content-Type": "application/x-www-form-urlencoded",
+        },
+        body: fdata
+    });
+    const data = await response.json();
+    if (response.status != 200) {
+        console.error("Error:", data)
+        return
+    }
+    showSchedule(data);
+}
+
+async function deleteSchedule() {
+    const detailEl = document.getElementById("detail");
+    const id = parseInt(detailEl.getAttribute("job-id"));
+    if (isNaN(id)) { return }
+
+    const response = await fetch(`/v1/job/${id}/schedule`, { method: "DELETE" });
+    if (response.status != 200) {
+        response.json()
+            .then(err => { console.error("Error:", err) })
+            .catch(err => { console.error("Error:", err) });
+        return
+    }
+    document.getElementById("schedule").setAttribute("hidden", "");
+}
+
+var clone_api_name_to_id = {
+    "openai": 5,
+    "cohere": 6
+}
+
+var clone_embedding_models = {
+    "openai": [
+        "text-embedding-ada-002",
+    ],
+    "cohere": [
+        "embed-multilingual-light-v3.0",
+        "embed-multilingual-v3.0",
+        "embed-english-v3.0",
+        "embed-english-light-v3.0",
+    ],
+}
+
+function showCloneModels() {
+    const api = document.getElementById("clone-api").value;
+    const selectEl = document.getElementById("clone-embedding-model");
+    selectEl.replaceChildren();
+    clone_embedding_models[api].forEach((m) => {
+        let opt = document.createElement("option")
+        opt.value = m
+        opt.textContent = m
+        selectEl.appendChild(opt)
+    })
+}
+
+document.addEventListener("DOMContentLoaded", showCloneModels);
+
+// clone the selected job with the chosen analyzer and show the new job
+async function cloneJob() {
+    const detailEl = document.getElementById("detail");
+    const id = parseInt(detailEl.getAttribute("job-id"));
+    if (isNaN(id)) { return }
+
+    const api = document.getElementById("clone-api").value;
+    const fdata = new URLSearchParams();
+    fdata.append("api", api);
+    fdata.append("llm-api-id", clone_api_name_to_id[api]);
+    fdata.append("embedding-model", document.getElementById("clone-embedding-model").value);
+    fdata.append("do-embedding", true);
+    fdata.append("do-sentiment", true);
+    fdata.append("max-tokens", 100);
+
+    const response = await fetch(`/v1/job/${id}/clone`, {
+        method: "POST",
+        headers: {
+            "Content-Type": "application/x-www-form-urlencoded",
+        },
+        body: fdata
+    });
+    const data = await response.json();
+    if (response.status != 200) {
+        console.error("Error:", data)
+        return
+    }
+
+    // the job list is outdated
+    pagerCache.clear();
+    getJobDetails(data["job-id"]);
+}
+
+// show the sentiments of a cloned job next to those of its source
+async function getComparison(id, withId) {
+    const compareEl = document.getElementById("compare");
+    if (withId === undefined) {
+        compareEl.setAttribute("hidden", "");
+        return
+    }
+
+    const response = await fetch(`/v1/job/${id}/compare?with=${withId}`);
+    if (response.status != 200) {
+        compareEl.setAttribute("hidden", "");
+        return
+    }
+
+    const data = await response.json();
+    const detailEl = document.getElementById("detail");
+    if (detailEl.getAttribute("job-id") !== ('' + id)) { return }
+
+    ["a", "b"].forEach((k) => {
+        let job = data[`compare-job_${k}`]
+        document.getElementById(`compare-job-${k}`).textContent =
+            `Job ${job["job-id"]} (${job["job-model"]})`
+    })
+
+    const tbodyEl = document.getElementById("compare-table-body");
+    tbodyEl.replaceChildren();
+    data["compare-sentiments"].forEach((row) => {
+        let tr = document.createElement("tr")
+
+        let news = document.createElement("td")
+        let a = document.createElement("a")
+        a.setAttribute("href", row["news-link"])
+        a.setAttribute("target", "_blank")
+        a.textContent = row["news-title"]
+        news.appendChild(a)
+        tr.appendChild(news)
+
+        // highlight the news on which the analyzers disagree
+        const differ = row["sentiment-a"] && row["sentiment-b"] &&
+            row["sentiment-a"] !== row["sentiment-b"]
+        const sentiments = [row["sentiment-a"], row["sentiment-b"]]
+        sentiments.forEach((sentiment) => {
+            let td = document.createElement("td")
+            td.textContent = sentiment || "-"
+            td.classList.add("mono")
+            if (differ) {
+                td.classList.add("highlight-alert")
+            }
+            tr.appendChild(td)
+        })
+
+        tbodyEl.appendChild(tr)
+    })
+    compareEl.removeAttribute("hidden");
+}
+
+var jobStats = null;
+
+async function getStats(id) {
+    const statsEl = document.getElementById("stats");
+    const response = await fetch(`/v1/job/${id}/stats`);
+    if (response.status != 200) {
+        statsEl.setAttribute("hidden", "");
+        return
+    }
+
+    const data = await response.json();
+    const detailEl = document.getElementById("detail");
+    if (detailEl.getAttribute("job-id") !== ('' + id)) { return }
+    if (data["stats-overall"]["stats-n_news"] === 0) {
+        // nothing has been analyzed yet
+        statsEl.setAttribute("hidden", "");
+        return
+    }
+    jobStats = data;
+    showStats();
+    statsEl.removeAttribute("hidden");
+}
+
+function newStatsRow(header, stats) {
+    let tr = document.createElement("tr")
+    let th = document.createElement("th")
+    th.textContent = header
+    th.setAttribute("scope", "row")
+    tr.appendChild(th)
+
+    let n = document.createElement("td")
+    n.textContent = stats["stats-n_news"]
+    n.classList.add("mono")
+    tr.appendChild(n)
+
+    const sentiments = ["very_positive", "positive", "neutral", "negative", "very_negative"]
+    sentiments.forEach((s) => {
+        let td = document.createElement("td")
+        let ratio = (100 * stats[`stats-${s}_ratio`]).toFixed(1)
+        td.textContent = `${stats[`stats-n_${s}`]} (${ratio}%)`
+        td.classList.add("mono")
+        tr.appendChild(td)
+    })
+
+    let mean = document.createElement("td")
+    mean.textContent = stats["stats-mean_score"].toFixed(3)
+    mean.classList.add("mono")
+    tr.appendChild(mean)
+    return tr
+}
+
+function showStats() {
+    if (jobStats === null) { return }
+    const dimension = document.getElementById("stats-dimension").value;
+    const tbodyEl = document.getElementById("stats-table-body");
+    tbodyEl.replaceChildren();
+    tbodyEl.appendChild(newStatsRow("All", jobStats["stats-overall"]));
+    jobStats[dimension].forEach((stats) => {
+        let bucket = stats["stats-bucket"] || "-"
+        if ("stats-entity" in stats) {
+            // an empty bucket is the entity over all the sources
+            bucket = `${stats["stats-entity"]} × ${stats["stats-bucket"] || "All"}`
+        }
+        tbodyEl.appendChild(newStatsRow(bucket, stats));
+    })
+    showLabelStats();
+}
+
+function showLabelStats() {
+    const labelEl = document.getElementById("label-stats");
+    if (!jobStats["job-task"]) {
+        labelEl.setAttribute("hidden", "");
+        return
+    }
+    document.getElementById("label-stats-task").textContent = `Classification: ${jobStats["job-task"]}`;
+
+    const tbodyEl = document.getElementById("label-stats-table-body");
+    tbodyEl.replaceChildren();
+    jobStats["stats-label_source"].forEach((stats) => {
+        let tr = document.createElement("tr")
+        // an empty bucket is all the sources
+        const cells = [
+            stats["stats-bucket"] || "All",
+            stats["stats-label"],
+            stats["stats-n_news"],
+            `${(100 * stats["stats-ratio"]).toFixed(1)}%`,
+        ]
+        cells.forEach((c, i) => {
+            let td = document.createElement(i == 0 ? "th" : "td")
+            td.textContent = c
+            if (i > 1) { td.classList.add("mono") }
+            tr.appendChild(td)
+        })
+        tbodyEl.appendChild(tr)
+    })
+    labelEl.removeAttribute("hidden");
 }